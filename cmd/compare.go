@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var compareInterval time.Duration
+
+var compareCmd = &cobra.Command{
+	Use:   "compare URL_OR_FILE URL_OR_FILE [URL_OR_FILE...]",
+	Short: "Compare copies of a media playlist side by side",
+	Long: `Compare several copies of the same media playlist, such as an origin and its
+CDN edges, in synchronized columns aligned by media sequence number.
+
+Lagging edges, missing segments and segments whose duration, size or ETag
+differ are highlighted. Live playlists are reloaded every target duration
+unless --interval is given.
+
+Examples:
+  pantui compare https://origin.example.com/live/720p.m3u8 https://cdn-a.example.com/live/720p.m3u8
+  pantui compare --interval 2s origin.m3u8 edge-a.m3u8 edge-b.m3u8`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := newApp()
+		if err != nil {
+			return err
+		}
+		return app.RunCompare(args, compareInterval)
+	},
+}
+
+func init() {
+	compareCmd.Flags().DurationVar(&compareInterval, "interval", 0, "Reload interval for live playlists (default: target duration)")
+
+	rootCmd.AddCommand(compareCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/analyzer"
+	"github.com/soldiermoth/pantui/internal/config"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+	"github.com/soldiermoth/pantui/internal/tui/views"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configPath string
+	profile    string
+	themeName  string
+	settings   *config.Settings
+)
+
+// loadSettings reads the config file with the selected profile and applies it to the packages it configures.
+// Flags given on the command line win over the config file.
+func loadSettings(cmd *cobra.Command) error {
+	c, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if settings, err = c.Profile(profile); err != nil {
+		return fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+
+	if themeName != "" {
+		settings.Theme = themeName
+	}
+	theme, err := loadTheme(c, settings.Theme, 0)
+	if err != nil {
+		return fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+	for name, value := range settings.Colors {
+		if err := theme.Set(name, value); err != nil {
+			return fmt.Errorf("invalid config %s: %w", configPath, err)
+		}
+	}
+	colors.Apply(theme)
+
+	hosts := make([]fetch.Host, len(settings.Hosts))
+	for i, host := range settings.Hosts {
+		hosts[i] = fetch.Host{Pattern: host.Match, Headers: host.Headers}
+		if host.Proxy != "" {
+			if hosts[i].Proxy, err = url.Parse(host.Proxy); err != nil {
+				return fmt.Errorf("invalid config %s: proxy for %s: %w", configPath, host.Match, err)
+			}
+		}
+	}
+	if len(hosts) > 0 {
+		fetch.SetHosts(hosts)
+	}
+
+	if settings.FFProbe.Path != "" {
+		analyzer.FFProbePath = settings.FFProbe.Path
+	}
+	analyzer.FFProbeArgs = settings.FFProbe.Args
+	if settings.FFPlay.Path != "" {
+		views.FFPlayPath = settings.FFPlay.Path
+	}
+	views.FFPlayArgs = settings.FFPlay.Args
+
+	flags := cmd.Flags()
+	if !flags.Changed("cache-dir") && !flags.Changed("no-cache") {
+		cacheDir = settings.Cache.Dir
+		noCache = settings.Cache.Disabled
+	}
+	if settings.Cache.MaxMemoryMB > 0 {
+		cacheMaxMemory = settings.Cache.MaxMemoryMB << 20
+	}
+	if settings.Cache.MaxDiskMB > 0 {
+		cacheMaxDisk = settings.Cache.MaxDiskMB << 20
+	}
+	if settings.Export.Query != "" && flags.Lookup("format") != nil && !flags.Changed("format") {
+		queryFormat = settings.Export.Query
+	}
+	return nil
+}
+
+// maxThemeDepth bounds the chain of custom themes based on each other, catching loops
+const maxThemeDepth = 8
+
+// loadTheme returns a built-in theme or a custom theme of the config file with the colors it changes in its base.
+// No theme is the default, which respects NO_COLOR.
+func loadTheme(c *config.Config, name string, depth int) (*colors.Theme, error) {
+	if name == "" {
+		return colors.Default(), nil
+	}
+	custom, ok := c.Themes[name]
+	if theme, builtin := colors.Builtin(name); builtin {
+		if ok {
+			return nil, fmt.Errorf("theme %q replaces a built-in theme, give it another name", name)
+		}
+		return theme, nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q (expected one of %s or a theme under themes)",
+			name, strings.Join(colors.BuiltinNames(), ", "))
+	}
+	if depth >= maxThemeDepth {
+		return nil, fmt.Errorf("theme %q is based on itself", name)
+	}
+
+	base := custom.Base
+	if base == "" {
+		base = "dark"
+	}
+	theme, err := loadTheme(c, base, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	theme.Name = name
+	for color, value := range custom.Colors {
+		if err := theme.Set(color, value); err != nil {
+			return nil, fmt.Errorf("theme %s: %w", name, err)
+		}
+	}
+	return theme, nil
+}
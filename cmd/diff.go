@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/soldiermoth/pantui/internal/diff"
+	"github.com/soldiermoth/pantui/internal/hls"
+
+	"github.com/spf13/cobra"
+)
+
+var diffContext int
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD NEW",
+	Short: "Compare two HLS manifests",
+	Long: `Compare two HLS manifests (URLs or files) and print the semantic changes
+followed by a unified line diff.
+
+Semantic changes cover playlist values, variants matched by URI, renditions
+matched by type/group/name and segments matched by media sequence.
+
+Examples:
+  pantui diff before.m3u8 after.m3u8
+  pantui diff --context 1 https://cdn-a.example.com/live.m3u8 https://cdn-b.example.com/live.m3u8`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldManifest, err := hls.NewParser().Load(args[0])
+		if err != nil {
+			return err
+		}
+		newManifest, err := hls.NewParser().Load(args[1])
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		changes := diff.Manifests(oldManifest, newManifest)
+		edits := diff.Lines(oldManifest.Content, newManifest.Content)
+		if len(changes) == 0 && len(diff.Hunks(edits, 0)) == 0 {
+			fmt.Fprintln(out, "Manifests are identical")
+			return nil
+		}
+
+		fmt.Fprintf(out, "Semantic changes (%d):\n", len(changes))
+		for _, change := range changes {
+			fmt.Fprintf(out, "  %s\n", change)
+		}
+
+		fmt.Fprintf(out, "\n--- %s\n+++ %s\n", args[0], args[1])
+		fmt.Fprint(out, diff.Unified(edits, diffContext))
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().IntVar(&diffContext, "context", 3, "Number of unchanged lines shown around each change")
+
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/query"
+
+	"github.com/spf13/cobra"
+)
+
+var queryFormat string
+
+var queryCmd = &cobra.Command{
+	Use:   "query URL_OR_FILE EXPRESSION",
+	Short: "Run a query against an HLS manifest",
+	Long: `Run a query expression against a parsed HLS manifest and print the result.
+
+A query starts with a source (segments, variants, renditions or tags) followed
+by pipeline stages: where, select, sort [by] FIELD [asc|desc], limit N and count.
+
+Examples:
+  pantui query media.m3u8 'segments | where duration > 6.1 | select sequence, uri'
+  pantui query master.m3u8 'variants | where resolution == "1920x1080"'
+  pantui query media.m3u8 'segments | where key.method != "NONE" | count'
+  pantui query --format csv media.m3u8 'segments | select sequence, duration'`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifest, err := hls.NewParser().Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := query.Run(args[1], manifest)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+
+		var output string
+		switch queryFormat {
+		case "table":
+			output = result.Table()
+		case "csv":
+			output, err = result.CSV()
+		case "json":
+			output, err = result.JSON()
+		default:
+			return fmt.Errorf("unknown format %q (expected table, csv or json)", queryFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), output)
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVar(&queryFormat, "format", "table", "Output format: table, csv or json")
+
+	rootCmd.AddCommand(queryCmd)
+}
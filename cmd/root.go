@@ -2,14 +2,27 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/soldiermoth/pantui/internal/analyzer"
+	"github.com/soldiermoth/pantui/internal/cache"
+	"github.com/soldiermoth/pantui/internal/config"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/state"
 	"github.com/soldiermoth/pantui/internal/tui"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	manifestURL string
-	filePath    string
+	manifestURL  string
+	filePath     string
+	probeTimeout time.Duration
+	cacheDir     string
+	noCache      bool
+	cacheMaxMemory int64 = cache.DefaultMaxMemory
+	cacheMaxDisk   int64 = cache.DefaultMaxDisk
+	resume       bool
+	sessionCache *cache.Cache
 	versionInfo struct {
 		version string
 		commit  string
@@ -18,18 +31,48 @@ var (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "pantui [URL_OR_FILE]",
+	Use:   "pantui [URL_OR_FILE...]",
 	Short: "A TUI for exploring HLS manifests",
 	Long: `pantui is a terminal user interface for exploring HLS (HTTP Live Streaming) manifests.
 Navigate through master manifests, media playlists, and segments with detailed FFProbe analysis.
+Several manifests open in tabs, switched with < and >.
+Bookmarks and the tabs open on exit are kept in pantui/state.json under the user config directory.
+Settings are read from pantui/config.yaml there, with named profiles selected by --profile.
+Colors follow the dark theme unless --theme or the config file picks another; NO_COLOR turns them off.
 
 Examples:
   pantui https://example.com/master.m3u8
   pantui /path/to/manifest.m3u8
+  pantui https://customer.example.com/live.m3u8 https://reference.example.com/live.m3u8
   pantui --url https://example.com/master.m3u8
-  pantui --file /path/to/manifest.m3u8`,
-	Args: cobra.MaximumNArgs(1),
+  pantui --file /path/to/manifest.m3u8
+  pantui --resume
+  pantui --profile customer-a https://customer-a.example.com/live.m3u8
+  pantui --theme light https://example.com/master.m3u8`,
+	Args: cobra.ArbitraryArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadSettings(cmd); err != nil {
+			return err
+		}
+		if noCache {
+			return nil
+		}
+		var err error
+		if sessionCache, err = cache.New(cacheDir, cacheMaxMemory, cacheMaxDisk); err != nil {
+			return fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		fetch.Cache = sessionCache
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if resume {
+			return runResume(args)
+		}
+		
+		if (manifestURL != "" || filePath != "") && len(args) > 0 {
+			return fmt.Errorf("--url and --file open one manifest, pass several manifests as arguments to open them in tabs")
+		}
+		
 		var targetURL, targetFile string
 		
 		// Handle direct argument
@@ -42,7 +85,7 @@ Examples:
 			}
 		}
 		
-		// Override with flags if provided
+		// Flags name the manifest instead of an argument, several arguments open in tabs
 		if manifestURL != "" {
 			targetURL = manifestURL
 			targetFile = ""
@@ -52,12 +95,18 @@ Examples:
 			targetURL = ""
 		}
 		
-		if targetURL == "" && targetFile == "" {
+		if targetURL == "" && targetFile == "" && len(args) < 2 {
 			return fmt.Errorf("please provide a manifest URL or file path")
 		}
 		
-		app := tui.NewApp()
+		app, err := newApp()
+		if err != nil {
+			return err
+		}
 		
+		if targetURL == "" && targetFile == "" {
+			return app.RunWithTargets(args)
+		}
 		if targetURL != "" {
 			return app.RunWithURL(targetURL)
 		} else {
@@ -66,6 +115,37 @@ Examples:
 	},
 }
 
+// runResume reopens the tabs open when pantui last exited
+func runResume(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("--resume reopens the last session and takes no manifests")
+	}
+	saved, err := state.Load(state.DefaultPath())
+	if err != nil {
+		return fmt.Errorf("failed to load the last session: %w", err)
+	}
+	if saved.Session == nil || len(saved.Session.Tabs) == 0 {
+		return fmt.Errorf("no session to resume")
+	}
+	
+	app, err := newApp()
+	if err != nil {
+		return err
+	}
+	return app.RunWithSession(saved.Session)
+}
+
+// newApp creates the TUI with the analyzer timeout, cache and keymap of the command line and config file
+func newApp() (*tui.App, error) {
+	app := tui.NewApp()
+	app.SetAnalyzerTimeout(probeTimeout)
+	app.SetCache(sessionCache)
+	if err := app.SetKeymap(settings.Keymap.Preset, settings.Keymap.Bindings); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+	return app, nil
+}
+
 // SetVersionInfo sets the version information from main package
 func SetVersionInfo(version, commit, date string) {
 	versionInfo.version = version
@@ -81,6 +161,14 @@ func Execute() error {
 func init() {
 	rootCmd.Flags().StringVarP(&manifestURL, "url", "u", "", "HLS manifest URL")
 	rootCmd.Flags().StringVarP(&filePath, "file", "f", "", "Local HLS manifest file path")
+	rootCmd.Flags().BoolVar(&resume, "resume", false, "Reopen the tabs, views and cursors of the last session")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", config.DefaultPath(), "Config file")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Config file profile to apply, such as a customer's headers and proxy")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "Color theme: dark, light, high-contrast, none or a theme of the config file (default: dark, none with NO_COLOR set)")
+	rootCmd.PersistentFlags().DurationVar(&probeTimeout, "probe-timeout", analyzer.DefaultTimeout, "Time limit for each segment analyzer run, such as ffprobe (0 for none)")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory keeping fetched data and analyzer results across sessions (default: memory only)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Fetch and analyze everything again instead of reusing earlier results")
 	
-	rootCmd.MarkFlagsMutuallyExclusive("url", "file")
+	rootCmd.MarkFlagsMutuallyExclusive("url", "file", "resume")
+	rootCmd.MarkFlagsMutuallyExclusive("cache-dir", "no-cache")
 }
@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/cache"
+	"time"
+)
+
+// DefaultTimeout bounds a single analysis, so a hung fetch inside ffprobe doesn't run forever
+const DefaultTimeout = 2 * time.Minute
+
+// ErrNotApplicable is returned by analyzers that find out only from the data that they don't apply
+var ErrNotApplicable = errors.New("analyzer does not apply to this segment")
+
+// Segment describes the segment being inspected. It is also the metadata external analyzers receive.
+type Segment struct {
+	URI           string  `json:"uri"` // As written in the playlist
+	URL           string  `json:"url"` // Resolved URL or file path
+	Sequence      int     `json:"sequence"`
+	Duration      float64 `json:"duration"`
+	ByteRange     string  `json:"byte_range,omitempty"`
+	InitURL       string  `json:"init_url,omitempty"`
+	InitByteRange string  `json:"init_byte_range,omitempty"`
+	KeyMethod     string  `json:"key_method,omitempty"`
+	Decrypted     bool    `json:"decrypted"` // Data was decrypted with the playlist's AES-128 key
+	Data          []byte  `json:"-"`         // The segment's bytes, decrypted
+}
+
+// Result is the outcome of an analysis
+type Result struct {
+	Data     interface{} // Structured result, e.g. *FFProbeOutput
+	Section  string      // Rendered with tview color tags for the segment view
+	Findings []Finding   // Issues found, shown after the section by severity
+	Cached   bool        // Section and findings came from the cache, without Data
+}
+
+// cached is what the cache keeps of a result
+type cached struct {
+	Section  string    `json:"section"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Analyzer inspects a segment and its init fragment, which is nil for segments without EXT-X-MAP
+type Analyzer interface {
+	Name() string
+	Applies(segment *Segment, init []byte) bool
+	Analyze(ctx context.Context, segment *Segment, init []byte) (*Result, error)
+}
+
+// Registry holds the analyzers in the order their sections are shown
+type Registry struct {
+	Timeout   time.Duration // Per analysis, none when zero
+	Cache     *cache.Cache  // Keeps rendered sections of successful analyses, optional
+	analyzers []Analyzer
+}
+
+// NewRegistry creates a registry with the given analyzers and the default timeout
+func NewRegistry(analyzers ...Analyzer) *Registry {
+	r := &Registry{Timeout: DefaultTimeout}
+	for _, a := range analyzers {
+		r.Register(a)
+	}
+	return r
+}
+
+// Register adds an analyzer, replacing one with the same name in place
+func (r *Registry) Register(a Analyzer) {
+	for i, existing := range r.analyzers {
+		if existing.Name() == a.Name() {
+			r.analyzers[i] = a
+			return
+		}
+	}
+	r.analyzers = append(r.analyzers, a)
+}
+
+// Analyzers lists every registered analyzer
+func (r *Registry) Analyzers() []Analyzer {
+	return append([]Analyzer(nil), r.analyzers...)
+}
+
+// Get returns the analyzer with a name, nil if there is none
+func (r *Registry) Get(name string) Analyzer {
+	for _, a := range r.analyzers {
+		if a.Name() == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// Applicable lists the analyzers that apply to a segment
+func (r *Registry) Applicable(segment *Segment, init []byte) []Analyzer {
+	var applicable []Analyzer
+	for _, a := range r.analyzers {
+		if a.Applies(segment, init) {
+			applicable = append(applicable, a)
+		}
+	}
+	return applicable
+}
+
+// Run runs an analyzer until it finishes, the context ends or the registry's timeout passes.
+// With a cache, an analysis of the same segment bytes and init fragment is reused instead of run again.
+func (r *Registry) Run(ctx context.Context, a Analyzer, segment *Segment, init []byte) (*Result, error) {
+	var key, version string
+	if r.Cache != nil {
+		key = cache.Key("analyzer", a.Name(), segment.URL, segment.ByteRange, segment.InitURL, segment.InitByteRange)
+		version = inputVersion(segment, init)
+		var kept cached
+		if entry, ok := r.Cache.Get(key); ok && entry.Version == version && json.Unmarshal(entry.Data, &kept) == nil {
+			return &Result{Section: kept.Section, Findings: kept.Findings, Cached: true}, nil
+		}
+	}
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	result, err := a.Analyze(ctx, segment, init)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s timed out after %s", a.Name(), r.Timeout)
+	}
+	if err == nil && r.Cache != nil {
+		if data, err := json.Marshal(&cached{Section: result.Section, Findings: result.Findings}); err == nil {
+			r.Cache.Put(key, &cache.Entry{Version: version, Data: data})
+		}
+	}
+	return result, err
+}
+
+// inputVersion identifies the bytes an analysis ran on, so a changed segment or init fragment is analyzed again
+func inputVersion(segment *Segment, init []byte) string {
+	return fmt.Sprintf("%x-%x", sha256.Sum256(segment.Data), sha256.Sum256(init))
+}
@@ -0,0 +1,211 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/cache"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fake is an analyzer applying to segments with a URI suffix
+type fake struct {
+	name, suffix string
+}
+
+func (f *fake) Name() string { return f.name }
+
+func (f *fake) Applies(segment *Segment, init []byte) bool {
+	return strings.HasSuffix(segment.URI, f.suffix)
+}
+
+func (f *fake) Analyze(ctx context.Context, segment *Segment, init []byte) (*Result, error) {
+	return &Result{Section: f.name + f.suffix}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(&fake{"a", ".ts"}, &fake{"b", ".m4s"}, &fake{"c", ""})
+	registry.Register(&fake{"a", ".m4s"})
+
+	if names := analyzerNames(registry.Analyzers()); names != "a,b,c" {
+		t.Errorf("Expected a replaced analyzer to keep its place, got %s", names)
+	}
+	if names := analyzerNames(registry.Applicable(&Segment{URI: "seg1.m4s"}, nil)); names != "a,b,c" {
+		t.Errorf("Expected every analyzer to apply to an fMP4 segment, got %s", names)
+	}
+	if names := analyzerNames(registry.Applicable(&Segment{URI: "seg1.ts"}, nil)); names != "c" {
+		t.Errorf("Expected only c to apply to a TS segment, got %s", names)
+	}
+	if registry.Get("b") == nil || registry.Get("d") != nil {
+		t.Errorf("Get didn't find analyzers by name")
+	}
+}
+
+func analyzerNames(analyzers []Analyzer) string {
+	names := make([]string, len(analyzers))
+	for i, a := range analyzers {
+		names[i] = a.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// writeScript writes an executable shell script into dir
+func writeScript(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExternal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts need a Unix shell")
+	}
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request.json")
+	writeScript(t, dir, "loudness.sh", `cat > `+requestFile+`
+cat "$(sed 's/.*"file":"\([^"]*\)".*/\1/' `+requestFile+`)" > `+requestFile+`.data
+echo '{"summary": "Integrated -23 LUFS", "fields": [{"name": "Peak", "value": "-1 dBTP"}], "findings": [{"severity": "warning", "message": "Too [loud]"}]}'
+`)
+	writeScript(t, dir, "skip", `cat > /dev/null; echo '{"applicable": false}'`)
+	writeScript(t, dir, "broken", `cat > /dev/null; echo oops >&2; exit 3`)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not executable"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	externals, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range externals {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "broken,loudness,skip" {
+		t.Fatalf("Expected the executables sorted by name, got %v", names)
+	}
+
+	segment := &Segment{URI: "seg1.ts", URL: "https://cdn/seg1.ts", Sequence: 7, Duration: 6, Data: []byte("payload")}
+	result, err := externals[1].Analyze(context.Background(), segment, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Integrated -23 LUFS", "Peak: -1 dBTP"} {
+		if !strings.Contains(result.Section, want) {
+			t.Errorf("Section %q doesn't contain %q", result.Section, want)
+		}
+	}
+	if strings.Contains(result.Section, "Too [loud") {
+		t.Errorf("Expected the findings to be left out of the section, got %q", result.Section)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Severity != "warning" || result.Findings[0].Message != "Too [loud]" {
+		t.Errorf("Expected the warning as a finding, got %+v", result.Findings)
+	}
+	if report := result.Data.(*Report); len(report.Findings) != 1 {
+		t.Errorf("Expected the parsed report as data, got %+v", report)
+	}
+
+	var request Request
+	data, err := os.ReadFile(requestFile)
+	if err == nil {
+		err = json.Unmarshal(data, &request)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.URL != segment.URL || request.Sequence != 7 || request.File == "" || request.InitFile != "" {
+		t.Errorf("Unexpected request %+v", request)
+	}
+	if data, _ := os.ReadFile(requestFile + ".data"); string(data) != "payload" {
+		t.Errorf("Expected the segment bytes in the request file, got %q", data)
+	}
+	if _, err := os.Stat(request.File); !os.IsNotExist(err) {
+		t.Errorf("Expected the segment file to be removed, got %v", err)
+	}
+
+	if _, err := externals[2].Analyze(context.Background(), segment, nil); err != ErrNotApplicable {
+		t.Errorf("Expected ErrNotApplicable, got %v", err)
+	}
+	if _, err := externals[0].Analyze(context.Background(), segment, nil); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("Expected the failure with stderr, got %v", err)
+	}
+}
+
+func TestDiscoverMissingDir(t *testing.T) {
+	externals, err := Discover(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(externals) != 0 {
+		t.Errorf("Expected no analyzers and no error, got %v, %v", externals, err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts need a Unix shell")
+	}
+	dir := t.TempDir()
+	writeScript(t, dir, "hang", "cat > /dev/null; sleep 30 & wait\n")
+
+	registry := NewRegistry(NewExternal(filepath.Join(dir, "hang")))
+	registry.Timeout = 200 * time.Millisecond
+	start := time.Now()
+	_, err := registry.Run(context.Background(), registry.Get("hang"), &Segment{URI: "seg1.ts"}, nil)
+	if err == nil || err.Error() != "hang timed out after 200ms" {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	// The child holding the output open must die with the script rather than run out the wait delay
+	if elapsed := time.Since(start); elapsed > waitDelay {
+		t.Errorf("Expected the process group to be killed, took %s", elapsed)
+	}
+}
+
+// counting is an analyzer counting its runs
+type counting struct {
+	runs int
+}
+
+func (c *counting) Name() string { return "counting" }
+
+func (c *counting) Applies(segment *Segment, init []byte) bool { return true }
+
+func (c *counting) Analyze(ctx context.Context, segment *Segment, init []byte) (*Result, error) {
+	c.runs++
+	return &Result{Section: fmt.Sprintf("run %d", c.runs), Findings: []Finding{{Severity: "info", Message: "counted"}}}, nil
+}
+
+func TestRunCache(t *testing.T) {
+	c, err := cache.New("", cache.DefaultMaxMemory, cache.DefaultMaxDisk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &counting{}
+	registry := NewRegistry(a)
+	registry.Cache = c
+	segment := &Segment{URL: "https://cdn/seg1.m4s", InitURL: "https://cdn/init.mp4", Data: []byte("v1")}
+
+	run := func(init, want string, cached bool) {
+		t.Helper()
+		result, err := registry.Run(context.Background(), a, segment, []byte(init))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Section != want || result.Cached != cached {
+			t.Errorf("Expected %q with cached %t, got %q with cached %t", want, cached, result.Section, result.Cached)
+		}
+		if len(result.Findings) != 1 || result.Findings[0].Message != "counted" {
+			t.Errorf("Expected the finding kept with the section, got %+v", result.Findings)
+		}
+	}
+
+	run("init", "run 1", false)
+	run("init", "run 1", true)
+	run("init v2", "run 2", false)
+	segment.Data = []byte("v2")
+	run("init v2", "run 3", false)
+	segment.ByteRange = "100@0"
+	run("init v2", "run 4", false)
+}
@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// External runs an executable as an analyzer. It receives a Request as JSON on stdin and answers with a Report
+// as JSON on stdout; a non-zero exit status is a failed analysis and stderr is shown as the error.
+type External struct {
+	name string
+	Path string
+}
+
+// Request is the JSON an external analyzer reads from stdin. The segment's (decrypted) bytes and the init
+// fragment are written to temporary files so analyzers don't need to fetch or decrypt them again.
+type Request struct {
+	*Segment
+	File     string `json:"file"`
+	InitFile string `json:"init_file,omitempty"`
+}
+
+// Report is the JSON an external analyzer writes to stdout
+type Report struct {
+	Applicable *bool           `json:"applicable,omitempty"` // False hides the analyzer for this segment
+	Summary    string          `json:"summary,omitempty"`
+	Fields     []Field         `json:"fields,omitempty"`
+	Findings   []Finding       `json:"findings,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+// Field is a labelled value in a report
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Finding is an issue an external analyzer found, with severity error, warning or info
+type Finding struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// NewExternal creates an analyzer running the executable at a path, named after the file without extension
+func NewExternal(path string) *External {
+	name := filepath.Base(path)
+	return &External{name: strings.TrimSuffix(name, filepath.Ext(name)), Path: path}
+}
+
+// DefaultDir is where external analyzers are discovered, pantui/analyzers under the user's config directory
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pantui", "analyzers")
+}
+
+// Discover lists the executables in a directory as external analyzers, sorted by name.
+// A directory that doesn't exist has none.
+func Discover(dir string) ([]*External, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var analyzers []*External
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		analyzers = append(analyzers, NewExternal(filepath.Join(dir, entry.Name())))
+	}
+	sort.Slice(analyzers, func(i, j int) bool { return analyzers[i].name < analyzers[j].name })
+	return analyzers, nil
+}
+
+// Name returns the executable's name
+func (e *External) Name() string {
+	return e.name
+}
+
+// Applies is always true, external analyzers opt out by answering "applicable": false
+func (e *External) Applies(segment *Segment, init []byte) bool {
+	return true
+}
+
+// Analyze runs the executable and renders its report
+func (e *External) Analyze(ctx context.Context, segment *Segment, init []byte) (*Result, error) {
+	request := &Request{Segment: segment}
+	var err error
+	if request.File, err = writeTemp("pantui_segment_*", segment.Data); err != nil {
+		return nil, err
+	}
+	defer removeTemp(request.File)
+	if init != nil {
+		if request.InitFile, err = writeTemp("pantui_init_*", init); err != nil {
+			return nil, err
+		}
+		defer removeTemp(request.InitFile)
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, e.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("%s failed: %v\n%s", e.Path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var report Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, fmt.Errorf("%s returned invalid JSON: %v\nOutput:\n%s", e.Path, err, stdout.String())
+	}
+	if report.Applicable != nil && !*report.Applicable {
+		return nil, ErrNotApplicable
+	}
+	return &Result{Data: &report, Section: report.Render(), Findings: report.Findings}, nil
+}
+
+// Render formats the summary and fields of the report, escaped for tview. Findings are left to the
+// views, which color them by severity.
+func (r *Report) Render() string {
+	var content strings.Builder
+	if r.Summary != "" {
+		content.WriteString(tview.Escape(r.Summary) + "\n")
+	}
+	for _, field := range r.Fields {
+		content.WriteString(fmt.Sprintf("%s: %s\n", tview.Escape(field.Name), tview.Escape(field.Value)))
+	}
+	return content.String()
+}
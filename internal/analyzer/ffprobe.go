@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// FFProbeOutput represents ffprobe JSON output
+type FFProbeOutput struct {
+	Format  FFProbeFormat   `json:"format"`
+	Streams []FFProbeStream `json:"streams"`
+}
+
+// FFProbeFormat represents format information from ffprobe
+type FFProbeFormat struct {
+	Filename       string            `json:"filename"`
+	NBStreams      int               `json:"nb_streams"`
+	NBPrograms     int               `json:"nb_programs"`
+	FormatName     string            `json:"format_name"`
+	FormatLongName string            `json:"format_long_name"`
+	StartTime      string            `json:"start_time"`
+	Duration       string            `json:"duration"`
+	Size           string            `json:"size"`
+	BitRate        string            `json:"bit_rate"`
+	ProbeScore     int               `json:"probe_score"`
+	Tags           map[string]string `json:"tags"`
+}
+
+// FFProbeStream represents stream information from ffprobe
+type FFProbeStream struct {
+	Index              int               `json:"index"`
+	CodecName          string            `json:"codec_name"`
+	CodecLongName      string            `json:"codec_long_name"`
+	Profile            string            `json:"profile"`
+	CodecType          string            `json:"codec_type"`
+	CodecTimeBase      string            `json:"codec_time_base"`
+	CodecTagString     string            `json:"codec_tag_string"`
+	CodecTag           string            `json:"codec_tag"`
+	Width              int               `json:"width"`
+	Height             int               `json:"height"`
+	CodedWidth         int               `json:"coded_width"`
+	CodedHeight        int               `json:"coded_height"`
+	HasBFrames         int               `json:"has_b_frames"`
+	SampleAspectRatio  string            `json:"sample_aspect_ratio"`
+	DisplayAspectRatio string            `json:"display_aspect_ratio"`
+	PixFmt             string            `json:"pix_fmt"`
+	Level              int               `json:"level"`
+	ColorRange         string            `json:"color_range"`
+	ColorSpace         string            `json:"color_space"`
+	ColorTransfer      string            `json:"color_transfer"`
+	ColorPrimaries     string            `json:"color_primaries"`
+	ChromaLocation     string            `json:"chroma_location"`
+	RFrameRate         string            `json:"r_frame_rate"`
+	AvgFrameRate       string            `json:"avg_frame_rate"`
+	TimeBase           string            `json:"time_base"`
+	StartPts           int               `json:"start_pts"`
+	StartTime          string            `json:"start_time"`
+	DurationTs         int64             `json:"duration_ts"`
+	Duration           string            `json:"duration"`
+	BitRate            string            `json:"bit_rate"`
+	BitsPerRawSample   string            `json:"bits_per_raw_sample"`
+	NBFrames           string            `json:"nb_frames"`
+	SampleFmt          string            `json:"sample_fmt"`
+	SampleRate         string            `json:"sample_rate"`
+	Channels           int               `json:"channels"`
+	ChannelLayout      string            `json:"channel_layout"`
+	BitsPerSample      int               `json:"bits_per_sample"`
+	Tags               map[string]string `json:"tags"`
+}
+
+// FFProbePath is the ffprobe executable, looked up on the PATH unless it is a path
+var FFProbePath = "ffprobe"
+
+// FFProbeArgs are extra arguments passed to ffprobe before the probed file
+var FFProbeArgs []string
+
+// FFProbeAvailable reports whether ffprobe is on the PATH
+func FFProbeAvailable() bool {
+	_, err := exec.LookPath(FFProbePath)
+	return err == nil
+}
+
+// ProbeSegment writes the init fragment and the segment's (decrypted) bytes to a temporary file and probes it,
+// so ffprobe sees exactly what the other analyzers see
+func ProbeSegment(ctx context.Context, segment *Segment, init []byte) (*FFProbeOutput, error) {
+	// Init fragments are not encrypted with the segment, prepend them as-is
+	data := append(append([]byte(nil), init...), segment.Data...)
+	name, err := writeTemp("pantui_segment_*"+path.Ext(strings.SplitN(segment.URI, "?", 2)[0]), data)
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(name)
+	return Probe(ctx, name)
+}
+
+// Probe executes ffprobe on a URL or file and returns parsed results
+func Probe(ctx context.Context, target string) (*FFProbeOutput, error) {
+	// ffprobe command with JSON output
+	args := append([]string{"-v", "quiet", "-print_format", "json", "-show_format", "-show_streams"}, FFProbeArgs...)
+	cmd := exec.CommandContext(ctx, FFProbePath, append(args, target)...)
+
+	// Capture both stdout and stderr
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("FFProbe execution failed\nCommand: %s %s \"%s\"\nError: %v\nOutput:\n%s", FFProbePath, strings.Join(args, " "), target, err, output.String())
+	}
+
+	var probeOutput FFProbeOutput
+	if err := json.Unmarshal(output.Bytes(), &probeOutput); err != nil {
+		return nil, fmt.Errorf("FFProbe succeeded but JSON parsing failed\nJSON Error: %v\nFFProbe Raw Output:\n%s", err, output.String())
+	}
+	return &probeOutput, nil
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// waitDelay bounds how long a killed analyzer's output pipes may stay open, e.g. held by its children
+const waitDelay = 2 * time.Second
+
+// running tracks the processes and temporary files of analyses in flight, so they can be cleaned up
+// when pantui exits without waiting for them
+var running = struct {
+	sync.Mutex
+	processes map[*os.Process]bool
+	files     map[string]bool
+}{processes: make(map[*os.Process]bool), files: make(map[string]bool)}
+
+// runCommand starts a command and waits for it, tracking its process meanwhile.
+// Commands created with exec.CommandContext are killed with their children when their context ends.
+func runCommand(cmd *exec.Cmd) error {
+	isolate(cmd)
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	running.Lock()
+	running.processes[cmd.Process] = true
+	running.Unlock()
+
+	err := cmd.Wait()
+	running.Lock()
+	delete(running.processes, cmd.Process)
+	running.Unlock()
+	return err
+}
+
+// writeTemp writes data to a new, tracked temporary file and returns its name
+func writeTemp(pattern string, data []byte) (string, error) {
+	tmpFile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %v", err)
+	}
+	running.Lock()
+	running.files[tmpFile.Name()] = true
+	running.Unlock()
+
+	_, err = tmpFile.Write(data)
+	tmpFile.Close()
+	if err != nil {
+		removeTemp(tmpFile.Name())
+		return "", fmt.Errorf("failed to write temp file: %v", err)
+	}
+	return tmpFile.Name(), nil
+}
+
+// removeTemp removes a temporary file created by writeTemp
+func removeTemp(name string) {
+	running.Lock()
+	delete(running.files, name)
+	running.Unlock()
+	os.Remove(name)
+}
+
+// Shutdown kills the analyzer processes still running and removes their temporary files
+func Shutdown() {
+	running.Lock()
+	defer running.Unlock()
+	for process := range running.processes {
+		kill(process)
+	}
+	for name := range running.files {
+		os.Remove(name)
+	}
+	running.processes = make(map[*os.Process]bool)
+	running.files = make(map[string]bool)
+}
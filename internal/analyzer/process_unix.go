@@ -0,0 +1,22 @@
+//go:build !windows
+
+package analyzer
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// isolate starts the command in its own process group, so cancelling it also kills the processes it spawned
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return kill(cmd.Process)
+	}
+}
+
+// kill kills a process started by isolate and its process group
+func kill(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package analyzer
+
+import (
+	"os"
+	"os/exec"
+)
+
+// isolate leaves the command as is, Windows has no process groups to kill at once
+func isolate(cmd *exec.Cmd) {}
+
+// kill kills a process
+func kill(process *os.Process) error {
+	return process.Kill()
+}
@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxMemory bounds the bytes kept in memory before the least recently used entries are dropped
+const DefaultMaxMemory = 512 << 20
+
+// DefaultMaxDisk bounds the bytes kept in the cache directory before the least recently used files are removed
+const DefaultMaxDisk = 2 << 30
+
+// Entry is a cached response or analysis
+type Entry struct {
+	ETag         string // Validators of the HTTP response the data came from
+	LastModified string
+	Version      string // Identifies the input a derived entry, such as an analysis, was computed from
+	Data         []byte // Shared with every reader, which must not modify it
+}
+
+// stored is an entry as written to the cache directory
+type stored struct {
+	Key   string
+	Entry Entry
+}
+
+// Cache keeps entries in memory for the session and, with a directory, on disk across sessions.
+// Both are bounded, dropping the least recently used entries. Disk errors only cost cache hits, so they are ignored.
+type Cache struct {
+	mu        sync.Mutex
+	dir       string
+	maxMemory int64
+	size      int64
+	maxDisk   int64
+	diskSize  int64 // Bytes in the cache directory, counted when it was last pruned plus those written since
+	entries   map[string]*list.Element
+	order     *list.List // Most recently used first
+}
+
+// item is an entry in the recency list
+type item struct {
+	key   string
+	entry *Entry
+}
+
+// New creates a cache keeping up to maxMemory bytes in memory and, if dir isn't empty, up to maxDisk bytes
+// on disk. Files left over the disk limit by earlier sessions are pruned right away.
+func New(dir string, maxMemory, maxDisk int64) (*Cache, error) {
+	c := &Cache{
+		dir:       dir,
+		maxMemory: maxMemory,
+		maxDisk:   maxDisk,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		c.prune()
+	}
+	return c, nil
+}
+
+// Key joins the parts identifying an entry, such as the resolved URL, byte range and init fragment URI
+func Key(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// Dir returns the cache directory, empty for a memory-only cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns the entry for a key, loading it from disk if it isn't in memory
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*item).entry, true
+	}
+
+	entry := c.load(key)
+	if entry == nil {
+		return nil, false
+	}
+	c.remember(key, entry)
+	return entry, true
+}
+
+// Put stores an entry, replacing the one with the same key
+func (c *Cache) Put(key string, entry *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remember(key, entry)
+	c.save(key, entry)
+}
+
+// Delete removes the entry for a key
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forget(key)
+	if c.dir == "" {
+		return
+	}
+	if info, err := os.Stat(c.path(key)); err == nil && os.Remove(c.path(key)) == nil {
+		c.diskSize -= info.Size()
+	}
+}
+
+// remember keeps an entry in memory, dropping the least recently used entries over the limit
+func (c *Cache) remember(key string, entry *Entry) {
+	c.forget(key)
+	if int64(len(entry.Data)) > c.maxMemory {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&item{key: key, entry: entry})
+	c.size += int64(len(entry.Data))
+	for c.size > c.maxMemory {
+		c.forget(c.order.Back().Value.(*item).key)
+	}
+}
+
+// forget drops an entry from memory
+func (c *Cache) forget(key string) {
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(element)
+	delete(c.entries, key)
+	c.size -= int64(len(element.Value.(*item).entry.Data))
+}
+
+// path is the file an entry is stored in, named by the hash of its key
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// load reads an entry from the cache directory
+func (c *Cache) load(key string) *Entry {
+	if c.dir == "" {
+		return nil
+	}
+	file, err := os.Open(c.path(key))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var s stored
+	if err := gob.NewDecoder(file).Decode(&s); err != nil || s.Key != key {
+		return nil
+	}
+
+	// The modification time records the last use for pruning
+	now := time.Now()
+	os.Chtimes(file.Name(), now, now)
+	return &s.Entry
+}
+
+// save writes an entry to the cache directory, replacing the file atomically
+func (c *Cache) save(key string, entry *Entry) {
+	if c.dir == "" {
+		return
+	}
+	file, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	err = gob.NewEncoder(file).Encode(&stored{Key: key, Entry: *entry})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	var info os.FileInfo
+	if err == nil {
+		info, err = os.Stat(file.Name())
+	}
+	if err == nil && info.Size() > c.maxDisk {
+		err = fmt.Errorf("entry exceeds the disk limit")
+	}
+	// A rename over an earlier entry for the key frees that entry's file
+	var replaced int64
+	if existing, statErr := os.Stat(c.path(key)); err == nil && statErr == nil {
+		replaced = existing.Size()
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return
+	}
+
+	c.diskSize += info.Size() - replaced
+	if c.diskSize > c.maxDisk {
+		c.prune()
+	}
+}
+
+// prune removes the least recently used files of the cache directory until it is a tenth under the disk
+// limit, so it isn't pruned again on every write. Files are ordered by modification time, which loading
+// updates, so the entries of other sessions sharing the directory are weighed the same way.
+func (c *Cache) prune() {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var infos []os.FileInfo
+	c.diskSize = 0
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		infos = append(infos, info)
+		c.diskSize += info.Size()
+	}
+	if c.diskSize <= c.maxDisk {
+		return
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+	target := c.maxDisk - c.maxDisk/10
+	for _, info := range infos {
+		if c.diskSize <= target {
+			break
+		}
+		if os.Remove(filepath.Join(c.dir, info.Name())) == nil {
+			c.diskSize -= info.Size()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	c, err := New("", 10, DefaultMaxDisk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("a", &Entry{Data: []byte("aaaa")})
+	c.Put("b", &Entry{Data: []byte("bbbb")})
+	c.Get("a") // Makes b the least recently used
+	c.Put("c", &Entry{Data: []byte("cccc")})
+	c.Put("huge", &Entry{Data: make([]byte, 11)})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "huge": false} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Expected %s cached to be %t", key, want)
+		}
+	}
+	if c.size != 8 {
+		t.Errorf("Expected 8 bytes in memory, got %d", c.size)
+	}
+
+	c.Put("a", &Entry{Data: []byte("a")})
+	c.Delete("c")
+	if c.size != 1 {
+		t.Errorf("Expected replaced and deleted entries to free their bytes, got %d", c.size)
+	}
+}
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, DefaultMaxMemory, DefaultMaxDisk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key("https://cdn.example.com/seg1.ts", "1000@0")
+	c.Put(key, &Entry{ETag: `"v1"`, Data: []byte("payload")})
+
+	// A new session finds the entry on disk
+	c, err = New(dir, DefaultMaxMemory, DefaultMaxDisk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := c.Get(key)
+	if !ok || entry.ETag != `"v1"` || string(entry.Data) != "payload" {
+		t.Fatalf("Expected the entry from disk, got %+v, %t", entry, ok)
+	}
+	if _, ok := c.Get(Key("https://cdn.example.com/seg1.ts", "1000@1000")); ok {
+		t.Errorf("Expected another byte range to miss")
+	}
+
+	c.Delete(key)
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected Delete to remove the file, found %d", len(files))
+	}
+}
+
+func TestDiskLimit(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, DefaultMaxMemory, DefaultMaxDisk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("a", &Entry{Data: make([]byte, 1000)})
+	info, err := os.Stat(c.path("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := info.Size()
+
+	// Room for two and a half entries, used in the order b, a
+	c, err = New(dir, DefaultMaxMemory, 2*size+size/2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("b", &Entry{Data: make([]byte, 1000)})
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(c.path("a"), old, old)
+	os.Chtimes(c.path("b"), old.Add(-time.Minute), old.Add(-time.Minute))
+	fresh, _ := New(dir, DefaultMaxMemory, 2*size+size/2)
+	fresh.Get("a")
+
+	c.Put("c", &Entry{Data: make([]byte, 1000)})
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, err := os.Stat(c.path(key)); (err == nil) != want {
+			t.Errorf("Expected %s on disk to be %t", key, want)
+		}
+	}
+
+	// A session with a smaller limit prunes what earlier ones left
+	os.Chtimes(c.path("a"), old, old)
+	if _, err := New(dir, DefaultMaxMemory, size+size/2); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"a": false, "c": true} {
+		if _, err := os.Stat(c.path(key)); (err == nil) != want {
+			t.Errorf("Expected %s on disk to be %t after pruning", key, want)
+		}
+	}
+
+	// Replacing an entry counts only the new file
+	before := c.diskSize
+	c.Put("c", &Entry{Data: make([]byte, 1000)})
+	if c.diskSize != before {
+		t.Errorf("Expected replacing an entry to keep the disk size at %d, got %d", before, c.diskSize)
+	}
+
+	// Entries over the limit aren't written at all
+	c.Put("huge", &Entry{Data: make([]byte, 4*size)})
+	if _, err := os.Stat(c.path("huge")); err == nil {
+		t.Error("Expected an entry over the disk limit not to be written")
+	}
+}
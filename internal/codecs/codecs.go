@@ -0,0 +1,198 @@
+package codecs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Kinds of media a codec carries
+const (
+	Video     = "video"
+	Audio     = "audio"
+	Subtitles = "subtitles"
+	Unknown   = "unknown"
+)
+
+// kinds maps sample entry codes to the media they carry
+var kinds = map[string]string{
+	"avc1": Video, "avc2": Video, "avc3": Video, "avc4": Video,
+	"hvc1": Video, "hev1": Video, "dvh1": Video, "dvhe": Video,
+	"av01": Video, "vp08": Video, "vp09": Video,
+	"mp4a": Audio, "ac-3": Audio, "ec-3": Audio, "ac-4": Audio,
+	"opus": Audio, "Opus": Audio, "fLaC": Audio, "alac": Audio,
+	"stpp": Subtitles, "wvtt": Subtitles,
+}
+
+// families groups sample entries that carry the same bitstream but signal parameter sets differently
+var families = map[string]string{
+	"avc1": "avc", "avc2": "avc", "avc3": "avc", "avc4": "avc",
+	"hvc1": "hevc", "hev1": "hevc",
+	"dvh1": "dolby-vision", "dvhe": "dolby-vision",
+}
+
+// Codec is one entry of a CODECS attribute, e.g. avc1.64001f
+type Codec struct {
+	Value  string
+	Entry  string   // Sample entry code, e.g. avc1
+	Fields []string // Dot separated parameters after the entry code
+}
+
+// Parse splits a codec string into its sample entry and parameters
+func Parse(value string) Codec {
+	parts := strings.Split(strings.TrimSpace(value), ".")
+	return Codec{Value: strings.TrimSpace(value), Entry: parts[0], Fields: parts[1:]}
+}
+
+// Split parses every codec of a CODECS attribute value
+func Split(attribute string) []Codec {
+	var codecs []Codec
+	for _, value := range strings.Split(attribute, ",") {
+		if strings.TrimSpace(value) != "" {
+			codecs = append(codecs, Parse(value))
+		}
+	}
+	return codecs
+}
+
+// Kind is the media the codec carries: video, audio, subtitles or unknown
+func (c Codec) Kind() string {
+	if kind, ok := kinds[c.Entry]; ok {
+		return kind
+	}
+	return Unknown
+}
+
+// Family groups sample entries of the same codec, e.g. avc1 and avc3
+func (c Codec) Family() string {
+	if family, ok := families[c.Entry]; ok {
+		return family
+	}
+	return strings.ToLower(c.Entry)
+}
+
+// AVC returns the profile, constraint flags and level of an avc1/avc3 codec
+func (c Codec) AVC() (profile, constraints, level byte, ok bool) {
+	if c.Family() != "avc" || len(c.Fields) == 0 || len(c.Fields[0]) != 6 {
+		return 0, 0, 0, false
+	}
+	value, err := strconv.ParseUint(c.Fields[0], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return byte(value >> 16), byte(value >> 8), byte(value), true
+}
+
+// AudioObjectType returns the MPEG-4 audio object type of an mp4a.40.x codec
+func (c Codec) AudioObjectType() (int, bool) {
+	if c.Entry != "mp4a" || len(c.Fields) < 2 || !strings.EqualFold(c.Fields[0], "40") {
+		return 0, false
+	}
+	objectType, err := strconv.Atoi(c.Fields[1])
+	return objectType, err == nil
+}
+
+// avcProfiles names H.264 profile_idc values
+var avcProfiles = map[byte]string{
+	66: "Baseline", 77: "Main", 88: "Extended", 100: "High",
+	110: "High 10", 122: "High 4:2:2", 244: "High 4:4:4",
+}
+
+// hevcProfiles names HEVC general_profile_idc values
+var hevcProfiles = map[string]string{
+	"1": "Main", "2": "Main 10", "3": "Main Still Picture", "4": "Range Extensions",
+}
+
+// audioObjectTypes names MPEG-4 audio object types
+var audioObjectTypes = map[int]string{
+	2: "AAC-LC", 5: "HE-AAC", 29: "HE-AACv2", 23: "AAC-LD", 39: "AAC-ELD", 34: "MP3",
+}
+
+// Describe names the codec with its profile and level, e.g. "H.264 High@4.0"
+func (c Codec) Describe() string {
+	switch c.Family() {
+	case "avc":
+		profile, constraints, level, ok := c.AVC()
+		if !ok {
+			return "H.264"
+		}
+		name, known := avcProfiles[profile]
+		if !known {
+			name = fmt.Sprintf("profile %d", profile)
+		}
+		if profile == 66 && constraints&0x40 != 0 {
+			name = "Constrained Baseline"
+		}
+		return fmt.Sprintf("H.264 %s@%d.%d", name, level/10, level%10)
+	case "hevc":
+		if len(c.Fields) < 3 {
+			return "HEVC"
+		}
+		profile := strings.TrimLeft(c.Fields[0], "ABC")
+		name, known := hevcProfiles[profile]
+		if !known {
+			name = "profile " + profile
+		}
+		tierLevel := c.Fields[2]
+		tier := "Main Tier"
+		if strings.HasPrefix(tierLevel, "H") {
+			tier = "High Tier"
+		}
+		level, err := strconv.Atoi(strings.TrimLeft(tierLevel, "LH"))
+		if err != nil {
+			return "HEVC " + name
+		}
+		return fmt.Sprintf("HEVC %s@%.1f %s", name, float64(level)/30, tier)
+	case "mp4a":
+		if objectType, ok := c.AudioObjectType(); ok {
+			if name, known := audioObjectTypes[objectType]; known {
+				return name
+			}
+			return fmt.Sprintf("MPEG-4 audio object type %d", objectType)
+		}
+		return "MPEG-4 Audio"
+	case "ac-3":
+		return "Dolby Digital"
+	case "ec-3":
+		return "Dolby Digital Plus"
+	case "av01":
+		return "AV1"
+	}
+	return c.Entry
+}
+
+// AVCString formats an H.264 codec string from the SPS profile, constraint flags and level
+func AVCString(entry string, profile, constraints, level byte) string {
+	return fmt.Sprintf("%s.%02x%02x%02x", entry, profile, constraints, level)
+}
+
+// HEVCString formats an HEVC codec string from the 12 byte general profile_tier_level,
+// as ISO/IEC 14496-15 Annex E describes. The same bytes start hvcC after its version.
+func HEVCString(entry string, ptl []byte) string {
+	if len(ptl) < 12 {
+		return entry
+	}
+	profileSpace := ptl[0] >> 6
+	tier := "L"
+	if ptl[0]&0x20 != 0 {
+		tier = "H"
+	}
+	profile := ptl[0] & 0x1f
+	compatibility := bits.Reverse32(binary.BigEndian.Uint32(ptl[1:5]))
+	level := ptl[11]
+
+	codec := fmt.Sprintf("%s.%s%d.%x.%s%d", entry, []string{"", "A", "B", "C"}[profileSpace], profile, compatibility, tier, level)
+
+	// Constraint flags, trailing zero bytes omitted
+	constraints := ptl[5:11]
+	end := len(constraints)
+	for end > 0 && constraints[end-1] == 0 {
+		end--
+	}
+	for _, constraint := range constraints[:end] {
+		codec += fmt.Sprintf(".%X", constraint)
+	}
+	return codec
+}
@@ -0,0 +1,69 @@
+package codecs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := map[string]string{
+		"avc1.64001f":      "H.264 High@3.1",
+		"avc1.42e01e":      "H.264 Constrained Baseline@3.0",
+		"avc3.4d4028":      "H.264 Main@4.0",
+		"hvc1.2.4.L153.B0": "HEVC Main 10@5.1 Main Tier",
+		"mp4a.40.2":        "AAC-LC",
+		"mp4a.40.5":        "HE-AAC",
+		"ec-3":             "Dolby Digital Plus",
+	}
+	for value, want := range tests {
+		if got := Parse(value).Describe(); got != want {
+			t.Errorf("Describe(%s) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	codecs := Split("avc1.64001f, mp4a.40.2,")
+	if len(codecs) != 2 || codecs[1].Value != "mp4a.40.2" || codecs[1].Kind() != Audio {
+		t.Errorf("Unexpected split: %+v", codecs)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared string
+		actual   string
+		adts     bool
+		want     []string // Severity and a fragment of each finding's message
+	}{
+		{"match", "avc1.64001f,mp4a.40.2", "avc1.64001f,mp4a.40.2", false, nil},
+		{"profile and level", "avc1.4d401f,mp4a.40.2", "avc1.640028,mp4a.40.2", false,
+			[]string{"error: CODECS declares avc1.4d401f (H.264 Main@3.1) but the media is avc1.640028 (H.264 High@4.0)"}},
+		{"level overstated", "avc1.640028", "avc1.64001f", false, []string{"warning: CODECS declares avc1.640028"}},
+		{"missing audio", "avc1.64001f", "avc1.64001f,mp4a.40.2", false,
+			[]string{"error: Media has audio mp4a.40.2 but CODECS declares no audio codec"}},
+		{"missing stream", "avc1.64001f,mp4a.40.2,ec-3", "avc1.64001f,mp4a.40.2", false,
+			[]string{"warning: CODECS declares ec-3"}},
+		{"wrong audio", "avc1.64001f,mp4a.40.2", "avc1.64001f,ac-3", false,
+			[]string{"error: Media has ac-3", "warning: CODECS declares mp4a.40.2"}},
+		{"implicit HE-AAC", "mp4a.40.5", "mp4a.40.2", true, []string{"info: "}},
+		{"HE-AAC in fMP4", "mp4a.40.5", "mp4a.40.2", false, []string{"error: "}},
+		{"sample entry", "hvc1.1.6.L93.B0", "hev1.1.6.L93.B0", false, []string{"warning: CODECS declares sample entry hvc1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings := Compare(Split(test.declared), Split(test.actual), test.adts)
+			if len(findings) != len(test.want) {
+				t.Fatalf("Expected %d findings, got %+v", len(test.want), findings)
+			}
+			for i, want := range test.want {
+				got := findings[i].Severity + ": " + findings[i].Message
+				if !strings.Contains(got, want) {
+					t.Errorf("Finding %d = %q, want it to contain %q", i, got, want)
+				}
+			}
+		})
+	}
+}
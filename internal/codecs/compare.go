@@ -0,0 +1,134 @@
+package codecs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severities of a finding
+const (
+	Error   = "error"
+	Warning = "warning"
+	Info    = "info"
+)
+
+// Finding is a difference between declared and actual codecs
+type Finding struct {
+	Severity string
+	Message  string
+}
+
+// Compare checks the codecs declared in a CODECS attribute against the codecs found in the media.
+// Only video and audio are compared; adts reports that audio came from ADTS headers, which can't signal HE-AAC.
+func Compare(declared, actual []Codec, adts bool) []Finding {
+	var findings []Finding
+	for _, kind := range []string{Video, Audio} {
+		findings = append(findings, compareKind(kind, ofKind(declared, kind), ofKind(actual, kind), adts)...)
+	}
+	return findings
+}
+
+// ofKind filters codecs by kind
+func ofKind(list []Codec, kind string) []Codec {
+	var filtered []Codec
+	for _, codec := range list {
+		if codec.Kind() == kind {
+			filtered = append(filtered, codec)
+		}
+	}
+	return filtered
+}
+
+// compareKind pairs declared and actual codecs of one kind by family
+func compareKind(kind string, declared, actual []Codec, adts bool) []Finding {
+	var findings []Finding
+	if len(declared) == 0 && len(actual) > 0 {
+		return append(findings, Finding{Error, fmt.Sprintf("Media has %s %s but CODECS declares no %s codec", kind, values(actual), kind)})
+	}
+	if len(declared) > 0 && len(actual) == 0 {
+		return append(findings, Finding{Error, fmt.Sprintf("CODECS declares %s but no %s stream was found", values(declared), kind)})
+	}
+
+	matched := make([]bool, len(declared))
+	for _, found := range actual {
+		index := -1
+		for i, candidate := range declared {
+			if !matched[i] && candidate.Family() == found.Family() {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			findings = append(findings, Finding{Error, fmt.Sprintf("Media has %s (%s) which CODECS does not declare (declared %s)",
+				found.Value, found.Describe(), values(declared))})
+			continue
+		}
+		matched[index] = true
+		findings = append(findings, compareCodec(declared[index], found, adts)...)
+	}
+
+	for i, candidate := range declared {
+		if !matched[i] {
+			findings = append(findings, Finding{Warning, fmt.Sprintf("CODECS declares %s (%s) but the media has no such stream",
+				candidate.Value, candidate.Describe())})
+		}
+	}
+	return findings
+}
+
+// compareCodec compares a declared codec with the actual one of the same family
+func compareCodec(declared, actual Codec, adts bool) []Finding {
+	var findings []Finding
+	if declared.Entry != actual.Entry {
+		findings = append(findings, Finding{Warning, fmt.Sprintf("CODECS declares sample entry %s but the media uses %s",
+			declared.Entry, actual.Entry)})
+	}
+
+	mismatch := func(severity string) Finding {
+		return Finding{severity, fmt.Sprintf("CODECS declares %s (%s) but the media is %s (%s)",
+			declared.Value, declared.Describe(), actual.Value, actual.Describe())}
+	}
+
+	switch declared.Family() {
+	case "avc":
+		declaredProfile, declaredConstraints, declaredLevel, ok := declared.AVC()
+		actualProfile, actualConstraints, actualLevel, actualOK := actual.AVC()
+		if !ok || !actualOK {
+			break
+		}
+		switch {
+		case declaredProfile != actualProfile || declaredLevel < actualLevel:
+			findings = append(findings, mismatch(Error))
+		case declaredLevel > actualLevel || declaredConstraints != actualConstraints:
+			findings = append(findings, mismatch(Warning))
+		}
+	case "mp4a":
+		declaredType, ok := declared.AudioObjectType()
+		actualType, actualOK := actual.AudioObjectType()
+		if !ok || !actualOK || declaredType == actualType {
+			break
+		}
+		// ADTS headers carry AAC-LC for HE-AAC, whose SBR is only signalled implicitly
+		if adts && actualType == 2 && (declaredType == 5 || declaredType == 29) {
+			findings = append(findings, Finding{Info, fmt.Sprintf("CODECS declares %s (%s); ADTS headers show %s, which is expected for implicitly signalled HE-AAC",
+				declared.Value, declared.Describe(), actual.Value)})
+			break
+		}
+		findings = append(findings, mismatch(Error))
+	default:
+		// The sample entry was compared above, only the parameters are left
+		if len(actual.Fields) > 0 && !strings.EqualFold(strings.Join(declared.Fields, "."), strings.Join(actual.Fields, ".")) {
+			findings = append(findings, mismatch(Warning))
+		}
+	}
+	return findings
+}
+
+// values joins codec strings for messages
+func values(list []Codec) string {
+	var parts []string
+	for _, codec := range list {
+		parts = append(parts, codec.Value)
+	}
+	return strings.Join(parts, ",")
+}
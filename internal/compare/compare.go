@@ -0,0 +1,356 @@
+package compare
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is the reload interval used when no target duration is known
+const DefaultInterval = 6 * time.Second
+
+// Origin is one copy of the stream, e.g. the origin or a CDN edge
+type Origin struct {
+	URL       string
+	Manifest  *hls.Manifest
+	Err       error
+	FetchedAt time.Time
+}
+
+// FirstSequence returns the first media sequence in the playlist, or -1
+func (o *Origin) FirstSequence() int {
+	if o.Manifest == nil || len(o.Manifest.Segments) == 0 {
+		return -1
+	}
+	return o.Manifest.Segments[0].Sequence
+}
+
+// LastSequence returns the last media sequence in the playlist, or -1
+func (o *Origin) LastSequence() int {
+	if o.Manifest == nil || len(o.Manifest.Segments) == 0 {
+		return -1
+	}
+	return o.Manifest.Segments[len(o.Manifest.Segments)-1].Sequence
+}
+
+// CellState describes a segment's presence on one origin
+type CellState int
+
+const (
+	Present     CellState = iota
+	Missing               // inside the origin's window but absent
+	Lagging               // newer than the origin's last segment
+	Expired               // older than the origin's first segment
+	Unavailable           // the origin's playlist could not be loaded
+)
+
+// String returns a short label for the state
+func (s CellState) String() string {
+	switch s {
+	case Present:
+		return "present"
+	case Missing:
+		return "missing"
+	case Lagging:
+		return "lagging"
+	case Expired:
+		return "expired"
+	default:
+		return "unavailable"
+	}
+}
+
+// Probe holds response metadata for a segment
+type Probe struct {
+	Size int64
+	ETag string
+	Err  error
+}
+
+// Cell is one origin's view of a media sequence number
+type Cell struct {
+	State   CellState
+	Segment *hls.Segment
+	URL     string
+	Probe   *Probe
+}
+
+// Row aligns every origin on one media sequence number
+type Row struct {
+	Sequence int
+	Cells    []Cell
+}
+
+// Differences lists the ways present cells disagree with each other
+func (r Row) Differences() []string {
+	var differences []string
+	var first *Cell
+	for i := range r.Cells {
+		cell := &r.Cells[i]
+		if cell.State != Present {
+			continue
+		}
+		if first == nil {
+			first = cell
+			continue
+		}
+		if cell.Segment.Duration != first.Segment.Duration {
+			differences = appendOnce(differences, "duration")
+		}
+		if path.Base(cell.Segment.URI) != path.Base(first.Segment.URI) {
+			differences = appendOnce(differences, "uri")
+		}
+		if cell.Probe != nil && first.Probe != nil && cell.Probe.Err == nil && first.Probe.Err == nil {
+			if cell.Probe.Size > 0 && first.Probe.Size > 0 && cell.Probe.Size != first.Probe.Size {
+				differences = appendOnce(differences, "size")
+			}
+			if cell.Probe.ETag != "" && first.Probe.ETag != "" && cell.Probe.ETag != first.Probe.ETag {
+				differences = appendOnce(differences, "etag")
+			}
+		}
+	}
+	return differences
+}
+
+// appendOnce appends a value unless it is already present
+func appendOnce(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Snapshot is the aligned state of every origin at one point in time
+type Snapshot struct {
+	Origins []*Origin
+	Rows    []Row
+	Latest  int // highest media sequence seen on any origin
+}
+
+// Lag returns how many segments and how much media an origin is behind the newest origin
+func (s *Snapshot) Lag(index int) (int, time.Duration) {
+	origin := s.Origins[index]
+	last := origin.LastSequence()
+	if last < 0 || last >= s.Latest {
+		return 0, 0
+	}
+
+	var behind time.Duration
+	for _, row := range s.Rows {
+		if row.Sequence <= last {
+			continue
+		}
+		for _, cell := range row.Cells {
+			if cell.State == Present {
+				behind += time.Duration(cell.Segment.Duration * float64(time.Second))
+				break
+			}
+		}
+	}
+	return s.Latest - last, behind
+}
+
+// Comparison tracks several origins of the same stream
+type Comparison struct {
+	URLs     []string
+	Interval time.Duration // 0 derives the interval from the target duration
+	Current  *Snapshot
+}
+
+// New creates a comparison of the given URLs or files
+func New(urls []string, interval time.Duration) *Comparison {
+	return &Comparison{URLs: urls, Interval: interval}
+}
+
+// ReloadInterval returns how often the playlists should be reloaded, or 0 when every playlist has ended
+func (c *Comparison) ReloadInterval() time.Duration {
+	if c.Current == nil {
+		return c.interval(0)
+	}
+
+	targetDuration := 0
+	live := false
+	for _, origin := range c.Current.Origins {
+		if origin.Manifest == nil {
+			live = true
+			continue
+		}
+		if !origin.Manifest.Ended() {
+			live = true
+		}
+		if origin.Manifest.TargetDuration > targetDuration {
+			targetDuration = origin.Manifest.TargetDuration
+		}
+	}
+	if !live {
+		return 0
+	}
+	return c.interval(targetDuration)
+}
+
+// interval applies the configured interval or falls back to the target duration
+func (c *Comparison) interval(targetDuration int) time.Duration {
+	if c.Interval > 0 {
+		return c.Interval
+	}
+	if targetDuration > 0 {
+		return time.Duration(targetDuration) * time.Second
+	}
+	return DefaultInterval
+}
+
+// Load fetches every origin concurrently and aligns the results.
+// Probes from the current snapshot carry over when the segment URL is unchanged.
+func (c *Comparison) Load() *Snapshot {
+	origins := make([]*Origin, len(c.URLs))
+	var wg sync.WaitGroup
+	for i, target := range c.URLs {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			manifest, err := load(target)
+			origins[i] = &Origin{URL: target, Manifest: manifest, Err: err, FetchedAt: time.Now()}
+		}(i, target)
+	}
+	wg.Wait()
+
+	snapshot := Align(origins)
+	if c.Current != nil {
+		carryProbes(c.Current, snapshot)
+	}
+	return snapshot
+}
+
+// load parses a playlist from a URL or a local file
+func load(target string) (*hls.Manifest, error) {
+	manifest, err := hls.NewParser().Load(target)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Type != hls.MediaManifest {
+		return nil, fmt.Errorf("%s is a %s playlist, compare expects media playlists", target, manifest.Type)
+	}
+	return manifest, nil
+}
+
+// Align lines up the segments of every origin by media sequence number, with a row for each sequence
+// some origin has. Origins whose sequence numbers diverged don't fill the span between them with rows.
+func Align(origins []*Origin) *Snapshot {
+	snapshot := &Snapshot{Origins: origins, Latest: -1}
+
+	seen := make(map[int]bool)
+	bySequence := make([]map[int]*hls.Segment, len(origins))
+	for i, origin := range origins {
+		bySequence[i] = make(map[int]*hls.Segment)
+		if origin.Manifest == nil {
+			continue
+		}
+		for j := range origin.Manifest.Segments {
+			segment := &origin.Manifest.Segments[j]
+			bySequence[i][segment.Sequence] = segment
+			seen[segment.Sequence] = true
+			if segment.Sequence > snapshot.Latest {
+				snapshot.Latest = segment.Sequence
+			}
+		}
+	}
+
+	sequences := make([]int, 0, len(seen))
+	for sequence := range seen {
+		sequences = append(sequences, sequence)
+	}
+	sort.Ints(sequences)
+
+	for _, sequence := range sequences {
+		row := Row{Sequence: sequence, Cells: make([]Cell, len(origins))}
+		for i, origin := range origins {
+			row.Cells[i] = cellFor(origin, bySequence[i][sequence], sequence)
+		}
+		snapshot.Rows = append(snapshot.Rows, row)
+	}
+	return snapshot
+}
+
+// cellFor classifies a sequence number for one origin
+func cellFor(origin *Origin, segment *hls.Segment, sequence int) Cell {
+	switch {
+	case origin.Manifest == nil:
+		return Cell{State: Unavailable}
+	case segment != nil:
+		return Cell{State: Present, Segment: segment, URL: origin.Manifest.ResolveURL(segment.URI)}
+	case sequence > origin.LastSequence():
+		return Cell{State: Lagging}
+	case sequence < origin.FirstSequence():
+		return Cell{State: Expired}
+	default:
+		return Cell{State: Missing}
+	}
+}
+
+// carryProbes copies probe results for unchanged segment URLs into the next snapshot
+func carryProbes(previous, next *Snapshot) {
+	probes := make(map[string]*Probe)
+	for _, row := range previous.Rows {
+		for _, cell := range row.Cells {
+			if cell.Probe != nil && cell.URL != "" {
+				probes[cell.URL] = cell.Probe
+			}
+		}
+	}
+	for r := range next.Rows {
+		for c := range next.Rows[r].Cells {
+			cell := &next.Rows[r].Cells[c]
+			if probe, ok := probes[cell.URL]; ok {
+				cell.Probe = probe
+			}
+		}
+	}
+}
+
+// ProbeSegment fetches the size and ETag of a segment with a HEAD request, or stats a local file
+func ProbeSegment(cell Cell) *Probe {
+	if cell.State != Present {
+		return nil
+	}
+
+	var probe *Probe
+	if strings.HasPrefix(cell.URL, "http://") || strings.HasPrefix(cell.URL, "https://") {
+		probe = headProbe(cell.URL)
+	} else if info, err := os.Stat(cell.URL); err != nil {
+		probe = &Probe{Err: err}
+	} else {
+		probe = &Probe{Size: info.Size()}
+	}
+
+	// A byte range segment is only part of the resource
+	if probe.Err == nil && cell.Segment.ByteRange != "" {
+		var length int64
+		if _, err := fmt.Sscanf(cell.Segment.ByteRange, "%d", &length); err == nil {
+			probe.Size = length
+		}
+	}
+	return probe
+}
+
+// headProbe issues the HEAD request behind ProbeSegment
+func headProbe(url string) *Probe {
+	resp, err := fetch.Head(url)
+	if err != nil {
+		return &Probe{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &Probe{Err: fmt.Errorf("HTTP error: %d", resp.StatusCode)}
+	}
+	return &Probe{Size: resp.ContentLength, ETag: strings.Trim(resp.Header.Get("ETag"), `"`)}
+}
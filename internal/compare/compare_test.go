@@ -0,0 +1,120 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soldiermoth/pantui/internal/hls"
+)
+
+func TestAlign(t *testing.T) {
+	origins := []*Origin{
+		origin(t, "origin.m3u8", 100, 101, 102, 103),
+		origin(t, "edge-a.m3u8", 100, 101, 103),
+		origin(t, "edge-b.m3u8", 101, 102),
+		{URL: "edge-c.m3u8", Err: fmt.Errorf("HTTP error: 503")},
+	}
+
+	snapshot := Align(origins)
+
+	if snapshot.Latest != 103 {
+		t.Errorf("Expected latest sequence 103, got %d", snapshot.Latest)
+	}
+	if len(snapshot.Rows) != 4 {
+		t.Fatalf("Expected 4 rows, got %d", len(snapshot.Rows))
+	}
+
+	expected := map[int][]CellState{
+		100: {Present, Present, Expired, Unavailable},
+		101: {Present, Present, Present, Unavailable},
+		102: {Present, Missing, Present, Unavailable},
+		103: {Present, Present, Lagging, Unavailable},
+	}
+	for _, row := range snapshot.Rows {
+		for i, cell := range row.Cells {
+			if cell.State != expected[row.Sequence][i] {
+				t.Errorf("Sequence %d origin %d: expected %s, got %s", row.Sequence, i, expected[row.Sequence][i], cell.State)
+			}
+		}
+	}
+
+	segments, behind := snapshot.Lag(2)
+	if segments != 1 || behind != 6*time.Second {
+		t.Errorf("Expected edge-b to lag 1 segment (6s), got %d (%s)", segments, behind)
+	}
+	if segments, _ := snapshot.Lag(0); segments != 0 {
+		t.Errorf("Expected origin not to lag, got %d", segments)
+	}
+}
+
+func TestAlignDivergedSequences(t *testing.T) {
+	origins := []*Origin{
+		origin(t, "origin.m3u8", 1700000000, 1700000001),
+		origin(t, "edge-a.m3u8", 5, 6),
+	}
+
+	snapshot := Align(origins)
+
+	if len(snapshot.Rows) != 4 {
+		t.Fatalf("Expected a row for each sequence present, got %d", len(snapshot.Rows))
+	}
+	if snapshot.Rows[1].Sequence != 6 || snapshot.Rows[2].Sequence != 1700000000 {
+		t.Errorf("Expected rows in sequence order, got %d then %d", snapshot.Rows[1].Sequence, snapshot.Rows[2].Sequence)
+	}
+	if state := snapshot.Rows[0].Cells[0].State; state != Expired {
+		t.Errorf("Expected sequence 5 to be expired on the origin, got %s", state)
+	}
+}
+
+func TestRowDifferences(t *testing.T) {
+	snapshot := Align([]*Origin{
+		origin(t, "a.m3u8", 1),
+		origin(t, "b.m3u8", 1),
+	})
+	row := snapshot.Rows[0]
+	if differences := row.Differences(); len(differences) != 0 {
+		t.Errorf("Expected no differences, got %v", differences)
+	}
+
+	row.Cells[0].Probe = &Probe{Size: 1000, ETag: "abc"}
+	row.Cells[1].Probe = &Probe{Size: 1200, ETag: "abc"}
+	if differences := strings.Join(row.Differences(), ","); differences != "size" {
+		t.Errorf("Expected size difference, got %q", differences)
+	}
+}
+
+func TestReloadInterval(t *testing.T) {
+	comparison := New([]string{"a.m3u8"}, 0)
+	comparison.Current = Align([]*Origin{origin(t, "a.m3u8", 1)})
+	if interval := comparison.ReloadInterval(); interval != 6*time.Second {
+		t.Errorf("Expected target duration interval, got %s", interval)
+	}
+
+	comparison.Interval = 2 * time.Second
+	if interval := comparison.ReloadInterval(); interval != 2*time.Second {
+		t.Errorf("Expected configured interval, got %s", interval)
+	}
+}
+
+// origin builds a loaded origin holding the given media sequence numbers
+func origin(t *testing.T, name string, sequences ...int) *Origin {
+	t.Helper()
+	var content strings.Builder
+	content.WriteString("#EXTM3U\n#EXT-X-TARGETDURATION:6\n")
+	content.WriteString(fmt.Sprintf("#EXT-X-MEDIA-SEQUENCE:%d\n", sequences[0]))
+	for _, sequence := range sequences {
+		content.WriteString(fmt.Sprintf("#EXTINF:6.0,\nsegment%d.ts\n", sequence))
+	}
+
+	manifest, err := hls.ParseContent(content.String(), name)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	// Gaps are expressed by renumbering segments after parsing
+	for i := range manifest.Segments {
+		manifest.Segments[i].Sequence = sequences[i]
+	}
+	return &Origin{URL: name, Manifest: manifest}
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the config file: settings for every run, named profiles changing some of them and custom themes
+type Config struct {
+	Settings `yaml:",inline"`
+	Profiles map[string]Settings `yaml:"profiles"`
+	Themes   map[string]Theme    `yaml:"themes"`
+}
+
+// Settings are what the config file and its profiles can change
+type Settings struct {
+	Theme   string            `yaml:"theme"`  // Built-in theme, such as light, or one of the custom themes
+	Colors  map[string]string `yaml:"colors"` // Color name, such as uri, to a color name or #rrggbb value
+	Keymap  Keymap            `yaml:"keymap"`
+	Hosts   []Host            `yaml:"hosts"`
+	FFProbe Tool              `yaml:"ffprobe"`
+	FFPlay  Tool              `yaml:"ffplay"`
+	Cache   Cache             `yaml:"cache"`
+	Export  Export            `yaml:"export"`
+}
+
+// Theme is a custom theme: the colors it changes in the theme it is based on
+type Theme struct {
+	Base   string            `yaml:"base"` // Built-in or custom theme, dark when empty
+	Colors map[string]string `yaml:"colors"`
+}
+
+// Keymap binds the actions of the views to keys
+type Keymap struct {
+	Preset   string                       `yaml:"preset"`  // Keys of another program, such as vim
+	Bindings map[string]map[string]string `yaml:",inline"` // Scope, global or a view such as master, to action to keys
+}
+
+// Host sets headers and a proxy for requests to the hosts matching a pattern
+type Host struct {
+	Match   string            `yaml:"match"` // Glob matched against the host name, such as *.akamaized.net
+	Headers map[string]string `yaml:"headers"`
+	Proxy   string            `yaml:"proxy"`
+}
+
+// Tool is an external program and the extra arguments passed to it
+type Tool struct {
+	Path string   `yaml:"path"`
+	Args []string `yaml:"args"`
+}
+
+// Cache configures the cache of fetched data and analyzer results
+type Cache struct {
+	Dir         string `yaml:"dir"`
+	MaxMemoryMB int64  `yaml:"max_memory_mb"`
+	MaxDiskMB   int64  `yaml:"max_disk_mb"` // Limit of the cache directory, pruned least recently used first
+	Disabled    bool   `yaml:"disabled"`    // Disabled by the settings or the profile
+}
+
+// Export sets the default formats of exported results
+type Export struct {
+	Query string `yaml:"query"` // table, csv or json
+}
+
+// DefaultPath is the config file, pantui/config.yaml under the user's config directory
+// ($XDG_CONFIG_HOME or ~/.config on Linux)
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pantui", "config.yaml")
+}
+
+// Load reads a config file. A file that doesn't exist is an empty config, unknown fields are errors.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Profile returns the settings with a named profile applied over them, the settings alone for an empty name
+func (c *Config) Profile(name string) (*Settings, error) {
+	settings := c.Settings
+	if name != "" {
+		profile, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (config has %s)", name, c.profileNames())
+		}
+		settings = settings.merge(profile)
+	}
+
+	settings.FFProbe.Path = expandHome(settings.FFProbe.Path)
+	settings.FFPlay.Path = expandHome(settings.FFPlay.Path)
+	settings.Cache.Dir = expandHome(settings.Cache.Dir)
+	return &settings, settings.validate()
+}
+
+// merge applies a profile over the settings: its maps add to theirs, its hosts are matched after theirs,
+// and whatever else it sets replaces theirs
+func (s Settings) merge(profile Settings) Settings {
+	if profile.Theme != "" {
+		s.Theme = profile.Theme
+	}
+	s.Colors = mergeMaps(s.Colors, profile.Colors)
+	s.Keymap = s.Keymap.merge(profile.Keymap)
+	s.Hosts = append(append([]Host(nil), s.Hosts...), profile.Hosts...)
+	s.FFProbe = s.FFProbe.merge(profile.FFProbe)
+	s.FFPlay = s.FFPlay.merge(profile.FFPlay)
+	if profile.Cache.Dir != "" {
+		s.Cache.Dir = profile.Cache.Dir
+	}
+	if profile.Cache.MaxMemoryMB != 0 {
+		s.Cache.MaxMemoryMB = profile.Cache.MaxMemoryMB
+	}
+	if profile.Cache.MaxDiskMB != 0 {
+		s.Cache.MaxDiskMB = profile.Cache.MaxDiskMB
+	}
+	s.Cache.Disabled = s.Cache.Disabled || profile.Cache.Disabled
+	if profile.Export.Query != "" {
+		s.Export.Query = profile.Export.Query
+	}
+	return s
+}
+
+// merge applies the path and arguments a profile sets
+func (t Tool) merge(profile Tool) Tool {
+	if profile.Path != "" {
+		t.Path = profile.Path
+	}
+	if profile.Args != nil {
+		t.Args = profile.Args
+	}
+	return t
+}
+
+// merge applies the preset a profile sets and adds its keys to those of each scope
+func (k Keymap) merge(profile Keymap) Keymap {
+	if profile.Preset != "" {
+		k.Preset = profile.Preset
+	}
+	if len(profile.Bindings) == 0 {
+		return k
+	}
+	bindings := make(map[string]map[string]string, len(k.Bindings)+len(profile.Bindings))
+	for scope, actions := range k.Bindings {
+		bindings[scope] = actions
+	}
+	for scope, actions := range profile.Bindings {
+		bindings[scope] = mergeMaps(bindings[scope], actions)
+	}
+	k.Bindings = bindings
+	return k
+}
+
+// validate checks the settings that can be checked without the packages they configure
+func (s *Settings) validate() error {
+	for _, host := range s.Hosts {
+		if host.Match == "" {
+			return fmt.Errorf("host rule without a match pattern")
+		}
+	}
+	if s.Cache.MaxMemoryMB < 0 {
+		return fmt.Errorf("cache max_memory_mb can't be negative")
+	}
+	if s.Cache.MaxDiskMB < 0 {
+		return fmt.Errorf("cache max_disk_mb can't be negative")
+	}
+	switch s.Export.Query {
+	case "", "table", "csv", "json":
+	default:
+		return fmt.Errorf("unknown query export format %q (expected table, csv or json)", s.Export.Query)
+	}
+	return nil
+}
+
+// profileNames lists the profiles for error messages
+func (c *Config) profileNames() string {
+	if len(c.Profiles) == 0 {
+		return "no profiles"
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// mergeMaps returns the entries of both maps, the second one winning
+func mergeMaps(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+	return merged
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
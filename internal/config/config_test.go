@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `
+theme: solarized
+colors:
+  uri: lightcyan
+keymap:
+  preset: vim
+  master:
+    keyframes: F2
+hosts:
+  - match: "*.akamaized.net"
+    headers:
+      Authorization: Bearer abc
+ffprobe:
+  path: /opt/ffmpeg/bin/ffprobe
+  args: [-analyzeduration, "10M"]
+cache:
+  dir: ~/.cache/pantui
+  max_disk_mb: 1024
+export:
+  query: csv
+
+themes:
+  solarized:
+    base: light
+    colors:
+      text: "#657b83"
+
+profiles:
+  customer-a:
+    theme: high-contrast
+    keymap:
+      master:
+        diff: d
+    colors:
+      uri: yellow
+    hosts:
+      - match: "*.customer-a.com"
+        proxy: http://proxy.customer-a.com:3128
+    ffprobe:
+      args: [-probesize, "5M"]
+    cache:
+      disabled: true
+      max_disk_mb: 64
+    export:
+      query: json
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProfile(t *testing.T) {
+	c, err := Load(writeConfig(t, sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := c.Profile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, _ := os.UserHomeDir()
+	if base.Cache.Dir != filepath.Join(home, ".cache/pantui") {
+		t.Errorf("Expected ~ to be expanded, got %s", base.Cache.Dir)
+	}
+	if base.Export.Query != "csv" || base.Colors["uri"] != "lightcyan" || len(base.Hosts) != 1 || base.Theme != "solarized" {
+		t.Errorf("Unexpected settings %+v", base)
+	}
+
+	profile, err := c.Profile("customer-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Colors["uri"] != "yellow" {
+		t.Errorf("Expected the profile's colors over the settings' colors, got %v", profile.Colors)
+	}
+	if len(profile.Hosts) != 2 || profile.Hosts[1].Proxy != "http://proxy.customer-a.com:3128" {
+		t.Errorf("Expected the profile's hosts after the settings' hosts, got %+v", profile.Hosts)
+	}
+	if profile.FFProbe.Path != "/opt/ffmpeg/bin/ffprobe" || strings.Join(profile.FFProbe.Args, " ") != "-probesize 5M" {
+		t.Errorf("Expected the profile's ffprobe arguments with the settings' path, got %+v", profile.FFProbe)
+	}
+	if profile.Keymap.Preset != "vim" || profile.Keymap.Bindings["master"]["keyframes"] != "F2" || profile.Keymap.Bindings["master"]["diff"] != "d" {
+		t.Errorf("Expected the profile's keys added to the settings' keymap, got %+v", profile.Keymap)
+	}
+	if profile.Theme != "high-contrast" || c.Themes["solarized"].Base != "light" {
+		t.Errorf("Expected the profile's theme and the custom themes, got %s and %+v", profile.Theme, c.Themes)
+	}
+	if base.Cache.MaxDiskMB != 1024 {
+		t.Errorf("Expected the settings' disk limit, got %d", base.Cache.MaxDiskMB)
+	}
+	if !profile.Cache.Disabled || profile.Cache.MaxDiskMB != 64 || profile.Export.Query != "json" {
+		t.Errorf("Expected the profile's cache and export settings, got %+v %+v", profile.Cache, profile.Export)
+	}
+	if c.Settings.Colors["uri"] != "lightcyan" {
+		t.Errorf("Expected the profile to leave the settings unchanged, got %v", c.Settings.Colors)
+	}
+
+	if _, err := c.Profile("customer-b"); err == nil || !strings.Contains(err.Error(), "customer-a") {
+		t.Errorf("Expected an unknown profile error listing customer-a, got %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || len(c.Profiles) != 0 {
+		t.Errorf("Expected a missing file to be an empty config, got %+v, %v", c, err)
+	}
+
+	if _, err := Load(writeConfig(t, "colour:\n  uri: red\n")); err == nil {
+		t.Error("Expected unknown fields to be rejected")
+	}
+
+	c, err = Load(writeConfig(t, "export:\n  query: xml\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Profile(""); err == nil {
+		t.Error("Expected an unknown export format to be rejected")
+	}
+
+	c, err = Load(writeConfig(t, "cache:\n  max_disk_mb: -1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Profile(""); err == nil {
+		t.Error("Expected a negative disk limit to be rejected")
+	}
+}
@@ -0,0 +1,65 @@
+package decrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// AES128 decrypts a segment encrypted with METHOD=AES-128 (AES-128-CBC with PKCS7 padding)
+func AES128(data, key, iv []byte) ([]byte, error) {
+	if len(key) != aes.BlockSize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", aes.BlockSize, len(key))
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("IV must be %d bytes, got %d", aes.BlockSize, len(iv))
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext length %d is not a multiple of the block size", len(data))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, data)
+	return unpad(plaintext)
+}
+
+// unpad removes PKCS7 padding
+func unpad(data []byte) ([]byte, error) {
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(data) {
+		return nil, fmt.Errorf("invalid PKCS7 padding, the key or IV is probably wrong")
+	}
+	if !bytes.Equal(data[len(data)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, fmt.Errorf("invalid PKCS7 padding, the key or IV is probably wrong")
+	}
+	return data[:len(data)-padding], nil
+}
+
+// IV returns the IV attribute as bytes, or derives it from the media sequence number
+// as RFC 8216 requires when the attribute is absent
+func IV(attribute string, sequence int) ([]byte, error) {
+	if attribute == "" {
+		iv := make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(iv[8:], uint64(sequence))
+		return iv, nil
+	}
+
+	value := strings.TrimPrefix(strings.TrimPrefix(attribute, "0x"), "0X")
+	iv, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IV %q: %w", attribute, err)
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid IV %q: expected %d bytes, got %d", attribute, aes.BlockSize, len(iv))
+	}
+	return iv, nil
+}
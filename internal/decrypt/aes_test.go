@@ -0,0 +1,67 @@
+package decrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAES128RoundTrip(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	iv, err := IV("", 1042)
+	if err != nil {
+		t.Fatalf("Failed to derive IV: %v", err)
+	}
+	plaintext := []byte("transport stream payload that is not block aligned")
+
+	decrypted, err := AES128(encrypt(t, plaintext, key, iv), key, iv)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, decrypted)
+	}
+
+	wrongKey := []byte("fedcba9876543210")
+	if _, err := AES128(encrypt(t, plaintext, key, iv), wrongKey, iv); err == nil {
+		t.Error("Expected an error decrypting with the wrong key")
+	}
+}
+
+func TestIV(t *testing.T) {
+	iv, err := IV("", 0x0102)
+	if err != nil {
+		t.Fatalf("Failed to derive IV: %v", err)
+	}
+	if got := hex.EncodeToString(iv); got != "00000000000000000000000000000102" {
+		t.Errorf("Expected IV derived from sequence, got %s", got)
+	}
+
+	iv, err = IV("0x000102030405060708090A0B0C0D0E0F", 7)
+	if err != nil {
+		t.Fatalf("Failed to parse IV: %v", err)
+	}
+	if got := hex.EncodeToString(iv); got != "000102030405060708090a0b0c0d0e0f" {
+		t.Errorf("Expected explicit IV, got %s", got)
+	}
+
+	if _, err := IV("0x0102", 7); err == nil {
+		t.Error("Expected an error for a short IV")
+	}
+}
+
+// encrypt pads and encrypts plaintext the way a packager would
+func encrypt(t *testing.T, plaintext, key, iv []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("Failed to create cipher: %v", err)
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+	return ciphertext
+}
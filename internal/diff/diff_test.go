@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/soldiermoth/pantui/internal/hls"
+)
+
+func TestLines(t *testing.T) {
+	oldText := "a\nb\nc\nd\n"
+	newText := "a\nc\nd\ne\n"
+
+	edits := Lines(oldText, newText)
+
+	var ops []string
+	for _, edit := range edits {
+		switch edit.Op {
+		case Equal:
+			ops = append(ops, " "+edit.Text)
+		case Insert:
+			ops = append(ops, "+"+edit.Text)
+		case Delete:
+			ops = append(ops, "-"+edit.Text)
+		}
+	}
+
+	expected := []string{" a", "-b", " c", " d", "+e"}
+	if strings.Join(ops, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected edits %v, got %v", expected, ops)
+	}
+}
+
+func TestUnifiedContext(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		oldLines = append(oldLines, line)
+		if i != 10 {
+			newLines = append(newLines, line)
+		}
+	}
+
+	unified := Unified(Lines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")), 2)
+	expected := "@@ -9,5 +9,4 @@\n i\n j\n-k\n l\n m\n"
+	if unified != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, unified)
+	}
+}
+
+func TestManifestsSegments(t *testing.T) {
+	oldManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:10
+#EXTINF:6.0,
+seg10.ts
+#EXTINF:6.0,
+seg11.ts
+#EXTINF:6.0,
+seg12.ts`
+
+	newManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:12
+#EXTINF:5.5,
+seg12.ts
+#EXT-X-KEY:METHOD=AES-128,URI="key2"
+#EXTINF:6.0,
+seg13.ts
+#EXTINF:6.0,
+seg14.ts`
+
+	changes := Manifests(parse(t, oldManifest), parse(t, newManifest))
+
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.String())
+	}
+	got := strings.Join(lines, "\n")
+
+	for _, expected := range []string{
+		"changed media sequence: 10 -> 12",
+		"removed segments 10-11",
+		"added segments 13-14",
+		"changed segment 12: duration 6.000 -> 5.500",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected change %q in:\n%s", expected, got)
+		}
+	}
+}
+
+func TestManifestsVariants(t *testing.T) {
+	oldManifest := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360
+low.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=3000000,RESOLUTION=1280x720
+mid.m3u8`
+
+	newManifest := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=900000,RESOLUTION=640x360
+low.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=6000000,RESOLUTION=1920x1080
+high.m3u8`
+
+	changes := Manifests(parse(t, oldManifest), parse(t, newManifest))
+
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.String())
+	}
+	got := strings.Join(lines, "\n")
+
+	for _, expected := range []string{
+		"removed variant mid.m3u8",
+		"added variant high.m3u8",
+		"changed variant low.m3u8: BANDWIDTH 800000 -> 900000",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected change %q in:\n%s", expected, got)
+		}
+	}
+}
+
+func parse(t *testing.T, content string) *hls.Manifest {
+	t.Helper()
+	manifest, err := hls.ParseContent(content, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	return manifest
+}
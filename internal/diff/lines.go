@@ -0,0 +1,256 @@
+// Package diff compares HLS manifests line by line and semantically.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op identifies the kind of a line edit
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// LineEdit is a single line of a line diff
+type LineEdit struct {
+	Op      Op
+	Text    string
+	OldLine int // 1-based line in the old content, 0 for inserts
+	NewLine int // 1-based line in the new content, 0 for deletes
+}
+
+// maxEditDistance bounds the Myers search; larger diffs fall back to replacing the changed region
+const maxEditDistance = 2000
+
+// Lines computes a minimal line diff between two texts
+func Lines(oldText, newText string) []LineEdit {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// Trim the common prefix and suffix, which is most of a live playlist
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []LineEdit
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, LineEdit{Op: Equal, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	middle := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, edit := range middle {
+		if edit.OldLine > 0 {
+			edit.OldLine += prefix
+		}
+		if edit.NewLine > 0 {
+			edit.NewLine += prefix
+		}
+		edits = append(edits, edit)
+	}
+
+	for i := 0; i < suffix; i++ {
+		oldIdx := len(a) - suffix + i
+		newIdx := len(b) - suffix + i
+		edits = append(edits, LineEdit{Op: Equal, Text: a[oldIdx], OldLine: oldIdx + 1, NewLine: newIdx + 1})
+	}
+
+	return edits
+}
+
+// splitLines splits text into trimmed lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+// myers runs the Myers O(ND) diff algorithm
+func myers(a, b []string) []LineEdit {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxEditDistance {
+		limit = maxEditDistance
+	}
+
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		// Only diagonals -d-1..d+1 are read back, so keep just that window
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+
+	// Too many differences: replace the whole region
+	edits := make([]LineEdit, 0, n+m)
+	for i, line := range a {
+		edits = append(edits, LineEdit{Op: Delete, Text: line, OldLine: i + 1})
+	}
+	for i, line := range b {
+		edits = append(edits, LineEdit{Op: Insert, Text: line, NewLine: i + 1})
+	}
+	return edits
+}
+
+// backtrack walks the Myers trace back from the end to recover the edits
+func backtrack(a, b []string, trace [][]int) []LineEdit {
+	x, y := len(a), len(b)
+	var reversed []LineEdit
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		window := d + 1 // Index of diagonal 0 in the snapshot
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[window+k-1] < v[window+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[window+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, LineEdit{Op: Equal, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, LineEdit{Op: Insert, Text: b[y-1], NewLine: y})
+			} else {
+				reversed = append(reversed, LineEdit{Op: Delete, Text: a[x-1], OldLine: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]LineEdit, len(reversed))
+	for i, edit := range reversed {
+		edits[len(reversed)-1-i] = edit
+	}
+	return edits
+}
+
+// Hunk is a run of edits with surrounding context
+type Hunk struct {
+	Edits []LineEdit
+}
+
+// Hunks groups edits into hunks with the given lines of context, dropping unchanged runs
+func Hunks(edits []LineEdit, context int) []Hunk {
+	var hunks []Hunk
+	var current []LineEdit
+	lastChange := -1
+
+	for i, edit := range edits {
+		if edit.Op == Equal {
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		if lastChange >= 0 && start <= lastChange+context+1 {
+			// Extend the current hunk through the gap
+			for j := lastChange + 1; j <= i; j++ {
+				current = append(current, edits[j])
+			}
+		} else {
+			if current != nil {
+				hunks = append(hunks, Hunk{Edits: appendContext(current, edits, lastChange, context)})
+			}
+			current = append([]LineEdit(nil), edits[start:i+1]...)
+		}
+		lastChange = i
+	}
+
+	if current != nil {
+		hunks = append(hunks, Hunk{Edits: appendContext(current, edits, lastChange, context)})
+	}
+	return hunks
+}
+
+// appendContext appends trailing context after the last change of a hunk
+func appendContext(hunk, edits []LineEdit, lastChange, context int) []LineEdit {
+	for j := lastChange + 1; j < len(edits) && j <= lastChange+context; j++ {
+		hunk = append(hunk, edits[j])
+	}
+	return hunk
+}
+
+// Header returns a unified diff style header for the hunk
+func (h Hunk) Header() string {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, edit := range h.Edits {
+		if edit.Op != Insert {
+			if oldStart == 0 {
+				oldStart = edit.OldLine
+			}
+			oldCount++
+		}
+		if edit.Op != Delete {
+			if newStart == 0 {
+				newStart = edit.NewLine
+			}
+			newCount++
+		}
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount)
+}
+
+// Unified renders the edits as a plain unified diff
+func Unified(edits []LineEdit, context int) string {
+	var b strings.Builder
+	for _, hunk := range Hunks(edits, context) {
+		b.WriteString(hunk.Header() + "\n")
+		for _, edit := range hunk.Edits {
+			switch edit.Op {
+			case Equal:
+				b.WriteString(" " + edit.Text + "\n")
+			case Insert:
+				b.WriteString("+" + edit.Text + "\n")
+			case Delete:
+				b.WriteString("-" + edit.Text + "\n")
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,265 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"sort"
+	"strings"
+)
+
+// ChangeKind identifies how an item changed
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a single semantic difference between two manifests
+type Change struct {
+	Kind    ChangeKind
+	Subject string // e.g. "variant hi/index.m3u8", "segment 1042"
+	Detail  string // e.g. "BANDWIDTH 800000 -> 900000"
+}
+
+// String formats the change on a single line
+func (c Change) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s", c.Kind, c.Subject)
+	}
+	return fmt.Sprintf("%s %s: %s", c.Kind, c.Subject, c.Detail)
+}
+
+// Manifests compares two parsed manifests semantically
+func Manifests(oldManifest, newManifest *hls.Manifest) []Change {
+	var changes []Change
+
+	if oldManifest.Type != newManifest.Type {
+		return []Change{{Kind: Changed, Subject: "playlist type", Detail: fmt.Sprintf("%s -> %s", oldManifest.Type, newManifest.Type)}}
+	}
+
+	changes = append(changes, comparePlaylist(oldManifest, newManifest)...)
+	changes = append(changes, compareVariants(oldManifest.Variants, newManifest.Variants)...)
+	changes = append(changes, compareRenditions(oldManifest.Renditions, newManifest.Renditions)...)
+	changes = append(changes, compareSegments(oldManifest.Segments, newManifest.Segments)...)
+
+	return changes
+}
+
+// comparePlaylist compares playlist-level values
+func comparePlaylist(a, b *hls.Manifest) []Change {
+	var changes []Change
+	check := func(subject string, oldValue, newValue interface{}) {
+		if oldValue != newValue {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("%v -> %v", oldValue, newValue)})
+		}
+	}
+
+	check("version", a.Version, b.Version)
+	if a.Type == hls.MediaManifest {
+		check("target duration", a.TargetDuration, b.TargetDuration)
+		check("media sequence", a.Sequence, b.Sequence)
+		check("endlist", a.Ended(), b.Ended())
+	}
+	return changes
+}
+
+// compareVariants matches variants by URI and compares their attributes
+func compareVariants(a, b []hls.Variant) []Change {
+	var changes []Change
+	oldByURI := make(map[string]hls.Variant)
+	for _, v := range a {
+		oldByURI[v.URI] = v
+	}
+	newByURI := make(map[string]hls.Variant)
+	for _, v := range b {
+		newByURI[v.URI] = v
+	}
+
+	for _, v := range a {
+		if _, ok := newByURI[v.URI]; !ok {
+			changes = append(changes, Change{Kind: Removed, Subject: "variant " + v.URI})
+		}
+	}
+	for _, v := range b {
+		old, ok := oldByURI[v.URI]
+		if !ok {
+			changes = append(changes, Change{Kind: Added, Subject: "variant " + v.URI})
+			continue
+		}
+		for _, detail := range compareAttributes(old.Attributes, v.Attributes) {
+			changes = append(changes, Change{Kind: Changed, Subject: "variant " + v.URI, Detail: detail})
+		}
+	}
+	return changes
+}
+
+// compareRenditions matches renditions by type, group and name
+func compareRenditions(a, b []hls.Rendition) []Change {
+	var changes []Change
+	key := func(r hls.Rendition) string {
+		return fmt.Sprintf("%s %s/%s", strings.ToLower(r.Type), r.GroupID, r.Name)
+	}
+
+	oldByKey := make(map[string]hls.Rendition)
+	for _, r := range a {
+		oldByKey[key(r)] = r
+	}
+	newByKey := make(map[string]hls.Rendition)
+	for _, r := range b {
+		newByKey[key(r)] = r
+	}
+
+	for _, r := range a {
+		if _, ok := newByKey[key(r)]; !ok {
+			changes = append(changes, Change{Kind: Removed, Subject: "rendition " + key(r)})
+		}
+	}
+	for _, r := range b {
+		old, ok := oldByKey[key(r)]
+		if !ok {
+			changes = append(changes, Change{Kind: Added, Subject: "rendition " + key(r)})
+			continue
+		}
+		for _, detail := range compareAttributes(old.Attributes, r.Attributes) {
+			changes = append(changes, Change{Kind: Changed, Subject: "rendition " + key(r), Detail: detail})
+		}
+	}
+	return changes
+}
+
+// compareAttributes lists attribute differences in a stable order
+func compareAttributes(a, b map[string]string) []string {
+	names := make(map[string]bool)
+	for name := range a {
+		names[name] = true
+	}
+	for name := range b {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var details []string
+	for _, name := range sorted {
+		oldValue, oldOK := a[name]
+		newValue, newOK := b[name]
+		switch {
+		case !oldOK:
+			details = append(details, fmt.Sprintf("%s added (%s)", name, newValue))
+		case !newOK:
+			details = append(details, fmt.Sprintf("%s removed (was %s)", name, oldValue))
+		case oldValue != newValue:
+			details = append(details, fmt.Sprintf("%s %s -> %s", name, oldValue, newValue))
+		}
+	}
+	return details
+}
+
+// compareSegments matches segments by media sequence number
+func compareSegments(a, b []hls.Segment) []Change {
+	var changes []Change
+	oldBySeq := make(map[int]*hls.Segment)
+	for i := range a {
+		oldBySeq[a[i].Sequence] = &a[i]
+	}
+	newBySeq := make(map[int]*hls.Segment)
+	for i := range b {
+		newBySeq[b[i].Sequence] = &b[i]
+	}
+
+	var removed, added []int
+	for _, s := range a {
+		if _, ok := newBySeq[s.Sequence]; !ok {
+			removed = append(removed, s.Sequence)
+		}
+	}
+	for _, s := range b {
+		if _, ok := oldBySeq[s.Sequence]; !ok {
+			added = append(added, s.Sequence)
+		}
+	}
+	for _, r := range sequenceRanges(removed) {
+		changes = append(changes, Change{Kind: Removed, Subject: r})
+	}
+	for _, r := range sequenceRanges(added) {
+		changes = append(changes, Change{Kind: Added, Subject: r})
+	}
+
+	for i := range b {
+		s := &b[i]
+		old, ok := oldBySeq[s.Sequence]
+		if !ok {
+			continue
+		}
+		subject := fmt.Sprintf("segment %d", s.Sequence)
+		if old.URI != s.URI {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("URI %s -> %s", old.URI, s.URI)})
+		}
+		if old.Duration != s.Duration {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("duration %.3f -> %.3f", old.Duration, s.Duration)})
+		}
+		if old.ByteRange != s.ByteRange {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("byte range %q -> %q", old.ByteRange, s.ByteRange)})
+		}
+		if old.Discontinuity != s.Discontinuity {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("discontinuity %t -> %t", old.Discontinuity, s.Discontinuity)})
+		}
+		if oldKey, newKey := describeKey(old.Key), describeKey(s.Key); oldKey != newKey {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("key rotated %s -> %s", oldKey, newKey)})
+		}
+		if oldMap, newMap := describeMap(old.Map), describeMap(s.Map); oldMap != newMap {
+			changes = append(changes, Change{Kind: Changed, Subject: subject, Detail: fmt.Sprintf("init map %s -> %s", oldMap, newMap)})
+		}
+	}
+	return changes
+}
+
+// sequenceRanges compresses sorted sequence numbers into "segments 10-14" style ranges
+func sequenceRanges(sequences []int) []string {
+	sort.Ints(sequences)
+	var ranges []string
+	for i := 0; i < len(sequences); {
+		j := i
+		for j+1 < len(sequences) && sequences[j+1] == sequences[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("segment %d", sequences[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("segments %d-%d", sequences[i], sequences[j]))
+		}
+		i = j + 1
+	}
+	return ranges
+}
+
+// describeKey summarizes a key for comparison
+func describeKey(key *hls.Key) string {
+	if key == nil || key.Method == "" || key.Method == "NONE" {
+		return "NONE"
+	}
+	description := key.Method
+	if key.URI != "" {
+		description += " " + key.URI
+	}
+	if key.IV != "" {
+		description += " IV=" + key.IV
+	}
+	return description
+}
+
+// describeMap summarizes an init map for comparison
+func describeMap(m *hls.Map) string {
+	if m == nil {
+		return "none"
+	}
+	if m.ByteRange != "" {
+		return m.URI + "@" + m.ByteRange
+	}
+	return m.URI
+}
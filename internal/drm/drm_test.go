@@ -0,0 +1,103 @@
+package drm
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/soldiermoth/pantui/internal/hls"
+)
+
+func TestByKeyFormat(t *testing.T) {
+	tests := map[string]string{
+		"urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed": "Widevine",
+		"com.microsoft.playready":                       "PlayReady",
+		"com.apple.streamingkeydelivery":                "FairPlay",
+		"":                                              "Identity",
+		"identity":                                      "Identity",
+	}
+	for keyFormat, expected := range tests {
+		if system, _ := ByKeyFormat(keyFormat); system.Name != expected {
+			t.Errorf("For KEYFORMAT %q expected %s, got %s", keyFormat, expected, system.Name)
+		}
+	}
+	if _, ok := ByKeyFormat("com.example.drm"); ok {
+		t.Error("Expected an unknown KEYFORMAT not to match")
+	}
+}
+
+func TestDescribeWidevineDataURI(t *testing.T) {
+	kid, _ := hex.DecodeString("0123456789abcdef0123456789abcdef")
+	systemID, _ := hex.DecodeString("edef8ba979d64acea3c827dcd51d21ed")
+
+	// WidevinePsshData: key_id (field 2) and provider (field 3)
+	data := append([]byte{0x12, 0x10}, kid...)
+	data = append(data, 0x1a, 0x04)
+	data = append(data, "acme"...)
+
+	key := &hls.Key{
+		Method:    "SAMPLE-AES-CTR",
+		URI:       "data:text/plain;base64," + base64.StdEncoding.EncodeToString(psshBox(0, systemID, nil, data)),
+		KeyFormat: "urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed",
+	}
+
+	info := DescribeKey(key)
+	if info.System.Name != "Widevine" || info.Scheme != "SAMPLE-AES-CTR (cenc)" {
+		t.Errorf("Unexpected key info: %+v", info)
+	}
+	if info.PSSH == nil || info.PSSH.System().Name != "Widevine" {
+		t.Fatalf("Expected a Widevine PSSH, got %+v", info.PSSH)
+	}
+	notes := strings.Join(info.Notes, "\n")
+	for _, expected := range []string{"Key ID: 01234567-89ab-cdef-0123-456789abcdef", "Provider: acme"} {
+		if !strings.Contains(notes, expected) {
+			t.Errorf("Expected %q in notes:\n%s", expected, notes)
+		}
+	}
+}
+
+func TestParsePSSHVersion1(t *testing.T) {
+	kid, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+	systemID, _ := hex.DecodeString("9a04f07998404286ab92e65be0885f95")
+
+	pssh, err := ParsePSSH(psshBox(1, systemID, [][]byte{kid}, nil))
+	if err != nil {
+		t.Fatalf("Failed to parse PSSH: %v", err)
+	}
+	if pssh.System().Name != "PlayReady" {
+		t.Errorf("Expected PlayReady, got %s", pssh.System().Name)
+	}
+	if len(pssh.KeyIDs) != 1 || pssh.KeyIDs[0] != "00112233-4455-6677-8899-aabbccddeeff" {
+		t.Errorf("Unexpected key IDs: %v", pssh.KeyIDs)
+	}
+}
+
+func TestSegmentSystems(t *testing.T) {
+	segment := &hls.Segment{Keys: []*hls.Key{
+		{Method: "SAMPLE-AES-CTR", KeyFormat: "urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed"},
+		{Method: "SAMPLE-AES", KeyFormat: "com.apple.streamingkeydelivery"},
+	}}
+	if systems := strings.Join(SegmentSystems(segment), ","); systems != "Widevine,FairPlay" {
+		t.Errorf("Expected Widevine,FairPlay, got %s", systems)
+	}
+}
+
+// psshBox builds a pssh box
+func psshBox(version byte, systemID []byte, kids [][]byte, data []byte) []byte {
+	payload := []byte{version, 0, 0, 0}
+	payload = append(payload, systemID...)
+	if version > 0 {
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(kids)))
+		for _, kid := range kids {
+			payload = append(payload, kid...)
+		}
+	}
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(data)))
+	payload = append(payload, data...)
+
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+	box = append(box, "pssh"...)
+	return append(box, payload...)
+}
@@ -0,0 +1,93 @@
+package drm
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"strings"
+)
+
+// KeyInfo describes which system protects content under one EXT-X-KEY
+type KeyInfo struct {
+	System            System
+	Method            string
+	Scheme            string
+	KeyFormat         string
+	KeyFormatVersions string
+	URI               string
+	KeyIDs            []string
+	PSSH              *PSSH
+	Notes             []string // Decoding problems and system specific details
+}
+
+// DescribeKey identifies the system behind a key and decodes PSSH data URIs
+func DescribeKey(key *hls.Key) KeyInfo {
+	system, _ := ByKeyFormat(key.KeyFormat)
+	info := KeyInfo{
+		System:            system,
+		Method:            key.Method,
+		Scheme:            Scheme(key.Method),
+		KeyFormat:         key.Format(),
+		KeyFormatVersions: key.KeyFormatVersions,
+		URI:               key.URI,
+	}
+	if key.KeyID != "" {
+		info.KeyIDs = append(info.KeyIDs, strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(key.KeyID, "0x"), "0X")))
+	}
+
+	if !strings.HasPrefix(key.URI, "data:") {
+		return info
+	}
+
+	data, err := DecodeDataURI(key.URI)
+	if err != nil {
+		info.Notes = append(info.Notes, fmt.Sprintf("Failed to decode data URI: %v", err))
+		return info
+	}
+
+	switch {
+	case len(data) >= 8 && string(data[4:8]) == "pssh":
+		pssh, err := ParsePSSH(data)
+		if err != nil {
+			info.Notes = append(info.Notes, fmt.Sprintf("Failed to decode PSSH: %v", err))
+			return info
+		}
+		info.PSSH = pssh
+		info.KeyIDs = append(info.KeyIDs, pssh.KeyIDs...)
+		info.Notes = append(info.Notes, pssh.Details()...)
+	case system.Name == PlayReady.Name:
+		// PlayReady key URIs carry a PlayReady Object rather than a pssh box
+		info.Notes = append(info.Notes, playReadyDetails(data)...)
+	default:
+		info.Notes = append(info.Notes, fmt.Sprintf("Data URI payload: %d bytes", len(data)))
+	}
+	return info
+}
+
+// ActiveKeys returns the keys encrypting a segment
+func ActiveKeys(segment *hls.Segment) []*hls.Key {
+	if len(segment.Keys) > 0 {
+		return segment.Keys
+	}
+	if segment.Key != nil && segment.Key.Method != "" && segment.Key.Method != "NONE" {
+		return []*hls.Key{segment.Key}
+	}
+	return nil
+}
+
+// SegmentSystems lists the distinct key systems protecting a segment
+func SegmentSystems(segment *hls.Segment) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, key := range ActiveKeys(segment) {
+		system, _ := ByKeyFormat(key.KeyFormat)
+		name := system.Name
+		if system.Name == Identity.Name {
+			name = key.Method
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,255 @@
+package drm
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf16"
+)
+
+// PSSH is a decoded Protection System Specific Header box
+type PSSH struct {
+	Version  int
+	SystemID string
+	KeyIDs   []string
+	Data     []byte
+}
+
+// System returns the key system the PSSH is for
+func (p *PSSH) System() System {
+	system, _ := BySystemID(p.SystemID)
+	return system
+}
+
+// Details decodes the system specific data where the format is known
+func (p *PSSH) Details() []string {
+	switch p.SystemID {
+	case Widevine.SystemID:
+		return widevineDetails(p.Data)
+	case PlayReady.SystemID:
+		return playReadyDetails(p.Data)
+	}
+	return nil
+}
+
+// ParsePSSH decodes a complete pssh box, header included
+func ParsePSSH(box []byte) (*PSSH, error) {
+	if len(box) < 32 {
+		return nil, fmt.Errorf("pssh box too short (%d bytes)", len(box))
+	}
+	size := binary.BigEndian.Uint32(box[0:4])
+	if string(box[4:8]) != "pssh" {
+		return nil, fmt.Errorf("not a pssh box (%q)", box[4:8])
+	}
+	if int(size) > len(box) || size < 32 {
+		return nil, fmt.Errorf("pssh box size %d does not match %d bytes of data", size, len(box))
+	}
+	box = box[:size]
+
+	pssh := &PSSH{
+		Version:  int(box[8]),
+		SystemID: formatUUID(box[12:28]),
+	}
+	offset := 28
+	if pssh.Version > 0 {
+		if offset+4 > len(box) {
+			return nil, fmt.Errorf("pssh box truncated in key ID count")
+		}
+		count := int(binary.BigEndian.Uint32(box[offset:]))
+		offset += 4
+		if offset+count*16 > len(box) {
+			return nil, fmt.Errorf("pssh box truncated in key IDs")
+		}
+		for i := 0; i < count; i++ {
+			pssh.KeyIDs = append(pssh.KeyIDs, formatUUID(box[offset:offset+16]))
+			offset += 16
+		}
+	}
+
+	if offset+4 > len(box) {
+		return nil, fmt.Errorf("pssh box truncated in data size")
+	}
+	dataSize := int(binary.BigEndian.Uint32(box[offset:]))
+	offset += 4
+	if offset+dataSize > len(box) {
+		return nil, fmt.Errorf("pssh box truncated in data")
+	}
+	pssh.Data = box[offset : offset+dataSize]
+	return pssh, nil
+}
+
+// DecodeDataURI returns the payload of a data: URI
+func DecodeDataURI(uri string) ([]byte, error) {
+	if !strings.HasPrefix(uri, "data:") {
+		return nil, fmt.Errorf("not a data URI")
+	}
+	comma := strings.Index(uri, ",")
+	if comma < 0 {
+		return nil, fmt.Errorf("data URI has no payload")
+	}
+	header, payload := uri[len("data:"):comma], uri[comma+1:]
+
+	if strings.HasSuffix(header, ";base64") {
+		if data, err := base64.StdEncoding.DecodeString(payload); err == nil {
+			return data, nil
+		}
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+	}
+	unescaped, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(unescaped), nil
+}
+
+// widevineDetails decodes the key IDs, provider and content ID of a WidevinePsshData message
+func widevineDetails(data []byte) []string {
+	var details []string
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			break
+		}
+		data = data[n:]
+		field, wireType := tag>>3, tag&0x7
+
+		switch wireType {
+		case 0:
+			value, n := binary.Uvarint(data)
+			if n <= 0 {
+				return details
+			}
+			data = data[n:]
+			if field == 9 {
+				scheme := make([]byte, 4)
+				binary.BigEndian.PutUint32(scheme, uint32(value))
+				details = append(details, "Protection scheme: "+string(scheme))
+			}
+		case 2:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return details
+			}
+			value := data[n : n+int(length)]
+			data = data[n+int(length):]
+			switch field {
+			case 2:
+				if len(value) == 16 {
+					details = append(details, "Key ID: "+formatUUID(value))
+				} else {
+					details = append(details, "Key ID: "+hex.EncodeToString(value))
+				}
+			case 3:
+				details = append(details, "Provider: "+string(value))
+			case 4:
+				details = append(details, "Content ID: "+printable(value))
+			}
+		case 5:
+			if len(data) < 4 {
+				return details
+			}
+			data = data[4:]
+		case 1:
+			if len(data) < 8 {
+				return details
+			}
+			data = data[8:]
+		default:
+			return details
+		}
+	}
+	return details
+}
+
+// playReadyDetails extracts key IDs and the license URL from a PlayReady Object
+func playReadyDetails(data []byte) []string {
+	header := PlayReadyHeader(data)
+	if header == "" {
+		return nil
+	}
+
+	var details []string
+	for _, kid := range xmlValues(header, "KID") {
+		details = append(details, "Key ID: "+kid)
+	}
+	for _, licenseURL := range xmlValues(header, "LA_URL") {
+		details = append(details, "License URL: "+licenseURL)
+	}
+	return details
+}
+
+// PlayReadyHeader returns the WRMHEADER XML of a PlayReady Object
+func PlayReadyHeader(data []byte) string {
+	if len(data) < 10 {
+		return ""
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	offset := 6
+	for i := 0; i < count && offset+4 <= len(data); i++ {
+		recordType := binary.LittleEndian.Uint16(data[offset:])
+		length := int(binary.LittleEndian.Uint16(data[offset+2:]))
+		offset += 4
+		if offset+length > len(data) {
+			return ""
+		}
+		if recordType == 1 {
+			return decodeUTF16LE(data[offset : offset+length])
+		}
+		offset += length
+	}
+	return ""
+}
+
+// decodeUTF16LE decodes little endian UTF-16 text
+func decodeUTF16LE(data []byte) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// xmlValues finds the text or VALUE attribute of every element with the given name
+func xmlValues(document, element string) []string {
+	var values []string
+	rest := document
+	for {
+		start := strings.Index(rest, "<"+element)
+		if start < 0 {
+			return values
+		}
+		rest = rest[start+len(element)+1:]
+		end := strings.Index(rest, ">")
+		if end < 0 {
+			return values
+		}
+		attributes := rest[:end]
+		rest = rest[end+1:]
+
+		if i := strings.Index(attributes, `VALUE="`); i >= 0 {
+			value := attributes[i+len(`VALUE="`):]
+			if j := strings.Index(value, `"`); j >= 0 {
+				values = append(values, value[:j])
+				continue
+			}
+		}
+		if closing := strings.Index(rest, "</"+element+">"); closing >= 0 {
+			if text := strings.TrimSpace(rest[:closing]); text != "" {
+				values = append(values, text)
+			}
+		}
+	}
+}
+
+// printable returns text as-is when it is printable, hex otherwise
+func printable(value []byte) string {
+	for _, c := range value {
+		if c < 0x20 || c > 0x7e {
+			return hex.EncodeToString(value)
+		}
+	}
+	return string(value)
+}
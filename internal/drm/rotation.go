@@ -0,0 +1,204 @@
+package drm
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"sort"
+	"strings"
+)
+
+// Period is a contiguous run of segments encrypted with the same keys
+type Period struct {
+	Index         int
+	Keys          []*hls.Key // Active keys, empty for clear segments
+	FirstSequence int
+	LastSequence  int
+	FirstLine     int // Line of the first segment's URI
+	LastLine      int // Line of the last segment's URI
+	Segments      int
+	Duration      float64
+	Discontinuity bool // The period starts at a discontinuity
+}
+
+// Encrypted reports whether the period's segments are encrypted
+func (p *Period) Encrypted() bool {
+	return len(p.Keys) > 0
+}
+
+// Label describes the period's keys on one line
+func (p *Period) Label() string {
+	if !p.Encrypted() {
+		return "clear"
+	}
+	var parts []string
+	for _, key := range p.Keys {
+		part := key.Method
+		if system, _ := ByKeyFormat(key.KeyFormat); system.Name != Identity.Name {
+			part += " " + system.Name
+		}
+		if key.URI != "" && !strings.HasPrefix(key.URI, "data:") {
+			part += " " + key.URI
+		}
+		if key.IV != "" {
+			part += " IV=" + key.IV
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Warning is an abnormal key rotation
+type Warning struct {
+	Sequence int
+	Message  string
+}
+
+// keySignature identifies a key by method, format, URI and IV
+func keySignature(key *hls.Key) string {
+	return strings.Join([]string{key.Method, key.Format(), key.URI, key.IV}, "|")
+}
+
+// periodSignature identifies the set of keys active for a segment
+func periodSignature(keys []*hls.Key) string {
+	signatures := make([]string, 0, len(keys))
+	for _, key := range keys {
+		signatures = append(signatures, keySignature(key))
+	}
+	sort.Strings(signatures)
+	return strings.Join(signatures, "\n")
+}
+
+// Periods groups a media playlist's segments into key periods
+func Periods(manifest *hls.Manifest) []Period {
+	var periods []Period
+	signature := ""
+	for i := range manifest.Segments {
+		segment := &manifest.Segments[i]
+		keys := ActiveKeys(segment)
+		next := periodSignature(keys)
+
+		if len(periods) == 0 || next != signature {
+			periods = append(periods, Period{
+				Index:         len(periods),
+				Keys:          keys,
+				FirstSequence: segment.Sequence,
+				FirstLine:     segment.LineNumber,
+				Discontinuity: segment.Discontinuity,
+			})
+			signature = next
+		}
+
+		period := &periods[len(periods)-1]
+		period.LastSequence = segment.Sequence
+		period.LastLine = segment.LineNumber
+		period.Segments++
+		period.Duration += segment.Duration
+	}
+	return periods
+}
+
+// RotationWarnings flags abnormal rotations between key periods
+func RotationWarnings(periods []Period) []Warning {
+	var warnings []Warning
+
+	// IVs and key URIs seen so far, to spot reuse
+	ivKeys := make(map[string]string)
+	usedKeys := make(map[string]int)
+
+	for i := range periods {
+		period := &periods[i]
+
+		if i > 0 {
+			previous := &periods[i-1]
+			if previous.Encrypted() && period.Encrypted() && formats(previous) != formats(period) && !period.Discontinuity {
+				warnings = append(warnings, Warning{
+					Sequence: period.FirstSequence,
+					Message:  fmt.Sprintf("KEYFORMAT changed from %s to %s without EXT-X-DISCONTINUITY", formats(previous), formats(period)),
+				})
+			}
+			if previous.Encrypted() != period.Encrypted() && !period.Discontinuity {
+				state := "stopped"
+				if period.Encrypted() {
+					state = "started"
+				}
+				warnings = append(warnings, Warning{
+					Sequence: period.FirstSequence,
+					Message:  fmt.Sprintf("Encryption %s without EXT-X-DISCONTINUITY", state),
+				})
+			}
+		}
+
+		for _, key := range period.Keys {
+			if key.URI != "" {
+				if first, ok := usedKeys[keySignature(key)]; ok && first != period.Index && first != period.Index-1 {
+					warnings = append(warnings, Warning{
+						Sequence: period.FirstSequence,
+						Message:  fmt.Sprintf("Key %s reused after rotating away in period %d", key.URI, first+1),
+					})
+				} else if !ok {
+					usedKeys[keySignature(key)] = period.Index
+				}
+			}
+
+			if key.IV == "" {
+				continue
+			}
+			iv := strings.ToLower(key.IV)
+			if uri, ok := ivKeys[iv]; ok && uri != key.URI {
+				warnings = append(warnings, Warning{
+					Sequence: period.FirstSequence,
+					Message:  fmt.Sprintf("IV %s reused with keys %s and %s", key.IV, uri, key.URI),
+				})
+			} else if !ok {
+				ivKeys[iv] = key.URI
+			}
+		}
+	}
+
+	return append(warnings, cadenceWarnings(periods)...)
+}
+
+// formats lists the key formats of a period
+func formats(period *Period) string {
+	if !period.Encrypted() {
+		return "none"
+	}
+	var names []string
+	for _, key := range period.Keys {
+		names = append(names, key.Format())
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// cadenceWarnings flags rotations far shorter or longer than the typical period.
+// The first and last periods are usually cut by the live window and are skipped.
+func cadenceWarnings(periods []Period) []Warning {
+	var complete []*Period
+	for i := 1; i < len(periods)-1; i++ {
+		if periods[i].Encrypted() {
+			complete = append(complete, &periods[i])
+		}
+	}
+	if len(complete) < 3 {
+		return nil
+	}
+
+	durations := make([]float64, len(complete))
+	for i, period := range complete {
+		durations[i] = period.Duration
+	}
+	sort.Float64s(durations)
+	median := durations[len(durations)/2]
+
+	var warnings []Warning
+	for _, period := range complete {
+		if period.Duration*1.5 < median || period.Duration > median*1.5 {
+			warnings = append(warnings, Warning{
+				Sequence: period.FirstSequence,
+				Message:  fmt.Sprintf("Key period lasted %.1fs, typical rotation is %.1fs", period.Duration, median),
+			})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,93 @@
+package drm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/soldiermoth/pantui/internal/hls"
+)
+
+func TestPeriods(t *testing.T) {
+	manifest := parseManifest(t, `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.0,
+clear0.ts
+#EXT-X-DISCONTINUITY
+#EXT-X-KEY:METHOD=AES-128,URI="key1",IV=0x01
+#EXTINF:6.0,
+seg1.ts
+#EXTINF:6.0,
+seg2.ts
+#EXT-X-KEY:METHOD=AES-128,URI="key2",IV=0x01
+#EXTINF:6.0,
+seg3.ts`)
+
+	periods := Periods(manifest)
+	if len(periods) != 3 {
+		t.Fatalf("Expected 3 periods, got %d", len(periods))
+	}
+	if periods[0].Encrypted() || !periods[1].Encrypted() {
+		t.Error("Expected a clear period followed by encrypted periods")
+	}
+	if periods[1].FirstSequence != 1 || periods[1].LastSequence != 2 || periods[1].Duration != 12 {
+		t.Errorf("Unexpected second period: %+v", periods[1])
+	}
+
+	warnings := RotationWarnings(periods)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "IV 0x01 reused with keys key1 and key2") {
+		t.Errorf("Expected an IV reuse warning, got %+v", warnings)
+	}
+}
+
+func TestRotationWarningsFormatChange(t *testing.T) {
+	manifest := parseManifest(t, `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://a",KEYFORMAT="com.apple.streamingkeydelivery"
+#EXTINF:6.0,
+seg0.mp4
+#EXT-X-KEY:METHOD=SAMPLE-AES-CTR,URI="data:text/plain;base64,AA==",KEYFORMAT="urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed"
+#EXTINF:6.0,
+seg1.mp4
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:6.0,
+seg2.mp4`)
+
+	var messages []string
+	for _, warning := range RotationWarnings(Periods(manifest)) {
+		messages = append(messages, warning.Message)
+	}
+	got := strings.Join(messages, "\n")
+	for _, expected := range []string{"KEYFORMAT changed", "Encryption stopped without EXT-X-DISCONTINUITY"} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, got)
+		}
+	}
+}
+
+func TestRotationWarningsCadence(t *testing.T) {
+	content := "#EXTM3U\n#EXT-X-TARGETDURATION:6\n"
+	// Rotate every 2 segments, except one period of a single segment
+	runs := []int{2, 2, 2, 1, 2, 2}
+	sequence := 0
+	for i, run := range runs {
+		content += "#EXT-X-KEY:METHOD=AES-128,URI=\"key" + string(rune('a'+i)) + "\"\n"
+		for j := 0; j < run; j++ {
+			content += "#EXTINF:6.0,\nseg" + string(rune('a'+sequence)) + ".ts\n"
+			sequence++
+		}
+	}
+
+	warnings := RotationWarnings(Periods(parseManifest(t, content)))
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "lasted 6.0s, typical rotation is 12.0s") {
+		t.Errorf("Expected one cadence warning, got %+v", warnings)
+	}
+}
+
+func parseManifest(t *testing.T, content string) *hls.Manifest {
+	t.Helper()
+	manifest, err := hls.ParseContent(content, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	return manifest
+}
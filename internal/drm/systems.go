@@ -0,0 +1,106 @@
+package drm
+
+import (
+	"strings"
+)
+
+// System is a key or DRM system identified by KEYFORMAT or PSSH system ID
+type System struct {
+	Name       string
+	SystemID   string   // lowercase UUID, empty for identity keys
+	KeyFormats []string // KEYFORMAT values that select this system
+}
+
+// Known key systems
+var (
+	Widevine = System{
+		Name:       "Widevine",
+		SystemID:   "edef8ba9-79d6-4ace-a3c8-27dcd51d21ed",
+		KeyFormats: []string{"urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed", "com.widevine"},
+	}
+	PlayReady = System{
+		Name:       "PlayReady",
+		SystemID:   "9a04f079-9840-4286-ab92-e65be0885f95",
+		KeyFormats: []string{"com.microsoft.playready", "urn:uuid:9a04f079-9840-4286-ab92-e65be0885f95"},
+	}
+	FairPlay = System{
+		Name:       "FairPlay",
+		SystemID:   "94ce86fb-07ff-4f43-adb8-93d2fa968ca2",
+		KeyFormats: []string{"com.apple.streamingkeydelivery", "urn:uuid:94ce86fb-07ff-4f43-adb8-93d2fa968ca2"},
+	}
+	ClearKey = System{
+		Name:       "ClearKey",
+		SystemID:   "e2719d58-a985-b3c9-781a-b030af78d30e",
+		KeyFormats: []string{"org.w3.clearkey", "urn:uuid:e2719d58-a985-b3c9-781a-b030af78d30e", "urn:uuid:1077efec-c0b2-4d02-ace3-3c1e52e2fb4b"},
+	}
+	Identity = System{
+		Name:       "Identity",
+		KeyFormats: []string{"identity"},
+	}
+)
+
+// Systems lists every known key system
+var Systems = []System{Widevine, PlayReady, FairPlay, ClearKey, Identity}
+
+// W3C common PSSH system ID, used by ClearKey
+const commonSystemID = "1077efec-c0b2-4d02-ace3-3c1e52e2fb4b"
+
+// ByKeyFormat identifies a system from a KEYFORMAT attribute
+func ByKeyFormat(keyFormat string) (System, bool) {
+	if keyFormat == "" {
+		return Identity, true
+	}
+	keyFormat = strings.ToLower(keyFormat)
+	for _, system := range Systems {
+		for _, format := range system.KeyFormats {
+			if keyFormat == format {
+				return system, true
+			}
+		}
+	}
+	return System{Name: "Unknown (" + keyFormat + ")"}, false
+}
+
+// BySystemID identifies a system from a PSSH system ID
+func BySystemID(systemID string) (System, bool) {
+	systemID = strings.ToLower(systemID)
+	if systemID == commonSystemID {
+		return ClearKey, true
+	}
+	for _, system := range Systems {
+		if system.SystemID != "" && system.SystemID == systemID {
+			return system, true
+		}
+	}
+	return System{Name: "Unknown (" + systemID + ")", SystemID: systemID}, false
+}
+
+// Scheme describes the encryption scheme implied by an EXT-X-KEY METHOD
+func Scheme(method string) string {
+	switch method {
+	case "AES-128":
+		return "AES-128 (whole segment, CBC)"
+	case "SAMPLE-AES":
+		return "SAMPLE-AES (cbcs)"
+	case "SAMPLE-AES-CTR", "SAMPLE-AES-CENC":
+		return "SAMPLE-AES-CTR (cenc)"
+	case "", "NONE":
+		return "none"
+	default:
+		return method
+	}
+}
+
+// formatUUID formats 16 bytes as a lowercase UUID
+func formatUUID(b []byte) string {
+	const hexDigits = "0123456789abcdef"
+	var out strings.Builder
+	for i, c := range b {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			out.WriteByte('-')
+		}
+		out.WriteByte(hexDigits[c>>4])
+		out.WriteByte(hexDigits[c&0x0f])
+	}
+	return out.String()
+}
@@ -0,0 +1,154 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/cache"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds connecting, the TLS handshake and waiting for the response headers of every request
+// made through the shared client. Reading the body is bounded by the caller's context alone, so large segments
+// on slow links aren't cut off.
+const DefaultTimeout = 30 * time.Second
+
+// UserAgent is sent with every request
+const UserAgent = "pantui"
+
+// Client is the HTTP client shared by manifest, segment and key requests
+var Client = &http.Client{Transport: newTransport()}
+
+// Cache, when set, keeps HTTP responses carrying an ETag or Last-Modified header by URL and byte range.
+// Cached responses are revalidated with a conditional request, so only changed resources are downloaded again.
+var Cache *cache.Cache
+
+// newTransport returns the default transport with DefaultTimeout bounding everything before the body
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: DefaultTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = DefaultTimeout
+	transport.ResponseHeaderTimeout = DefaultTimeout
+	return transport
+}
+
+// NewRequest creates a request with the standard pantui headers and those of the matching host rules
+func NewRequest(method, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	for name, value := range HeadersFor(rawURL) {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// Get issues a GET request with the shared client
+func Get(rawURL string) (*http.Response, error) {
+	req, err := NewRequest(http.MethodGet, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return Client.Do(req)
+}
+
+// Head issues a HEAD request with the shared client
+func Head(rawURL string) (*http.Response, error) {
+	req, err := NewRequest(http.MethodHead, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return Client.Do(req)
+}
+
+// Read loads a URL or local file, optionally limited to an HLS byte range ("length@offset").
+// The parser fills in offsets that EXT-X-BYTERANGE tags leave out.
+func Read(target, byteRange string) ([]byte, error) {
+	return ReadContext(context.Background(), target, byteRange)
+}
+
+// ReadContext is Read with a context that cancels the request
+func ReadContext(ctx context.Context, target, byteRange string) ([]byte, error) {
+	var length, offset int64
+	if byteRange != "" {
+		if n, _ := fmt.Sscanf(byteRange, "%d@%d", &length, &offset); n != 2 || length <= 0 {
+			return nil, fmt.Errorf("byte range %q needs an explicit length@offset", byteRange)
+		}
+	}
+
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return nil, err
+		}
+		if byteRange == "" {
+			return data, nil
+		}
+		if offset+length > int64(len(data)) {
+			return nil, fmt.Errorf("byte range %q exceeds file size %d", byteRange, len(data))
+		}
+		return data[offset : offset+length], nil
+	}
+
+	req, err := NewRequest(http.MethodGet, target)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+
+	key := cache.Key(target, byteRange)
+	var cached *cache.Entry
+	if Cache != nil {
+		if cached, _ = Cache.Get(key); cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Data, nil
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers that ignore Range send the whole resource
+	if byteRange != "" && resp.StatusCode == http.StatusOK {
+		if offset+length > int64(len(data)) {
+			return nil, fmt.Errorf("byte range %q exceeds response size %d", byteRange, len(data))
+		}
+		data = data[offset : offset+length]
+	}
+
+	if Cache != nil {
+		entry := &cache.Entry{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), Data: data}
+		if entry.ETag != "" || entry.LastModified != "" {
+			Cache.Put(key, entry)
+		} else if cached != nil {
+			Cache.Delete(key)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,134 @@
+package fetch
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/cache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadRevalidatesCachedResponses(t *testing.T) {
+	version := "v1"
+	var bodies int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain.ts" {
+			bodies++
+			w.Write([]byte("plain"))
+			return
+		}
+		etag := `"` + version + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		bodies++
+		w.Header().Set("ETag", etag)
+		w.Write([]byte("segment " + version))
+	}))
+	defer server.Close()
+
+	c, err := cache.New("", cache.DefaultMaxMemory, cache.DefaultMaxDisk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Cache = c
+	defer func() { Cache = nil }()
+
+	read := func(path, want string) {
+		t.Helper()
+		data, err := Read(server.URL+path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("Expected %q, got %q", want, data)
+		}
+	}
+
+	read("/seg1.ts", "segment v1")
+	read("/seg1.ts", "segment v1")
+	if bodies != 1 {
+		t.Errorf("Expected the unchanged segment to be downloaded once, got %d", bodies)
+	}
+
+	version = "v2"
+	read("/seg1.ts", "segment v2")
+	if bodies != 2 {
+		t.Errorf("Expected the changed segment to be downloaded again, got %d downloads", bodies)
+	}
+
+	read("/plain.ts", "plain")
+	read("/plain.ts", "plain")
+	if bodies != 4 {
+		t.Errorf("Expected responses without validators to be downloaded every time, got %d downloads", bodies)
+	}
+}
+
+func TestReadByteRange(t *testing.T) {
+	body := "0123456789"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ranged.ts" {
+			// Serve the Range header of the form bytes=start-end
+			var start, end int
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(body[start : end+1]))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path      string
+		byteRange string
+		want      string
+	}{
+		{"/ranged.ts", "4@2", "2345"},
+		{"/whole.ts", "4@2", "2345"},
+		{"/whole.ts", "", body},
+	}
+	for _, tt := range tests {
+		data, err := Read(server.URL+tt.path, tt.byteRange)
+		if err != nil {
+			t.Errorf("Read(%s, %q) failed: %v", tt.path, tt.byteRange, err)
+			continue
+		}
+		if string(data) != tt.want {
+			t.Errorf("Read(%s, %q) = %q, want %q", tt.path, tt.byteRange, data, tt.want)
+		}
+	}
+
+	if _, err := Read(server.URL+"/whole.ts", "4@8"); err == nil {
+		t.Error("Expected an error for a byte range beyond the response")
+	}
+}
+
+func TestHostRules(t *testing.T) {
+	var auth, agent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, agent = r.Header.Get("Authorization"), r.Header.Get("User-Agent")
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	SetHosts([]Host{
+		{Pattern: "*", Headers: map[string]string{"Authorization": "Bearer default", "User-Agent": "player/1.0"}},
+		{Pattern: "127.0.0.*", Headers: map[string]string{"Authorization": "Bearer local"}},
+		{Pattern: "*.example.com", Headers: map[string]string{"Authorization": "Bearer example"}},
+	})
+	defer SetHosts(nil)
+
+	if _, err := Read(server.URL+"/master.m3u8", ""); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer local" || agent != "player/1.0" {
+		t.Errorf("Expected later matching rules to override earlier ones, got %q and %q", auth, agent)
+	}
+
+	headers := HeadersFor("https://cdn.example.com/live.m3u8")
+	if headers["Authorization"] != "Bearer example" {
+		t.Errorf("Expected the example.com rule to apply, got %v", headers)
+	}
+}
@@ -0,0 +1,69 @@
+package fetch
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Host sets headers and a proxy for requests to the hosts matching a pattern, such as *.akamaized.net
+type Host struct {
+	Pattern string // Glob matched against the host name, * matches every host
+	Headers map[string]string
+	Proxy   *url.URL // Nil uses the proxy from the environment
+}
+
+// hosts are the rules set by SetHosts, later matches overriding earlier ones
+var hosts []Host
+
+// SetHosts applies host rules to every request made through the shared client
+func SetHosts(rules []Host) {
+	hosts = rules
+
+	transport := newTransport()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		var proxy *url.URL
+		for _, host := range matchingHosts(req.URL) {
+			if host.Proxy != nil {
+				proxy = host.Proxy
+			}
+		}
+		if proxy != nil {
+			return proxy, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+	Client.Transport = transport
+}
+
+// HeadersFor returns the headers the host rules add to requests for a URL, for tools making their own requests
+func HeadersFor(rawURL string) map[string]string {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	var headers map[string]string
+	for _, host := range matchingHosts(target) {
+		for name, value := range host.Headers {
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// matchingHosts returns the rules matching the host of a URL, in order
+func matchingHosts(target *url.URL) []Host {
+	var matching []Host
+	name := strings.ToLower(target.Hostname())
+	for _, host := range hosts {
+		if ok, _ := path.Match(strings.ToLower(host.Pattern), name); ok {
+			matching = append(matching, host)
+		}
+	}
+	return matching
+}
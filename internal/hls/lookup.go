@@ -0,0 +1,73 @@
+package hls
+
+import (
+	"time"
+)
+
+// HasTag reports whether the manifest contains a tag, such as #EXT-X-ENDLIST
+func (m *Manifest) HasTag(name string) bool {
+	for _, tag := range m.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Ended reports whether a media playlist is complete
+func (m *Manifest) Ended() bool {
+	return m.HasTag("#EXT-X-ENDLIST")
+}
+
+// SegmentBySequence returns the segment with the given media sequence number
+func (m *Manifest) SegmentBySequence(sequence int) *Segment {
+	for i := range m.Segments {
+		if m.Segments[i].Sequence == sequence {
+			return &m.Segments[i]
+		}
+	}
+	return nil
+}
+
+// SegmentAtOffset returns the segment playing at the given offset from the start of the playlist
+func (m *Manifest) SegmentAtOffset(offset time.Duration) *Segment {
+	if offset < 0 || len(m.Segments) == 0 {
+		return nil
+	}
+
+	var start time.Duration
+	for i := range m.Segments {
+		end := start + time.Duration(m.Segments[i].Duration*float64(time.Second))
+		if offset < end {
+			return &m.Segments[i]
+		}
+		start = end
+	}
+	return nil
+}
+
+// SegmentAtTime returns the segment whose program date time range contains the wall clock time
+func (m *Manifest) SegmentAtTime(t time.Time) *Segment {
+	for i := range m.Segments {
+		segment := &m.Segments[i]
+		if segment.ProgramDateTime == nil {
+			continue
+		}
+		start := *segment.ProgramDateTime
+		end := start.Add(time.Duration(segment.Duration * float64(time.Second)))
+		if !t.Before(start) && t.Before(end) {
+			return segment
+		}
+	}
+	return nil
+}
+
+// FirstProgramDateTime returns the earliest program date time in the playlist, if any
+func (m *Manifest) FirstProgramDateTime() *time.Time {
+	for i := range m.Segments {
+		if m.Segments[i].ProgramDateTime != nil {
+			return m.Segments[i].ProgramDateTime
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package hls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSegmentLookup(t *testing.T) {
+	mediaManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:40
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T12:00:00Z
+#EXTINF:6.0,
+segment40.ts
+#EXTINF:6.0,
+segment41.ts
+#EXTINF:6.0,
+segment42.ts`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(mediaManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse media manifest: %v", err)
+	}
+
+	if segment := manifest.SegmentBySequence(41); segment == nil || segment.URI != "segment41.ts" {
+		t.Errorf("Expected segment41.ts for sequence 41, got %v", segment)
+	}
+	if segment := manifest.SegmentBySequence(7); segment != nil {
+		t.Errorf("Expected no segment for sequence 7, got %v", segment)
+	}
+
+	if segment := manifest.SegmentAtOffset(13 * time.Second); segment == nil || segment.URI != "segment42.ts" {
+		t.Errorf("Expected segment42.ts at 13s, got %v", segment)
+	}
+	if segment := manifest.SegmentAtOffset(18 * time.Second); segment != nil {
+		t.Errorf("Expected no segment past the end, got %v", segment)
+	}
+
+	wallClock := time.Date(2024, 1, 1, 12, 0, 7, 0, time.UTC)
+	if segment := manifest.SegmentAtTime(wallClock); segment == nil || segment.URI != "segment41.ts" {
+		t.Errorf("Expected segment41.ts at 12:00:07, got %v", segment)
+	}
+}
+
+func TestLoadEnded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "media.m3u8")
+	content := "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\nseg0.ts\n#EXT-X-ENDLIST\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := NewParser().Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if manifest.Type != MediaManifest || len(manifest.Segments) != 1 {
+		t.Errorf("Expected a media playlist with 1 segment, got %s with %d", manifest.Type, len(manifest.Segments))
+	}
+	if !manifest.Ended() || !manifest.HasTag("#EXT-X-TARGETDURATION") {
+		t.Error("Expected the playlist to have its ENDLIST and TARGETDURATION tags")
+	}
+	if manifest.HasTag("#EXT-X-KEY") {
+		t.Error("Expected no EXT-X-KEY tag")
+	}
+
+	if _, err := NewParser().Load(filepath.Join(t.TempDir(), "missing.m3u8")); err == nil {
+		t.Error("Expected an error loading a missing file")
+	}
+}
@@ -2,14 +2,16 @@ package hls
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"github.com/soldiermoth/pantui/internal/fetch"
 	"io"
-	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ManifestType represents the type of HLS manifest
@@ -27,6 +29,8 @@ type Manifest struct {
 	Content     string        `json:"content"`
 	Lines       []Line        `json:"lines"`
 	Variants    []Variant     `json:"variants,omitempty"`
+	Renditions  []Rendition   `json:"renditions,omitempty"`
+	SessionKeys []Key         `json:"session_keys,omitempty"`
 	Segments    []Segment     `json:"segments,omitempty"`
 	Tags        []Tag         `json:"tags"`
 	BaseURL     string        `json:"base_url"`
@@ -49,6 +53,20 @@ type Variant struct {
 	Resolution string            `json:"resolution,omitempty"`
 	Codecs     string            `json:"codecs,omitempty"`
 	Attributes map[string]string `json:"attributes"`
+	LineNumber int               `json:"line_number"`
+}
+
+// Rendition represents an alternative rendition from EXT-X-MEDIA
+type Rendition struct {
+	Type       string            `json:"type"`
+	GroupID    string            `json:"group_id"`
+	Name       string            `json:"name"`
+	Language   string            `json:"language,omitempty"`
+	URI        string            `json:"uri,omitempty"`
+	Default    bool              `json:"default"`
+	Autoselect bool              `json:"autoselect"`
+	Attributes map[string]string `json:"attributes"`
+	LineNumber int               `json:"line_number"`
 }
 
 // Segment represents a media segment in a media manifest
@@ -56,9 +74,13 @@ type Segment struct {
 	URI      string  `json:"uri"`
 	Duration float64 `json:"duration"`
 	Sequence int     `json:"sequence"`
-	ByteRange string `json:"byte_range,omitempty"`
+	ByteRange string `json:"byte_range,omitempty"` // length@offset, the offset filled in when the tag leaves it out
 	Key      *Key    `json:"key,omitempty"`
+	Keys     []*Key  `json:"keys,omitempty"` // Every active key, one per KEYFORMAT
 	Map      *Map    `json:"map,omitempty"`
+	Discontinuity   bool       `json:"discontinuity,omitempty"`
+	ProgramDateTime *time.Time `json:"program_date_time,omitempty"`
+	LineNumber      int        `json:"line_number"`
 }
 
 // Map represents initialization segment information from EXT-X-MAP
@@ -73,6 +95,17 @@ type Key struct {
 	URI    string `json:"uri,omitempty"`
 	IV     string `json:"iv,omitempty"`
 	KeyFormat string `json:"key_format,omitempty"`
+	KeyFormatVersions string `json:"key_format_versions,omitempty"`
+	KeyID             string `json:"key_id,omitempty"`
+	LineNumber        int    `json:"line_number"`
+}
+
+// Format returns the key format, defaulting to "identity" as the spec does
+func (k *Key) Format() string {
+	if k.KeyFormat == "" {
+		return "identity"
+	}
+	return k.KeyFormat
 }
 
 // Tag represents an HLS tag
@@ -95,19 +128,17 @@ func NewParser() *Parser {
 
 // ParseFromURL parses an HLS manifest from a URL
 func (p *Parser) ParseFromURL(manifestURL string) (*Manifest, error) {
-	resp, err := http.Get(manifestURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
-	}
+	return p.parseFromURL(context.Background(), manifestURL)
+}
 
-	content, err := io.ReadAll(resp.Body)
+// parseFromURL fetches and parses a manifest until the context is cancelled. Manifests are small,
+// so the whole download is also bounded by fetch.DefaultTimeout.
+func (p *Parser) parseFromURL(ctx context.Context, manifestURL string) (*Manifest, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetch.DefaultTimeout)
+	defer cancel()
+	content, err := fetch.ReadContext(ctx, manifestURL, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read manifest: %w", err)
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
 
 	p.baseURL = p.getBaseURL(manifestURL)
@@ -131,6 +162,30 @@ func (p *Parser) ParseFromFile(filePath string) (*Manifest, error) {
 	return p.parseContent(string(content), filePath)
 }
 
+// Load parses a manifest from a URL or a local file path
+func (p *Parser) Load(location string) (*Manifest, error) {
+	return p.LoadContext(context.Background(), location)
+}
+
+// LoadContext is Load with a context that cancels fetching the manifest
+func (p *Parser) LoadContext(ctx context.Context, location string) (*Manifest, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return p.parseFromURL(ctx, location)
+	}
+	return p.ParseFromFile(location)
+}
+
+// ParseContent parses manifest content that was obtained elsewhere
+func ParseContent(content, sourceURL string) (*Manifest, error) {
+	p := NewParser()
+	if strings.HasPrefix(sourceURL, "http://") || strings.HasPrefix(sourceURL, "https://") {
+		p.baseURL = p.getBaseURL(sourceURL)
+	} else {
+		p.baseURL = path.Dir(sourceURL)
+	}
+	return p.parseContent(content, sourceURL)
+}
+
 // parseContent parses the manifest content
 func (p *Parser) parseContent(content, sourceURL string) (*Manifest, error) {
 	manifest := &Manifest{
@@ -227,8 +282,25 @@ func (p *Parser) parseMasterManifest(manifest *Manifest) error {
 			if codecs, ok := attributes["CODECS"]; ok {
 				currentVariant.Codecs = codecs
 			}
+		} else if strings.HasPrefix(line, "#EXT-X-MEDIA:") {
+			attributes := p.parseAttributes(strings.TrimPrefix(line, "#EXT-X-MEDIA:"))
+			manifest.Renditions = append(manifest.Renditions, Rendition{
+				Type:       attributes["TYPE"],
+				GroupID:    attributes["GROUP-ID"],
+				Name:       attributes["NAME"],
+				Language:   attributes["LANGUAGE"],
+				URI:        attributes["URI"],
+				Default:    attributes["DEFAULT"] == "YES",
+				Autoselect: attributes["AUTOSELECT"] == "YES",
+				Attributes: attributes,
+				LineNumber: lineNumber,
+			})
+		} else if strings.HasPrefix(line, "#EXT-X-SESSION-KEY:") {
+			attributes := p.parseAttributes(strings.TrimPrefix(line, "#EXT-X-SESSION-KEY:"))
+			manifest.SessionKeys = append(manifest.SessionKeys, *p.newKey(attributes, lineNumber))
 		} else if currentVariant != nil && !strings.HasPrefix(line, "#") {
 			currentVariant.URI = line
+			currentVariant.LineNumber = lineNumber
 			variants = append(variants, *currentVariant)
 			currentVariant = nil
 		}
@@ -253,7 +325,13 @@ func (p *Parser) parseMediaManifest(manifest *Manifest) error {
 	
 	var currentSegment *Segment
 	var currentKey *Key
+	var currentKeys []*Key
 	var currentMap *Map
+	var discontinuity bool
+	var programDateTime *time.Time
+	var taggedDateTime bool // programDateTime comes from a tag since the last segment URI
+	var rangeURI string     // Resource of the previous segment's byte range, which rangeEnd ends
+	var rangeEnd int64
 	
 	for scanner.Scan() {
 		lineNumber++
@@ -284,12 +362,35 @@ func (p *Parser) parseMediaManifest(manifest *Manifest) error {
 			
 			if duration, err := strconv.ParseFloat(durationStr, 64); err == nil {
 				currentSegment = &Segment{
-					Duration: duration,
-					Sequence: sequence,
-					Key:      currentKey,
-					Map:      currentMap,
+					Duration:        duration,
+					Sequence:        sequence,
+					Key:             currentKey,
+					Keys:            currentKeys,
+					Map:             currentMap,
+					Discontinuity:   discontinuity,
+					ProgramDateTime: programDateTime,
 				}
 				sequence++
+				discontinuity = false
+			}
+		} else if line == "#EXT-X-DISCONTINUITY" {
+			// Extrapolated wall clock times do not carry across a discontinuity, tagged ones may precede it
+			if !taggedDateTime {
+				programDateTime = nil
+			}
+			if currentSegment != nil {
+				currentSegment.Discontinuity = true
+				currentSegment.ProgramDateTime = programDateTime
+			} else {
+				discontinuity = true
+			}
+		} else if strings.HasPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:") {
+			if pdt, err := parseProgramDateTime(strings.TrimPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:")); err == nil {
+				programDateTime = &pdt
+				taggedDateTime = true
+				if currentSegment != nil {
+					currentSegment.ProgramDateTime = programDateTime
+				}
 			}
 		} else if strings.HasPrefix(line, "#EXT-X-BYTERANGE:") {
 			// Handle byte range for current segment
@@ -298,12 +399,8 @@ func (p *Parser) parseMediaManifest(manifest *Manifest) error {
 			}
 		} else if strings.HasPrefix(line, "#EXT-X-KEY:") {
 			attributes := p.parseAttributes(strings.TrimPrefix(line, "#EXT-X-KEY:"))
-			currentKey = &Key{
-				Method: attributes["METHOD"],
-				URI:    attributes["URI"],
-				IV:     attributes["IV"],
-				KeyFormat: attributes["KEYFORMAT"],
-			}
+			currentKey = p.newKey(attributes, lineNumber)
+			currentKeys = activeKeys(currentKeys, currentKey)
 		} else if strings.HasPrefix(line, "#EXT-X-MAP:") {
 			attributes := p.parseAttributes(strings.TrimPrefix(line, "#EXT-X-MAP:"))
 			currentMap = &Map{
@@ -312,7 +409,16 @@ func (p *Parser) parseMediaManifest(manifest *Manifest) error {
 			}
 		} else if currentSegment != nil && !strings.HasPrefix(line, "#") {
 			currentSegment.URI = line
+			currentSegment.LineNumber = lineNumber
+			rangeURI, rangeEnd = completeByteRange(currentSegment, rangeURI, rangeEnd)
 			segments = append(segments, *currentSegment)
+			
+			// Later segments without their own tag inherit an extrapolated time
+			if programDateTime != nil {
+				next := programDateTime.Add(time.Duration(currentSegment.Duration * float64(time.Second)))
+				programDateTime = &next
+			}
+			taggedDateTime = false
 			currentSegment = nil
 		}
 		
@@ -327,6 +433,67 @@ func (p *Parser) parseMediaManifest(manifest *Manifest) error {
 	return nil
 }
 
+// completeByteRange fills in the offset of a segment's byte range when EXT-X-BYTERANGE leaves it out.
+// The sub-range then follows the previous segment's sub-range of the same resource, or starts at 0.
+// It returns the resource and end of the segment's sub-range for the next segment.
+func completeByteRange(segment *Segment, rangeURI string, rangeEnd int64) (string, int64) {
+	if segment.ByteRange == "" {
+		return "", 0
+	}
+	var length, offset int64
+	n, _ := fmt.Sscanf(segment.ByteRange, "%d@%d", &length, &offset)
+	switch n {
+	case 0:
+		return "", 0
+	case 1:
+		if rangeURI == segment.URI {
+			offset = rangeEnd
+		}
+		segment.ByteRange = fmt.Sprintf("%d@%d", length, offset)
+	}
+	return segment.URI, offset + length
+}
+
+// newKey builds a key from EXT-X-KEY or EXT-X-SESSION-KEY attributes
+func (p *Parser) newKey(attributes map[string]string, lineNumber int) *Key {
+	return &Key{
+		Method:            attributes["METHOD"],
+		URI:               attributes["URI"],
+		IV:                attributes["IV"],
+		KeyFormat:         attributes["KEYFORMAT"],
+		KeyFormatVersions: attributes["KEYFORMATVERSIONS"],
+		KeyID:             attributes["KEYID"],
+		LineNumber:        lineNumber,
+	}
+}
+
+// activeKeys applies a new EXT-X-KEY to the active key set. A key replaces the
+// previous key with the same KEYFORMAT and METHOD=NONE clears every key.
+func activeKeys(keys []*Key, key *Key) []*Key {
+	if key.Method == "NONE" {
+		return nil
+	}
+
+	// Segments keep the slice they were given, so build a new one
+	next := make([]*Key, 0, len(keys)+1)
+	for _, existing := range keys {
+		if existing.Format() != key.Format() {
+			next = append(next, existing)
+		}
+	}
+	return append(next, key)
+}
+
+// parseProgramDateTime parses an EXT-X-PROGRAM-DATE-TIME value
+func parseProgramDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	// Some packagers omit the colon in the zone offset
+	return time.Parse("2006-01-02T15:04:05.999999999Z0700", value)
+}
+
 // parseTag parses an HLS tag
 func (p *Parser) parseTag(line string, lineNumber int) Tag {
 	tag := Tag{
@@ -417,6 +584,10 @@ func (p *Parser) ResolveURL(relativeURL string) string {
 	
 	if strings.HasPrefix(p.baseURL, "http://") || strings.HasPrefix(p.baseURL, "https://") {
 		if baseURL, err := url.Parse(p.baseURL); err == nil {
+			// The base is a directory, so keep its last path element when resolving
+			if !strings.HasSuffix(baseURL.Path, "/") {
+				baseURL.Path += "/"
+			}
 			if resolvedURL, err := baseURL.Parse(relativeURL); err == nil {
 				return resolvedURL.String()
 			}
@@ -425,3 +596,9 @@ func (p *Parser) ResolveURL(relativeURL string) string {
 	
 	return path.Join(p.baseURL, relativeURL)
 }
+
+// ResolveURL resolves a URI from the manifest against the manifest's base URL
+func (m *Manifest) ResolveURL(uri string) string {
+	p := &Parser{baseURL: m.BaseURL}
+	return p.ResolveURL(uri)
+}
@@ -1,7 +1,6 @@
 package hls
 
 import (
-	"strings"
 	"testing"
 )
 
@@ -203,8 +202,8 @@ func TestResolveURL(t *testing.T) {
 
 	// Relative URL should be resolved
 	result = parser.ResolveURL("segment.ts")
-	if !strings.Contains(result, "segment.ts") {
-		t.Errorf("Expected resolved URL to contain 'segment.ts', got '%s'", result)
+	if result != "https://example.com/video/segment.ts" {
+		t.Errorf("Expected resolved URL 'https://example.com/video/segment.ts', got '%s'", result)
 	}
 
 	// Test with file path base URL
@@ -215,3 +214,197 @@ func TestResolveURL(t *testing.T) {
 		t.Errorf("Expected resolved path '%s', got '%s'", expected, result)
 	}
 }
+
+func TestParseMediaManifestTimingTags(t *testing.T) {
+	mediaManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T12:00:00.000Z
+#EXTINF:6.0,
+segment100.ts
+#EXTINF:4.0,
+segment101.ts
+#EXT-X-DISCONTINUITY
+#EXTINF:6.0,
+segment102.ts`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(mediaManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse media manifest: %v", err)
+	}
+
+	if len(manifest.Segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d", len(manifest.Segments))
+	}
+
+	first := manifest.Segments[0]
+	if first.LineNumber != 6 {
+		t.Errorf("Expected line number 6, got %d", first.LineNumber)
+	}
+	if first.ProgramDateTime == nil || first.ProgramDateTime.Format("15:04:05") != "12:00:00" {
+		t.Errorf("Expected program date time 12:00:00, got %v", first.ProgramDateTime)
+	}
+
+	second := manifest.Segments[1]
+	if second.ProgramDateTime == nil || second.ProgramDateTime.Format("15:04:05") != "12:00:06" {
+		t.Errorf("Expected extrapolated program date time 12:00:06, got %v", second.ProgramDateTime)
+	}
+
+	third := manifest.Segments[2]
+	if !third.Discontinuity {
+		t.Error("Expected third segment to follow a discontinuity")
+	}
+	if third.ProgramDateTime != nil {
+		t.Errorf("Expected no program date time after discontinuity, got %v", third.ProgramDateTime)
+	}
+}
+
+func TestParseProgramDateTimeBeforeDiscontinuity(t *testing.T) {
+	mediaManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T12:00:00.000Z
+#EXTINF:6.0,
+segment0.ts
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T13:00:00.000Z
+#EXT-X-DISCONTINUITY
+#EXTINF:6.0,
+segment1.ts
+#EXTINF:6.0,
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T14:00:00.000Z
+#EXT-X-DISCONTINUITY
+segment2.ts`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(mediaManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse media manifest: %v", err)
+	}
+	if len(manifest.Segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d", len(manifest.Segments))
+	}
+
+	for i, expected := range []string{"12:00:00", "13:00:00", "14:00:00"} {
+		segment := manifest.Segments[i]
+		if segment.ProgramDateTime == nil || segment.ProgramDateTime.Format("15:04:05") != expected {
+			t.Errorf("Segment %d: expected program date time %s, got %v", i, expected, segment.ProgramDateTime)
+		}
+		if i > 0 && !segment.Discontinuity {
+			t.Errorf("Segment %d: expected a discontinuity", i)
+		}
+	}
+}
+
+func TestParseImplicitByteRangeOffsets(t *testing.T) {
+	mediaManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.0,
+#EXT-X-BYTERANGE:1000
+main.ts
+#EXTINF:6.0,
+#EXT-X-BYTERANGE:2000
+main.ts
+#EXTINF:6.0,
+#EXT-X-BYTERANGE:500@10000
+main.ts
+#EXTINF:6.0,
+#EXT-X-BYTERANGE:700
+main.ts
+#EXTINF:6.0,
+#EXT-X-BYTERANGE:300
+other.ts
+#EXTINF:6.0,
+plain.ts`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(mediaManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse media manifest: %v", err)
+	}
+
+	expected := []string{"1000@0", "2000@1000", "500@10000", "700@10500", "300@0", ""}
+	if len(manifest.Segments) != len(expected) {
+		t.Fatalf("Expected %d segments, got %d", len(expected), len(manifest.Segments))
+	}
+	for i, byteRange := range expected {
+		if got := manifest.Segments[i].ByteRange; got != byteRange {
+			t.Errorf("Segment %d: expected byte range %q, got %q", i, byteRange, got)
+		}
+	}
+}
+
+func TestParseMasterManifestRenditions(t *testing.T) {
+	masterManifest := `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="English",LANGUAGE="en",DEFAULT=YES,URI="audio/en.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=1280000,AUDIO="aud"
+low/index.m3u8`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(masterManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse master manifest: %v", err)
+	}
+
+	if len(manifest.Renditions) != 1 {
+		t.Fatalf("Expected 1 rendition, got %d", len(manifest.Renditions))
+	}
+	rendition := manifest.Renditions[0]
+	if rendition.GroupID != "aud" || rendition.Language != "en" || !rendition.Default {
+		t.Errorf("Unexpected rendition: %+v", rendition)
+	}
+	if manifest.Variants[0].LineNumber != 4 {
+		t.Errorf("Expected variant line number 4, got %d", manifest.Variants[0].LineNumber)
+	}
+}
+
+func TestParseMultipleKeyFormats(t *testing.T) {
+	mediaManifest := `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXT-X-KEY:METHOD=SAMPLE-AES-CTR,URI="data:text/plain;base64,AAAA",KEYFORMAT="urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed",KEYFORMATVERSIONS="1"
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://key1",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXTINF:6.0,
+segment0.mp4
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://key2",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXTINF:6.0,
+segment1.mp4
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:6.0,
+segment2.mp4`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(mediaManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse media manifest: %v", err)
+	}
+
+	if keys := manifest.Segments[0].Keys; len(keys) != 2 || keys[1].KeyFormatVersions != "1" {
+		t.Fatalf("Expected 2 active keys on the first segment, got %+v", keys)
+	}
+	keys := manifest.Segments[1].Keys
+	if len(keys) != 2 || keys[0].Format() != "urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed" || keys[1].URI != "skd://key2" {
+		t.Errorf("Expected the FairPlay key to be replaced on the second segment, got %+v %+v", keys[0], keys[1])
+	}
+	if len(manifest.Segments[0].Keys) != 2 || manifest.Segments[0].Keys[1].URI != "skd://key1" {
+		t.Error("Expected the first segment to keep its own keys")
+	}
+	if len(manifest.Segments[2].Keys) != 0 {
+		t.Errorf("Expected METHOD=NONE to clear keys, got %d", len(manifest.Segments[2].Keys))
+	}
+}
+
+func TestParseSessionKeys(t *testing.T) {
+	masterManifest := `#EXTM3U
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://session",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-STREAM-INF:BANDWIDTH=1280000
+low/index.m3u8`
+
+	parser := NewParser()
+	manifest, err := parser.parseContent(masterManifest, "test.m3u8")
+	if err != nil {
+		t.Fatalf("Failed to parse master manifest: %v", err)
+	}
+
+	if len(manifest.SessionKeys) != 1 || manifest.SessionKeys[0].KeyFormat != "com.apple.streamingkeydelivery" {
+		t.Errorf("Expected one FairPlay session key, got %+v", manifest.SessionKeys)
+	}
+}
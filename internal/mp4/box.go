@@ -0,0 +1,118 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Box is an ISO BMFF box with its children parsed when it is a container
+type Box struct {
+	Type       string
+	Offset     int64 // Offset of the box header in the parsed data
+	Size       int64
+	HeaderSize int
+	Bytes      []byte // The whole box, header included
+	Children   []*Box
+}
+
+// Payload returns the box contents after the header
+func (b *Box) Payload() []byte {
+	return b.Bytes[b.HeaderSize:]
+}
+
+// containers are boxes whose payload is a list of boxes
+var containers = map[string]bool{
+	"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true,
+	"mvex": true, "moof": true, "traf": true, "edts": true, "dinf": true,
+	"sinf": true, "schi": true, "udta": true, "mfra": true, "tref": true,
+}
+
+// sampleEntryHeaders is the size of the fixed fields before child boxes in sample entries
+var sampleEntryHeaders = map[string]int{
+	// Visual sample entries
+	"avc1": 78, "avc3": 78, "hvc1": 78, "hev1": 78, "dvh1": 78, "dvhe": 78,
+	"av01": 78, "vp08": 78, "vp09": 78, "encv": 78,
+	// Audio sample entries
+	"mp4a": 28, "ac-3": 28, "ec-3": 28, "ac-4": 28, "Opus": 28, "fLaC": 28, "enca": 28,
+}
+
+// Parse reads the boxes in data, descending into containers and sample descriptions
+func Parse(data []byte) ([]*Box, error) {
+	return parseBoxes(data, 0)
+}
+
+// parseBoxes reads consecutive boxes starting at the given absolute offset
+func parseBoxes(data []byte, base int64) ([]*Box, error) {
+	var boxes []*Box
+	offset := 0
+	for offset+8 <= len(data) {
+		size := int64(binary.BigEndian.Uint32(data[offset:]))
+		boxType := string(data[offset+4 : offset+8])
+		headerSize := 8
+
+		switch size {
+		case 1:
+			if offset+16 > len(data) {
+				return boxes, fmt.Errorf("%s box at %d: truncated 64-bit size", boxType, base+int64(offset))
+			}
+			size = int64(binary.BigEndian.Uint64(data[offset+8:]))
+			headerSize = 16
+		case 0:
+			size = int64(len(data) - offset)
+		}
+		if size < int64(headerSize) || size > int64(len(data)-offset) {
+			return boxes, fmt.Errorf("%s box at %d: size %d exceeds %d available bytes", boxType, base+int64(offset), size, len(data)-offset)
+		}
+
+		box := &Box{
+			Type:       boxType,
+			Offset:     base + int64(offset),
+			Size:       size,
+			HeaderSize: headerSize,
+			Bytes:      data[offset : int64(offset)+size],
+		}
+		if err := parseChildren(box); err != nil {
+			boxes = append(boxes, box)
+			return boxes, err
+		}
+		boxes = append(boxes, box)
+		offset += int(size)
+	}
+	return boxes, nil
+}
+
+// parseChildren parses the children of container boxes, sample descriptions and sample entries
+func parseChildren(box *Box) error {
+	payload := box.Payload()
+	childOffset := 0
+
+	switch {
+	case containers[box.Type]:
+	case box.Type == "stsd":
+		// Full box header plus entry count
+		childOffset = 8
+	case sampleEntryHeaders[box.Type] > 0:
+		childOffset = sampleEntryHeaders[box.Type]
+	default:
+		return nil
+	}
+	if childOffset > len(payload) {
+		return nil
+	}
+
+	children, err := parseBoxes(payload[childOffset:], box.Offset+int64(box.HeaderSize+childOffset))
+	box.Children = children
+	return err
+}
+
+// Find returns every box of the given type, searching depth first
+func Find(boxes []*Box, boxType string) []*Box {
+	var found []*Box
+	for _, box := range boxes {
+		if box.Type == boxType {
+			found = append(found, box)
+		}
+		found = append(found, Find(box.Children, boxType)...)
+	}
+	return found
+}
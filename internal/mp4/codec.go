@@ -0,0 +1,162 @@
+package mp4
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+)
+
+// Codecs returns the RFC 6381 codec string of every sample entry in the boxes
+func Codecs(boxes []*Box) []string {
+	var found []string
+	for _, stsd := range Find(boxes, "stsd") {
+		for _, entry := range stsd.Children {
+			if codec := CodecString(entry); codec != "" {
+				found = append(found, codec)
+			}
+		}
+	}
+	return found
+}
+
+// SampleFormat is the sample entry's format, looking through encv/enca to the original format
+func SampleFormat(entry *Box) string {
+	if entry.Type == "encv" || entry.Type == "enca" {
+		for _, frma := range Find(entry.Children, "frma") {
+			if payload := frma.Payload(); len(payload) >= 4 {
+				return string(payload[:4])
+			}
+		}
+	}
+	return entry.Type
+}
+
+// CodecString derives the RFC 6381 codec string from a sample entry and its configuration box.
+// Entries without a known configuration return their four character code.
+func CodecString(entry *Box) string {
+	format := SampleFormat(entry)
+	config := func(boxType string) []byte {
+		for _, child := range entry.Children {
+			if child.Type == boxType {
+				return child.Payload()
+			}
+		}
+		return nil
+	}
+
+	switch format {
+	case "avc1", "avc2", "avc3", "avc4":
+		if avcC := config("avcC"); len(avcC) >= 4 {
+			return codecs.AVCString(format, avcC[1], avcC[2], avcC[3])
+		}
+	case "hvc1", "hev1", "dvh1", "dvhe":
+		if hvcC := config("hvcC"); len(hvcC) >= 13 {
+			return codecs.HEVCString(format, hvcC[1:13])
+		}
+	case "av01":
+		if av1C := config("av1C"); len(av1C) >= 3 {
+			return av1Codec(av1C)
+		}
+	case "vp08", "vp09":
+		if vpcC := config("vpcC"); len(vpcC) >= 7 {
+			return fmt.Sprintf("%s.%02d.%02d.%02d", format, vpcC[4], vpcC[5], vpcC[6]>>4)
+		}
+	case "mp4a":
+		if esds := config("esds"); len(esds) > 4 {
+			if codec := aacCodec(esds[4:]); codec != "" {
+				return codec
+			}
+		}
+		return "mp4a"
+	case "Opus":
+		return "opus"
+	}
+	return format
+}
+
+// av1Codec formats an AV1 codec string from an av1C payload
+func av1Codec(av1C []byte) string {
+	profile := av1C[1] >> 5
+	level := av1C[1] & 0x1f
+	tier := "M"
+	if av1C[2]&0x80 != 0 {
+		tier = "H"
+	}
+	bitDepth := 8
+	if av1C[2]&0x40 != 0 {
+		bitDepth = 10
+		if av1C[2]&0x20 != 0 {
+			bitDepth = 12
+		}
+	}
+	return fmt.Sprintf("av01.%d.%02d%s.%02d", profile, level, tier, bitDepth)
+}
+
+// aacCodec reads the object type indication and audio object type from ES descriptors
+func aacCodec(descriptors []byte) string {
+	objectType := -1
+	audioObjectType := 0
+	for i := 0; i < len(descriptors); {
+		tag := descriptors[i]
+		length, size := descriptorLength(descriptors[i+1:])
+		body := descriptors[i+1+size:]
+		if length < len(body) {
+			body = body[:length]
+		}
+
+		switch tag {
+		case 0x03: // ES_Descriptor, its children follow the header
+			if len(body) < 3 {
+				return ""
+			}
+			header := 3
+			flags := body[2]
+			if flags&0x80 != 0 {
+				header += 2
+			}
+			if flags&0x40 != 0 && len(body) > header {
+				header += 1 + int(body[header])
+			}
+			if flags&0x20 != 0 {
+				header += 2
+			}
+			i += 1 + size + header
+			continue
+		case 0x04: // DecoderConfigDescriptor
+			if len(body) < 13 {
+				return ""
+			}
+			objectType = int(body[0])
+			i += 1 + size + 13
+			continue
+		case 0x05: // DecoderSpecificInfo, the AudioSpecificConfig
+			if len(body) >= 1 {
+				audioObjectType = int(body[0] >> 3)
+				if audioObjectType == 31 && len(body) >= 2 {
+					audioObjectType = 32 + (int(body[0]&0x07)<<3 | int(body[1]>>5))
+				}
+			}
+		}
+		i += 1 + size + length
+	}
+
+	if objectType < 0 {
+		return ""
+	}
+	if audioObjectType == 0 {
+		return fmt.Sprintf("mp4a.%02x", objectType)
+	}
+	return fmt.Sprintf("mp4a.%02x.%d", objectType, audioObjectType)
+}
+
+// descriptorLength decodes the variable length size of an MPEG-4 descriptor
+func descriptorLength(data []byte) (length int, size int) {
+	for size < 4 && size < len(data) {
+		b := data[size]
+		size++
+		length = length<<7 | int(b&0x7f)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return length, size
+}
@@ -0,0 +1,255 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Describe summarizes the fields of well-known boxes on one line, empty for others
+func Describe(box *Box) string {
+	p := box.Payload()
+	switch box.Type {
+	case "ftyp", "styp":
+		if len(p) < 8 {
+			break
+		}
+		var compatible []string
+		for i := 8; i+4 <= len(p); i += 4 {
+			compatible = append(compatible, string(p[i:i+4]))
+		}
+		return fmt.Sprintf("major %s, minor %d, compatible %s", string(p[:4]), binary.BigEndian.Uint32(p[4:8]), strings.Join(compatible, ","))
+	case "mvhd":
+		if timescale, duration, ok := timing(p); ok {
+			return fmt.Sprintf("timescale %d, duration %d", timescale, duration)
+		}
+	case "mdhd":
+		if timescale, duration, ok := timing(p); ok {
+			language := ""
+			offset := 20
+			if p[0] == 1 {
+				offset = 32
+			}
+			if len(p) >= offset+2 {
+				packed := binary.BigEndian.Uint16(p[offset:])
+				language = string([]byte{byte(packed>>10&0x1f) + 0x60, byte(packed>>5&0x1f) + 0x60, byte(packed&0x1f) + 0x60})
+			}
+			return fmt.Sprintf("timescale %d, duration %d, language %s", timescale, duration, language)
+		}
+	case "tkhd":
+		trackID, dimensions := 12, 76
+		if len(p) > 0 && p[0] == 1 {
+			trackID, dimensions = 20, 88
+		}
+		if len(p) >= dimensions+8 {
+			return fmt.Sprintf("track %d, %dx%d", binary.BigEndian.Uint32(p[trackID:]),
+				binary.BigEndian.Uint32(p[dimensions:])>>16, binary.BigEndian.Uint32(p[dimensions+4:])>>16)
+		}
+	case "hdlr":
+		if len(p) >= 24 {
+			name := strings.TrimRight(string(p[24:]), "\x00")
+			return fmt.Sprintf("handler %s %q", string(p[8:12]), name)
+		}
+	case "stsd":
+		if len(p) >= 8 {
+			return fmt.Sprintf("%d entries", binary.BigEndian.Uint32(p[4:8]))
+		}
+	case "trex":
+		if len(p) >= 8 {
+			return fmt.Sprintf("track %d", binary.BigEndian.Uint32(p[4:8]))
+		}
+	case "mfhd":
+		if len(p) >= 8 {
+			return fmt.Sprintf("sequence %d", binary.BigEndian.Uint32(p[4:8]))
+		}
+	case "tfhd":
+		if len(p) >= 8 {
+			return fmt.Sprintf("track %d, flags 0x%06x", binary.BigEndian.Uint32(p[4:8]), flags(p))
+		}
+	case "tfdt":
+		if len(p) >= 8 && p[0] == 0 {
+			return fmt.Sprintf("base media decode time %d", binary.BigEndian.Uint32(p[4:8]))
+		}
+		if len(p) >= 12 {
+			return fmt.Sprintf("base media decode time %d", binary.BigEndian.Uint64(p[4:12]))
+		}
+	case "trun":
+		if run, ok := ParseTrun(p); ok {
+			description := fmt.Sprintf("%d samples", run.SampleCount)
+			if run.Duration > 0 {
+				description += fmt.Sprintf(", duration %d", run.Duration)
+			}
+			if run.Size > 0 {
+				description += fmt.Sprintf(", %d bytes", run.Size)
+			}
+			if run.HasDataOffset {
+				description += fmt.Sprintf(", data offset %d", run.DataOffset)
+			}
+			return description
+		}
+	case "sidx":
+		return describeSidx(p)
+	case "emsg":
+		return describeEmsg(p)
+	case "schm":
+		if len(p) >= 8 {
+			return fmt.Sprintf("scheme %s", string(p[4:8]))
+		}
+	case "frma":
+		if len(p) >= 4 {
+			return fmt.Sprintf("original format %s", string(p[:4]))
+		}
+	case "mdat":
+		return fmt.Sprintf("%d bytes of media data", len(p))
+	}
+
+	if headerSize := sampleEntryHeaders[box.Type]; headerSize > 0 && len(p) >= headerSize {
+		codec := CodecString(box)
+		if headerSize == 78 {
+			return fmt.Sprintf("%s, %dx%d", codec, binary.BigEndian.Uint16(p[24:]), binary.BigEndian.Uint16(p[26:]))
+		}
+		return fmt.Sprintf("%s, %d channels, %d Hz", codec, binary.BigEndian.Uint16(p[16:]), binary.BigEndian.Uint32(p[24:])>>16)
+	}
+	return ""
+}
+
+// flags returns the 24 bit flags of a full box
+func flags(p []byte) uint32 {
+	return uint32(p[1])<<16 | uint32(p[2])<<8 | uint32(p[3])
+}
+
+// timing reads the timescale and duration of mvhd and mdhd, whose layout depends on the version
+func timing(p []byte) (uint32, uint64, bool) {
+	if len(p) < 1 {
+		return 0, 0, false
+	}
+	if p[0] == 1 {
+		if len(p) < 32 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint32(p[20:]), binary.BigEndian.Uint64(p[24:]), true
+	}
+	if len(p) < 20 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint32(p[12:]), uint64(binary.BigEndian.Uint32(p[16:])), true
+}
+
+// TrackRun summarizes a trun box
+type TrackRun struct {
+	SampleCount   int
+	HasDataOffset bool
+	DataOffset    int32
+	Duration      uint64 // Sum of the sample durations, 0 when they come from defaults
+	Size          uint64 // Sum of the sample sizes, 0 when they come from defaults
+}
+
+// ParseTrun reads a trun payload
+func ParseTrun(p []byte) (TrackRun, bool) {
+	var run TrackRun
+	if len(p) < 8 {
+		return run, false
+	}
+	f := flags(p)
+	run.SampleCount = int(binary.BigEndian.Uint32(p[4:8]))
+	offset := 8
+	if f&0x1 != 0 {
+		if len(p) < offset+4 {
+			return run, false
+		}
+		run.HasDataOffset = true
+		run.DataOffset = int32(binary.BigEndian.Uint32(p[offset:]))
+		offset += 4
+	}
+	if f&0x4 != 0 {
+		offset += 4 // First sample flags
+	}
+
+	sampleSize := 0
+	for _, field := range []uint32{0x100, 0x200, 0x400, 0x800} {
+		if f&field != 0 {
+			sampleSize += 4
+		}
+	}
+	for i := 0; i < run.SampleCount && offset+sampleSize <= len(p); i++ {
+		field := offset
+		if f&0x100 != 0 {
+			run.Duration += uint64(binary.BigEndian.Uint32(p[field:]))
+			field += 4
+		}
+		if f&0x200 != 0 {
+			run.Size += uint64(binary.BigEndian.Uint32(p[field:]))
+		}
+		offset += sampleSize
+	}
+	return run, true
+}
+
+// describeSidx summarizes a segment index box
+func describeSidx(p []byte) string {
+	if len(p) < 12 {
+		return ""
+	}
+	timescale := binary.BigEndian.Uint32(p[8:12])
+	var earliest uint64
+	offset := 12
+	if p[0] == 0 {
+		if len(p) < 24 {
+			return ""
+		}
+		earliest = uint64(binary.BigEndian.Uint32(p[12:]))
+		offset = 20
+	} else {
+		if len(p) < 32 {
+			return ""
+		}
+		earliest = binary.BigEndian.Uint64(p[12:])
+		offset = 28
+	}
+	count := int(binary.BigEndian.Uint16(p[offset+2:]))
+	offset += 4
+
+	var duration uint64
+	for i := 0; i < count && offset+12 <= len(p); i++ {
+		duration += uint64(binary.BigEndian.Uint32(p[offset+4:]))
+		offset += 12
+	}
+	return fmt.Sprintf("timescale %d, earliest %d, %d references, %.3fs", timescale, earliest, count, float64(duration)/float64(max(timescale, 1)))
+}
+
+// describeEmsg summarizes an event message box, version 0 or 1
+func describeEmsg(p []byte) string {
+	if len(p) < 4 {
+		return ""
+	}
+	cString := func(data []byte) (string, []byte) {
+		for i, b := range data {
+			if b == 0 {
+				return string(data[:i]), data[i+1:]
+			}
+		}
+		return string(data), nil
+	}
+
+	rest := p[4:]
+	if p[0] == 0 {
+		scheme, rest := cString(rest)
+		value, rest := cString(rest)
+		if len(rest) < 16 {
+			return fmt.Sprintf("scheme %s, value %s", scheme, value)
+		}
+		return fmt.Sprintf("scheme %s, value %s, timescale %d, delta %d, duration %d, id %d", scheme, value,
+			binary.BigEndian.Uint32(rest), binary.BigEndian.Uint32(rest[4:]), binary.BigEndian.Uint32(rest[8:]), binary.BigEndian.Uint32(rest[12:]))
+	}
+
+	if len(rest) < 20 {
+		return ""
+	}
+	timescale := binary.BigEndian.Uint32(rest)
+	presentation := binary.BigEndian.Uint64(rest[4:])
+	duration := binary.BigEndian.Uint32(rest[12:])
+	id := binary.BigEndian.Uint32(rest[16:])
+	scheme, rest := cString(rest[20:])
+	value, _ := cString(rest)
+	return fmt.Sprintf("scheme %s, value %s, timescale %d, time %d, duration %d, id %d", scheme, value, timescale, presentation, duration, id)
+}
@@ -0,0 +1,246 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestParseProtection(t *testing.T) {
+	kid, _ := hex.DecodeString("0123456789abcdef0123456789abcdef")
+	systemID, _ := hex.DecodeString("edef8ba979d64acea3c827dcd51d21ed")
+
+	// tenc version 1 with a 1:9 pattern and a constant IV, as cbcs uses
+	tenc := []byte{1, 0, 0, 0, 0, 0x19, 1, 0}
+	tenc = append(tenc, kid...)
+	tenc = append(tenc, 16)
+	tenc = append(tenc, make([]byte, 16)...)
+
+	sinf := box("sinf",
+		box("frma", []byte("avc1")),
+		box("schm", []byte{0, 0, 0, 0}, []byte("cbcs"), []byte{0, 1, 0, 0}),
+		box("schi", box("tenc", tenc)),
+	)
+	encv := box("encv", make([]byte, 78), sinf)
+	stsd := box("stsd", []byte{0, 0, 0, 0, 0, 0, 0, 1}, encv)
+	pssh := box("pssh", []byte{0, 0, 0, 0}, systemID, []byte{0, 0, 0, 0})
+	init := append(box("ftyp", []byte("iso6")), box("moov",
+		box("trak", box("mdia", box("minf", box("stbl", stsd)))),
+		pssh,
+	)...)
+
+	protection := ParseProtection(init)
+	if len(protection.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", protection.Errors)
+	}
+	if len(protection.Tracks) != 1 {
+		t.Fatalf("Expected 1 protected track, got %d", len(protection.Tracks))
+	}
+
+	track := protection.Tracks[0]
+	if track.OriginalFormat != "avc1" || track.SchemeType != "cbcs" || track.SchemeVersion != "1.0" {
+		t.Errorf("Unexpected scheme: %+v", track)
+	}
+	if track.DefaultKID != "01234567-89ab-cdef-0123-456789abcdef" {
+		t.Errorf("Unexpected default KID %s", track.DefaultKID)
+	}
+	if track.CryptByteBlock != 1 || track.SkipByteBlock != 9 || track.DefaultConstantIV == "" {
+		t.Errorf("Unexpected pattern or IV: %+v", track)
+	}
+	if len(protection.PSSH) != 1 || protection.PSSH[0].System().Name != "Widevine" {
+		t.Errorf("Expected a Widevine PSSH, got %+v", protection.PSSH)
+	}
+}
+
+func TestParseSenc(t *testing.T) {
+	senc := box("senc", []byte{0, 0, 0, 2}, binary.BigEndian.AppendUint32(nil, 48))
+	segment := box("moof", box("traf", senc))
+
+	protection := ParseProtection(segment)
+	if len(protection.SampleEncryption) != 1 {
+		t.Fatalf("Expected 1 senc box, got %d", len(protection.SampleEncryption))
+	}
+	if sample := protection.SampleEncryption[0]; sample.SampleCount != 48 || !sample.UseSubsamples {
+		t.Errorf("Unexpected sample encryption: %+v", sample)
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	data := box("moov", box("trak", nil))
+	if _, err := Parse(data[:len(data)-2]); err == nil {
+		t.Error("Expected an error for a truncated box")
+	}
+}
+
+func TestParseHugeLargeSize(t *testing.T) {
+	for _, size := range []uint64{math.MaxInt64, math.MaxInt64 - 4, math.MaxUint64} {
+		// The box follows another so its offset plus size overflows
+		data := binary.BigEndian.AppendUint32(box("free", nil), 1)
+		data = append(data, "mdat"...)
+		data = binary.BigEndian.AppendUint64(data, size)
+		data = append(data, make([]byte, 16)...)
+		if _, err := Parse(data); err == nil {
+			t.Errorf("Expected an error for a 64-bit size of %d", size)
+		}
+	}
+}
+
+// box builds a box from its type and payload parts
+func box(boxType string, parts ...[]byte) []byte {
+	var payload []byte
+	for _, part := range parts {
+		payload = append(payload, part...)
+	}
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+	out = append(out, boxType...)
+	return append(out, payload...)
+}
+
+func TestCodecString(t *testing.T) {
+	visual := make([]byte, 78)
+	binary.BigEndian.PutUint16(visual[24:], 1920)
+	binary.BigEndian.PutUint16(visual[26:], 1080)
+
+	dsi := []byte{0x05, 2, 0x12, 0x10} // AAC LC
+	dcd := append([]byte{0x04, 17, 0x40, 0x15, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, dsi...)
+	es := append([]byte{0x03, 22, 0, 1, 0}, dcd...)
+
+	hvcC := []byte{1, 0x01, 0x60, 0, 0, 0, 0xb0, 0, 0, 0, 0, 0, 93}
+
+	tests := []struct {
+		name  string
+		entry []byte
+		want  string
+	}{
+		{"avc1", box("avc1", visual, box("avcC", []byte{1, 0x64, 0x00, 0x1f, 0xff})), "avc1.64001f"},
+		{"hvc1", box("hvc1", visual, box("hvcC", hvcC)), "hvc1.1.6.L93.B0"},
+		{"mp4a", box("mp4a", make([]byte, 28), box("esds", []byte{0, 0, 0, 0}, es)), "mp4a.40.2"},
+		{"av01", box("av01", visual, box("av1C", []byte{0x81, 0x08, 0x40, 0})), "av01.0.08M.10"},
+		{"encv", box("encv", visual, box("avcC", []byte{1, 0x4d, 0x40, 0x1e}), box("sinf", box("frma", []byte("avc1")))), "avc1.4d401e"},
+		{"ec-3", box("ec-3", make([]byte, 28)), "ec-3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			boxes, err := Parse(test.entry)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := CodecString(boxes[0]); got != test.want {
+				t.Errorf("CodecString() = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	stsd := box("stsd", []byte{0, 0, 0, 0, 0, 0, 0, 1}, tests[0].entry)
+	boxes, _ := Parse(box("moov", box("trak", box("mdia", box("minf", box("stbl", stsd))))))
+	if codecs := Codecs(boxes); len(codecs) != 1 || codecs[0] != "avc1.64001f" {
+		t.Errorf("Codecs() = %v", codecs)
+	}
+	if description := Describe(Find(boxes, "avc1")[0]); description != "avc1.64001f, 1920x1080" {
+		t.Errorf("Describe(avc1) = %q", description)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tfdt := box("tfdt", []byte{1, 0, 0, 0}, binary.BigEndian.AppendUint64(nil, 900000))
+	// Data offset plus per-sample duration and size for two samples
+	trun := box("trun", []byte{0, 0, 0x03, 0x01, 0, 0, 0, 2, 0, 0, 0, 100,
+		0, 0, 0x0b, 0xb8, 0, 0, 0x10, 0,
+		0, 0, 0x0b, 0xb8, 0, 0, 0x08, 0})
+	emsg := box("emsg", []byte{1, 0, 0, 0}, []byte{0, 0, 0x03, 0xe8}, binary.BigEndian.AppendUint64(nil, 5000),
+		[]byte{0, 0, 0, 10, 0, 0, 0, 7}, []byte("urn:scte:scte35:2013:bin\x00"), []byte("1\x00"))
+
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{tfdt, "base media decode time 900000"},
+		{trun, "2 samples, duration 6000, 6144 bytes, data offset 100"},
+		{emsg, "scheme urn:scte:scte35:2013:bin, value 1, timescale 1000, time 5000, duration 10, id 7"},
+		{box("ftyp", []byte("iso6"), []byte{0, 0, 0, 1}, []byte("iso6cmfc")), "major iso6, minor 1, compatible iso6,cmfc"},
+	}
+
+	for _, test := range tests {
+		boxes, err := Parse(test.data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if got := Describe(boxes[0]); got != test.want {
+			t.Errorf("Describe(%s) = %q, want %q", boxes[0].Type, got, test.want)
+		}
+	}
+}
+
+func TestFragments(t *testing.T) {
+	u32 := func(values ...uint32) []byte {
+		var out []byte
+		for _, value := range values {
+			out = binary.BigEndian.AppendUint32(out, value)
+		}
+		return out
+	}
+
+	tkhd := box("tkhd", u32(0, 0, 0, 2), make([]byte, 72))
+	mdhd := box("mdhd", u32(0, 0, 0, 48000, 0))
+	hdlr := box("hdlr", u32(0, 0), []byte("soun"), make([]byte, 12))
+	trex := box("trex", u32(0, 2, 1, 1024, 0, 0))
+	moov, err := Parse(box("moov", box("trak", tkhd, box("mdia", mdhd, hdlr)), box("mvex", trex)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	tracks := Tracks(moov)
+	if len(tracks) != 1 || tracks[0] != (Track{ID: 2, Handler: "soun", Timescale: 48000, DefaultDuration: 1024}) {
+		t.Fatalf("Unexpected tracks: %+v", tracks)
+	}
+
+	// The first run relies on the trex default, the second has per sample durations
+	moof, err := Parse(box("moof", box("traf",
+		box("tfhd", u32(0, 2)),
+		box("tfdt", u32(1<<24, 0, 96000)),
+		box("trun", u32(0, 3)),
+		box("trun", u32(0x100, 2, 1000, 1048)),
+	)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	fragments := Fragments(moof, tracks)
+	want := Fragment{TrackID: 2, BaseDecodeTime: 96000, Duration: 3*1024 + 2048, Samples: 5}
+	if len(fragments) != 1 || fragments[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, fragments)
+	}
+}
+
+func TestSamples(t *testing.T) {
+	u32 := func(values ...uint32) []byte {
+		var out []byte
+		for _, value := range values {
+			out = binary.BigEndian.AppendUint32(out, value)
+		}
+		return out
+	}
+
+	// Two samples with sizes, flags and composition offsets; the second is not a sync sample
+	trun := box("trun", u32(0x000e01, 2, 0), u32(2, 0x02000000, 100), u32(3, 0x01010000, 0))
+	moof := box("moof", box("traf", box("tfhd", u32(0x020000, 1)), box("tfdt", u32(0, 9000)), trun))
+	// Point the data offset at the mdat payload
+	binary.BigEndian.PutUint32(moof[len(moof)-len(trun)+16:], uint32(len(moof)+8))
+	data := append(moof, box("mdat", []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee})...)
+
+	boxes, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	samples := Samples(data, boxes, []Track{{ID: 1, DefaultDuration: 3000}})
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %+v", samples)
+	}
+	first, second := samples[0], samples[1]
+	if !first.Sync || first.DecodeTime != 9000 || first.CompositionOffset != 100 || string(first.Data) != "\xaa\xbb" {
+		t.Errorf("Unexpected first sample: %+v", first)
+	}
+	if second.Sync || second.DecodeTime != 12000 || string(second.Data) != "\xcc\xdd\xee" {
+		t.Errorf("Unexpected second sample: %+v", second)
+	}
+}
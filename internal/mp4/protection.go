@@ -0,0 +1,122 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/drm"
+)
+
+// TrackProtection is the protection scheme of one sample entry (sinf/frma/schm/tenc)
+type TrackProtection struct {
+	OriginalFormat         string
+	SchemeType             string // cenc, cbcs, cens or cbc1
+	SchemeVersion          string
+	DefaultIsProtected     bool
+	DefaultPerSampleIVSize int
+	DefaultKID             string
+	CryptByteBlock         int
+	SkipByteBlock          int
+	DefaultConstantIV      string
+}
+
+// SampleEncryption summarizes a senc box
+type SampleEncryption struct {
+	SampleCount   int
+	UseSubsamples bool
+}
+
+// Protection is the encryption information found in an init segment or media segment
+type Protection struct {
+	Tracks           []TrackProtection
+	PSSH             []*drm.PSSH
+	SampleEncryption []SampleEncryption
+	Errors           []string
+}
+
+// Encrypted reports whether any protection boxes were found
+func (p *Protection) Encrypted() bool {
+	return len(p.Tracks) > 0 || len(p.PSSH) > 0 || len(p.SampleEncryption) > 0
+}
+
+// ParseProtection collects sinf, pssh and senc information from fMP4 data
+func ParseProtection(data []byte) *Protection {
+	protection := &Protection{}
+	boxes, err := Parse(data)
+	if err != nil {
+		protection.Errors = append(protection.Errors, err.Error())
+	}
+
+	for _, sinf := range Find(boxes, "sinf") {
+		protection.Tracks = append(protection.Tracks, parseSinf(sinf))
+	}
+	for _, box := range Find(boxes, "pssh") {
+		pssh, err := drm.ParsePSSH(box.Bytes)
+		if err != nil {
+			protection.Errors = append(protection.Errors, err.Error())
+			continue
+		}
+		protection.PSSH = append(protection.PSSH, pssh)
+	}
+	for _, box := range Find(boxes, "senc") {
+		payload := box.Payload()
+		if len(payload) < 8 {
+			protection.Errors = append(protection.Errors, "senc box too short")
+			continue
+		}
+		protection.SampleEncryption = append(protection.SampleEncryption, SampleEncryption{
+			SampleCount:   int(binary.BigEndian.Uint32(payload[4:8])),
+			UseSubsamples: payload[3]&0x02 != 0,
+		})
+	}
+	return protection
+}
+
+// parseSinf reads the frma, schm and tenc boxes of a protection scheme info box
+func parseSinf(sinf *Box) TrackProtection {
+	var track TrackProtection
+	for _, box := range Find(sinf.Children, "frma") {
+		if payload := box.Payload(); len(payload) >= 4 {
+			track.OriginalFormat = string(payload[:4])
+		}
+	}
+	for _, box := range Find(sinf.Children, "schm") {
+		if payload := box.Payload(); len(payload) >= 12 {
+			track.SchemeType = string(payload[4:8])
+			version := binary.BigEndian.Uint32(payload[8:12])
+			track.SchemeVersion = fmt.Sprintf("%d.%d", version>>16, version&0xffff)
+		}
+	}
+	for _, box := range Find(sinf.Children, "tenc") {
+		parseTenc(box.Payload(), &track)
+	}
+	return track
+}
+
+// parseTenc reads a track encryption box
+func parseTenc(payload []byte, track *TrackProtection) {
+	if len(payload) < 24 {
+		return
+	}
+	version := payload[0]
+	if version > 0 {
+		track.CryptByteBlock = int(payload[5] >> 4)
+		track.SkipByteBlock = int(payload[5] & 0x0f)
+	}
+	track.DefaultIsProtected = payload[6] == 1
+	track.DefaultPerSampleIVSize = int(payload[7])
+	track.DefaultKID = formatKID(payload[8:24])
+
+	if track.DefaultIsProtected && track.DefaultPerSampleIVSize == 0 && len(payload) > 24 {
+		size := int(payload[24])
+		if 25+size <= len(payload) {
+			track.DefaultConstantIV = hex.EncodeToString(payload[25 : 25+size])
+		}
+	}
+}
+
+// formatKID formats a 16 byte key ID as a UUID
+func formatKID(kid []byte) string {
+	h := hex.EncodeToString(kid)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
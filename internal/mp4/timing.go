@@ -0,0 +1,303 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"github.com/soldiermoth/pantui/internal/nal"
+)
+
+// Track is a track declared in an init segment
+type Track struct {
+	ID              int
+	Handler         string // vide, soun, subt...
+	Timescale       uint32
+	Format          string // Sample entry format, looking through encv/enca
+	LengthSize      int    // NAL unit length size of AVC and HEVC samples, 0 for other formats
+	DefaultDuration uint32 // trex default sample duration
+	DefaultSize     uint32
+	DefaultFlags    uint32
+}
+
+// Fragment is the decode timing of one track fragment
+type Fragment struct {
+	TrackID        int
+	BaseDecodeTime uint64
+	Duration       uint64
+	Samples        int
+}
+
+// Sample is one sample of a track fragment
+type Sample struct {
+	TrackID           int
+	DecodeTime        uint64
+	CompositionOffset int64
+	Duration          uint32
+	Size              uint32
+	Sync              bool
+	Data              []byte // Nil when the sample lies outside the parsed data
+}
+
+// sampleNonSync is the sample_is_non_sync_sample bit of sample flags
+const sampleNonSync = 0x00010000
+
+// Tracks reads the tracks of a moov and their trex defaults
+func Tracks(boxes []*Box) []Track {
+	var tracks []Track
+	for _, trak := range Find(boxes, "trak") {
+		var track Track
+		for _, tkhd := range Find(trak.Children, "tkhd") {
+			p := tkhd.Payload()
+			offset := 12
+			if len(p) > 0 && p[0] == 1 {
+				offset = 20
+			}
+			if len(p) >= offset+4 {
+				track.ID = int(binary.BigEndian.Uint32(p[offset:]))
+			}
+		}
+		for _, mdhd := range Find(trak.Children, "mdhd") {
+			track.Timescale, _, _ = timing(mdhd.Payload())
+		}
+		for _, hdlr := range Find(trak.Children, "hdlr") {
+			if p := hdlr.Payload(); len(p) >= 12 {
+				track.Handler = string(p[8:12])
+			}
+		}
+		for _, stsd := range Find(trak.Children, "stsd") {
+			if len(stsd.Children) == 0 {
+				continue
+			}
+			entry := stsd.Children[0]
+			track.Format = SampleFormat(entry)
+			for _, child := range entry.Children {
+				if size := nal.LengthSize(child.Type, child.Payload()); size > 0 {
+					track.LengthSize = size
+				}
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	for _, trex := range Find(boxes, "trex") {
+		p := trex.Payload()
+		if len(p) < 24 {
+			continue
+		}
+		id := int(binary.BigEndian.Uint32(p[4:8]))
+		for i := range tracks {
+			if tracks[i].ID == id {
+				tracks[i].DefaultDuration = binary.BigEndian.Uint32(p[12:16])
+				tracks[i].DefaultSize = binary.BigEndian.Uint32(p[16:20])
+				tracks[i].DefaultFlags = binary.BigEndian.Uint32(p[20:24])
+			}
+		}
+	}
+	return tracks
+}
+
+// Fragments reads the tfdt and trun timing of every track fragment, using tfhd or trex defaults
+// when the runs carry no sample durations
+func Fragments(boxes []*Box, tracks []Track) []Fragment {
+	var fragments []Fragment
+	for _, moof := range Find(boxes, "moof") {
+		for _, traf := range trackFragments(moof, tracks) {
+			fragment := Fragment{TrackID: traf.trackID, BaseDecodeTime: traf.baseDecodeTime}
+			for _, run := range traf.runs {
+				for _, sample := range run.samples {
+					fragment.Duration += uint64(sample.duration)
+				}
+				fragment.Samples += len(run.samples)
+			}
+			fragments = append(fragments, fragment)
+		}
+	}
+	return fragments
+}
+
+// Samples lists the samples of every track fragment in data, which must be the boxes' source,
+// with their decode times and the sample bytes found through the trun data offsets
+func Samples(data []byte, boxes []*Box, tracks []Track) []Sample {
+	var samples []Sample
+	for _, moof := range Find(boxes, "moof") {
+		for _, traf := range trackFragments(moof, tracks) {
+			decodeTime := traf.baseDecodeTime
+			offset := traf.baseOffset
+			for _, run := range traf.runs {
+				if run.hasDataOffset {
+					offset = traf.baseOffset + int64(run.dataOffset)
+				}
+				for _, entry := range run.samples {
+					sample := Sample{
+						TrackID:           traf.trackID,
+						DecodeTime:        decodeTime,
+						CompositionOffset: entry.compositionOffset,
+						Duration:          entry.duration,
+						Size:              entry.size,
+						Sync:              entry.flags&sampleNonSync == 0,
+					}
+					if end := offset + int64(entry.size); offset >= 0 && end <= int64(len(data)) {
+						sample.Data = data[offset:end]
+					}
+					samples = append(samples, sample)
+					decodeTime += uint64(entry.duration)
+					offset += int64(entry.size)
+				}
+			}
+		}
+	}
+	return samples
+}
+
+// trackFragment is a parsed traf with its defaults applied
+type trackFragment struct {
+	trackID        int
+	baseDecodeTime uint64
+	baseOffset     int64 // Where trun data offsets count from
+	runs           []trackRun
+}
+
+// trackRun is a parsed trun
+type trackRun struct {
+	hasDataOffset bool
+	dataOffset    int32
+	samples       []runSample
+}
+
+// runSample is one trun entry with defaults applied
+type runSample struct {
+	duration          uint32
+	size              uint32
+	flags             uint32
+	compositionOffset int64
+}
+
+// trackFragments parses the trafs of a moof. Data offsets count from the moof unless tfhd sets a base data offset.
+func trackFragments(moof *Box, tracks []Track) []trackFragment {
+	var fragments []trackFragment
+	for _, traf := range Find(moof.Children, "traf") {
+		fragment := trackFragment{baseOffset: moof.Offset}
+		var defaults Track
+		for _, tfhd := range Find(traf.Children, "tfhd") {
+			p := tfhd.Payload()
+			if len(p) < 8 {
+				continue
+			}
+			fragment.trackID = int(binary.BigEndian.Uint32(p[4:8]))
+			for _, track := range tracks {
+				if track.ID == fragment.trackID {
+					defaults = track
+				}
+			}
+			// Optional fields come in flag order
+			f := flags(p)
+			offset := 8
+			read := func(flag uint32, size int) (uint64, bool) {
+				if f&flag == 0 || len(p) < offset+size {
+					return 0, false
+				}
+				var value uint64
+				if size == 8 {
+					value = binary.BigEndian.Uint64(p[offset:])
+				} else {
+					value = uint64(binary.BigEndian.Uint32(p[offset:]))
+				}
+				offset += size
+				return value, true
+			}
+			if value, ok := read(0x01, 8); ok {
+				fragment.baseOffset = int64(value)
+			}
+			read(0x02, 4) // Sample description index
+			if value, ok := read(0x08, 4); ok {
+				defaults.DefaultDuration = uint32(value)
+			}
+			if value, ok := read(0x10, 4); ok {
+				defaults.DefaultSize = uint32(value)
+			}
+			if value, ok := read(0x20, 4); ok {
+				defaults.DefaultFlags = uint32(value)
+			}
+		}
+		for _, tfdt := range Find(traf.Children, "tfdt") {
+			p := tfdt.Payload()
+			if len(p) >= 8 && p[0] == 0 {
+				fragment.baseDecodeTime = uint64(binary.BigEndian.Uint32(p[4:8]))
+			} else if len(p) >= 12 {
+				fragment.baseDecodeTime = binary.BigEndian.Uint64(p[4:12])
+			}
+		}
+		for _, trun := range Find(traf.Children, "trun") {
+			if run, ok := parseTrackRun(trun.Payload(), defaults); ok {
+				fragment.runs = append(fragment.runs, run)
+			}
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments
+}
+
+// parseTrackRun reads every trun entry, falling back to the defaults for absent fields
+func parseTrackRun(p []byte, defaults Track) (trackRun, bool) {
+	var run trackRun
+	if len(p) < 8 {
+		return run, false
+	}
+	f := flags(p)
+	count := int(binary.BigEndian.Uint32(p[4:8]))
+	offset := 8
+	if f&0x1 != 0 {
+		if len(p) < offset+4 {
+			return run, false
+		}
+		run.hasDataOffset = true
+		run.dataOffset = int32(binary.BigEndian.Uint32(p[offset:]))
+		offset += 4
+	}
+	firstFlags, hasFirstFlags := uint32(0), false
+	if f&0x4 != 0 {
+		if len(p) < offset+4 {
+			return run, false
+		}
+		firstFlags, hasFirstFlags = binary.BigEndian.Uint32(p[offset:]), true
+		offset += 4
+	}
+
+	entrySize := 0
+	for _, field := range []uint32{0x100, 0x200, 0x400, 0x800} {
+		if f&field != 0 {
+			entrySize += 4
+		}
+	}
+	for i := 0; i < count && offset+entrySize <= len(p); i++ {
+		sample := runSample{duration: defaults.DefaultDuration, size: defaults.DefaultSize, flags: defaults.DefaultFlags}
+		if i == 0 && hasFirstFlags {
+			sample.flags = firstFlags
+		}
+		field := func(flag uint32) (uint32, bool) {
+			if f&flag == 0 {
+				return 0, false
+			}
+			value := binary.BigEndian.Uint32(p[offset:])
+			offset += 4
+			return value, true
+		}
+		if value, ok := field(0x100); ok {
+			sample.duration = value
+		}
+		if value, ok := field(0x200); ok {
+			sample.size = value
+		}
+		if value, ok := field(0x400); ok {
+			sample.flags = value
+		}
+		if value, ok := field(0x800); ok {
+			// Version 1 offsets are signed
+			if p[0] == 1 {
+				sample.compositionOffset = int64(int32(value))
+			} else {
+				sample.compositionOffset = int64(value)
+			}
+		}
+		run.samples = append(run.samples, sample)
+	}
+	return run, true
+}
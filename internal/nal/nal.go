@@ -0,0 +1,211 @@
+package nal
+
+// Frame types of a video access unit
+const (
+	I = "I"
+	P = "P"
+	B = "B"
+)
+
+// Units splits an Annex B byte stream into NAL units, header byte first
+func Units(data []byte) [][]byte {
+	var units [][]byte
+	start := -1
+	for i := 0; i+3 <= len(data); i++ {
+		if data[i] == 0 && data[i+1] == 0 && data[i+2] == 1 {
+			if start >= 0 {
+				units = append(units, trimZeros(data[start:i]))
+			}
+			start = i + 3
+			i += 2
+		}
+	}
+	if start >= 0 && start < len(data) {
+		units = append(units, data[start:])
+	}
+	return nonEmpty(units)
+}
+
+// LengthPrefixed splits an ISO BMFF sample into NAL units, each preceded by a big endian length of size bytes
+func LengthPrefixed(data []byte, size int) [][]byte {
+	var units [][]byte
+	for offset := 0; offset+size <= len(data); {
+		length := 0
+		for _, b := range data[offset : offset+size] {
+			length = length<<8 | int(b)
+		}
+		offset += size
+		if length > len(data)-offset {
+			length = len(data) - offset
+		}
+		units = append(units, data[offset:offset+length])
+		offset += length
+	}
+	return nonEmpty(units)
+}
+
+// nonEmpty drops empty units left by zero stuffing
+func nonEmpty(units [][]byte) [][]byte {
+	kept := units[:0]
+	for _, unit := range units {
+		if len(unit) > 0 {
+			kept = append(kept, unit)
+		}
+	}
+	return kept
+}
+
+// trimZeros drops the zero byte a four byte start code leaves at the end of the previous unit
+func trimZeros(unit []byte) []byte {
+	for len(unit) > 0 && unit[len(unit)-1] == 0 {
+		unit = unit[:len(unit)-1]
+	}
+	return unit
+}
+
+// Unescape removes emulation prevention bytes from a NAL unit
+func Unescape(unit []byte) []byte {
+	rbsp := make([]byte, 0, len(unit))
+	zeros := 0
+	for _, b := range unit {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		rbsp = append(rbsp, b)
+	}
+	return rbsp
+}
+
+// Keyframe looks for an H.264 IDR or HEVC IRAP NAL unit in an access unit
+func Keyframe(hevc bool, units [][]byte) bool {
+	for _, unit := range units {
+		if hevc {
+			if nalType := unit[0] >> 1 & 0x3f; nalType >= 16 && nalType <= 23 {
+				return true
+			}
+		} else if unit[0]&0x1f == 5 {
+			return true
+		}
+	}
+	return false
+}
+
+// SliceType is the type of the first slice in an access unit: I, P or B, empty without a slice.
+// extraBits is the PPS num_extra_slice_header_bits of HEVC streams, almost always 0.
+func SliceType(hevc bool, units [][]byte, extraBits int) string {
+	for _, unit := range units {
+		if hevc {
+			nalType := int(unit[0] >> 1 & 0x3f)
+			if nalType > 21 || len(unit) < 3 {
+				continue
+			}
+			r := &bitReader{data: Unescape(unit[2:])}
+			if r.bit() != 1 {
+				continue // Not the first slice segment of the picture
+			}
+			if nalType >= 16 {
+				r.bit() // no_output_of_prior_pics_flag
+			}
+			r.ue() // slice_pic_parameter_set_id
+			r.skip(extraBits)
+			sliceType := r.ue()
+			if r.err {
+				return ""
+			}
+			return [...]string{B, P, I, ""}[min(sliceType, 3)]
+		}
+
+		if nalType := unit[0] & 0x1f; nalType != 1 && nalType != 5 {
+			continue
+		}
+		r := &bitReader{data: Unescape(unit[1:])}
+		r.ue() // first_mb_in_slice
+		sliceType := r.ue()
+		if r.err {
+			return ""
+		}
+		// SP slices predict like P slices and SI slices like I slices
+		return [...]string{P, B, I, P, I}[sliceType%5]
+	}
+	return ""
+}
+
+// ExtraSliceHeaderBits reads num_extra_slice_header_bits from an HEVC PPS among the units, -1 without one
+func ExtraSliceHeaderBits(units [][]byte) int {
+	for _, unit := range units {
+		if unit[0]>>1&0x3f != 34 || len(unit) < 3 {
+			continue
+		}
+		r := &bitReader{data: Unescape(unit[2:])}
+		r.ue()    // pps_pic_parameter_set_id
+		r.ue()    // pps_seq_parameter_set_id
+		r.skip(2) // dependent_slice_segments_enabled_flag, output_flag_present_flag
+		bits := r.bits(3)
+		if !r.err {
+			return bits
+		}
+	}
+	return -1
+}
+
+// bitReader reads Exp-Golomb coded RBSP fields
+type bitReader struct {
+	data []byte
+	pos  int
+	err  bool
+}
+
+// bit reads one bit, flagging an error past the end
+func (r *bitReader) bit() int {
+	if r.pos >= len(r.data)*8 {
+		r.err = true
+		return 0
+	}
+	b := int(r.data[r.pos/8] >> (7 - r.pos%8) & 1)
+	r.pos++
+	return b
+}
+
+// bits reads n bits
+func (r *bitReader) bits(n int) int {
+	value := 0
+	for i := 0; i < n; i++ {
+		value = value<<1 | r.bit()
+	}
+	return value
+}
+
+// skip drops n bits
+func (r *bitReader) skip(n int) {
+	r.pos += n
+}
+
+// ue reads an unsigned Exp-Golomb value
+func (r *bitReader) ue() int {
+	zeros := 0
+	for r.bit() == 0 && !r.err && zeros < 32 {
+		zeros++
+	}
+	return 1<<zeros - 1 + r.bits(zeros)
+}
+
+// LengthSize reads the NAL unit length size from an avcC or hvcC configuration record
+func LengthSize(boxType string, config []byte) int {
+	switch boxType {
+	case "avcC":
+		if len(config) >= 5 {
+			return int(config[4]&0x03) + 1
+		}
+	case "hvcC":
+		if len(config) >= 22 {
+			return int(config[21]&0x03) + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package nal
+
+import (
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	data := []byte{0, 0, 0, 1, 0x09, 0xf0, 0, 0, 1, 0x67, 0x64, 0, 0, 0, 1, 0x65, 0x88}
+	units := Units(data)
+	if len(units) != 3 || units[1][0] != 0x67 || len(units[1]) != 2 || units[2][0] != 0x65 {
+		t.Errorf("Unexpected units: %x", units)
+	}
+
+	sample := []byte{0, 0, 0, 2, 0x09, 0xf0, 0, 0, 0, 2, 0x65, 0x88}
+	units = LengthPrefixed(sample, 4)
+	if len(units) != 2 || units[1][0] != 0x65 {
+		t.Errorf("Unexpected length prefixed units: %x", units)
+	}
+}
+
+func TestSliceType(t *testing.T) {
+	tests := []struct {
+		name string
+		hevc bool
+		unit []byte
+		want string
+	}{
+		{"H.264 IDR", false, []byte{0x65, 0x88}, I},
+		{"H.264 P", false, []byte{0x41, 0x9a}, P},
+		{"H.264 B", false, []byte{0x01, 0xa8}, B},
+		{"HEVC IDR", true, []byte{0x26, 0x01, 0xac}, I},
+		{"HEVC P", true, []byte{0x02, 0x01, 0xd0}, P},
+		{"HEVC B", true, []byte{0x02, 0x01, 0xe0}, B},
+		{"no slice", false, []byte{0x67, 0x64}, ""},
+	}
+	for _, test := range tests {
+		if got := SliceType(test.hevc, [][]byte{test.unit}, 0); got != test.want {
+			t.Errorf("%s: SliceType() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestKeyframe(t *testing.T) {
+	if !Keyframe(false, [][]byte{{0x09}, {0x65}}) || Keyframe(false, [][]byte{{0x41}}) {
+		t.Error("Expected only the H.264 IDR to be a keyframe")
+	}
+	if !Keyframe(true, [][]byte{{0x26, 0x01}}) || Keyframe(true, [][]byte{{0x02, 0x01}}) {
+		t.Error("Expected only the HEVC IRAP to be a keyframe")
+	}
+}
+
+func TestExtraSliceHeaderBits(t *testing.T) {
+	pps := []byte{0x44, 0x01, 0xc4}
+	if got := ExtraSliceHeaderBits([][]byte{pps}); got != 2 {
+		t.Errorf("ExtraSliceHeaderBits() = %d, want 2", got)
+	}
+	if got := ExtraSliceHeaderBits([][]byte{{0x26, 0x01}}); got != -1 {
+		t.Errorf("Expected -1 without a PPS, got %d", got)
+	}
+}
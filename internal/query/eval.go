@@ -0,0 +1,401 @@
+package query
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/drm"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Row is a single item of a source, keyed by lowercase field name
+type Row map[string]interface{}
+
+// Result holds the rows produced by a query
+type Result struct {
+	Columns []string
+	Rows    []Row
+}
+
+// source describes a queryable collection in a manifest
+type source struct {
+	rows    func(m *hls.Manifest) []Row
+	columns []string // Default columns when no select stage is given
+	fields  []string // Fields a row may have, besides the attributes present in the manifest
+}
+
+// sources lists the collections a query can start from
+var sources = map[string]source{
+	"segments": {
+		rows:    segmentRows,
+		columns: []string{"sequence", "duration", "uri"},
+		fields: []string{"sequence", "duration", "uri", "byterange", "discontinuity", "line", "pdt",
+			"key.method", "key.uri", "key.iv", "key.keyformat", "drm", "map.uri", "map.byterange"},
+	},
+	"variants": {
+		rows:    variantRows,
+		columns: []string{"bandwidth", "resolution", "codecs", "uri"},
+		fields: []string{"uri", "bandwidth", "resolution", "codecs", "line", "width", "height",
+			"average_bandwidth", "frame_rate", "hdcp_level", "video_range", "audio", "video", "subtitles",
+			"closed_captions", "stable_variant_id", "pathway_id", "score", "supplemental_codecs"},
+	},
+	"renditions": {
+		rows:    renditionRows,
+		columns: []string{"type", "group_id", "name", "language", "uri"},
+		fields: []string{"type", "group_id", "name", "language", "uri", "default", "autoselect", "line",
+			"assoc_language", "forced", "instream_id", "characteristics", "channels", "stable_rendition_id"},
+	},
+	"tags": {
+		rows:    tagRows,
+		columns: []string{"line", "name", "value"},
+		fields:  []string{"line", "name", "value"},
+	},
+}
+
+// Run parses and evaluates a query against a manifest
+func Run(input string, manifest *hls.Manifest) (*Result, error) {
+	q, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	return q.Eval(manifest)
+}
+
+// Eval evaluates the query against a manifest
+func (q *Query) Eval(manifest *hls.Manifest) (*Result, error) {
+	src := sources[q.Source]
+	result := &Result{
+		Columns: src.columns,
+		Rows:    src.rows(manifest),
+	}
+	schema := src.schema(result.Rows)
+
+	for _, stage := range q.Stages {
+		switch s := stage.(type) {
+		case WhereStage:
+			condition, err := bindFields(s.Condition, schema)
+			if err != nil {
+				return nil, err
+			}
+			var kept []Row
+			for _, row := range result.Rows {
+				value, err := evalExpr(condition, row)
+				if err != nil {
+					return nil, err
+				}
+				if truthy(value) {
+					kept = append(kept, row)
+				}
+			}
+			result.Rows = kept
+		case SelectStage:
+			for _, field := range s.Fields {
+				if !schema[field] {
+					return nil, fmt.Errorf("unknown field %q", field)
+				}
+			}
+			result.Columns = s.Fields
+		case SortStage:
+			field := s.Field
+			if !schema[field] {
+				return nil, fmt.Errorf("unknown field %q", field)
+			}
+			sort.SliceStable(result.Rows, func(i, j int) bool {
+				c := compare(result.Rows[i][field], result.Rows[j][field])
+				if s.Descending {
+					return c > 0
+				}
+				return c < 0
+			})
+		case LimitStage:
+			if len(result.Rows) > s.Count {
+				result.Rows = result.Rows[:s.Count]
+			}
+		case CountStage:
+			result.Rows = []Row{{"count": float64(len(result.Rows))}}
+			result.Columns = []string{"count"}
+			schema = map[string]bool{"count": true}
+		}
+	}
+
+	return result, nil
+}
+
+// schema returns the fields of a source's rows: its own and every attribute of the rows
+func (s source) schema(rows []Row) map[string]bool {
+	fields := make(map[string]bool, len(s.fields))
+	for _, field := range s.fields {
+		fields[field] = true
+	}
+	for _, row := range rows {
+		for field := range row {
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+// bindFields checks the fields of an expression against a schema. Bare words that are not fields
+// are strings on the right of a comparison, e.g. type == AUDIO, and errors anywhere else.
+func bindFields(e Expr, schema map[string]bool) (Expr, error) {
+	switch ex := e.(type) {
+	case FieldExpr:
+		if !schema[ex.Name] {
+			return nil, fmt.Errorf("unknown field %q", ex.Name)
+		}
+	case NotExpr:
+		operand, err := bindFields(ex.Operand, schema)
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Operand: operand}, nil
+	case MatchExpr:
+		operand, err := bindFields(ex.Operand, schema)
+		if err != nil {
+			return nil, err
+		}
+		return MatchExpr{Operand: operand, Pattern: ex.Pattern, Negate: ex.Negate}, nil
+	case BinaryExpr:
+		left, err := bindFields(ex.Left, schema)
+		if err != nil {
+			return nil, err
+		}
+		right := ex.Right
+		if field, ok := right.(FieldExpr); ok && !schema[field.Name] && ex.Op != "&&" && ex.Op != "||" {
+			right = LiteralExpr{Value: field.Name}
+		} else if right, err = bindFields(ex.Right, schema); err != nil {
+			return nil, err
+		}
+		return BinaryExpr{Op: ex.Op, Left: left, Right: right}, nil
+	}
+	return e, nil
+}
+
+// evalExpr evaluates an expression against a row
+func evalExpr(e Expr, row Row) (interface{}, error) {
+	switch ex := e.(type) {
+	case LiteralExpr:
+		return ex.Value, nil
+	case FieldExpr:
+		// Rows without a field of their source have no value for it
+		return row[ex.Name], nil
+	case NotExpr:
+		value, err := evalExpr(ex.Operand, row)
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	case MatchExpr:
+		value, err := evalExpr(ex.Operand, row)
+		if err != nil {
+			return nil, err
+		}
+		return ex.Pattern.MatchString(toString(value)) != ex.Negate, nil
+	case BinaryExpr:
+		left, err := evalExpr(ex.Left, row)
+		if err != nil {
+			return nil, err
+		}
+		switch ex.Op {
+		case "&&":
+			if !truthy(left) {
+				return false, nil
+			}
+			right, err := evalExpr(ex.Right, row)
+			return truthy(right), err
+		case "||":
+			if truthy(left) {
+				return true, nil
+			}
+			right, err := evalExpr(ex.Right, row)
+			return truthy(right), err
+		}
+
+		right, err := evalExpr(ex.Right, row)
+		if err != nil {
+			return nil, err
+		}
+		return evalComparison(ex.Op, left, right)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", e)
+}
+
+// evalComparison applies a comparison operator
+func evalComparison(op string, left, right interface{}) (bool, error) {
+	switch op {
+	case "==":
+		return compare(left, right) == 0, nil
+	case "!=":
+		return compare(left, right) != 0, nil
+	case ">":
+		return compare(left, right) > 0, nil
+	case "<":
+		return compare(left, right) < 0, nil
+	case ">=":
+		return compare(left, right) >= 0, nil
+	case "<=":
+		return compare(left, right) <= 0, nil
+	}
+	return false, fmt.Errorf("unsupported operator %q", op)
+}
+
+// compare orders two values numerically when possible, otherwise as strings
+func compare(a, b interface{}) int {
+	af, aok := toNumber(a)
+	bf, bok := toNumber(b)
+	if aok && bok {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(toString(a)), strings.ToLower(toString(b)))
+}
+
+// toNumber converts a value to a number if it represents one
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toString formats a value for comparison and display
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(s)
+	case bool:
+		return strconv.FormatBool(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// truthy reports whether a value counts as true in a where clause
+func truthy(v interface{}) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	case float64:
+		return b != 0
+	case string:
+		return b != ""
+	}
+	return true
+}
+
+// attributeField converts an HLS attribute name to a field name
+func attributeField(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "-", "_")
+}
+
+// segmentRows builds rows for media segments
+func segmentRows(m *hls.Manifest) []Row {
+	rows := make([]Row, 0, len(m.Segments))
+	for _, s := range m.Segments {
+		row := Row{
+			"sequence":      float64(s.Sequence),
+			"duration":      s.Duration,
+			"uri":           s.URI,
+			"byterange":     s.ByteRange,
+			"discontinuity": s.Discontinuity,
+			"line":          float64(s.LineNumber),
+			"key.method":    "NONE",
+		}
+		if s.ProgramDateTime != nil {
+			row["pdt"] = s.ProgramDateTime.Format("2006-01-02T15:04:05.000Z07:00")
+		}
+		if s.Key != nil && s.Key.Method != "" {
+			row["key.method"] = s.Key.Method
+			row["key.uri"] = s.Key.URI
+			row["key.iv"] = s.Key.IV
+			row["key.keyformat"] = s.Key.KeyFormat
+		}
+		row["drm"] = strings.Join(drm.SegmentSystems(&s), ",")
+		if s.Map != nil {
+			row["map.uri"] = s.Map.URI
+			row["map.byterange"] = s.Map.ByteRange
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// variantRows builds rows for variant streams
+func variantRows(m *hls.Manifest) []Row {
+	rows := make([]Row, 0, len(m.Variants))
+	for _, v := range m.Variants {
+		row := Row{}
+		for key, value := range v.Attributes {
+			row[attributeField(key)] = value
+		}
+		row["uri"] = v.URI
+		row["bandwidth"] = float64(v.Bandwidth)
+		row["resolution"] = v.Resolution
+		row["codecs"] = v.Codecs
+		row["line"] = float64(v.LineNumber)
+
+		var width, height int
+		if _, err := fmt.Sscanf(v.Resolution, "%dx%d", &width, &height); err == nil {
+			row["width"] = float64(width)
+			row["height"] = float64(height)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// renditionRows builds rows for EXT-X-MEDIA renditions
+func renditionRows(m *hls.Manifest) []Row {
+	rows := make([]Row, 0, len(m.Renditions))
+	for _, r := range m.Renditions {
+		row := Row{}
+		for key, value := range r.Attributes {
+			row[attributeField(key)] = value
+		}
+		row["type"] = r.Type
+		row["group_id"] = r.GroupID
+		row["name"] = r.Name
+		row["language"] = r.Language
+		row["uri"] = r.URI
+		row["default"] = r.Default
+		row["autoselect"] = r.Autoselect
+		row["line"] = float64(r.LineNumber)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// tagRows builds rows for every tag in the manifest
+func tagRows(m *hls.Manifest) []Row {
+	rows := make([]Row, 0, len(m.Tags))
+	for _, t := range m.Tags {
+		row := Row{}
+		for key, value := range t.Attributes {
+			row[attributeField(key)] = value
+		}
+		row["name"] = strings.TrimPrefix(t.Name, "#")
+		row["value"] = t.Value
+		row["line"] = float64(t.LineNumber)
+		rows = append(rows, row)
+	}
+	return rows
+}
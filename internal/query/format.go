@@ -0,0 +1,96 @@
+package query
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Value returns the display value of a column in a row
+func (r Row) Value(column string) string {
+	return toString(r[column])
+}
+
+// Table renders the result as an aligned plain text table
+func (r *Result) Table() string {
+	widths := make([]int, len(r.Columns))
+	for i, column := range r.Columns {
+		widths[i] = len(column)
+	}
+	for _, row := range r.Rows {
+		for i, column := range r.Columns {
+			if n := len(row.Value(column)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(values []string) {
+		for i, value := range values {
+			if i == len(values)-1 {
+				b.WriteString(value)
+			} else {
+				b.WriteString(fmt.Sprintf("%-*s  ", widths[i], value))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	header := make([]string, len(r.Columns))
+	for i, column := range r.Columns {
+		header[i] = strings.ToUpper(column)
+	}
+	writeRow(header)
+
+	for _, row := range r.Rows {
+		values := make([]string, len(r.Columns))
+		for i, column := range r.Columns {
+			values[i] = row.Value(column)
+		}
+		writeRow(values)
+	}
+
+	return b.String()
+}
+
+// CSV renders the result as comma separated values with a header row
+func (r *Result) CSV() (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+
+	if err := w.Write(r.Columns); err != nil {
+		return "", err
+	}
+	for _, row := range r.Rows {
+		values := make([]string, len(r.Columns))
+		for i, column := range r.Columns {
+			values[i] = row.Value(column)
+		}
+		if err := w.Write(values); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+
+	return b.String(), w.Error()
+}
+
+// JSON renders the result as an array of objects holding the selected columns
+func (r *Result) JSON() (string, error) {
+	objects := make([]map[string]interface{}, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		object := make(map[string]interface{}, len(r.Columns))
+		for _, column := range r.Columns {
+			object[column] = row[column]
+		}
+		objects = append(objects, object)
+	}
+
+	data, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,449 @@
+// Package query implements a small pipeline language for querying HLS manifests.
+//
+// A query starts with a source and is followed by stages separated by pipes:
+//
+//	segments | where duration > 6.1 | select sequence, uri
+//	variants | where resolution == "1920x1080"
+//	segments | where key.method != "NONE" | sort duration desc | limit 10
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Query represents a parsed query pipeline
+type Query struct {
+	Source string
+	Stages []Stage
+}
+
+// Stage represents a single pipeline stage
+type Stage interface {
+	stage()
+}
+
+// WhereStage keeps rows matching a condition
+type WhereStage struct {
+	Condition Expr
+}
+
+// SelectStage picks the columns to output
+type SelectStage struct {
+	Fields []string
+}
+
+// SortStage orders rows by a field
+type SortStage struct {
+	Field      string
+	Descending bool
+}
+
+// LimitStage keeps the first N rows
+type LimitStage struct {
+	Count int
+}
+
+// CountStage replaces the rows with their count
+type CountStage struct{}
+
+func (WhereStage) stage()  {}
+func (SelectStage) stage() {}
+func (SortStage) stage()   {}
+func (LimitStage) stage()  {}
+func (CountStage) stage()  {}
+
+// Expr represents an expression evaluated against a row
+type Expr interface {
+	expr()
+}
+
+// BinaryExpr combines two expressions with an operator
+type BinaryExpr struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// NotExpr negates an expression
+type NotExpr struct {
+	Operand Expr
+}
+
+// FieldExpr references a row field
+type FieldExpr struct {
+	Name string
+}
+
+// LiteralExpr is a constant value
+type LiteralExpr struct {
+	Value interface{}
+}
+
+// MatchExpr matches an expression against a case-insensitive pattern, ~ or !~ when negated
+type MatchExpr struct {
+	Operand Expr
+	Pattern *regexp.Regexp
+	Negate  bool
+}
+
+func (BinaryExpr) expr()  {}
+func (NotExpr) expr()     {}
+func (FieldExpr) expr()   {}
+func (LiteralExpr) expr() {}
+func (MatchExpr) expr()   {}
+
+// Parse parses a query string
+func Parse(input string) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.parseQuery()
+}
+
+// tokenKind identifies the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenPipe
+	tokenComma
+	tokenLParen
+	tokenRParen
+)
+
+// token is a lexical token
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are matched longest first
+var operators = []string{"==", "!=", ">=", "<=", "!~", "&&", "||", "=", ">", "<", "~", "!"}
+
+// tokenize splits the input into tokens
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '|' && !strings.HasPrefix(input[i:], "||"):
+			tokens = append(tokens, token{tokenPipe, "|", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(input[i+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{tokenString, input[i+1 : i+1+end], i})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(input) && input[i+1] >= '0' && input[i+1] <= '9':
+			start := i
+			i++
+			for i < len(input) && (input[i] >= '0' && input[i] <= '9' || input[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, input[start:i], start})
+		case isIdentChar(c):
+			start := i
+			for i < len(input) && (isIdentChar(input[i]) || input[i] >= '0' && input[i] <= '9' || input[i] == '.' || input[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, input[start:i], start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{tokenOperator, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+
+	tokens = append(tokens, token{tokenEOF, "", len(input)})
+	return tokens, nil
+}
+
+// isIdentChar reports whether c may start an identifier
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+// parser is a recursive descent parser over tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek returns the current token
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consumes and returns the current token
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword reports whether the current token is the given keyword
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+// parseQuery parses source | stage | stage ...
+func (p *parser) parseQuery() (*Query, error) {
+	source := p.next()
+	if source.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a source (segments, variants, renditions, tags) at position %d", source.pos)
+	}
+
+	q := &Query{Source: strings.ToLower(source.text)}
+	if _, ok := sources[q.Source]; !ok {
+		return nil, fmt.Errorf("unknown source %q (expected segments, variants, renditions or tags)", source.text)
+	}
+
+	for p.peek().kind == tokenPipe {
+		p.next()
+		stage, err := p.parseStage()
+		if err != nil {
+			return nil, err
+		}
+		q.Stages = append(q.Stages, stage)
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return q, nil
+}
+
+// parseStage parses a single pipeline stage
+func (p *parser) parseStage() (Stage, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a stage (where, select, sort, limit, count) at position %d", t.pos)
+	}
+
+	switch strings.ToLower(t.text) {
+	case "where":
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return WhereStage{Condition: cond}, nil
+	case "select":
+		var fields []string
+		for {
+			f := p.next()
+			if f.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field name at position %d", f.pos)
+			}
+			fields = append(fields, strings.ToLower(f.text))
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		return SelectStage{Fields: fields}, nil
+	case "sort":
+		if p.isKeyword("by") {
+			p.next()
+		}
+		f := p.next()
+		if f.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a field name at position %d", f.pos)
+		}
+		stage := SortStage{Field: strings.ToLower(f.text)}
+		if p.isKeyword("desc") {
+			p.next()
+			stage.Descending = true
+		} else if p.isKeyword("asc") {
+			p.next()
+		}
+		return stage, nil
+	case "limit":
+		n := p.next()
+		count, err := strconv.Atoi(n.text)
+		if n.kind != tokenNumber || err != nil || count < 0 {
+			return nil, fmt.Errorf("expected a row count at position %d", n.pos)
+		}
+		return LimitStage{Count: count}, nil
+	case "count":
+		return CountStage{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown stage %q at position %d", t.text, t.pos)
+}
+
+// parseOr parses expr || expr
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "||" || p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = BinaryExpr{Op: "||", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses expr && expr
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "&&" || p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = BinaryExpr{Op: "&&", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseNot parses !expr
+func (p *parser) parseNot() (Expr, error) {
+	if p.peek().text == "!" || p.isKeyword("not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses operand OP operand
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return left, nil
+	}
+	switch t.text {
+	case "==", "=", "!=", ">", "<", ">=", "<=", "~", "!~":
+	default:
+		return left, nil
+	}
+	p.next()
+
+	if t.text == "~" || t.text == "!~" {
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		return MatchExpr{Operand: left, Pattern: pattern, Negate: t.text == "!~"}, nil
+	}
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	op := t.text
+	if op == "=" {
+		op = "=="
+	}
+	return BinaryExpr{Op: op, Left: left, Right: right}, nil
+}
+
+// parsePattern parses the string, word or number after ~ as a case-insensitive regular expression
+func (p *parser) parsePattern() (*regexp.Regexp, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString, tokenIdent, tokenNumber:
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of query, expected a pattern")
+	default:
+		return nil, fmt.Errorf("expected a pattern at position %d", t.pos)
+	}
+	pattern, err := regexp.Compile("(?i)" + t.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q at position %d: %w", t.text, t.pos, err)
+	}
+	return pattern, nil
+}
+
+// parsePrimary parses a field, literal or parenthesized expression
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+		return inner, nil
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return LiteralExpr{Value: value}, nil
+	case tokenString:
+		return LiteralExpr{Value: t.text}, nil
+	case tokenIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return LiteralExpr{Value: true}, nil
+		case "false":
+			return LiteralExpr{Value: false}, nil
+		}
+		return FieldExpr{Name: strings.ToLower(t.text)}, nil
+	}
+
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
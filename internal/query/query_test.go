@@ -0,0 +1,184 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/soldiermoth/pantui/internal/hls"
+)
+
+func testMediaManifest() *hls.Manifest {
+	return &hls.Manifest{
+		Type: hls.MediaManifest,
+		Segments: []hls.Segment{
+			{URI: "seg0.ts", Duration: 6.0, Sequence: 0},
+			{URI: "seg1.ts", Duration: 6.2, Sequence: 1, Key: &hls.Key{Method: "AES-128", URI: "key.bin"}},
+			{URI: "seg2.ts", Duration: 4.5, Sequence: 2, Discontinuity: true},
+		},
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"frames",
+		"segments | bogus",
+		"segments | where (duration > 1",
+		"segments | limit x",
+		`segments | where uri == "unterminated`,
+		`segments | where uri ~ "seg["`,
+		"segments | where uri ~",
+		"segments | where uri ~ (seg)",
+	}
+
+	for _, input := range tests {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error parsing %q", input)
+		}
+	}
+}
+
+func TestWhereSelect(t *testing.T) {
+	result, err := Run("segments | where duration > 6.1 | select sequence, uri", testMediaManifest())
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if len(result.Rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(result.Rows))
+	}
+	if got := result.Rows[0].Value("uri"); got != "seg1.ts" {
+		t.Errorf("Expected seg1.ts, got %s", got)
+	}
+	if strings.Join(result.Columns, ",") != "sequence,uri" {
+		t.Errorf("Expected columns sequence,uri, got %v", result.Columns)
+	}
+}
+
+func TestBooleanOperators(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{`segments | where key.method != "NONE"`, 1},
+		{`segments | where key.method == NONE && duration < 5`, 1},
+		{`segments | where discontinuity || duration == 6`, 2},
+		{`segments | where not discontinuity`, 2},
+		{`segments | where uri ~ "seg[12]"`, 2},
+		{`segments | where uri !~ "^seg0"`, 2},
+		{"segments | where uri ~ SEG1", 1},
+		{"segments | where sequence ~ 2", 1},
+	}
+
+	for _, test := range tests {
+		result, err := Run(test.query, testMediaManifest())
+		if err != nil {
+			t.Errorf("Query %q failed: %v", test.query, err)
+			continue
+		}
+		if len(result.Rows) != test.expected {
+			t.Errorf("Query %q: expected %d rows, got %d", test.query, test.expected, len(result.Rows))
+		}
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	tests := []string{
+		"segments | where duratoin > 6",
+		"segments | where bandwdith > 0",
+		"segments | where not discontinuty",
+		"segments | where duration > 1 && codec",
+		`segments | where NONE == key.method`,
+		"variants | where codec ~ avc1",
+		"segments | select sequnce, durtion",
+		"segments | sort durtion desc",
+		"segments | count | select sequence",
+	}
+
+	for _, query := range tests {
+		_, err := Run(query, testMediaManifest())
+		if err == nil || !strings.Contains(err.Error(), "unknown field") {
+			t.Errorf("Query %q: expected an unknown field error, got %v", query, err)
+		}
+	}
+}
+
+func TestAbsentFields(t *testing.T) {
+	// pdt and key.uri are segment fields, absent from rows without them
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"segments | where pdt", 0},
+		{"segments | where key.uri", 1},
+		{`segments | where key.uri == ""`, 2},
+		{"segments | where map.uri == init.mp4", 0},
+	}
+
+	for _, test := range tests {
+		result, err := Run(test.query, testMediaManifest())
+		if err != nil {
+			t.Errorf("Query %q failed: %v", test.query, err)
+			continue
+		}
+		if len(result.Rows) != test.expected {
+			t.Errorf("Query %q: expected %d rows, got %d", test.query, test.expected, len(result.Rows))
+		}
+	}
+}
+
+func TestSortLimitCount(t *testing.T) {
+	result, err := Run("segments | sort by duration desc | limit 2", testMediaManifest())
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0].Value("uri") != "seg1.ts" {
+		t.Errorf("Unexpected sort result: %v", result.Rows)
+	}
+
+	result, err = Run("segments | where duration >= 6 | count", testMediaManifest())
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Rows[0].Value("count") != "2" {
+		t.Errorf("Expected count 2, got %s", result.Rows[0].Value("count"))
+	}
+}
+
+func TestVariantQuery(t *testing.T) {
+	manifest := &hls.Manifest{
+		Type: hls.MasterManifest,
+		Variants: []hls.Variant{
+			{URI: "low.m3u8", Bandwidth: 800000, Resolution: "640x360", Attributes: map[string]string{"AUDIO": "aud"}},
+			{URI: "high.m3u8", Bandwidth: 6000000, Resolution: "1920x1080", Attributes: map[string]string{"AUDIO": "aud"}},
+		},
+	}
+
+	result, err := Run(`variants | where resolution == "1920x1080"`, manifest)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Value("uri") != "high.m3u8" {
+		t.Errorf("Unexpected result: %v", result.Rows)
+	}
+
+	result, err = Run(`variants | where height < 720 && audio == "aud"`, manifest)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Value("uri") != "low.m3u8" {
+		t.Errorf("Unexpected result: %v", result.Rows)
+	}
+}
+
+func TestTable(t *testing.T) {
+	result, err := Run("segments | select sequence, uri | limit 1", testMediaManifest())
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	expected := "SEQUENCE  URI\n0         seg0.ts\n"
+	if table := result.Table(); table != expected {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", expected, table)
+	}
+}
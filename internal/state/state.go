@@ -0,0 +1,137 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Bookmark kinds
+const (
+	ManifestBookmark = "manifest"
+	VariantBookmark  = "variant"
+	SegmentBookmark  = "segment"
+)
+
+// Bookmark is a manifest, variant or segment saved with a note to revisit later
+type Bookmark struct {
+	Kind     string    `json:"kind"`
+	Label    string    `json:"label"`
+	URL      string    `json:"url"`           // Manifest opened by the bookmark, the master manifest of a variant
+	URI      string    `json:"uri,omitempty"` // Variant or segment URI in the manifest
+	Sequence int       `json:"sequence,omitempty"`
+	Note     string    `json:"note,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// View is a view of a tab reopened by a resumed session, with the cursor and queries it had
+type View struct {
+	Type         string       `json:"type"`          // "master", "media" or "segment"
+	URL          string       `json:"url,omitempty"` // Manifest of master and media views
+	Label        string       `json:"label"`
+	Title        string       `json:"title,omitempty"`
+	Line         int          `json:"line,omitempty"`
+	ScrollRow    int          `json:"scroll_row,omitempty"`
+	ScrollColumn int          `json:"scroll_column,omitempty"`
+	SearchQuery  string       `json:"search_query,omitempty"`
+	FilterQuery  string       `json:"filter_query,omitempty"`
+	Segment      *hls.Segment `json:"segment,omitempty"`
+	ResolvedURL  string       `json:"resolved_url,omitempty"`
+}
+
+// Tab is the navigation stack of a tab, from its first view to the current one
+type Tab struct {
+	Views []View `json:"views"`
+}
+
+// Session is the tabs open when pantui last exited
+type Session struct {
+	Tabs   []Tab     `json:"tabs"`
+	Active int       `json:"active"`
+	Saved  time.Time `json:"saved"`
+}
+
+// State is what pantui keeps between runs
+type State struct {
+	Bookmarks []Bookmark `json:"bookmarks"`
+	Session   *Session   `json:"session,omitempty"`
+}
+
+// DefaultPath is the state file, pantui/state.json under the user's config directory
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pantui", "state.json")
+}
+
+// Load reads the state file. A file that doesn't exist is an empty state.
+func Load(path string) (*State, error) {
+	s := &State{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state file, replacing it at once so a crash never leaves it half written
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Update loads the state file, changes it and saves it again, so several instances of pantui
+// only overwrite what each of them changed
+func Update(path string, change func(s *State)) error {
+	if path == "" {
+		return fmt.Errorf("no state file, the user config directory is unknown")
+	}
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	change(s)
+	return s.Save(path)
+}
+
+// Location makes a manifest or segment location usable from any working directory by making file paths absolute
+func Location(location string) string {
+	if location == "" || strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return location
+	}
+	if abs, err := filepath.Abs(location); err == nil {
+		return abs
+	}
+	return location
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"github.com/soldiermoth/pantui/internal/hls"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Bookmarks) != 0 || s.Session != nil {
+		t.Errorf("Expected an empty state, got %+v", s)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pantui", "state.json")
+
+	err := Update(path, func(s *State) {
+		s.Bookmarks = append(s.Bookmarks, Bookmark{Kind: SegmentBookmark, Label: "seg 1042", URL: "https://example.com/720p.m3u8", URI: "seg1042.ts", Sequence: 1042, Note: "PTS jump"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Update(path, func(s *State) {
+		s.Session = &Session{Tabs: []Tab{{Views: []View{
+			{Type: "master", URL: "https://example.com/master.m3u8", Label: "Master", Line: 12},
+			{Type: "segment", Label: "seg 7", Segment: &hls.Segment{URI: "seg7.ts", Sequence: 7}, ResolvedURL: "https://example.com/seg7.ts"},
+		}}}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Bookmarks) != 1 || s.Bookmarks[0].Note != "PTS jump" || s.Bookmarks[0].Sequence != 1042 {
+		t.Errorf("Expected the bookmark to be kept, got %+v", s.Bookmarks)
+	}
+	if s.Session == nil || len(s.Session.Tabs) != 1 || len(s.Session.Tabs[0].Views) != 2 {
+		t.Fatalf("Expected the session to be saved, got %+v", s.Session)
+	}
+	views := s.Session.Tabs[0].Views
+	if views[0].Line != 12 || views[1].Segment == nil || views[1].Segment.Sequence != 7 {
+		t.Errorf("Expected the cursor and segment to be saved, got %+v", views)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	if got := Location("https://example.com/master.m3u8"); got != "https://example.com/master.m3u8" {
+		t.Errorf("Expected URLs to be kept, got %s", got)
+	}
+	if got := Location("streams/master.m3u8"); !filepath.IsAbs(got) {
+		t.Errorf("Expected an absolute path, got %s", got)
+	}
+}
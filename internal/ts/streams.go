@@ -0,0 +1,133 @@
+package ts
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/nal"
+)
+
+// streamTypes names the PMT stream types seen in HLS
+var streamTypes = map[byte]struct{ codec, kind string }{
+	0x01: {"MPEG-1 Video", "video"},
+	0x02: {"MPEG-2 Video", "video"},
+	0x03: {"MPEG-1 Audio", "audio"},
+	0x04: {"MPEG-2 Audio", "audio"},
+	0x0f: {"AAC", "audio"},
+	0x11: {"AAC LATM", "audio"},
+	0x15: {"ID3 Metadata", "metadata"},
+	0x1b: {"H.264", "video"},
+	0x24: {"HEVC", "video"},
+	0x81: {"AC-3", "audio"},
+	0x87: {"E-AC-3", "audio"},
+	0x86: {"SCTE-35", "data"},
+	// SAMPLE-AES streams, see the HLS Sample Encryption spec
+	0xdb: {"H.264 (SAMPLE-AES)", "video"},
+	0xcf: {"AAC (SAMPLE-AES)", "audio"},
+	0xc1: {"AC-3 (SAMPLE-AES)", "audio"},
+	0xc2: {"E-AC-3 (SAMPLE-AES)", "audio"},
+}
+
+// registrations maps registration descriptor format identifiers to codecs
+var registrations = map[string]struct{ codec, kind string }{
+	"AC-3": {"AC-3", "audio"},
+	"EAC3": {"E-AC-3", "audio"},
+	"ID3 ": {"ID3 Metadata", "metadata"},
+	"Opus": {"Opus", "audio"},
+	"CUEI": {"SCTE-35", "data"},
+}
+
+// describeStream names the codec and kind of an elementary stream, looking at descriptors for private streams
+func describeStream(streamType byte, descriptors []byte) (string, string) {
+	if known, ok := streamTypes[streamType]; ok {
+		return known.codec, known.kind
+	}
+
+	for i := 0; i+2 <= len(descriptors); {
+		tag := descriptors[i]
+		length := int(descriptors[i+1])
+		body := descriptors[i+2:]
+		if length < len(body) {
+			body = body[:length]
+		}
+		switch tag {
+		case 0x05: // Registration
+			if len(body) >= 4 {
+				if known, ok := registrations[string(body[:4])]; ok {
+					return known.codec, known.kind
+				}
+			}
+		case 0x6a:
+			return "AC-3", "audio"
+		case 0x7a:
+			return "E-AC-3", "audio"
+		case 0x59:
+			return "DVB Subtitles", "data"
+		}
+		i += 2 + length
+	}
+
+	if streamType == 0x06 {
+		return "Private PES", "data"
+	}
+	return "Unknown", "data"
+}
+
+// hevcTypes are the stream types carrying HEVC, whose NAL unit headers differ from H.264
+var hevcTypes = map[byte]bool{0x24: true}
+
+// isKeyframe looks for an IDR or IRAP NAL unit in a video access unit.
+// known is false when the codec's NAL units can't be inspected.
+func isKeyframe(streamType byte, units [][]byte) (keyframe bool, known bool) {
+	switch streamType {
+	case 0x1b, 0xdb, 0x24:
+		return nal.Keyframe(hevcTypes[streamType], units), true
+	}
+	return false, false
+}
+
+// frameType is the slice type of a video access unit, empty when it can't be parsed
+func frameType(streamType byte, units [][]byte, extraBits int) string {
+	switch streamType {
+	case 0x1b, 0xdb, 0x24:
+		return nal.SliceType(hevcTypes[streamType], units, extraBits)
+	}
+	return ""
+}
+
+// videoCodecString derives the RFC 6381 codec string from a sequence parameter set, empty without one
+func videoCodecString(streamType byte, units [][]byte) string {
+	for _, unit := range units {
+		switch streamType {
+		case 0x1b, 0xdb:
+			if unit[0]&0x1f == 7 && len(unit) >= 4 {
+				return codecs.AVCString("avc1", unit[1], unit[2], unit[3])
+			}
+		case 0x24:
+			// The general profile_tier_level follows the 2 byte header and one byte of SPS fields
+			if unit[0]>>1&0x3f == 33 {
+				if rbsp := nal.Unescape(unit); len(rbsp) >= 15 {
+					return codecs.HEVCString("hvc1", rbsp[3:15])
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// audioCodecString derives the RFC 6381 codec string from the start of an audio PES payload
+func audioCodecString(streamType byte, data []byte) string {
+	switch streamType {
+	case 0x0f, 0xcf:
+		// ADTS profile is the audio object type minus one
+		if len(data) >= 3 && data[0] == 0xff && data[1]&0xf0 == 0xf0 {
+			return fmt.Sprintf("mp4a.40.%d", data[2]>>6+1)
+		}
+	case 0x03, 0x04:
+		return "mp4a.40.34"
+	case 0x81, 0xc1:
+		return "ac-3"
+	case 0x87, 0xc2:
+		return "ec-3"
+	}
+	return ""
+}
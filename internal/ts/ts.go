@@ -0,0 +1,510 @@
+package ts
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/nal"
+	"sort"
+)
+
+// PacketSize is the size of an MPEG-TS packet
+const PacketSize = 188
+
+// SyncByte starts every MPEG-TS packet
+const SyncByte = 0x47
+
+// PIDs with a fixed meaning
+const (
+	PATPID  = 0x0000
+	NullPID = 0x1fff
+)
+
+// Clock is the frequency of PTS and DTS values
+const Clock = 90000
+
+// maxPESScan bounds how much of a video PES is kept to look for keyframe NAL units
+const maxPESScan = 64 * 1024
+
+// Stream is an elementary stream declared in a PMT
+type Stream struct {
+	PID         int
+	Program     int
+	StreamType  byte
+	Codec       string
+	Kind        string // video, audio, metadata or data
+	CodecString string // RFC 6381 codec string from the SPS or audio headers, when found
+}
+
+// Timestamps tracks the PTS or DTS values seen on a PID, in 90kHz ticks
+type Timestamps struct {
+	Count int
+	First int64
+	Last  int64
+	Min   int64
+	Max   int64
+}
+
+// add records a timestamp
+func (t *Timestamps) add(value int64) {
+	if t.Count == 0 {
+		t.First, t.Min, t.Max = value, value, value
+	}
+	t.Count++
+	t.Last = value
+	if value < t.Min {
+		t.Min = value
+	}
+	if value > t.Max {
+		t.Max = value
+	}
+}
+
+// Duration is the span between the lowest and highest timestamp in seconds
+func (t Timestamps) Duration() float64 {
+	return float64(t.Max-t.Min) / Clock
+}
+
+// PIDStats summarises the packets carried on one PID
+type PIDStats struct {
+	PID              int
+	Kind             string
+	Packets          int
+	ContinuityErrors int
+	PES              int // PES packets started on this PID
+	Keyframes        int
+	PTS              Timestamps
+	DTS              Timestamps // Falls back to the PTS when a PES carries no DTS
+}
+
+// ContinuityError is a continuity counter that did not follow the previous packet on its PID
+type ContinuityError struct {
+	PID      int
+	Packet   int // Index of the packet in the segment
+	Expected int
+	Got      int
+}
+
+// Frame is a video access unit, one PES packet of the first video PID
+type Frame struct {
+	PTS      int64
+	DTS      int64 // Equals the PTS when the PES carries no DTS
+	Size     int   // PES payload bytes
+	Keyframe bool
+	Type     string // I, P or B from the first slice header, empty when unknown
+}
+
+// Analysis is the result of walking an MPEG-TS segment
+type Analysis struct {
+	Size               int
+	Packets            int
+	Skipped            int // Bytes before the first sync byte, e.g. an ID3 header
+	SyncErrors         int
+	TransportErrors    int         // Packets with the transport error indicator set
+	Programs           map[int]int // Program number to PMT PID
+	Streams            []Stream
+	PIDs               []*PIDStats
+	ContinuityErrors   []ContinuityError
+	StartsWithPAT      bool
+	StartsWithPMT      bool
+	VideoPID           int // First video PID, 0 without video
+	StartsWithKeyframe bool
+	Frames             []Frame // Access units of VideoPID in decode order
+	Errors             []string
+}
+
+// PID returns the statistics for a PID, nil if it never appeared
+func (a *Analysis) PID(pid int) *PIDStats {
+	for _, stats := range a.PIDs {
+		if stats.PID == pid {
+			return stats
+		}
+	}
+	return nil
+}
+
+// Stream returns the elementary stream on a PID, nil if the PMT doesn't declare it
+func (a *Analysis) Stream(pid int) *Stream {
+	for i := range a.Streams {
+		if a.Streams[i].PID == pid {
+			return &a.Streams[i]
+		}
+	}
+	return nil
+}
+
+// Warnings lists the problems a player is likely to trip over
+func (a *Analysis) Warnings() []string {
+	var warnings []string
+	if !a.StartsWithPAT {
+		warnings = append(warnings, "Segment does not start with a PAT")
+	}
+	if !a.StartsWithPMT {
+		warnings = append(warnings, "PAT is not followed by a PMT")
+	}
+	if a.VideoPID != 0 && !a.StartsWithKeyframe {
+		warnings = append(warnings, fmt.Sprintf("First video frame on PID 0x%04x is not a keyframe", a.VideoPID))
+	}
+	if len(a.ContinuityErrors) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d continuity counter errors", len(a.ContinuityErrors)))
+	}
+	if a.SyncErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d sync byte errors", a.SyncErrors))
+	}
+	if a.TransportErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d packets flagged with transport errors", a.TransportErrors))
+	}
+	return append(warnings, a.Errors...)
+}
+
+// IsTS reports whether data looks like an MPEG-TS stream
+func IsTS(data []byte) bool {
+	return syncAt(data, 0)
+}
+
+// syncAt reports whether a packet starts at offset, checking the next packet's sync byte when there is one
+func syncAt(data []byte, offset int) bool {
+	if offset >= len(data) || data[offset] != SyncByte {
+		return false
+	}
+	next := offset + PacketSize
+	return next >= len(data) || data[next] == SyncByte
+}
+
+// resync finds the next offset where packets line up again, -1 if there is none
+func resync(data []byte, offset int) int {
+	for ; offset < len(data); offset++ {
+		if syncAt(data, offset) {
+			return offset
+		}
+	}
+	return -1
+}
+
+// pesState is a video PES being collected to find its NAL unit types
+type pesState struct {
+	randomAccess bool
+	pts, dts     int64
+	size         int
+	data         []byte
+}
+
+// analyzer holds the state while walking the packets
+type analyzer struct {
+	analysis   *Analysis
+	stats      map[int]*PIDStats
+	lastCC     map[int]int
+	pmtPIDs    map[int]int // PMT PID to program number
+	pending    map[int]*pesState
+	extraBits  map[int]int // HEVC num_extra_slice_header_bits per PID, from the last PPS
+	firstVideo bool        // Whether the first video PES has been judged
+}
+
+// Analyze walks the packets of an MPEG-TS segment
+func Analyze(data []byte) (*Analysis, error) {
+	start := resync(data, 0)
+	if start < 0 {
+		return nil, fmt.Errorf("no MPEG-TS sync byte found in %d bytes", len(data))
+	}
+
+	a := &analyzer{
+		analysis:  &Analysis{Size: len(data), Skipped: start, Programs: make(map[int]int)},
+		stats:     make(map[int]*PIDStats),
+		lastCC:    make(map[int]int),
+		pmtPIDs:   make(map[int]int),
+		pending:   make(map[int]*pesState),
+		extraBits: make(map[int]int),
+	}
+
+	offset := start
+	for offset < len(data) {
+		if offset+PacketSize > len(data) {
+			a.analysis.Errors = append(a.analysis.Errors, fmt.Sprintf("Truncated packet of %d bytes at offset %d", len(data)-offset, offset))
+			break
+		}
+		if data[offset] != SyncByte {
+			a.analysis.SyncErrors++
+			next := resync(data, offset+1)
+			if next < 0 {
+				break
+			}
+			offset = next
+			continue
+		}
+		a.packet(data[offset : offset+PacketSize])
+		offset += PacketSize
+	}
+
+	for pid := range a.pending {
+		a.finishPES(pid)
+	}
+
+	for _, stats := range a.stats {
+		a.analysis.PIDs = append(a.analysis.PIDs, stats)
+	}
+	sort.Slice(a.analysis.PIDs, func(i, j int) bool { return a.analysis.PIDs[i].PID < a.analysis.PIDs[j].PID })
+	return a.analysis, nil
+}
+
+// packet processes one 188 byte packet
+func (a *analyzer) packet(packet []byte) {
+	index := a.analysis.Packets
+	a.analysis.Packets++
+
+	if packet[1]&0x80 != 0 {
+		a.analysis.TransportErrors++
+	}
+	unitStart := packet[1]&0x40 != 0
+	pid := int(packet[1]&0x1f)<<8 | int(packet[2])
+	adaptation := (packet[3] >> 4) & 0x3
+	cc := int(packet[3] & 0x0f)
+
+	stats := a.pidStats(pid)
+	stats.Packets++
+
+	if index == 0 {
+		a.analysis.StartsWithPAT = pid == PATPID
+	}
+	if index == 1 {
+		_, a.analysis.StartsWithPMT = a.pmtPIDs[pid]
+	}
+
+	payload := packet[4:]
+	discontinuity, randomAccess := false, false
+	if adaptation&0x2 != 0 {
+		length := int(packet[4])
+		if length > 0 {
+			discontinuity = packet[5]&0x80 != 0
+			randomAccess = packet[5]&0x40 != 0
+		}
+		if 5+length > len(packet) {
+			return
+		}
+		payload = packet[5+length:]
+	}
+	hasPayload := adaptation&0x1 != 0
+	if !hasPayload {
+		payload = nil
+	}
+
+	if pid != NullPID && hasPayload {
+		last, seen := a.lastCC[pid]
+		// A repeated counter is an allowed duplicate packet
+		if seen && !discontinuity && cc != last && cc != (last+1)&0x0f {
+			stats.ContinuityErrors++
+			a.analysis.ContinuityErrors = append(a.analysis.ContinuityErrors, ContinuityError{
+				PID: pid, Packet: index, Expected: (last + 1) & 0x0f, Got: cc,
+			})
+		}
+		a.lastCC[pid] = cc
+	}
+
+	if len(payload) == 0 {
+		return
+	}
+
+	switch {
+	case pid == PATPID:
+		if unitStart {
+			a.parsePAT(payload)
+		}
+	case a.isPMT(pid):
+		if unitStart {
+			a.parsePMT(pid, payload)
+		}
+	default:
+		a.elementary(pid, stats, unitStart, randomAccess, payload)
+	}
+}
+
+// pidStats returns the statistics for a PID, creating them on first sight
+func (a *analyzer) pidStats(pid int) *PIDStats {
+	stats, ok := a.stats[pid]
+	if !ok {
+		stats = &PIDStats{PID: pid, Kind: "Unknown"}
+		switch {
+		case pid == PATPID:
+			stats.Kind = "PAT"
+		case pid == NullPID:
+			stats.Kind = "Null"
+		case a.isPMT(pid):
+			stats.Kind = "PMT"
+		case a.analysis.Stream(pid) != nil:
+			stats.Kind = a.analysis.Stream(pid).Codec
+		}
+		a.stats[pid] = stats
+	}
+	return stats
+}
+
+// isPMT reports whether the PAT maps a program to the PID
+func (a *analyzer) isPMT(pid int) bool {
+	_, ok := a.pmtPIDs[pid]
+	return ok
+}
+
+// section returns the PSI section at the start of a payload with its pointer field skipped
+func section(payload []byte) []byte {
+	pointer := int(payload[0])
+	if 1+pointer+3 > len(payload) {
+		return nil
+	}
+	section := payload[1+pointer:]
+	length := int(section[1]&0x0f)<<8 | int(section[2])
+	// Sections spanning several packets are cut to what this packet holds
+	if 3+length < len(section) {
+		section = section[:3+length]
+	}
+	return section
+}
+
+// parsePAT reads the program to PMT PID mapping
+func (a *analyzer) parsePAT(payload []byte) {
+	pat := section(payload)
+	if len(pat) < 12 || pat[0] != 0x00 {
+		a.analysis.Errors = append(a.analysis.Errors, "Malformed PAT")
+		return
+	}
+	// Entries run from after the header to before the CRC
+	for i := 8; i+4 <= len(pat)-4; i += 4 {
+		program := int(pat[i])<<8 | int(pat[i+1])
+		pid := int(pat[i+2]&0x1f)<<8 | int(pat[i+3])
+		if program == 0 {
+			continue // Network PID
+		}
+		a.analysis.Programs[program] = pid
+		a.pmtPIDs[pid] = program
+		if stats, ok := a.stats[pid]; ok {
+			stats.Kind = "PMT"
+		}
+	}
+}
+
+// parsePMT reads the elementary streams of a program, once per PMT PID
+func (a *analyzer) parsePMT(pid int, payload []byte) {
+	program := a.pmtPIDs[pid]
+	for _, stream := range a.analysis.Streams {
+		if stream.Program == program {
+			return
+		}
+	}
+
+	pmt := section(payload)
+	if len(pmt) < 16 || pmt[0] != 0x02 {
+		a.analysis.Errors = append(a.analysis.Errors, fmt.Sprintf("Malformed PMT on PID 0x%04x", pid))
+		return
+	}
+	infoLength := int(pmt[10]&0x0f)<<8 | int(pmt[11])
+	end := len(pmt) - 4
+	for i := 12 + infoLength; i+5 <= end; {
+		streamType := pmt[i]
+		streamPID := int(pmt[i+1]&0x1f)<<8 | int(pmt[i+2])
+		esInfoLength := int(pmt[i+3]&0x0f)<<8 | int(pmt[i+4])
+		descriptors := pmt[i+5:]
+		if esInfoLength < len(descriptors) {
+			descriptors = descriptors[:esInfoLength]
+		}
+
+		codec, kind := describeStream(streamType, descriptors)
+		a.analysis.Streams = append(a.analysis.Streams, Stream{
+			PID: streamPID, Program: program, StreamType: streamType, Codec: codec, Kind: kind,
+		})
+		if kind == "video" && a.analysis.VideoPID == 0 {
+			a.analysis.VideoPID = streamPID
+		}
+		if stats, ok := a.stats[streamPID]; ok {
+			stats.Kind = codec
+		}
+		i += 5 + esInfoLength
+	}
+}
+
+// elementary handles a packet of an elementary stream
+func (a *analyzer) elementary(pid int, stats *PIDStats, unitStart, randomAccess bool, payload []byte) {
+	stream := a.analysis.Stream(pid)
+	video := stream != nil && stream.Kind == "video"
+
+	if unitStart {
+		if video {
+			a.finishPES(pid)
+		}
+		if len(payload) < 9 || payload[0] != 0 || payload[1] != 0 || payload[2] != 1 {
+			return
+		}
+		stats.PES++
+
+		flags := payload[7] >> 6
+		headerLength := int(payload[8])
+		var pts, dts int64
+		if flags&0x2 != 0 && len(payload) >= 14 {
+			pts = timestamp(payload[9:14])
+			dts = pts
+			if flags == 0x3 && len(payload) >= 19 {
+				dts = timestamp(payload[14:19])
+			}
+			stats.PTS.add(pts)
+			stats.DTS.add(dts)
+		}
+
+		var data []byte
+		if 9+headerLength <= len(payload) {
+			data = payload[9+headerLength:]
+		}
+		if video {
+			a.pending[pid] = &pesState{randomAccess: randomAccess, pts: pts, dts: dts, size: len(data), data: append([]byte(nil), data...)}
+		} else if stream != nil && stream.CodecString == "" {
+			stream.CodecString = audioCodecString(stream.StreamType, data)
+		}
+		return
+	}
+
+	if state, ok := a.pending[pid]; ok && video {
+		state.size += len(payload)
+		if len(state.data) < maxPESScan {
+			state.data = append(state.data, payload...)
+		}
+	}
+}
+
+// finishPES judges whether the collected video PES is a keyframe
+func (a *analyzer) finishPES(pid int) {
+	state, ok := a.pending[pid]
+	if !ok {
+		return
+	}
+	delete(a.pending, pid)
+
+	stream := a.analysis.Stream(pid)
+	frame := Frame{PTS: state.pts, DTS: state.dts, Size: state.size, Keyframe: state.randomAccess}
+	if stream != nil {
+		units := nal.Units(state.data)
+		if nalKeyframe, known := isKeyframe(stream.StreamType, units); known {
+			frame.Keyframe = nalKeyframe
+		}
+		if stream.CodecString == "" {
+			stream.CodecString = videoCodecString(stream.StreamType, units)
+		}
+		if bits := nal.ExtraSliceHeaderBits(units); bits >= 0 {
+			a.extraBits[pid] = bits
+		}
+		frame.Type = frameType(stream.StreamType, units, a.extraBits[pid])
+	}
+	if frame.Keyframe {
+		a.stats[pid].Keyframes++
+	}
+	if pid == a.analysis.VideoPID {
+		a.analysis.Frames = append(a.analysis.Frames, frame)
+	}
+
+	if pid == a.analysis.VideoPID && !a.firstVideo {
+		a.firstVideo = true
+		a.analysis.StartsWithKeyframe = frame.Keyframe
+	}
+}
+
+// timestamp decodes a 33 bit PTS or DTS
+func timestamp(b []byte) int64 {
+	return int64(b[0]>>1&0x07)<<30 |
+		int64(b[1])<<22 |
+		int64(b[2]>>1)<<15 |
+		int64(b[3])<<7 |
+		int64(b[4]>>1)
+}
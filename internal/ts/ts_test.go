@@ -0,0 +1,205 @@
+package ts
+
+import (
+	"testing"
+)
+
+// packet builds a TS packet, padding the payload with stuffing bytes
+func packet(pid int, unitStart bool, cc int, randomAccess bool, payload []byte) []byte {
+	p := []byte{SyncByte, byte(pid >> 8 & 0x1f), byte(pid), byte(0x10 | cc&0x0f)}
+	if unitStart {
+		p[1] |= 0x40
+	}
+	if randomAccess {
+		p[3] |= 0x20
+		p = append(p, 1, 0x40)
+	}
+	p = append(p, payload...)
+	for len(p) < PacketSize {
+		p = append(p, 0xff)
+	}
+	return p
+}
+
+// psi wraps a section after a zero pointer field, with a dummy CRC
+func psi(tableID byte, body []byte) []byte {
+	length := len(body) + 5 + 4
+	section := []byte{0, tableID, byte(0xb0 | length>>8), byte(length), 0, 1, 0xc1, 0, 0}
+	section = append(section, body...)
+	return append(section, 0, 0, 0, 0)
+}
+
+// pes builds a PES header with a PTS and DTS followed by data
+func pes(pts, dts int64, data []byte) []byte {
+	header := []byte{0, 0, 1, 0xe0, 0, 0, 0x80, 0xc0, 10}
+	header = append(header, encodeTimestamp(0x3, pts)...)
+	header = append(header, encodeTimestamp(0x1, dts)...)
+	return append(header, data...)
+}
+
+// encodeTimestamp encodes a 33 bit PTS or DTS with its 4 bit prefix
+func encodeTimestamp(prefix byte, value int64) []byte {
+	return []byte{
+		prefix<<4 | byte(value>>29&0x0e) | 1,
+		byte(value >> 22),
+		byte(value>>14&0xfe) | 1,
+		byte(value >> 7),
+		byte(value<<1) | 1,
+	}
+}
+
+// segment builds a segment with a PAT, a PMT with H.264 and AAC and two video frames
+func segment() []byte {
+	pat := psi(0x00, []byte{0, 1, 0xf0, 0x00}) // Program 1 on PID 0x1000
+	pmt := psi(0x02, []byte{
+		0xe1, 0x00, 0xf0, 0x00, // PCR PID 0x100, no program info
+		0x1b, 0xe1, 0x00, 0xf0, 0x00, // H.264 on 0x100
+		0x0f, 0xe1, 0x01, 0xf0, 0x00, // AAC on 0x101
+	})
+	idr := []byte{0, 0, 0, 1, 0x09, 0xf0, 0, 0, 0, 1, 0x67, 0x64, 0x00, 0x1f, 0xac, 0, 0, 0, 1, 0x65, 0x88}
+	nonIDR := []byte{0, 0, 0, 1, 0x09, 0xf0, 0, 0, 0, 1, 0x41, 0x9a}
+
+	var data []byte
+	data = append(data, packet(PATPID, true, 0, false, pat)...)
+	data = append(data, packet(0x1000, true, 0, false, pmt)...)
+	data = append(data, packet(0x100, true, 0, true, pes(183003, 180000, idr))...)
+	data = append(data, packet(0x100, false, 1, false, []byte{0xaa})...)
+	data = append(data, packet(0x101, true, 0, false, pes(180000, 180000, []byte{0xff, 0xf1, 0x50, 0x80}))...)
+	data = append(data, packet(0x100, true, 2, false, pes(186006, 183003, nonIDR))...)
+	return data
+}
+
+func TestAnalyze(t *testing.T) {
+	analysis, err := Analyze(segment())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if analysis.Packets != 6 {
+		t.Errorf("Expected 6 packets, got %d", analysis.Packets)
+	}
+	if !analysis.StartsWithPAT || !analysis.StartsWithPMT || !analysis.StartsWithKeyframe {
+		t.Errorf("Expected PAT, PMT and keyframe at start, got %v %v %v",
+			analysis.StartsWithPAT, analysis.StartsWithPMT, analysis.StartsWithKeyframe)
+	}
+	if analysis.Programs[1] != 0x1000 {
+		t.Errorf("Expected program 1 on PID 0x1000, got %v", analysis.Programs)
+	}
+	if len(analysis.Streams) != 2 || analysis.Streams[0].Codec != "H.264" || analysis.Streams[1].Codec != "AAC" {
+		t.Fatalf("Unexpected streams: %+v", analysis.Streams)
+	}
+	if analysis.Streams[0].CodecString != "avc1.64001f" || analysis.Streams[1].CodecString != "mp4a.40.2" {
+		t.Errorf("Expected avc1.64001f and mp4a.40.2, got %q and %q", analysis.Streams[0].CodecString, analysis.Streams[1].CodecString)
+	}
+	if analysis.VideoPID != 0x100 {
+		t.Errorf("Expected video PID 0x100, got 0x%x", analysis.VideoPID)
+	}
+	if warnings := analysis.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	video := analysis.PID(0x100)
+	if video == nil {
+		t.Fatal("Expected stats for PID 0x100")
+	}
+	if video.Kind != "H.264" || video.Packets != 3 || video.PES != 2 || video.Keyframes != 1 {
+		t.Errorf("Unexpected video stats: %+v", video)
+	}
+	if video.PTS.First != 183003 || video.PTS.Last != 186006 {
+		t.Errorf("Expected PTS 183003-186006, got %d-%d", video.PTS.First, video.PTS.Last)
+	}
+	if video.DTS.First != 180000 || video.DTS.Last != 183003 {
+		t.Errorf("Expected DTS 180000-183003, got %d-%d", video.DTS.First, video.DTS.Last)
+	}
+	if len(analysis.Frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %+v", analysis.Frames)
+	}
+	if frame := analysis.Frames[0]; !frame.Keyframe || frame.Type != "I" || frame.PTS != 183003 || frame.DTS != 180000 {
+		t.Errorf("Expected an I keyframe first, got %+v", frame)
+	}
+	if frame := analysis.Frames[1]; frame.Keyframe || frame.Type != "P" || frame.Size != PacketSize-4-19 {
+		t.Errorf("Expected a P frame filling one packet, got %+v", frame)
+	}
+	if pmt := analysis.PID(0x1000); pmt == nil || pmt.Kind != "PMT" {
+		t.Errorf("Expected PID 0x1000 to be the PMT, got %+v", pmt)
+	}
+}
+
+func TestAnalyzeContinuityErrors(t *testing.T) {
+	data := segment()
+	// Lose four packets before the second video packet
+	data[3*PacketSize+3] = 0x10 | 5
+	data[5*PacketSize+3] = 0x10 | 6
+
+	analysis, err := Analyze(data)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(analysis.ContinuityErrors) != 1 {
+		t.Fatalf("Expected 1 continuity error, got %+v", analysis.ContinuityErrors)
+	}
+	cc := analysis.ContinuityErrors[0]
+	if cc.PID != 0x100 || cc.Packet != 3 || cc.Expected != 1 || cc.Got != 5 {
+		t.Errorf("Unexpected continuity error: %+v", cc)
+	}
+	if analysis.PID(0x100).ContinuityErrors != 1 {
+		t.Errorf("Expected the error to be counted on PID 0x100")
+	}
+}
+
+func TestAnalyzeMissingKeyframe(t *testing.T) {
+	data := segment()
+	// Start with the non-IDR frame by dropping the first access unit
+	data = append(data[:2*PacketSize], data[4*PacketSize:]...)
+
+	analysis, err := Analyze(data)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if analysis.StartsWithKeyframe {
+		t.Error("Expected the segment not to start with a keyframe")
+	}
+	if len(analysis.Warnings()) == 0 {
+		t.Error("Expected a warning for the missing keyframe")
+	}
+}
+
+func TestAnalyzeResync(t *testing.T) {
+	// An ID3 tag in front and a corrupt packet in the middle
+	data := append([]byte("ID3junk"), segment()...)
+	data[7+2*PacketSize] = 0x00
+
+	analysis, err := Analyze(data)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if analysis.Skipped != 7 {
+		t.Errorf("Expected 7 skipped bytes, got %d", analysis.Skipped)
+	}
+	if analysis.SyncErrors != 1 {
+		t.Errorf("Expected 1 sync error, got %d", analysis.SyncErrors)
+	}
+	if analysis.Packets != 5 {
+		t.Errorf("Expected 5 packets after resync, got %d", analysis.Packets)
+	}
+
+	if _, err := Analyze([]byte("not a transport stream")); err == nil {
+		t.Error("Expected an error without sync bytes")
+	}
+}
+
+func TestVideoCodecStringHEVC(t *testing.T) {
+	// SPS header, one byte of SPS fields, then Main profile, level 4.0 with an emulation prevention byte
+	sps := []byte{0x42, 0x01, 0x01, 0x01, 0x60, 0x00, 0x00, 0x03, 0x00, 0x90, 0x00, 0x00, 0x00, 0x00, 0x00, 120, 0xa0}
+	if got := videoCodecString(0x24, [][]byte{sps}); got != "hvc1.1.6.L120.90" {
+		t.Errorf("videoCodecString() = %q, want hvc1.1.6.L120.90", got)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	for _, value := range []int64{0, 90000, 1<<33 - 1, 8589934000} {
+		if got := timestamp(encodeTimestamp(0x2, value)); got != value {
+			t.Errorf("timestamp(%d) = %d", value, got)
+		}
+	}
+}
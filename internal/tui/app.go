@@ -2,9 +2,16 @@ package tui
 
 import (
 	"fmt"
+	"github.com/soldiermoth/pantui/internal/analyzer"
+	"github.com/soldiermoth/pantui/internal/cache"
+	"github.com/soldiermoth/pantui/internal/compare"
 	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/state"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
 	"github.com/soldiermoth/pantui/internal/tui/components"
 	"github.com/soldiermoth/pantui/internal/tui/views"
+	"github.com/soldiermoth/pantui/internal/verify"
+	"os"
 	"strings"
 	"time"
 
@@ -14,43 +21,80 @@ import (
 
 // App represents the main TUI application
 type App struct {
+	*tab           // The active tab
+	tabs           []*tab
+	openInNewTab   bool // Set while the selection is opened with T
 	app            *tview.Application
-	pages          *tview.Pages
-	parser         *hls.Parser
-	navStack       []*views.ViewState
-	currentView    views.View
+	analyzers      *analyzer.Registry
+	history        []historyEntry
+	statePath      string // Bookmarks and the session kept for --resume
+	keymap         *keymap
+	actions        map[string]func() // Handlers of the global actions
+	breadcrumb     *components.Breadcrumb
 	statusBar      *components.StatusBar
 	keyBar         *components.KeyBar
+	inspector      *components.Inspector
+	prompt         *components.Prompt
+	picker         *components.Picker
+	body           *tview.Flex
 	layout         *tview.Flex
+	inspectorShown bool
+	promptActive   bool
+	pickerActive   bool
 	loadingModal   *tview.Modal
 	loadingTicker  *time.Ticker
 	spinnerIndex   int
 }
 
+// historyEntry is a manifest visited during the session
+type historyEntry struct {
+	label    string
+	manifest *hls.Manifest
+	visited  time.Time
+}
+
 // NewApp creates a new TUI application
 func NewApp() *App {
 	app := &App{
-		app:      tview.NewApplication(),
-		pages:    tview.NewPages(),
-		parser:   hls.NewParser(),
-		navStack: make([]*views.ViewState, 0),
+		app:       tview.NewApplication(),
+		statePath: state.DefaultPath(),
 	}
+	app.tab = newTab(hls.NewParser())
+	app.tabs = []*tab{app.tab}
 
+	app.keymap, _ = newKeymap("", nil)
 	app.setupLayout()
+	app.setupActions()
 	app.setupKeybindings()
 	
+	// External analyzers that fail to load leave the built-in ones usable
+	var err error
+	if app.analyzers, err = views.NewAnalyzerRegistry(analyzer.DefaultDir()); err != nil {
+		app.statusBar.SetError(fmt.Sprintf("Failed to load external analyzers: %v", err))
+	}
+	
 	return app
 }
 
 // setupLayout sets up the main application layout
 func (a *App) setupLayout() {
+	a.breadcrumb = components.NewBreadcrumb()
 	a.statusBar = components.NewStatusBar()
 	a.keyBar = components.NewKeyBar()
+	a.inspector = components.NewInspector()
+	a.prompt = components.NewPrompt()
+	a.picker = components.NewPicker()
+	
+	// Body: pages with an optional inspector pane on the right
+	a.body = tview.NewFlex().
+		SetDirection(tview.FlexColumn).
+		AddItem(a.pages, 0, 2, true)
 	
-	// Main layout: pages + status bar + key bar
+	// Main layout: breadcrumb + body + status bar + key bar
 	a.layout = tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(a.pages, 0, 1, true).
+		AddItem(a.breadcrumb.GetPrimitive(), 1, 0, false).
+		AddItem(a.body, 0, 1, true).
 		AddItem(a.statusBar.GetPrimitive(), 1, 0, false).
 		AddItem(a.keyBar.GetPrimitive(), 1, 0, false)
 	
@@ -60,31 +104,32 @@ func (a *App) setupLayout() {
 // setupKeybindings sets up global key bindings
 func (a *App) setupKeybindings() {
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyCtrlC:
-			a.app.Stop()
-			return nil
-		case tcell.KeyEscape:
-			if len(a.navStack) > 0 {
-				a.navigateBack()
+		// Keys go straight to the prompt or picker while it is open
+		if a.promptActive || a.pickerActive {
+			if event.Key() == tcell.KeyCtrlC {
+				a.app.Stop()
 				return nil
 			}
-			a.app.Stop()
-			return nil
-		case tcell.KeyF1:
-			a.showHelp()
-			return nil
+			return event
 		}
 		
-		// Pass to current view
-		if a.currentView != nil {
-			return a.currentView.HandleKey(event)
-		}
-		
-		return event
+		return a.runAction(event)
 	})
 }
 
+// goBack returns to the previous view. Leaving the first view of a tab closes the tab, the last one exits.
+func (a *App) goBack() {
+	if len(a.navStack) > 0 {
+		a.navigateBack()
+		return
+	}
+	if len(a.tabs) > 1 {
+		a.closeTab()
+		return
+	}
+	a.app.Stop()
+}
+
 // RunWithURL runs the application with a manifest URL
 func (a *App) RunWithURL(url string) error {
 	// Parse manifest synchronously for initial load
@@ -93,8 +138,8 @@ func (a *App) RunWithURL(url string) error {
 		return fmt.Errorf("failed to parse manifest from URL: %w", err)
 	}
 
-	a.showManifest(manifest)
-	return a.app.Run()
+	a.showManifest(manifest, views.ManifestLabel(manifest, nil, ""))
+	return a.run()
 }
 
 // RunWithFile runs the application with a manifest file
@@ -105,87 +150,124 @@ func (a *App) RunWithFile(filePath string) error {
 		return fmt.Errorf("failed to parse manifest from file: %w", err)
 	}
 
-	a.showManifest(manifest)
-	return a.app.Run()
+	a.showManifest(manifest, views.ManifestLabel(manifest, nil, ""))
+	return a.run()
 }
 
-// showManifest displays the manifest in the appropriate view
-func (a *App) showManifest(manifest *hls.Manifest) {
+// RunWithTargets runs the application with a tab for each manifest URL or file
+func (a *App) RunWithTargets(targets []string) error {
+	for i, target := range targets {
+		if i > 0 {
+			a.addTab(hls.NewParser())
+		}
+		
+		manifest, err := a.parser.Load(target)
+		if err != nil {
+			return fmt.Errorf("failed to parse manifest %s: %w", target, err)
+		}
+		a.showManifest(manifest, views.ManifestLabel(manifest, nil, ""))
+	}
+	
+	a.selectTab(0)
+	return a.run()
+}
+
+// RunCompare runs the application comparing several origins of the same stream
+func (a *App) RunCompare(targets []string, interval time.Duration) error {
+	comparison := compare.New(targets, interval)
+	comparison.Current = comparison.Load()
+
+	failed := 0
+	for _, origin := range comparison.Current.Origins {
+		if origin.Err != nil {
+			failed++
+		}
+	}
+	if failed == len(targets) {
+		return fmt.Errorf("failed to load any playlist: %w", comparison.Current.Origins[0].Err)
+	}
+
+	a.showCompare(comparison)
+	return a.run()
+}
+
+// run runs the event loop, then stops the analyses still running so no ffprobe outlives pantui
+// and keeps the session for --resume
+func (a *App) run() error {
+	defer analyzer.Shutdown()
+	if err := a.app.Run(); err != nil {
+		return err
+	}
+	if err := a.saveSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save session: %v\n", err)
+	}
+	return nil
+}
+
+// SetAnalyzerTimeout bounds each segment analysis, zero disables the timeout
+func (a *App) SetAnalyzerTimeout(timeout time.Duration) {
+	a.analyzers.Timeout = timeout
+}
+
+// SetCache reuses segment analyses kept in a cache, nil disables it
+func (a *App) SetCache(c *cache.Cache) {
+	a.analyzers.Cache = c
+}
+
+// showManifest displays the manifest in the appropriate view, labelled in the breadcrumb and history
+func (a *App) showManifest(manifest *hls.Manifest, label string) {
 	switch manifest.Type {
 	case hls.MasterManifest:
-		a.showMasterManifest(manifest)
+		a.showMasterManifest(manifest, label)
 	case hls.MediaManifest:
-		a.showMediaManifest(manifest)
+		a.showMediaManifest(manifest, label)
 	}
 }
 
 // showMasterManifest displays a master manifest
-func (a *App) showMasterManifest(manifest *hls.Manifest) {
-	view := views.NewMasterView(manifest, a.parser)
-	view.SetNavigationCallback(func(uri string) {
-		a.navigateToSubManifest(uri)
-	})
-	view.SetStatusCallback(func(status string) {
-		a.statusBar.SetStatus(status)
-	})
-	view.SetUpdateCallback(func(updateFunc func()) {
-		a.app.QueueUpdateDraw(updateFunc)
-	})
-	
-	a.setCurrentView(view, &views.ViewState{
+func (a *App) showMasterManifest(manifest *hls.Manifest, label string) {
+	a.showView(&views.ViewState{
 		Type:     views.MasterViewType,
 		Manifest: manifest,
 		Title:    fmt.Sprintf("Master Manifest - %s", manifest.URL),
+		Label:    label,
 	})
 }
 
 // showMediaManifest displays a media manifest
-func (a *App) showMediaManifest(manifest *hls.Manifest) {
-	view := views.NewMediaView(manifest, a.parser)
-	view.SetNavigationCallback(func(uri string) {
-		// Create a minimal segment object for backwards compatibility
-		segment := &hls.Segment{URI: uri}
-		a.navigateToSegment(segment)
-	})
-	view.SetSegmentNavigationCallback(func(segment *hls.Segment) {
-		a.navigateToSegment(segment)
-	})
-	view.SetStatusCallback(func(status string) {
-		a.statusBar.SetStatus(status)
-	})
-	view.SetUpdateCallback(func(updateFunc func()) {
-		a.app.QueueUpdateDraw(updateFunc)
-	})
-	
-	a.setCurrentView(view, &views.ViewState{
+func (a *App) showMediaManifest(manifest *hls.Manifest, label string) {
+	a.showView(&views.ViewState{
 		Type:     views.MediaViewType,
 		Manifest: manifest,
 		Title:    fmt.Sprintf("Media Manifest - %s", manifest.URL),
+		Label:    label,
+	})
+}
+
+// showCompare displays a multi-origin comparison
+func (a *App) showCompare(comparison *compare.Comparison) {
+	a.showView(&views.ViewState{
+		Type:       views.CompareViewType,
+		Comparison: comparison,
+		Title:      fmt.Sprintf("Compare - %d origins", len(comparison.URLs)),
+		Label:      "Compare",
 	})
 }
 
 // navigateToSubManifest navigates to a sub-manifest
 func (a *App) navigateToSubManifest(uri string) {
 	resolvedURL := a.parser.ResolveURL(uri)
+	parent := a.currentView.GetManifest()
+	newTab := a.openInNewTab
 	
 	// Show loading modal
 	a.showLoadingModal(fmt.Sprintf("Fetching manifest: %s", resolvedURL))
 	
 	// Parse manifest in a goroutine to allow UI updates
 	go func() {
-		var manifest *hls.Manifest
-		var err error
-		
 		// Create a fresh parser instance to avoid state issues
 		freshParser := hls.NewParser()
-		
-		// Try parsing as URL first, then as file if it's a local path
-		if strings.HasPrefix(resolvedURL, "http://") || strings.HasPrefix(resolvedURL, "https://") {
-			manifest, err = freshParser.ParseFromURL(resolvedURL)
-		} else {
-			// It's a local file path
-			manifest, err = freshParser.ParseFromFile(resolvedURL)
-		}
+		manifest, err := freshParser.Load(resolvedURL)
 		
 		a.app.QueueUpdateDraw(func() {
 			a.hideLoadingModal()
@@ -193,42 +275,160 @@ func (a *App) navigateToSubManifest(uri string) {
 				a.statusBar.SetError(fmt.Sprintf("Failed to load manifest: %v", err))
 				return
 			}
-			a.showManifest(manifest)
+			if newTab {
+				a.addTab(freshParser)
+			}
+			a.showManifest(manifest, views.ManifestLabel(manifest, parent, uri))
 		})
 	}()
 }
 
-// navigateToSegment shows segment details
-func (a *App) navigateToSegment(segment *hls.Segment) {
-	resolvedURL := a.parser.ResolveURL(segment.URI)
+// navigateToSegment shows the details of a segment of a media playlist
+func (a *App) navigateToSegment(playlist *hls.Manifest, segment *hls.Segment) {
+	if a.openInNewTab {
+		parser := *a.parser
+		a.addTab(&parser)
+	}
 	
-	view := views.NewSegmentView(segment, resolvedURL)
-	view.SetStatusCallback(func(status string) {
-		a.statusBar.SetStatus(status)
+	a.showView(&views.ViewState{
+		Type:     views.SegmentViewType,
+		Manifest: playlist,
+		Segment:  segment,
+		Title:    fmt.Sprintf("Segment - %s", segment.URI),
+		Label:    fmt.Sprintf("seg %d", segment.Sequence),
 	})
-	view.SetUpdateCallback(func(updateFunc func()) {
-		a.app.QueueUpdateDraw(updateFunc)
+}
+
+// showReport shows a text report on top of the navigation stack
+func (a *App) showReport(title, content string) {
+	a.showView(&views.ViewState{
+		Type:    views.ReportViewType,
+		Heading: title,
+		Content: content,
+		Title:   title,
+		Label:   strings.SplitN(title, " - ", 2)[0],
 	})
-	
-	a.setCurrentView(view, &views.ViewState{
-		Type:  views.SegmentViewType,
-		Title: fmt.Sprintf("Segment - %s", segment.URI),
+}
+
+// showBoxTree browses the boxes of fMP4 files on top of the navigation stack
+func (a *App) showBoxTree(title string, files []views.BoxFile) {
+	a.showView(&views.ViewState{
+		Type:    views.BoxTreeViewType,
+		Heading: title,
+		Files:   files,
+		Title:   fmt.Sprintf("Boxes - %s", title),
+		Label:   "Boxes",
+	})
+}
+
+// showAlignment shows a cross-variant segment alignment matrix on top of the navigation stack
+func (a *App) showAlignment(title string, report *verify.AlignmentReport) {
+	a.showView(&views.ViewState{
+		Type:      views.AlignmentViewType,
+		Alignment: report,
+		Title:     title,
+		Label:     "Alignment",
 	})
 }
 
+// showView opens a new view of a state on top of the navigation stack
+func (a *App) showView(state *views.ViewState) {
+	if view := a.newView(state); view != nil {
+		a.setCurrentView(view, state)
+	}
+}
+
+// newView creates the view of a state with the callbacks connecting it to the app, nil for unknown types
+func (a *App) newView(state *views.ViewState) views.View {
+	update := func(updateFunc func()) {
+		a.app.QueueUpdateDraw(updateFunc)
+	}
+	
+	switch state.Type {
+	case views.MasterViewType:
+		view := views.NewMasterView(state.Manifest, a.parser)
+		view.SetNavigationCallback(a.navigateToSubManifest)
+		view.SetStatusCallback(a.tabStatus())
+		view.SetUpdateCallback(update)
+		view.SetSelectionCallback(a.tabSelection())
+		view.SetPromptCallback(a.showPrompt)
+		view.SetReportCallback(a.showReport)
+		view.SetAlignmentCallback(a.showAlignment)
+		return view
+	case views.MediaViewType:
+		manifest := state.Manifest
+		view := views.NewMediaView(manifest, a.parser)
+		view.SetNavigationCallback(func(uri string) {
+			// Create a minimal segment object for backwards compatibility
+			segment := &hls.Segment{URI: uri}
+			a.navigateToSegment(manifest, segment)
+		})
+		view.SetSegmentNavigationCallback(a.navigateToSegment)
+		view.SetStatusCallback(a.tabStatus())
+		view.SetUpdateCallback(update)
+		view.SetSelectionCallback(a.tabSelection())
+		view.SetPromptCallback(a.showPrompt)
+		view.SetReportCallback(a.showReport)
+		return view
+	case views.CompareViewType:
+		view := views.NewCompareView(state.Comparison)
+		view.SetSegmentNavigationCallback(a.navigateToSegment)
+		view.SetStatusCallback(a.tabStatus())
+		view.SetUpdateCallback(update)
+		view.SetSelectionCallback(a.tabSelection())
+		return view
+	case views.AlignmentViewType:
+		view := views.NewAlignmentView(state.Alignment)
+		view.SetSegmentNavigationCallback(a.navigateToSegment)
+		view.SetStatusCallback(a.tabStatus())
+		view.SetSelectionCallback(a.tabSelection())
+		return view
+	case views.SegmentViewType:
+		view := views.NewSegmentView(state.Manifest, state.Segment, a.analyzers)
+		view.SetStatusCallback(a.tabStatus())
+		view.SetUpdateCallback(update)
+		view.SetBoxTreeCallback(a.showBoxTree)
+		return view
+	case views.ReportViewType:
+		view := views.NewReportView(state.Heading, state.Content)
+		view.SetStatusCallback(a.tabStatus())
+		return view
+	case views.BoxTreeViewType:
+		view := views.NewBoxTreeView(state.Heading, state.Files)
+		view.SetStatusCallback(a.tabStatus())
+		view.SetSelectionCallback(a.tabSelection())
+		return view
+	}
+	return nil
+}
+
 // setCurrentView sets the current view and updates the navigation stack
 func (a *App) setCurrentView(view views.View, state *views.ViewState) {
 	// Save current view state if there is one
 	if a.currentView != nil {
 		a.navStack = append(a.navStack, a.getCurrentViewState())
+		a.currentView.Close()
+	}
+	
+	// Going somewhere new drops the views left by navigating back, like a browser
+	a.forwardStack = nil
+	if state.Type == views.MasterViewType || state.Type == views.MediaViewType {
+		a.history = append(a.history, historyEntry{label: state.Label, manifest: state.Manifest, visited: time.Now()})
+	}
+	
+	// Views without a selection leave the inspector empty
+	if state.Type == views.SegmentViewType || state.Type == views.ReportViewType {
+		a.inspector.Clear()
 	}
 	
 	a.currentView = view
+	a.currentLabel = state.Label
 	a.pages.AddAndSwitchToPage(state.Type.String(), view.GetPrimitive(), true)
 	
-	// Update status and key bars
+	// Update breadcrumb, status and key bars
+	a.updateBreadcrumb()
 	a.statusBar.SetStatus(state.Title)
-	a.keyBar.SetKeys(view.GetKeyBindings())
+	a.updateKeyBar()
 }
 
 // getCurrentViewState gets the current view state
@@ -237,14 +437,23 @@ func (a *App) getCurrentViewState() *views.ViewState {
 		return nil
 	}
 	
-	return &views.ViewState{
+	state := &views.ViewState{
 		Type:     a.currentView.GetType(),
 		Manifest: a.currentView.GetManifest(),
 		Title:    a.statusBar.GetStatus(),
+		Label:    a.currentLabel,
+	}
+	if compareView, ok := a.currentView.(*views.CompareView); ok {
+		state.Comparison = compareView.Comparison()
 	}
+	if alignmentView, ok := a.currentView.(*views.AlignmentView); ok {
+		state.Alignment = alignmentView.Report()
+	}
+	a.currentView.SaveState(state)
+	return state
 }
 
-// navigateBack navigates back to the previous view
+// navigateBack navigates back to the previous view, keeping the current one for navigateForward
 func (a *App) navigateBack() {
 	if len(a.navStack) == 0 {
 		return
@@ -253,56 +462,163 @@ func (a *App) navigateBack() {
 	// Pop the last state
 	lastState := a.navStack[len(a.navStack)-1]
 	a.navStack = a.navStack[:len(a.navStack)-1]
+	a.forwardStack = append(a.forwardStack, a.getCurrentViewState())
+	a.restoreView(lastState)
+}
+
+// navigateForward returns to the view left by the last navigateBack
+func (a *App) navigateForward() {
+	if len(a.forwardStack) == 0 {
+		a.statusBar.SetStatus("Nothing to go forward to")
+		return
+	}
 	
-	// Create view based on state
-	var view views.View
-	switch lastState.Type {
-	case views.MasterViewType:
-		view = views.NewMasterView(lastState.Manifest, a.parser)
-		view.SetNavigationCallback(func(uri string) {
-			a.navigateToSubManifest(uri)
-		})
-		view.SetStatusCallback(func(status string) {
-			a.statusBar.SetStatus(status)
-		})
-		view.SetUpdateCallback(func(updateFunc func()) {
-			a.app.QueueUpdateDraw(updateFunc)
-		})
-	case views.MediaViewType:
-		view = views.NewMediaView(lastState.Manifest, a.parser)
-		view.SetNavigationCallback(func(uri string) {
-			// Create a minimal segment object for backwards compatibility
-			segment := &hls.Segment{URI: uri}
-			a.navigateToSegment(segment)
-		})
-		view.SetSegmentNavigationCallback(func(segment *hls.Segment) {
-			a.navigateToSegment(segment)
-		})
-		view.SetStatusCallback(func(status string) {
-			a.statusBar.SetStatus(status)
-		})
-		view.SetUpdateCallback(func(updateFunc func()) {
-			a.app.QueueUpdateDraw(updateFunc)
-		})
-	case views.SegmentViewType:
-		// For segment view, we need to go back to the previous media view
-		if len(a.navStack) > 0 {
-			a.navigateBack()
-			return
-		}
+	nextState := a.forwardStack[len(a.forwardStack)-1]
+	a.forwardStack = a.forwardStack[:len(a.forwardStack)-1]
+	a.navStack = append(a.navStack, a.getCurrentViewState())
+	a.restoreView(nextState)
+}
+
+// jumpToAncestor goes back to a view on the navigation stack at once, as if pressing Esc repeatedly
+func (a *App) jumpToAncestor(index int) {
+	if index < 0 || index >= len(a.navStack) {
+		return
 	}
 	
+	// The views skipped over stay reachable with navigateForward, nearest first
+	a.forwardStack = append(a.forwardStack, a.getCurrentViewState())
+	for len(a.navStack) > index+1 {
+		a.forwardStack = append(a.forwardStack, a.navStack[len(a.navStack)-1])
+		a.navStack = a.navStack[:len(a.navStack)-1]
+	}
+	lastState := a.navStack[index]
+	a.navStack = a.navStack[:index]
+	a.restoreView(lastState)
+}
+
+// restoreView replaces the current view with one recreated from a saved state
+func (a *App) restoreView(lastState *views.ViewState) {
+	if a.currentView != nil {
+		a.currentView.Close()
+	}
+	
+	view := a.newView(lastState)
+	if view == nil {
+		return
+	}
+	if lastState.Type == views.SegmentViewType || lastState.Type == views.ReportViewType {
+		a.inspector.Clear()
+	}
+	view.RestoreState(lastState)
+	
 	a.currentView = view
+	a.currentLabel = lastState.Label
 	a.pages.AddAndSwitchToPage(lastState.Type.String(), view.GetPrimitive(), true)
 	
-	// Update status and key bars
+	// Update breadcrumb, status and key bars
+	a.updateBreadcrumb()
 	a.statusBar.SetStatus(lastState.Title)
-	a.keyBar.SetKeys(view.GetKeyBindings())
+	a.updateKeyBar()
+}
+
+// updateBreadcrumb shows the open tabs and the labels of the navigation stack and the current view
+func (a *App) updateBreadcrumb() {
+	labels := make([]string, 0, len(a.navStack)+1)
+	for _, state := range a.navStack {
+		labels = append(labels, state.Label)
+	}
+	tabs := make([]string, len(a.tabs))
+	active := 0
+	for i, t := range a.tabs {
+		tabs[i] = t.label()
+		if t == a.tab {
+			active = i
+		}
+	}
+	a.breadcrumb.SetTabs(tabs, active)
+	a.breadcrumb.SetCrumbs(append(labels, a.currentLabel), len(a.forwardStack) > 0)
+}
+
+// showAncestors lets the user pick a view on the navigation stack to jump back to
+func (a *App) showAncestors() {
+	if len(a.navStack) == 0 {
+		a.statusBar.SetStatus("Already at the first view")
+		return
+	}
+	
+	items := make([]string, len(a.navStack))
+	for i, state := range a.navStack {
+		items[i] = fmt.Sprintf("%s%s", strings.Repeat("  ", i), state.Label)
+	}
+	a.showPicker("Jump Up", items, len(items)-1, a.jumpToAncestor)
+}
+
+// showHistory lists every manifest visited this session, opening the chosen one again
+func (a *App) showHistory() {
+	if len(a.history) == 0 {
+		a.statusBar.SetStatus("No manifests visited yet")
+		return
+	}
+	
+	items := make([]string, len(a.history))
+	for i, entry := range a.history {
+		items[i] = fmt.Sprintf("%s  %-24s %s", entry.visited.Format("15:04:05"), entry.label, entry.manifest.URL)
+	}
+	a.showPicker("History", items, len(items)-1, func(index int) {
+		entry := a.history[index]
+		a.showManifest(entry.manifest, entry.label)
+	})
+}
+
+// showPicker shows a list of choices over the page until one is chosen or it is cancelled
+func (a *App) showPicker(title string, items []string, selected int, choose func(index int)) {
+	if a.pickerActive {
+		return
+	}
+	
+	a.picker.Open(title, items, selected, choose, func() {
+		a.pickerActive = false
+		a.pages.RemovePage("picker")
+		a.app.SetFocus(a.pages)
+	})
+	
+	a.pickerActive = true
+	a.pages.AddPage("picker", a.picker.GetPrimitive(), true, true)
+	a.app.SetFocus(a.picker.GetPrimitive())
+}
+
+// toggleInspector shows or hides the inspector pane
+func (a *App) toggleInspector() {
+	if a.inspectorShown {
+		a.body.RemoveItem(a.inspector.GetPrimitive())
+	} else {
+		a.body.AddItem(a.inspector.GetPrimitive(), 0, 1, false)
+	}
+	a.inspectorShown = !a.inspectorShown
+}
+
+// showPrompt replaces the key bar with an input prompt until it is submitted or cancelled
+func (a *App) showPrompt(label, initial string, submit func(text string)) {
+	if a.promptActive {
+		return
+	}
+	
+	a.prompt.Open(label, initial, submit, func() {
+		a.promptActive = false
+		a.layout.RemoveItem(a.prompt.GetPrimitive())
+		a.layout.AddItem(a.keyBar.GetPrimitive(), 1, 0, false)
+		a.app.SetFocus(a.pages)
+	})
+	
+	a.promptActive = true
+	a.layout.RemoveItem(a.keyBar.GetPrimitive())
+	a.layout.AddItem(a.prompt.GetPrimitive(), 1, 0, true)
+	a.app.SetFocus(a.prompt.GetPrimitive())
 }
 
 // showHelp shows the help dialog
 func (a *App) showHelp() {
-	helpView := views.NewHelpView()
+	helpView := views.NewHelpView(a.helpSections())
 	
 	modal := tview.NewModal().
 		SetText(helpView.GetContent()).
@@ -326,7 +642,7 @@ func (a *App) showLoadingModal(message string) {
 	
 	// Create animated loading modal
 	a.loadingModal = tview.NewModal().
-		SetBackgroundColor(tcell.ColorDarkBlue)
+		SetBackgroundColor(colors.Current.Bar)
 	
 	// Spinner characters for animation
 	spinnerFrames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
@@ -340,9 +656,10 @@ func (a *App) showLoadingModal(message string) {
 	a.pages.AddPage("loading", a.loadingModal, false, true)
 	
 	// Start animation ticker after adding to pages
-	a.loadingTicker = time.NewTicker(100 * time.Millisecond)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	a.loadingTicker = ticker
 	go func() {
-		for range a.loadingTicker.C {
+		for range ticker.C {
 			a.app.QueueUpdateDraw(func() {
 				if a.loadingModal != nil {
 					spinner := spinnerFrames[a.spinnerIndex%len(spinnerFrames)]
@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/state"
+	"github.com/soldiermoth/pantui/internal/tui/views"
+	"strings"
+	"time"
+)
+
+// addBookmark asks for a note, then bookmarks the selected variant or segment, or else the current manifest
+func (a *App) addBookmark() {
+	bookmark, err := a.currentBookmark()
+	if err != nil {
+		a.statusBar.SetStatus(err.Error())
+		return
+	}
+
+	a.showPrompt(fmt.Sprintf("Note for %s: ", bookmark.Label), "", func(note string) {
+		bookmark.Note = strings.TrimSpace(note)
+		bookmark.Created = time.Now()
+		err := state.Update(a.statePath, func(s *state.State) {
+			s.Bookmarks = append(s.Bookmarks, *bookmark)
+		})
+		if err != nil {
+			a.statusBar.SetError(fmt.Sprintf("Failed to save bookmark: %v", err))
+			return
+		}
+		a.statusBar.SetSuccess(fmt.Sprintf("Bookmarked %s, press ' to list bookmarks", bookmark.Label))
+	})
+}
+
+// currentBookmark describes what the cursor is on: a variant or rendition of a master manifest, a segment,
+// or the manifest itself. A playlist opened from a master manifest is bookmarked as its variant.
+func (a *App) currentBookmark() (*state.Bookmark, error) {
+	if a.currentView == nil {
+		return nil, fmt.Errorf("Nothing to bookmark here")
+	}
+	current := a.getCurrentViewState()
+	var parent *views.ViewState
+	if len(a.navStack) > 0 {
+		parent = a.navStack[len(a.navStack)-1]
+	}
+
+	switch current.Type {
+	case views.MasterViewType:
+		manifest := current.Manifest
+		if uri, ok := variantAt(manifest, current.Line); ok {
+			return &state.Bookmark{
+				Kind:  state.VariantBookmark,
+				Label: views.ManifestLabel(manifest, manifest, uri),
+				URL:   state.Location(manifest.URL),
+				URI:   uri,
+			}, nil
+		}
+		return &state.Bookmark{Kind: state.ManifestBookmark, Label: a.currentLabel, URL: state.Location(manifest.URL)}, nil
+	case views.MediaViewType:
+		manifest := current.Manifest
+		for _, segment := range manifest.Segments {
+			if segment.LineNumber == current.Line {
+				return &state.Bookmark{
+					Kind:     state.SegmentBookmark,
+					Label:    fmt.Sprintf("seg %d", segment.Sequence),
+					URL:      state.Location(manifest.URL),
+					URI:      segment.URI,
+					Sequence: segment.Sequence,
+				}, nil
+			}
+		}
+		if parent != nil && parent.Type == views.MasterViewType {
+			if uri, ok := referencingURI(parent.Manifest, manifest.URL); ok {
+				return &state.Bookmark{Kind: state.VariantBookmark, Label: a.currentLabel, URL: state.Location(parent.Manifest.URL), URI: uri}, nil
+			}
+		}
+		return &state.Bookmark{Kind: state.ManifestBookmark, Label: a.currentLabel, URL: state.Location(manifest.URL)}, nil
+	case views.SegmentViewType:
+		if parent == nil || parent.Type != views.MediaViewType {
+			return nil, fmt.Errorf("Open the segment from its playlist to bookmark it")
+		}
+		return &state.Bookmark{
+			Kind:     state.SegmentBookmark,
+			Label:    a.currentLabel,
+			URL:      state.Location(parent.Manifest.URL),
+			URI:      current.Segment.URI,
+			Sequence: current.Segment.Sequence,
+		}, nil
+	}
+	return nil, fmt.Errorf("Nothing to bookmark here, bookmark the manifest, variant or segment instead")
+}
+
+// variantAt returns the URI of the variant or rendition on a line of a master manifest
+func variantAt(manifest *hls.Manifest, line int) (string, bool) {
+	for _, variant := range manifest.Variants {
+		if variant.LineNumber == line {
+			return variant.URI, true
+		}
+	}
+	for _, rendition := range manifest.Renditions {
+		if rendition.LineNumber == line && rendition.URI != "" {
+			return rendition.URI, true
+		}
+	}
+	return "", false
+}
+
+// variantLine returns the line of the variant or rendition with a URI in a master manifest
+func variantLine(manifest *hls.Manifest, uri string) (int, bool) {
+	for _, variant := range manifest.Variants {
+		if variant.URI == uri {
+			return variant.LineNumber, true
+		}
+	}
+	for _, rendition := range manifest.Renditions {
+		if rendition.URI == uri {
+			return rendition.LineNumber, true
+		}
+	}
+	return 0, false
+}
+
+// referencingURI returns the URI a master manifest references a playlist by
+func referencingURI(master *hls.Manifest, playlistURL string) (string, bool) {
+	for _, variant := range master.Variants {
+		if master.ResolveURL(variant.URI) == playlistURL {
+			return variant.URI, true
+		}
+	}
+	for _, rendition := range master.Renditions {
+		if rendition.URI != "" && master.ResolveURL(rendition.URI) == playlistURL {
+			return rendition.URI, true
+		}
+	}
+	return "", false
+}
+
+// showBookmarks lists the bookmarks, opening the chosen one in a new tab
+func (a *App) showBookmarks() {
+	saved, err := state.Load(a.statePath)
+	if err != nil {
+		a.statusBar.SetError(fmt.Sprintf("Failed to load bookmarks: %v", err))
+		return
+	}
+	if len(saved.Bookmarks) == 0 {
+		a.statusBar.SetStatus("No bookmarks yet, press m to bookmark the selection")
+		return
+	}
+
+	bookmarks := saved.Bookmarks
+	items := make([]string, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		items[i] = fmt.Sprintf("%-8s %-20s %-30s %s", bookmark.Kind, bookmark.Label, bookmark.Note, bookmark.URL)
+	}
+	a.showPicker("Bookmarks", items, 0, func(index int) {
+		a.openBookmark(bookmarks[index])
+	})
+	a.picker.SetRemoveFunc(func(index int) {
+		removed := bookmarks[index]
+		bookmarks = append(bookmarks[:index:index], bookmarks[index+1:]...)
+		err := state.Update(a.statePath, func(s *state.State) {
+			for i, bookmark := range s.Bookmarks {
+				if bookmark.Created.Equal(removed.Created) && bookmark.URL == removed.URL && bookmark.URI == removed.URI {
+					s.Bookmarks = append(s.Bookmarks[:i], s.Bookmarks[i+1:]...)
+					return
+				}
+			}
+		})
+		if err != nil {
+			a.statusBar.SetError(fmt.Sprintf("Failed to remove bookmark: %v", err))
+			return
+		}
+		a.statusBar.SetStatus(fmt.Sprintf("Removed bookmark %s", removed.Label))
+	})
+}
+
+// openBookmark opens the manifest of a bookmark in a new tab, then the variant or segment it points to
+func (a *App) openBookmark(bookmark state.Bookmark) {
+	a.showLoadingModal(fmt.Sprintf("Fetching manifest: %s", bookmark.URL))
+
+	go func() {
+		parser := hls.NewParser()
+		manifest, err := parser.Load(bookmark.URL)
+
+		a.app.QueueUpdateDraw(func() {
+			a.hideLoadingModal()
+			if err != nil {
+				a.statusBar.SetError(fmt.Sprintf("Failed to load manifest: %v", err))
+				return
+			}
+			a.addTab(parser)
+			a.showManifest(manifest, views.ManifestLabel(manifest, nil, ""))
+
+			switch bookmark.Kind {
+			case state.VariantBookmark:
+				if line, ok := variantLine(manifest, bookmark.URI); ok {
+					a.moveCursor(line)
+					a.navigateToSubManifest(bookmark.URI)
+					return
+				}
+				a.statusBar.SetWarning(fmt.Sprintf("%s is no longer in the manifest", bookmark.Label))
+			case state.SegmentBookmark:
+				if segment := findSegment(manifest, bookmark.Sequence, bookmark.URI); segment != nil {
+					a.moveCursor(segment.LineNumber)
+					a.navigateToSegment(manifest, segment)
+					return
+				}
+				a.statusBar.SetWarning(fmt.Sprintf("%s is no longer in the playlist", bookmark.Label))
+			}
+		})
+	}()
+}
+
+// findSegment finds a segment by URI, or else by media sequence for playlists that reuse URIs
+func findSegment(manifest *hls.Manifest, sequence int, uri string) *hls.Segment {
+	for i := range manifest.Segments {
+		if manifest.Segments[i].URI == uri && manifest.Segments[i].Sequence == sequence {
+			return &manifest.Segments[i]
+		}
+	}
+	for i := range manifest.Segments {
+		if manifest.Segments[i].URI == uri {
+			return &manifest.Segments[i]
+		}
+	}
+	for i := range manifest.Segments {
+		if manifest.Segments[i].Sequence == sequence {
+			return &manifest.Segments[i]
+		}
+	}
+	return nil
+}
+
+// moveCursor puts the cursor of the current manifest view on a line, scrolled into view,
+// so navigating back from what the line opens returns to it
+func (a *App) moveCursor(line int) {
+	row := line - 5
+	if row < 0 {
+		row = 0
+	}
+	a.currentView.RestoreState(&views.ViewState{Line: line, ScrollRow: row})
+}
@@ -1,32 +1,137 @@
 package colors
 
-import "github.com/gdamore/tcell/v2"
-
-// HLS syntax colors similar to hlsq
-var (
-	// Tag colors
-	TagColor       = tcell.ColorYellow
-	TagValueColor  = tcell.ColorWhite
-	CommentColor   = tcell.ColorDarkGray
-	
-	// Manifest content colors
-	URIColor       = tcell.ColorLightCyan
-	BandwidthColor = tcell.ColorGreen
-	ResolutionColor = tcell.ColorPurple
-	CodecsColor    = tcell.ColorBlue
-	DurationColor  = tcell.ColorGreen
-	SequenceColor  = tcell.ColorAqua
-	
-	// Headers and UI colors
-	HeaderColor    = tcell.ColorYellow
-	HeaderBgColor  = tcell.ColorDarkBlue
-	BorderColor    = tcell.ColorWhite
-	SelectedColor  = tcell.ColorWhite
-	ErrorColor     = tcell.ColorRed
-	WarningColor   = tcell.ColorYellow
-	SuccessColor   = tcell.ColorGreen
-	
-	// Encryption colors
-	EncryptedColor = tcell.ColorRed
-	UnencryptedColor = tcell.ColorDarkGray
-)
\ No newline at end of file
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme is a color scheme for the manifest renderer, the bars and the views.
+// Views write the text roles as color tags, such as [heading]Segment Information[text],
+// which Apply registers with the colors of the theme.
+type Theme struct {
+	Name string
+
+	// Text roles, used as color tags of the same name
+	Text    tcell.Color
+	Muted   tcell.Color
+	Heading tcell.Color
+	Label   tcell.Color
+	Good    tcell.Color
+	Bad     tcell.Color
+	Warning tcell.Color
+	Accent  tcell.Color
+
+	// Screen
+	Background   tcell.Color
+	Bar          tcell.Color // Breadcrumb and status bar background
+	KeyBar       tcell.Color
+	Field        tcell.Color // Prompt input background
+	Border       tcell.Color
+	Selected     tcell.Color // Background of the selected line, tab or list item, reverse video when unset
+	SelectedText tcell.Color
+
+	// HLS syntax colors similar to hlsq
+	Tag         tcell.Color
+	TagValue    tcell.Color
+	Comment     tcell.Color
+	URI         tcell.Color
+	Bandwidth   tcell.Color
+	Resolution  tcell.Color
+	Codecs      tcell.Color
+	Duration    tcell.Color
+	Sequence    tcell.Color
+	Encrypted   tcell.Color
+	Unencrypted tcell.Color
+
+	// Cycled through for the bands telling consecutive key periods apart, used as color tags band1 to band5
+	Bands [5]tcell.Color
+}
+
+// Current is the theme applied last, dark until then
+var Current = Dark()
+
+func init() {
+	// Roles must not shadow a color of tcell, see Apply
+	for name := range Current.roles() {
+		if _, ok := tcell.ColorNames[name]; ok {
+			panic(fmt.Sprintf("color role %q shadows a tcell color name", name))
+		}
+	}
+	Apply(Current)
+}
+
+// roles maps the color tags of the text roles and bands to their colors
+func (t *Theme) roles() map[string]tcell.Color {
+	roles := map[string]tcell.Color{
+		"text":    t.Text,
+		"muted":   t.Muted,
+		"heading": t.Heading,
+		"label":   t.Label,
+		"good":    t.Good,
+		"bad":     t.Bad,
+		"warning": t.Warning,
+		"accent":  t.Accent,
+	}
+	for i, color := range t.Bands {
+		roles[BandTag(i)] = color
+	}
+	return roles
+}
+
+// BandTag names the color tag of the band for an index, cycling through the bands
+func BandTag(index int) string {
+	return fmt.Sprintf("band%d", index%len(Theme{}.Bands)+1)
+}
+
+// Apply makes a theme current, registering its text roles as color tags and setting the tview styles.
+// Primitives take their colors when they are created, so themes are applied before the UI is.
+//
+// tview resolves color tags with tcell.GetColor, so the roles are added to the process-wide
+// tcell.ColorNames. This is the only change to it: role names are checked not to shadow tcell's
+// own colors, which keep resolving as before, and Set refuses role names as color values.
+func Apply(theme *Theme) {
+	Current = theme
+	for name, color := range theme.roles() {
+		tcell.ColorNames[name] = color
+	}
+
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    theme.Background,
+		ContrastBackgroundColor:     theme.Bar,
+		MoreContrastBackgroundColor: theme.Selected,
+		BorderColor:                 theme.Border,
+		TitleColor:                  theme.Text,
+		GraphicsColor:               theme.Border,
+		PrimaryTextColor:            theme.Text,
+		SecondaryTextColor:          theme.Heading,
+		TertiaryTextColor:           theme.Good,
+		InverseTextColor:            theme.SelectedText,
+		ContrastSecondaryTextColor:  theme.Heading,
+	}
+}
+
+// Tag names a color in a color tag, "-" for the default color
+func Tag(color tcell.Color) string {
+	if !color.Valid() {
+		return "-"
+	}
+	return color.String()
+}
+
+// SelectedTag starts the highlight of a selected line or tab, reverse video for themes without colors
+func (t *Theme) SelectedTag() string {
+	if !t.Selected.Valid() {
+		return "[::r]"
+	}
+	return fmt.Sprintf("[%s:%s]", Tag(t.SelectedText), Tag(t.Selected))
+}
+
+// SelectedStyle is the style of the selected item of lists and tables
+func (t *Theme) SelectedStyle() tcell.Style {
+	if !t.Selected.Valid() {
+		return tcell.StyleDefault.Reverse(true)
+	}
+	return tcell.StyleDefault.Foreground(t.SelectedText).Background(t.Selected)
+}
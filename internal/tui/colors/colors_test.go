@@ -0,0 +1,58 @@
+package colors
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestApplyOnlyRegistersRoles(t *testing.T) {
+	defer Apply(Current)
+
+	before := make(map[string]tcell.Color, len(tcell.ColorNames))
+	for name, color := range tcell.ColorNames {
+		before[name] = color
+	}
+
+	for _, name := range BuiltinNames() {
+		theme, _ := Builtin(name)
+		Apply(theme)
+		roles := theme.roles()
+		for role, color := range roles {
+			if got := tcell.GetColor(role); got != color {
+				t.Errorf("%s: expected the %s tag to be %v, got %v", name, role, color, got)
+			}
+		}
+		for color, value := range before {
+			if _, role := roles[color]; role {
+				continue
+			}
+			if got := tcell.ColorNames[color]; got != value {
+				t.Errorf("%s: expected tcell color %s to stay %v, got %v", name, color, value, got)
+			}
+		}
+		if len(tcell.ColorNames) != len(before) {
+			t.Errorf("%s: expected %d color names, got %d", name, len(before), len(tcell.ColorNames))
+		}
+	}
+}
+
+func TestSetRejectsRoles(t *testing.T) {
+	theme := Dark()
+	for _, value := range []string{"text", "bad", "band2"} {
+		if err := theme.Set("uri", value); err == nil {
+			t.Errorf("Expected the role %s to be rejected as a color value", value)
+		}
+	}
+	if err := theme.Set("band2", "#87d7ff"); err != nil || theme.Bands[1] != tcell.GetColor("#87d7ff") {
+		t.Errorf("Expected band2 to be set, got %v, %v", theme.Bands[1], err)
+	}
+}
+
+func TestBandTag(t *testing.T) {
+	for index, want := range map[int]string{0: "band1", 4: "band5", 5: "band1", 12: "band3"} {
+		if got := BandTag(index); got != want {
+			t.Errorf("BandTag(%d) = %s, want %s", index, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package colors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// named maps the color names of the config file to the colors they set in a theme
+var named = map[string]func(t *Theme) *tcell.Color{
+	"text":          func(t *Theme) *tcell.Color { return &t.Text },
+	"muted":         func(t *Theme) *tcell.Color { return &t.Muted },
+	"heading":       func(t *Theme) *tcell.Color { return &t.Heading },
+	"label":         func(t *Theme) *tcell.Color { return &t.Label },
+	"good":          func(t *Theme) *tcell.Color { return &t.Good },
+	"bad":           func(t *Theme) *tcell.Color { return &t.Bad },
+	"warning":       func(t *Theme) *tcell.Color { return &t.Warning },
+	"accent":        func(t *Theme) *tcell.Color { return &t.Accent },
+	"background":    func(t *Theme) *tcell.Color { return &t.Background },
+	"bar":           func(t *Theme) *tcell.Color { return &t.Bar },
+	"key_bar":       func(t *Theme) *tcell.Color { return &t.KeyBar },
+	"field":         func(t *Theme) *tcell.Color { return &t.Field },
+	"border":        func(t *Theme) *tcell.Color { return &t.Border },
+	"selected":      func(t *Theme) *tcell.Color { return &t.Selected },
+	"selected_text": func(t *Theme) *tcell.Color { return &t.SelectedText },
+	"tag":           func(t *Theme) *tcell.Color { return &t.Tag },
+	"tag_value":     func(t *Theme) *tcell.Color { return &t.TagValue },
+	"comment":       func(t *Theme) *tcell.Color { return &t.Comment },
+	"uri":           func(t *Theme) *tcell.Color { return &t.URI },
+	"bandwidth":     func(t *Theme) *tcell.Color { return &t.Bandwidth },
+	"resolution":    func(t *Theme) *tcell.Color { return &t.Resolution },
+	"codecs":        func(t *Theme) *tcell.Color { return &t.Codecs },
+	"duration":      func(t *Theme) *tcell.Color { return &t.Duration },
+	"sequence":      func(t *Theme) *tcell.Color { return &t.Sequence },
+	"encrypted":     func(t *Theme) *tcell.Color { return &t.Encrypted },
+	"unencrypted":   func(t *Theme) *tcell.Color { return &t.Unencrypted },
+	"band1":         func(t *Theme) *tcell.Color { return &t.Bands[0] },
+	"band2":         func(t *Theme) *tcell.Color { return &t.Bands[1] },
+	"band3":         func(t *Theme) *tcell.Color { return &t.Bands[2] },
+	"band4":         func(t *Theme) *tcell.Color { return &t.Bands[3] },
+	"band5":         func(t *Theme) *tcell.Color { return &t.Bands[4] },
+}
+
+// Set changes a named color of the theme, such as uri, to a color name like lightcyan, a hex value like #87d7ff
+// or default for the terminal's own color
+func (t *Theme) Set(name, value string) error {
+	target, ok := named[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown color %q (expected one of %s)", name, strings.Join(Names(), ", "))
+	}
+	value = strings.ToLower(value)
+	if _, role := t.roles()[value]; role {
+		return fmt.Errorf("color value %q for %s names a color of the theme, not a color", value, name)
+	}
+	color := tcell.GetColor(value)
+	if color == tcell.ColorDefault && value != "default" {
+		return fmt.Errorf("unknown color value %q for %s", value, name)
+	}
+	*target(t) = color
+	return nil
+}
+
+// Names lists the color names Set accepts
+func Names() []string {
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,151 @@
+package colors
+
+import (
+	"os"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Dark is the default theme for dark terminals
+func Dark() *Theme {
+	return &Theme{
+		Name:         "dark",
+		Text:         tcell.ColorWhite,
+		Muted:        tcell.ColorDarkGray,
+		Heading:      tcell.ColorYellow,
+		Label:        tcell.ColorAqua,
+		Good:         tcell.ColorGreen,
+		Bad:          tcell.ColorRed,
+		Warning:      tcell.ColorYellow,
+		Accent:       tcell.ColorBlue,
+		Background:   tcell.ColorBlack,
+		Bar:          tcell.ColorDarkBlue,
+		KeyBar:       tcell.ColorDarkGray,
+		Field:        tcell.ColorDarkGray,
+		Border:       tcell.ColorWhite,
+		Selected:     tcell.ColorWhite,
+		SelectedText: tcell.ColorBlack,
+		Tag:          tcell.ColorYellow,
+		TagValue:     tcell.ColorWhite,
+		Comment:      tcell.ColorDarkGray,
+		URI:          tcell.ColorLightCyan,
+		Bandwidth:    tcell.ColorGreen,
+		Resolution:   tcell.ColorPurple,
+		Codecs:       tcell.ColorBlue,
+		Duration:     tcell.ColorGreen,
+		Sequence:     tcell.ColorAqua,
+		Encrypted:    tcell.ColorRed,
+		Unencrypted:  tcell.ColorDarkGray,
+		Bands:        [5]tcell.Color{tcell.ColorBlue, tcell.ColorGreen, tcell.ColorFuchsia, tcell.ColorTeal, tcell.ColorOlive},
+	}
+}
+
+// Light is for terminals with a light background, with dark text and muted colors
+func Light() *Theme {
+	return &Theme{
+		Name:         "light",
+		Text:         tcell.ColorBlack,
+		Muted:        tcell.ColorGray,
+		Heading:      tcell.ColorNavy,
+		Label:        tcell.ColorTeal,
+		Good:         tcell.ColorGreen,
+		Bad:          tcell.ColorMaroon,
+		Warning:      tcell.ColorOlive,
+		Accent:       tcell.ColorPurple,
+		Background:   tcell.ColorWhite,
+		Bar:          tcell.ColorLightSteelBlue,
+		KeyBar:       tcell.ColorSilver,
+		Field:        tcell.ColorSilver,
+		Border:       tcell.ColorGray,
+		Selected:     tcell.ColorSilver,
+		SelectedText: tcell.ColorBlack,
+		Tag:          tcell.ColorNavy,
+		TagValue:     tcell.ColorBlack,
+		Comment:      tcell.ColorGray,
+		URI:          tcell.ColorTeal,
+		Bandwidth:    tcell.ColorGreen,
+		Resolution:   tcell.ColorPurple,
+		Codecs:       tcell.ColorBlue,
+		Duration:     tcell.ColorGreen,
+		Sequence:     tcell.ColorTeal,
+		Encrypted:    tcell.ColorMaroon,
+		Unencrypted:  tcell.ColorGray,
+		Bands:        [5]tcell.Color{tcell.ColorNavy, tcell.ColorGreen, tcell.ColorPurple, tcell.ColorTeal, tcell.ColorOlive},
+	}
+}
+
+// HighContrast uses bright colors on black, with a yellow selection
+func HighContrast() *Theme {
+	return &Theme{
+		Name:         "high-contrast",
+		Text:         tcell.ColorWhite,
+		Muted:        tcell.ColorSilver,
+		Heading:      tcell.ColorYellow,
+		Label:        tcell.ColorAqua,
+		Good:         tcell.ColorLime,
+		Bad:          tcell.ColorRed,
+		Warning:      tcell.ColorYellow,
+		Accent:       tcell.ColorFuchsia,
+		Background:   tcell.ColorBlack,
+		Bar:          tcell.ColorNavy,
+		KeyBar:       tcell.ColorNavy,
+		Field:        tcell.ColorNavy,
+		Border:       tcell.ColorWhite,
+		Selected:     tcell.ColorYellow,
+		SelectedText: tcell.ColorBlack,
+		Tag:          tcell.ColorYellow,
+		TagValue:     tcell.ColorWhite,
+		Comment:      tcell.ColorSilver,
+		URI:          tcell.ColorAqua,
+		Bandwidth:    tcell.ColorLime,
+		Resolution:   tcell.ColorFuchsia,
+		Codecs:       tcell.ColorAqua,
+		Duration:     tcell.ColorLime,
+		Sequence:     tcell.ColorAqua,
+		Encrypted:    tcell.ColorRed,
+		Unencrypted:  tcell.ColorSilver,
+		Bands:        [5]tcell.Color{tcell.ColorAqua, tcell.ColorLime, tcell.ColorFuchsia, tcell.ColorYellow, tcell.ColorWhite},
+	}
+}
+
+// NoColor leaves every color to the terminal, showing selections in reverse video
+func NoColor() *Theme {
+	return &Theme{Name: "none"}
+}
+
+// builtin are the themes shipped with pantui by name
+var builtin = map[string]func() *Theme{
+	"dark":          Dark,
+	"light":         Light,
+	"high-contrast": HighContrast,
+	"none":          NoColor,
+}
+
+// Builtin returns a copy of a theme shipped with pantui, which can be changed without changing the original
+func Builtin(name string) (*Theme, bool) {
+	theme, ok := builtin[name]
+	if !ok {
+		return nil, false
+	}
+	return theme(), true
+}
+
+// BuiltinNames lists the themes shipped with pantui
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default is the theme used without one configured: none when NO_COLOR is set, as https://no-color.org asks,
+// otherwise dark
+func Default() *Theme {
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColor()
+	}
+	return Dark()
+}
@@ -0,0 +1,82 @@
+package components
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// breadcrumbSeparator separates the crumbs of the trail
+const breadcrumbSeparator = " [muted]›[text] "
+
+// Breadcrumb shows the open tabs and the trail of views from the first one to the current one above the page
+type Breadcrumb struct {
+	textView *tview.TextView
+	tabs     []string
+	active   int
+}
+
+// NewBreadcrumb creates a new breadcrumb line
+func NewBreadcrumb() *Breadcrumb {
+	b := &Breadcrumb{
+		textView: tview.NewTextView(),
+	}
+
+	b.textView.
+		SetDynamicColors(true).
+		SetWrap(false).
+		SetBorder(false).
+		SetBackgroundColor(colors.Current.Bar)
+
+	return b
+}
+
+// SetTabs sets the labels of the open tabs, shown before the trail when there is more than one
+func (b *Breadcrumb) SetTabs(labels []string, active int) {
+	b.tabs = labels
+	b.active = active
+}
+
+// SetCrumbs shows the labels of the views, the current one last, with the keys that navigate them
+func (b *Breadcrumb) SetCrumbs(labels []string, canForward bool) {
+	crumbs := make([]string, len(labels))
+	for i, label := range labels {
+		crumbs[i] = tview.Escape(label)
+	}
+	if len(crumbs) > 0 {
+		crumbs[len(crumbs)-1] = "[heading]" + crumbs[len(crumbs)-1] + "[text]"
+	}
+
+	var tabs string
+	if len(b.tabs) > 1 {
+		for i, label := range b.tabs {
+			if i == b.active {
+				tabs += fmt.Sprintf("%s %d %s [-:-:-] ", colors.Current.SelectedTag(), i+1, tview.Escape(label))
+			} else {
+				tabs += fmt.Sprintf("[muted] %d %s [text] ", i+1, tview.Escape(label))
+			}
+		}
+		tabs += "[muted]│[text]"
+	}
+
+	var hints []string
+	if len(labels) > 1 {
+		hints = append(hints, "B=Jump Up")
+	}
+	if canForward {
+		hints = append(hints, "]=Forward")
+	}
+	hints = append(hints, "H=History")
+	if len(b.tabs) > 1 {
+		hints = append(hints, "</>=Tabs")
+	}
+
+	b.textView.SetText(tabs + " " + strings.Join(crumbs, breadcrumbSeparator) + "  [muted]" + strings.Join(hints, " ") + "[text]")
+}
+
+// GetPrimitive returns the underlying tview primitive
+func (b *Breadcrumb) GetPrimitive() tview.Primitive {
+	return b.textView
+}
@@ -0,0 +1,52 @@
+package components
+
+import (
+	"github.com/rivo/tview"
+)
+
+// Inspector represents the side pane showing details of the selected item
+type Inspector struct {
+	textView *tview.TextView
+	title    string
+	content  string
+}
+
+// NewInspector creates a new inspector pane
+func NewInspector() *Inspector {
+	in := &Inspector{
+		textView: tview.NewTextView(),
+	}
+
+	in.textView.
+		SetDynamicColors(true).
+		SetWrap(true).
+		SetScrollable(true).
+		SetBorder(true)
+
+	in.Clear()
+
+	return in
+}
+
+// SetContent sets the inspector title and content
+func (in *Inspector) SetContent(title, content string) {
+	in.title, in.content = title, content
+	in.textView.SetTitle(" " + title + " ")
+	in.textView.SetText(content)
+	in.textView.ScrollToBeginning()
+}
+
+// Clear resets the inspector to its empty state
+func (in *Inspector) Clear() {
+	in.SetContent("Inspector", "[muted]Nothing selected[text]")
+}
+
+// Content returns the inspector title and content
+func (in *Inspector) Content() (title, content string) {
+	return in.title, in.content
+}
+
+// GetPrimitive returns the underlying tview primitive
+func (in *Inspector) GetPrimitive() tview.Primitive {
+	return in.textView
+}
@@ -2,9 +2,9 @@ package components
 
 import (
 	"fmt"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
 	"strings"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -24,11 +24,6 @@ type KeyBar struct {
 func NewKeyBar() *KeyBar {
 	kb := &KeyBar{
 		textView: tview.NewTextView(),
-		bindings: []KeyBinding{
-			{"F1", "Help"},
-			{"Esc", "Back/Quit"},
-			{"Ctrl+C", "Exit"},
-		},
 	}
 	
 	kb.textView.
@@ -36,23 +31,16 @@ func NewKeyBar() *KeyBar {
 		SetRegions(true).
 		SetWrap(false).
 		SetBorder(false).
-		SetBackgroundColor(tcell.ColorDarkGray)
+		SetBackgroundColor(colors.Current.KeyBar)
 	
 	kb.updateDisplay()
 	
 	return kb
 }
 
-// SetKeys sets the key bindings
+// SetKeys sets the key bindings, the view's followed by the global ones
 func (kb *KeyBar) SetKeys(bindings []KeyBinding) {
-	// Always include global bindings
-	globalBindings := []KeyBinding{
-		{"F1", "Help"},
-		{"Esc", "Back/Quit"},
-		{"Ctrl+C", "Exit"},
-	}
-	
-	kb.bindings = append(bindings, globalBindings...)
+	kb.bindings = bindings
 	kb.updateDisplay()
 }
 
@@ -70,11 +58,11 @@ func (kb *KeyBar) updateDisplay() {
 	var parts []string
 	
 	for _, binding := range kb.bindings {
-		part := fmt.Sprintf("[white]%s[darkgray]=%s", binding.Key, binding.Description)
+		part := fmt.Sprintf("[text]%s[muted]=%s", tview.Escape(binding.Key), binding.Description)
 		parts = append(parts, part)
 	}
 	
-	content := " " + strings.Join(parts, " [darkgray]|[white] ")
+	content := " " + strings.Join(parts, " [muted]|[text] ")
 	kb.textView.SetText(content)
 }
 
@@ -0,0 +1,117 @@
+package components
+
+import (
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// pickerWidth and pickerMaxHeight bound the size of the picker box
+const (
+	pickerWidth     = 100
+	pickerMaxHeight = 20
+)
+
+// Picker is a list of choices shown over the page, such as the navigation history
+type Picker struct {
+	list     *tview.List
+	layout   *tview.Flex
+	title    string
+	onSelect func(index int)
+	onRemove func(index int)
+	onClose  func()
+}
+
+// NewPicker creates a new picker
+func NewPicker() *Picker {
+	p := &Picker{
+		list:   tview.NewList(),
+		layout: tview.NewFlex(),
+	}
+
+	p.list.
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedStyle(colors.Current.SelectedStyle()).
+		SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+			p.done(index)
+		}).
+		SetDoneFunc(func() {
+			p.done(-1)
+		})
+	p.list.SetBorder(true)
+	p.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if p.onRemove != nil && (event.Rune() == 'd' || event.Key() == tcell.KeyDelete) {
+			p.remove()
+			return nil
+		}
+		return event
+	})
+
+	return p
+}
+
+// Open fills the picker with items, selecting one, and calls onSelect with the chosen index unless it is cancelled
+func (p *Picker) Open(title string, items []string, selected int, onSelect func(index int), onClose func()) {
+	p.title = title
+	p.onSelect = onSelect
+	p.onRemove = nil
+	p.onClose = onClose
+
+	p.list.Clear()
+	for _, item := range items {
+		p.list.AddItem(item, "", 0, nil)
+	}
+	p.list.SetCurrentItem(selected)
+	p.list.SetTitle(" " + title + " (Enter selects, Esc closes) ")
+
+	height := len(items) + 2
+	if height > pickerMaxHeight {
+		height = pickerMaxHeight
+	}
+	column := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(p.list, height, 0, true).
+		AddItem(nil, 0, 1, false)
+	p.layout.Clear().
+		AddItem(nil, 0, 1, false).
+		AddItem(column, pickerWidth, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// SetRemoveFunc lets the open picker remove items with d or Delete, calling remove with the index of each
+func (p *Picker) SetRemoveFunc(remove func(index int)) {
+	p.onRemove = remove
+	p.list.SetTitle(" " + p.title + " (Enter selects, d removes, Esc closes) ")
+}
+
+// remove drops the selected item, closing the picker once it is empty
+func (p *Picker) remove() {
+	index := p.list.GetCurrentItem()
+	if index < 0 || index >= p.list.GetItemCount() {
+		return
+	}
+	p.list.RemoveItem(index)
+	p.onRemove(index)
+	if p.list.GetItemCount() == 0 {
+		p.done(-1)
+	}
+}
+
+// done closes the picker, then reports the chosen index, negative when cancelled
+func (p *Picker) done(index int) {
+	onSelect := p.onSelect
+	if p.onClose != nil {
+		p.onClose()
+	}
+	if index >= 0 && onSelect != nil {
+		onSelect(index)
+	}
+}
+
+// GetPrimitive returns the underlying tview primitive
+func (p *Picker) GetPrimitive() tview.Primitive {
+	return p.layout
+}
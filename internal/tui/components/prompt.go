@@ -0,0 +1,55 @@
+package components
+
+import (
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Prompt represents a single line input shown in place of the key bar
+type Prompt struct {
+	inputField *tview.InputField
+	onSubmit   func(text string)
+	onClose    func()
+}
+
+// NewPrompt creates a new prompt
+func NewPrompt() *Prompt {
+	p := &Prompt{
+		inputField: tview.NewInputField(),
+	}
+
+	p.inputField.
+		SetFieldBackgroundColor(colors.Current.Field).
+		SetLabelColor(colors.Current.Heading).
+		SetDoneFunc(p.done)
+
+	return p
+}
+
+// Open resets the prompt with a label and initial text
+func (p *Prompt) Open(label, initial string, onSubmit func(text string), onClose func()) {
+	p.onSubmit = onSubmit
+	p.onClose = onClose
+	p.inputField.SetLabel(label)
+	p.inputField.SetText(initial)
+}
+
+// done handles Enter and Escape in the input field
+func (p *Prompt) done(key tcell.Key) {
+	onSubmit := p.onSubmit
+	text := p.inputField.GetText()
+
+	if p.onClose != nil {
+		p.onClose()
+	}
+	if key == tcell.KeyEnter && onSubmit != nil {
+		onSubmit(text)
+	}
+}
+
+// GetPrimitive returns the underlying tview primitive
+func (p *Prompt) GetPrimitive() tview.Primitive {
+	return p.inputField
+}
@@ -2,9 +2,9 @@ package components
 
 import (
 	"fmt"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
 	"time"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -26,7 +26,7 @@ func NewStatusBar() *StatusBar {
 		SetRegions(true).
 		SetWrap(false).
 		SetBorder(false).
-		SetBackgroundColor(tcell.ColorDarkBlue)
+		SetBackgroundColor(colors.Current.Bar)
 	
 	sb.updateDisplay()
 	
@@ -41,19 +41,19 @@ func (sb *StatusBar) SetStatus(status string) {
 
 // SetError sets an error message
 func (sb *StatusBar) SetError(message string) {
-	sb.status = fmt.Sprintf("[red]ERROR: %s[white]", message)
+	sb.status = fmt.Sprintf("[bad]ERROR: %s[text]", message)
 	sb.updateDisplay()
 }
 
 // SetWarning sets a warning message
 func (sb *StatusBar) SetWarning(message string) {
-	sb.status = fmt.Sprintf("[yellow]WARNING: %s[white]", message)
+	sb.status = fmt.Sprintf("[warning]WARNING: %s[text]", message)
 	sb.updateDisplay()
 }
 
 // SetSuccess sets a success message
 func (sb *StatusBar) SetSuccess(message string) {
-	sb.status = fmt.Sprintf("[green]SUCCESS: %s[white]", message)
+	sb.status = fmt.Sprintf("[good]SUCCESS: %s[text]", message)
 	sb.updateDisplay()
 }
 
@@ -65,7 +65,7 @@ func (sb *StatusBar) GetStatus() string {
 // updateDisplay updates the status bar display
 func (sb *StatusBar) updateDisplay() {
 	timestamp := time.Now().Format("15:04:05")
-	content := fmt.Sprintf(" [white]%s[darkgray] | [white]%s", sb.status, timestamp)
+	content := fmt.Sprintf(" [text]%s[muted] | [text]%s", sb.status, timestamp)
 	sb.textView.SetText(content)
 }
 
@@ -0,0 +1,359 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/tui/components"
+	"github.com/soldiermoth/pantui/internal/tui/views"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// globalScope is the keymap scope of the keys that work in every view
+const globalScope = "global"
+
+// globalActions are the actions of every view, in the order the help view lists them
+var globalActions = []views.Action{
+	{Name: "help", Keys: "F1", Description: "Help", Help: "Show this help"},
+	{Name: "inspector", Keys: "Tab", Description: "Inspector", Help: "Toggle inspector pane"},
+	{Name: "back", Keys: "Esc", Description: "Back/Quit", Help: "Go back / Close tab at its first view / Exit application"},
+	{Name: "forward", Keys: "]", Description: "Forward", Help: "Go forward to the view left by going back"},
+	{Name: "breadcrumb", Keys: "B", Description: "Breadcrumb", Help: "Jump back to any view in the breadcrumb"},
+	{Name: "history", Keys: "H", Description: "History", Help: "Show every manifest visited this session"},
+	{Name: "new-tab", Keys: "T", Description: "New Tab", Help: "Open the selection in a new tab"},
+	{Name: "previous-tab", Keys: "<", Description: "Prev Tab", Help: "Previous tab"},
+	{Name: "next-tab", Keys: ">", Description: "Next Tab", Help: "Next tab"},
+	{Name: "close-tab", Keys: "Ctrl-W", Description: "Close Tab", Help: "Close tab"},
+	{Name: "bookmark", Keys: "m", Description: "Bookmark",
+		Help: "Bookmark the selected variant or segment, or the manifest, with a note"},
+	{Name: "bookmarks", Keys: "'", Description: "Bookmarks", Help: "List bookmarks (Enter opens in a new tab, d removes)"},
+	{Name: "quit", Keys: "Ctrl-C", Description: "Exit", Help: "Exit application"},
+	{Name: "up", Keys: "Up", Description: "Up", Help: "Move up"},
+	{Name: "down", Keys: "Down", Description: "Down", Help: "Move down"},
+	{Name: "left", Keys: "Left", Description: "Left", Help: "Move left"},
+	{Name: "right", Keys: "Right", Description: "Right", Help: "Move right"},
+	{Name: "page-up", Keys: "PgUp", Description: "Page Up", Help: "Move up a page"},
+	{Name: "page-down", Keys: "PgDn", Description: "Page Down", Help: "Move down a page"},
+	{Name: "top", Keys: "Home", Description: "Top", Help: "Move to the top"},
+	{Name: "bottom", Keys: "End", Description: "Bottom", Help: "Move to the bottom"},
+	{Name: "open", Keys: "Enter", Description: "Open", Help: "Open the selection"},
+}
+
+// keyBarActions are the global actions the key bar shows after the view's
+var keyBarActions = []string{"help", "inspector", "back", "quit"}
+
+// movementKeys are the keys movement actions pass to views that don't handle them, such as tables
+var movementKeys = map[string]tcell.Key{
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+	"page-up":   tcell.KeyPgUp,
+	"page-down": tcell.KeyPgDn,
+	"top":       tcell.KeyHome,
+	"bottom":    tcell.KeyEnd,
+	"open":      tcell.KeyEnter,
+}
+
+// presets change the keys of some actions by scope, such as vim's j and k moving the cursor
+var presets = map[string]map[string]map[string]string{
+	"vim": {
+		globalScope: {
+			"up":        "Up k",
+			"down":      "Down j",
+			"top":       "Home g",
+			"bottom":    "End G",
+			"page-up":   "PgUp Ctrl-U",
+			"page-down": "PgDn Ctrl-D",
+		},
+		"master":  {"keyframes": "K"},
+		"media":   {"goto": "Ctrl-G"},
+		"segment": {"frames": "K"},
+	},
+}
+
+// helpViews are the views the help view lists the keys of, in order
+var helpViews = []struct {
+	title    string
+	viewType views.ViewType
+}{
+	{"MASTER MANIFEST VIEW", views.MasterViewType},
+	{"MEDIA MANIFEST VIEW", views.MediaViewType},
+	{"COMPARE VIEW (pantui compare)", views.CompareViewType},
+	{"ALIGNMENT VIEW", views.AlignmentViewType},
+	{"SEGMENT VIEW", views.SegmentViewType},
+	{"BOX TREE", views.BoxTreeViewType},
+	{"REPORT VIEW", views.ReportViewType},
+}
+
+// keymap binds keys to the names of actions, in every view and in the views of one type
+type keymap struct {
+	actions map[string]map[keyID]string   // Scope to key to action
+	keys    map[string]map[string][]keyID // Scope to action to its keys in the order given
+}
+
+// newKeymap builds the keymap from the default keys of the actions, a preset and the keys of the config file
+// by scope and action. Keys are separated by spaces and replace those of the action, none or nothing unbinds it.
+func newKeymap(preset string, bindings map[string]map[string]string) (*keymap, error) {
+	keys := map[string]map[string]string{globalScope: {}}
+	for _, action := range globalActions {
+		keys[globalScope][action.Name] = action.Keys
+	}
+	for viewType, actions := range views.Actions {
+		keys[viewType.String()] = make(map[string]string)
+		for _, action := range actions {
+			if action.Keys != "" {
+				keys[viewType.String()][action.Name] = action.Keys
+			}
+		}
+	}
+
+	if preset != "" {
+		changes, ok := presets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown keymap preset %q (expected one of %s)", preset, strings.Join(presetNames(), ", "))
+		}
+		for scope, actions := range changes {
+			for action, names := range actions {
+				keys[scope][action] = names
+			}
+		}
+	}
+
+	for scope, actions := range bindings {
+		if _, ok := keys[scope]; !ok {
+			return nil, fmt.Errorf("unknown keymap scope %q (expected %s or a view: %s)", scope, globalScope, strings.Join(scopeNames(), ", "))
+		}
+		for action, names := range actions {
+			if !isAction(scope, action) {
+				return nil, fmt.Errorf("unknown action %q in keymap scope %s", action, scope)
+			}
+			if strings.EqualFold(strings.TrimSpace(names), "none") {
+				names = ""
+			}
+			keys[scope][action] = names
+		}
+	}
+
+	k := &keymap{
+		actions: make(map[string]map[keyID]string),
+		keys:    make(map[string]map[string][]keyID),
+	}
+	for scope, actions := range keys {
+		k.actions[scope] = make(map[keyID]string)
+		k.keys[scope] = make(map[string][]keyID)
+		for action, names := range actions {
+			for _, name := range strings.Fields(names) {
+				id, err := parseKey(name)
+				if err != nil {
+					return nil, fmt.Errorf("keymap %s %s: %w", scope, action, err)
+				}
+				if other, taken := k.actions[scope][id]; taken && other != action {
+					first, second := sortedPair(action, other)
+					return nil, fmt.Errorf("key %s is bound to both %s and %s in keymap scope %s", name, first, second, scope)
+				}
+				k.actions[scope][id] = action
+				k.keys[scope][action] = append(k.keys[scope][action], id)
+			}
+		}
+	}
+	return k, nil
+}
+
+// action returns the action a key is bound to in a view type's scope, or else in the global scope
+func (k *keymap) action(viewType views.ViewType, id keyID) (string, bool) {
+	if action, ok := k.actions[viewType.String()][id]; ok {
+		return action, true
+	}
+	action, ok := k.actions[globalScope][id]
+	return action, ok
+}
+
+// keyNames names the keys running an action in a view type's scope: its own and the global ones it doesn't rebind
+func (k *keymap) keyNames(scope, action string) []string {
+	var names []string
+	for _, id := range k.keys[scope][action] {
+		names = append(names, keyName(id))
+	}
+	if scope == globalScope {
+		return names
+	}
+	for _, id := range k.keys[globalScope][action] {
+		if _, rebound := k.actions[scope][id]; !rebound {
+			names = append(names, keyName(id))
+		}
+	}
+	return names
+}
+
+// bindings lists actions with the keys running them in a scope, leaving out actions without keys
+func (k *keymap) bindings(scope string, actions []views.Action, help bool) []components.KeyBinding {
+	var bindings []components.KeyBinding
+	for _, action := range actions {
+		names := k.keyNames(scope, action.Name)
+		if len(names) == 0 {
+			continue
+		}
+		description := action.Description
+		if help {
+			description = action.HelpText()
+		}
+		bindings = append(bindings, components.KeyBinding{Key: strings.Join(names, "/"), Description: description})
+	}
+	return bindings
+}
+
+// keyName names a key the way the keymap reads it
+func keyName(id keyID) string {
+	if id.key != tcell.KeyRune {
+		return tcell.KeyNames[id.key]
+	}
+	if id.ch == ' ' {
+		return "Space"
+	}
+	return string(id.ch)
+}
+
+// isAction reports whether an action can be bound in a scope: any action globally, the view's and the global ones
+// in a view's scope
+func isAction(scope, action string) bool {
+	for _, a := range globalActions {
+		if a.Name == action {
+			return true
+		}
+	}
+	for viewType, actions := range views.Actions {
+		if scope != globalScope && viewType.String() != scope {
+			continue
+		}
+		for _, a := range actions {
+			if a.Name == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopeNames lists the view scopes of the keymap for error messages
+func scopeNames() []string {
+	var names []string
+	for viewType := range views.Actions {
+		names = append(names, viewType.String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// presetNames lists the keymap presets for error messages
+func presetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedPair orders two names so errors read the same every run
+func sortedPair(a, b string) (string, string) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// SetKeymap binds the actions to the keys of a preset, such as vim, and of the keymap of the config file
+func (a *App) SetKeymap(preset string, bindings map[string]map[string]string) error {
+	k, err := newKeymap(preset, bindings)
+	if err != nil {
+		return err
+	}
+	a.keymap = k
+	a.updateKeyBar()
+	return nil
+}
+
+// setupActions sets the handlers of the global actions
+func (a *App) setupActions() {
+	a.actions = map[string]func(){
+		"help":         a.showHelp,
+		"inspector":    a.toggleInspector,
+		"back":         a.goBack,
+		"forward":      a.navigateForward,
+		"breadcrumb":   a.showAncestors,
+		"history":      a.showHistory,
+		"new-tab":      a.openSelectionInNewTab,
+		"previous-tab": func() { a.switchTab(-1) },
+		"next-tab":     func() { a.switchTab(1) },
+		"close-tab":    a.closeTab,
+		"bookmark":     a.addBookmark,
+		"bookmarks":    a.showBookmarks,
+		"quit":         a.app.Stop,
+	}
+}
+
+// runAction runs the action the keymap binds a key to: the current view's while it has focus, or the global one.
+// Movement actions nothing handles reach the focused primitive as the keys they are named after.
+func (a *App) runAction(event *tcell.EventKey) *tcell.EventKey {
+	viewType := views.HelpViewType
+	if a.currentView != nil {
+		viewType = a.currentView.GetType()
+	}
+	action, ok := a.keymap.action(viewType, eventKeyID(event))
+	if !ok {
+		return event
+	}
+
+	if handler := a.viewHandler(action); handler != nil {
+		handler()
+		return nil
+	}
+	if handler, ok := a.actions[action]; ok {
+		handler()
+		return nil
+	}
+	if key, ok := movementKeys[action]; ok {
+		return tcell.NewEventKey(key, 0, tcell.ModNone)
+	}
+	return event
+}
+
+// viewHandler returns the current view's handler of an action while the view has focus, not behind the help
+func (a *App) viewHandler(action string) func() {
+	if a.currentView == nil || a.app.GetFocus() != a.currentView.GetPrimitive() {
+		return nil
+	}
+	return a.currentView.ActionHandler(action)
+}
+
+// updateKeyBar shows the keys of the current view's actions and a few global ones
+func (a *App) updateKeyBar() {
+	var bindings []components.KeyBinding
+	if a.currentView != nil {
+		viewType := a.currentView.GetType()
+		bindings = a.keymap.bindings(viewType.String(), views.Actions[viewType], false)
+	}
+	for _, name := range keyBarActions {
+		for _, action := range globalActions {
+			if action.Name == name {
+				bindings = append(bindings, a.keymap.bindings(globalScope, []views.Action{action}, false)...)
+			}
+		}
+	}
+	a.keyBar.SetKeys(bindings)
+}
+
+// helpSections lists the keys of the global actions and of each view's actions for the help view
+func (a *App) helpSections() []views.KeySection {
+	sections := []views.KeySection{{Title: "GLOBAL KEYS", Keys: a.keymap.bindings(globalScope, globalActions, true)}}
+	for _, section := range helpViews {
+		scope := section.viewType.String()
+		sections = append(sections, views.KeySection{
+			Title: section.title,
+			Keys:  a.keymap.bindings(scope, views.Actions[section.viewType], true),
+		})
+	}
+	return sections
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"github.com/soldiermoth/pantui/internal/tui/views"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want keyID
+	}{
+		{"j", keyID{key: tcell.KeyRune, ch: 'j'}},
+		{"?", keyID{key: tcell.KeyRune, ch: '?'}},
+		{"Space", keyID{key: tcell.KeyRune, ch: ' '}},
+		{"Ctrl-D", keyID{key: tcell.KeyCtrlD}},
+		{"Ctrl+D", keyID{key: tcell.KeyCtrlD}},
+		{"ctrl-d", keyID{key: tcell.KeyCtrlD}},
+		{"PgDn", keyID{key: tcell.KeyPgDn}},
+		{"F5", keyID{key: tcell.KeyF5}},
+		{"Enter", keyID{key: tcell.KeyEnter}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseKey(tt.name)
+		if err != nil {
+			t.Errorf("parseKey(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseKey(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := parseKey("Hyper-X"); err == nil {
+		t.Error("Expected an unknown key name to be an error")
+	}
+}
+
+func TestKeymapPresets(t *testing.T) {
+	if _, err := newKeymap("", nil); err != nil {
+		t.Errorf("Default keymap has conflicts: %v", err)
+	}
+	for name := range presets {
+		if _, err := newKeymap(name, nil); err != nil {
+			t.Errorf("Preset %s has conflicts: %v", name, err)
+		}
+	}
+
+	k, err := newKeymap("vim", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, viewType := range []views.ViewType{views.MasterViewType, views.MediaViewType, views.SegmentViewType} {
+		if action, _ := k.action(viewType, keyID{key: tcell.KeyRune, ch: 'k'}); action != "up" {
+			t.Errorf("Expected k to move up in the %s view with the vim preset, got %q", viewType, action)
+		}
+	}
+}
+
+func TestKeymapErrors(t *testing.T) {
+	tests := []struct {
+		preset   string
+		bindings map[string]map[string]string
+		want     string // Expected in the error
+	}{
+		{bindings: map[string]map[string]string{"media": {"play": "r"}}, want: "key r is bound to both play and refresh in keymap scope media"},
+		{bindings: map[string]map[string]string{"global": {"help": "H"}}, want: "key H is bound to both help and history"},
+		{bindings: map[string]map[string]string{"player": {"play": "p"}}, want: `unknown keymap scope "player"`},
+		{bindings: map[string]map[string]string{"media": {"launch": "x"}}, want: `unknown action "launch" in keymap scope media`},
+		{bindings: map[string]map[string]string{"master": {"goto": "x"}}, want: `unknown action "goto" in keymap scope master`},
+		{bindings: map[string]map[string]string{"global": {"help": "Hyper-X"}}, want: `unknown key "Hyper-X"`},
+		{preset: "emacs", want: `unknown keymap preset "emacs"`},
+	}
+
+	for _, tt := range tests {
+		_, err := newKeymap(tt.preset, tt.bindings)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("newKeymap(%q, %v) error = %v, want %q", tt.preset, tt.bindings, err, tt.want)
+		}
+	}
+}
+
+func TestKeymapUnbind(t *testing.T) {
+	for _, value := range []string{"none", "None", ""} {
+		k, err := newKeymap("", map[string]map[string]string{"media": {"play": value}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := k.keyNames("media", "play"); len(names) != 0 {
+			t.Errorf("Expected %q to unbind play, got keys %v", value, names)
+		}
+		if _, ok := k.action(views.MediaViewType, keyID{key: tcell.KeyRune, ch: 'p'}); ok {
+			t.Errorf("Expected p to run nothing after %q unbinds play", value)
+		}
+	}
+}
+
+func TestKeymapScopes(t *testing.T) {
+	k, err := newKeymap("", map[string]map[string]string{
+		"global": {"down": "Down j"},
+		"master": {"keyframes": "j"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := keyID{key: tcell.KeyRune, ch: 'j'}
+	if action, _ := k.action(views.MasterViewType, j); action != "keyframes" {
+		t.Errorf("Expected the master view's j to shadow the global one, got %q", action)
+	}
+	if action, _ := k.action(views.MediaViewType, j); action != "down" {
+		t.Errorf("Expected the global j in the media view, got %q", action)
+	}
+
+	if names := strings.Join(k.keyNames("master", "down"), " "); names != "Down" {
+		t.Errorf("Expected the master view to list only the global keys it doesn't rebind, got %q", names)
+	}
+	if names := strings.Join(k.keyNames("media", "down"), " "); names != "Down j" {
+		t.Errorf("Expected the media view to list the global keys, got %q", names)
+	}
+}
@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// keyID identifies a key press regardless of modifiers tcell already folds into the key, such as Ctrl-D
+type keyID struct {
+	key tcell.Key
+	ch  rune
+}
+
+// eventKeyID identifies the key of an event
+func eventKeyID(event *tcell.EventKey) keyID {
+	if event.Key() == tcell.KeyRune {
+		return keyID{key: tcell.KeyRune, ch: event.Rune()}
+	}
+	return keyID{key: event.Key()}
+}
+
+// parseKey reads a key name as tcell names it, such as Down, PgDn, Ctrl-D or Ctrl+D, F1 or a single character
+func parseKey(name string) (keyID, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		ch, _ := utf8.DecodeRuneInString(name)
+		return keyID{key: tcell.KeyRune, ch: ch}, nil
+	}
+	if strings.EqualFold(name, "Space") {
+		return keyID{key: tcell.KeyRune, ch: ' '}, nil
+	}
+
+	normalized := strings.ReplaceAll(name, "+", "-")
+	for key, keyName := range tcell.KeyNames {
+		if strings.EqualFold(keyName, normalized) {
+			return keyID{key: key}, nil
+		}
+	}
+	return keyID{}, fmt.Errorf("unknown key %q", name)
+}
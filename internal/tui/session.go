@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/state"
+	"github.com/soldiermoth/pantui/internal/tui/views"
+	"strings"
+	"time"
+)
+
+// saveSession keeps the open tabs, their navigation stacks and cursors for pantui --resume.
+// Only manifest and segment views are kept, a tab ends at the first view derived from them.
+func (a *App) saveSession() error {
+	if a.statePath == "" {
+		return nil
+	}
+	session := &state.Session{Saved: time.Now()}
+	for _, t := range a.tabs {
+		saved := a.sessionTab(t)
+		if len(saved.Views) == 0 {
+			continue
+		}
+		if t == a.tab {
+			session.Active = len(session.Tabs)
+		}
+		session.Tabs = append(session.Tabs, saved)
+	}
+	if len(session.Tabs) == 0 {
+		return nil
+	}
+
+	return state.Update(a.statePath, func(s *state.State) {
+		s.Session = session
+	})
+}
+
+// sessionTab records the views of a tab, from its first view to the current one
+func (a *App) sessionTab(t *tab) state.Tab {
+	var saved state.Tab
+	if t.currentView == nil {
+		return saved
+	}
+
+	// Tabs in the background keep their status, the active one shows it
+	var current *views.ViewState
+	if t == a.tab {
+		current = a.getCurrentViewState()
+	} else {
+		current = &views.ViewState{
+			Type:     t.currentView.GetType(),
+			Manifest: t.currentView.GetManifest(),
+			Title:    t.status,
+			Label:    t.currentLabel,
+		}
+		t.currentView.SaveState(current)
+	}
+
+	for _, viewState := range append(append([]*views.ViewState{}, t.navStack...), current) {
+		view := state.View{
+			Type:         viewState.Type.String(),
+			Label:        viewState.Label,
+			Title:        viewState.Title,
+			Line:         viewState.Line,
+			ScrollRow:    viewState.ScrollRow,
+			ScrollColumn: viewState.ScrollColumn,
+			SearchQuery:  viewState.SearchQuery,
+			FilterQuery:  viewState.FilterQuery,
+		}
+		switch viewState.Type {
+		case views.MasterViewType, views.MediaViewType:
+			view.URL = state.Location(viewState.Manifest.URL)
+		case views.SegmentViewType:
+			view.Segment = viewState.Segment
+			view.ResolvedURL = state.Location(viewState.ResolvedURL)
+		default:
+			return saved
+		}
+		saved.Views = append(saved.Views, view)
+	}
+	return saved
+}
+
+// RunWithSession runs the application with the tabs of a saved session, fetching their manifests again.
+// Tabs whose first manifest can't be loaded are left out, the others end before a manifest that can't.
+func (a *App) RunWithSession(session *state.Session) error {
+	var failures []string
+	active := 0
+	opened := 0
+	for i, saved := range session.Tabs {
+		parser := hls.NewParser()
+		states, err := loadSessionTab(parser, saved)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		if len(states) == 0 {
+			continue
+		}
+
+		if opened > 0 {
+			a.addTab(parser)
+		} else {
+			a.parser = parser
+		}
+		if i <= session.Active {
+			active = opened
+		}
+		opened++
+
+		last := len(states) - 1
+		a.navStack = states[:last]
+		a.restoreView(states[last])
+		for _, viewState := range states {
+			if viewState.Manifest != nil {
+				a.history = append(a.history, historyEntry{label: viewState.Label, manifest: viewState.Manifest, visited: time.Now()})
+			}
+		}
+	}
+	if opened == 0 {
+		return fmt.Errorf("failed to resume session: %s", strings.Join(failures, "; "))
+	}
+
+	a.selectTab(active)
+	if len(failures) > 0 {
+		a.statusBar.SetWarning(fmt.Sprintf("Resumed %d of %d tabs: %s", opened, len(session.Tabs), strings.Join(failures, "; ")))
+	}
+	return a.run()
+}
+
+// loadSessionTab fetches the manifests of a saved tab, the first one with the tab's parser, and recreates its view
+// states. It returns the views before the first one that failed, with the error.
+func loadSessionTab(parser *hls.Parser, saved state.Tab) ([]*views.ViewState, error) {
+	states := make([]*views.ViewState, 0, len(saved.Views))
+	for i, view := range saved.Views {
+		viewState := &views.ViewState{
+			Label:        view.Label,
+			Title:        view.Title,
+			Line:         view.Line,
+			ScrollRow:    view.ScrollRow,
+			ScrollColumn: view.ScrollColumn,
+			SearchQuery:  view.SearchQuery,
+			FilterQuery:  view.FilterQuery,
+		}
+
+		switch view.Type {
+		case views.MasterViewType.String(), views.MediaViewType.String():
+			p := parser
+			if i > 0 {
+				p = hls.NewParser()
+			}
+			manifest, err := p.Load(view.URL)
+			if err != nil {
+				return states, fmt.Errorf("%s: %w", view.Label, err)
+			}
+			viewState.Manifest = manifest
+			viewState.Type = views.MasterViewType
+			if manifest.Type == hls.MediaManifest {
+				viewState.Type = views.MediaViewType
+			}
+		case views.SegmentViewType.String():
+			if view.Segment == nil {
+				return states, fmt.Errorf("%s: no segment saved", view.Label)
+			}
+			// Segments are saved after the media playlist they resolve against
+			if len(states) == 0 || states[len(states)-1].Type != views.MediaViewType {
+				return states, fmt.Errorf("%s: no playlist saved for the segment", view.Label)
+			}
+			viewState.Type = views.SegmentViewType
+			viewState.Manifest = states[len(states)-1].Manifest
+			viewState.Segment = view.Segment
+			viewState.ResolvedURL = view.ResolvedURL
+		default:
+			return states, fmt.Errorf("%s: can't reopen %s views", view.Label, view.Type)
+		}
+		states = append(states, viewState)
+	}
+	return states, nil
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/tui/views"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tab is a stream open in the workspace, with its own pages, navigation and parser
+type tab struct {
+	pages            *tview.Pages
+	parser           *hls.Parser
+	navStack         []*views.ViewState
+	forwardStack     []*views.ViewState // Views left by navigating back, most recent last
+	currentView      views.View
+	currentLabel     string
+	status           string // Status bar and inspector while the tab is in the background
+	inspectorTitle   string
+	inspectorContent string
+}
+
+// newTab creates an empty tab resolving URIs with a parser
+func newTab(parser *hls.Parser) *tab {
+	return &tab{
+		pages:    tview.NewPages(),
+		parser:   parser,
+		navStack: make([]*views.ViewState, 0),
+	}
+}
+
+// label names the tab after its first view
+func (t *tab) label() string {
+	if len(t.navStack) > 0 {
+		return t.navStack[0].Label
+	}
+	return t.currentLabel
+}
+
+// addTab opens an empty tab after the last one and switches to it
+func (a *App) addTab(parser *hls.Parser) {
+	a.tabs = append(a.tabs, newTab(parser))
+	a.selectTab(len(a.tabs) - 1)
+}
+
+// selectTab shows a tab, keeping the status and inspector of the one it replaces
+func (a *App) selectTab(index int) {
+	if a.tab != nil {
+		a.tab.status = a.statusBar.GetStatus()
+		a.tab.inspectorTitle, a.tab.inspectorContent = a.inspector.Content()
+	}
+	a.tab = a.tabs[index]
+
+	a.body.Clear().AddItem(a.pages, 0, 2, true)
+	if a.inspectorShown {
+		a.body.AddItem(a.inspector.GetPrimitive(), 0, 1, false)
+	}
+	if a.currentView != nil {
+		a.updateKeyBar()
+	}
+	a.statusBar.SetStatus(a.status)
+	if a.inspectorTitle != "" {
+		a.inspector.SetContent(a.inspectorTitle, a.inspectorContent)
+	} else {
+		a.inspector.Clear()
+	}
+	a.updateBreadcrumb()
+	a.app.SetFocus(a.pages)
+}
+
+// switchTab shows the next or previous tab, wrapping around
+func (a *App) switchTab(delta int) {
+	if len(a.tabs) == 1 {
+		a.statusBar.SetStatus("Only one tab open, press T to open the selection in a new tab")
+		return
+	}
+	if a.loadingModal != nil {
+		return
+	}
+
+	for i, t := range a.tabs {
+		if t == a.tab {
+			a.selectTab((i + delta + len(a.tabs)) % len(a.tabs))
+			return
+		}
+	}
+}
+
+// closeTab closes the active tab and shows its neighbour
+func (a *App) closeTab() {
+	if len(a.tabs) == 1 {
+		a.statusBar.SetStatus("Can't close the last tab, press Esc to exit")
+		return
+	}
+	if a.loadingModal != nil {
+		return
+	}
+
+	for i, t := range a.tabs {
+		if t != a.tab {
+			continue
+		}
+		if t.currentView != nil {
+			t.currentView.Close()
+		}
+		a.tabs = append(a.tabs[:i], a.tabs[i+1:]...)
+		a.tab = nil
+		if i == len(a.tabs) {
+			i--
+		}
+		a.selectTab(i)
+		return
+	}
+}
+
+// openSelectionInNewTab opens the selected variant, rendition or segment in a new tab, as Enter would in this one
+func (a *App) openSelectionInNewTab() {
+	if a.currentView == nil || a.loadingModal != nil {
+		return
+	}
+	switch a.currentView.GetType() {
+	case views.MasterViewType, views.MediaViewType, views.CompareViewType, views.AlignmentViewType:
+	default:
+		a.statusBar.SetStatus("Nothing to open in a new tab here")
+		return
+	}
+
+	a.openInNewTab = true
+	defer func() { a.openInNewTab = false }()
+	if open := a.currentView.ActionHandler("open"); open != nil {
+		open()
+	} else if handler := a.currentView.GetPrimitive().InputHandler(); handler != nil {
+		handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p tview.Primitive) {
+			a.app.SetFocus(p)
+		})
+	}
+}
+
+// tabStatus returns a status callback for views of the active tab.
+// While the tab is in the background its status is kept for when it is shown again.
+func (a *App) tabStatus() views.StatusCallback {
+	t := a.tab
+	return func(status string) {
+		if a.tab == t {
+			a.statusBar.SetStatus(status)
+			return
+		}
+		t.status = status
+	}
+}
+
+// tabSelection returns a selection callback for views of the active tab, kept like tabStatus keeps the status
+func (a *App) tabSelection() views.SelectionCallback {
+	t := a.tab
+	return func(title, details string) {
+		if a.tab == t {
+			a.inspector.SetContent(title, details)
+			return
+		}
+		t.inspectorTitle, t.inspectorContent = title, details
+	}
+}
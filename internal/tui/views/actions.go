@@ -0,0 +1,93 @@
+package views
+
+// Action is a named command of a view, which the keymap binds to keys
+type Action struct {
+	Name        string // Name in the keymap of the config file, such as play
+	Keys        string // Default keys separated by spaces, none for actions bound by the global keymap
+	Description string // Short description for the key bar
+	Help        string // Longer description for the help view, the description when empty
+}
+
+// Actions lists the actions of each view type in the order the key bar and help view show them
+var Actions = map[ViewType][]Action{
+	MasterViewType: {
+		{Name: "open", Description: "Open Variant", Help: "Open selected variant manifest"},
+		{Name: "play", Keys: "p", Description: "Play", Help: "Play the manifest with ffplay"},
+		{Name: "refresh", Keys: "r", Description: "Refresh", Help: "Refresh manifest"},
+		{Name: "search", Keys: "/", Description: "Search", Help: "Search lines and attributes"},
+		{Name: "next-match", Keys: "n", Description: "Next", Help: "Jump to next match"},
+		{Name: "previous-match", Keys: "N", Description: "Prev", Help: "Jump to previous match"},
+		{Name: "filter", Keys: "f", Description: "Filter", Help: "Filter entries (empty clears)"},
+		{Name: "query", Keys: ":", Description: "Query", Help: "Run a query and show the result"},
+		{Name: "diff", Keys: "D", Description: "Diff", Help: "Diff against the manifest before the last refresh"},
+		{Name: "verify", Keys: "v", Description: "Verify CODECS", Help: "Verify CODECS attributes against the media"},
+		{Name: "keyframes", Keys: "k", Description: "Keyframes", Help: "Compare keyframes across variants at a media sequence"},
+		{Name: "alignment", Keys: "A", Description: "Alignment", Help: "Show the segment alignment matrix of all variants"},
+	},
+	MediaViewType: {
+		{Name: "open", Description: "Open Segment", Help: "Open selected segment details"},
+		{Name: "play", Keys: "p", Description: "Play", Help: "Play the playlist with ffplay"},
+		{Name: "summary", Keys: "s", Description: "Summary", Help: "Show manifest summary and key periods"},
+		{Name: "refresh", Keys: "r", Description: "Refresh", Help: "Refresh manifest"},
+		{Name: "search", Keys: "/", Description: "Search", Help: "Search lines and attributes"},
+		{Name: "next-match", Keys: "n", Description: "Next", Help: "Jump to next match"},
+		{Name: "previous-match", Keys: "N", Description: "Prev", Help: "Jump to previous match"},
+		{Name: "filter", Keys: "f", Description: "Filter", Help: "Filter entries (empty clears)"},
+		{Name: "query", Keys: ":", Description: "Query", Help: "Run a query and show the result"},
+		{Name: "diff", Keys: "D", Description: "Diff", Help: "Diff against the manifest before the last refresh"},
+		{Name: "goto", Keys: "g", Description: "Go To",
+			Help: "Go to sequence (1042), offset (12:34),\nwall clock (@00:41:10) or line (L120)"},
+		{Name: "continuity", Keys: "c", Description: "Continuity", Help: "Check timestamp continuity from the selected segment"},
+	},
+	SegmentViewType: {
+		{Name: "copy-url", Keys: "c", Description: "Copy URL", Help: "Copy segment URL to clipboard"},
+		{Name: "open-browser", Keys: "o", Description: "Open in Browser", Help: "Open segment in browser"},
+		{Name: "headers", Keys: "h", Description: "HTTP Headers", Help: "Show HTTP headers"},
+		{Name: "inspect", Keys: "i", Description: "Inspect",
+			Help: "Inspect segment with every applicable analyzer\n(ffprobe, MPEG-TS, fMP4 and external analyzers)\nEsc cancels a running analysis"},
+		{Name: "drm", Keys: "d", Description: "DRM", Help: "Show DRM systems, PSSH and fMP4 protection boxes"},
+		{Name: "ts-analysis", Keys: "t", Description: "TS Analysis", Help: "Analyze MPEG-TS packets natively (no ffprobe needed)"},
+		{Name: "boxes", Keys: "b", Description: "Boxes", Help: "Browse fMP4 boxes of the init fragment and segment"},
+		{Name: "frames", Keys: "k", Description: "Frames", Help: "List video frames and GOPs, check the keyframe start"},
+	},
+	CompareViewType: {
+		{Name: "open", Description: "Open Segment", Help: "Open the selected origin's segment"},
+		{Name: "reload", Keys: "r", Description: "Reload", Help: "Reload every playlist"},
+		{Name: "auto-reload", Keys: "a", Description: "Auto Reload", Help: "Toggle auto reload"},
+		{Name: "probe", Keys: "h", Description: "Probe Size/ETag", Help: "Probe size and ETag of the selected sequence"},
+		{Name: "newest", Keys: "G", Description: "Newest", Help: "Jump to the newest sequence"},
+	},
+	AlignmentViewType: {
+		{Name: "open", Description: "Open Segment", Help: "Open the selected variant's segment"},
+		{Name: "next-issue", Keys: "n", Description: "Next Issue", Help: "Jump to the next misaligned sequence"},
+		{Name: "previous-issue", Keys: "N", Description: "Prev Issue", Help: "Jump to the previous misaligned sequence"},
+		{Name: "findings", Keys: "f", Description: "Findings", Help: "Show the findings in the inspector"},
+	},
+	BoxTreeViewType: {
+		{Name: "open", Description: "Expand/Collapse", Help: "Expand/collapse the selected box"},
+		{Name: "expand-all", Keys: "e", Description: "Expand All", Help: "Expand all boxes"},
+		{Name: "collapse-all", Keys: "c", Description: "Collapse All", Help: "Collapse all boxes"},
+	},
+	ReportViewType: {
+		{Name: "top", Keys: "g", Description: "Top", Help: "Scroll to the top"},
+		{Name: "bottom", Keys: "G", Description: "Bottom", Help: "Scroll to the bottom"},
+	},
+}
+
+// ViewTypeNamed returns the view type of a name in the keymap, such as master
+func ViewTypeNamed(name string) (ViewType, bool) {
+	for viewType := range Actions {
+		if viewType.String() == name {
+			return viewType, true
+		}
+	}
+	return 0, false
+}
+
+// HelpText returns the description of an action for the help view
+func (a Action) HelpText() string {
+	if a.Help != "" {
+		return a.Help
+	}
+	return a.Description
+}
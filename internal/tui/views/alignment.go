@@ -0,0 +1,278 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+	"github.com/soldiermoth/pantui/internal/verify"
+	"path"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// alignmentHeaderRows is the number of fixed header rows in the alignment matrix
+const alignmentHeaderRows = 2
+
+// checkAlignment loads every variant's media playlist and shows their segments side by side
+func (mv *MasterView) checkAlignment() {
+	if mv.alignmentCallback == nil {
+		return
+	}
+	if len(mv.manifest.Variants) == 0 {
+		mv.setStatus("No variants to align")
+		return
+	}
+
+	mv.setStatus(fmt.Sprintf("Loading %d variant playlists...", len(mv.manifest.Variants)))
+	manifest := mv.manifest
+	go func() {
+		report := verify.SegmentAlignment(mv.ctx, manifest)
+		if mv.ctx.Err() != nil || mv.updateCallback == nil {
+			return
+		}
+		mv.updateCallback(func() {
+			mv.alignmentCallback(fmt.Sprintf("Alignment - %s", manifest.URL), report)
+		})
+	}()
+}
+
+// AlignmentView shows the segments of every variant in a matrix, rows by media sequence
+type AlignmentView struct {
+	*BaseView
+	table  *tview.Table
+	report *verify.AlignmentReport
+}
+
+// NewAlignmentView creates an alignment matrix from a finished report
+func NewAlignmentView(report *verify.AlignmentReport) *AlignmentView {
+	table := tview.NewTable().
+		SetFixed(alignmentHeaderRows, 1).
+		SetSelectable(true, true).
+		SetSeparator(tview.Borders.Vertical)
+
+	av := &AlignmentView{
+		table:  table,
+		report: report,
+	}
+
+	av.BaseView = NewBaseView(table, AlignmentViewType, nil)
+	av.table.SetSelectionChangedFunc(func(row, column int) {
+		av.emitSelection()
+	})
+	av.table.SetSelectedFunc(func(row, column int) {
+		av.openSegment(row, column)
+	})
+	av.setupActions()
+	av.render()
+	av.table.Select(alignmentHeaderRows, 1)
+
+	return av
+}
+
+// Report returns the report backing the view
+func (av *AlignmentView) Report() *verify.AlignmentReport {
+	return av.report
+}
+
+// SaveState records the selected cell and the scroll position
+func (av *AlignmentView) SaveState(state *ViewState) {
+	state.Row, state.Column = av.table.GetSelection()
+	state.ScrollRow, state.ScrollColumn = av.table.GetOffset()
+}
+
+// RestoreState selects the saved cell again
+func (av *AlignmentView) RestoreState(state *ViewState) {
+	av.table.SetOffset(state.ScrollRow, state.ScrollColumn)
+	av.table.Select(state.Row, state.Column)
+}
+
+// SetSelectionCallback sets the selection callback and reports the current row
+func (av *AlignmentView) SetSelectionCallback(callback SelectionCallback) {
+	av.BaseView.SetSelectionCallback(callback)
+	av.emitSelection()
+}
+
+// setupActions sets the handlers of the alignment view's actions
+func (av *AlignmentView) setupActions() {
+	av.Handle("next-issue", func() { av.jumpToIssue(true) })
+	av.Handle("previous-issue", func() { av.jumpToIssue(false) })
+	av.Handle("findings", av.showFindings)
+}
+
+// showFindings shows the findings of the report in the inspector
+func (av *AlignmentView) showFindings() {
+	if av.selectionCallback != nil {
+		av.selectionCallback("Alignment Findings", av.formatFindings())
+	}
+}
+
+// render fills the table from the report
+func (av *AlignmentView) render() {
+	av.table.Clear()
+
+	av.table.SetCell(0, 0, headerCell("SEQ"))
+	av.table.SetCell(1, 0, headerCell(""))
+	for i, playlist := range av.report.Variants {
+		av.table.SetCell(0, i+1, headerCell(tview.Escape(variantLabel(playlist.Variant))))
+		var status string
+		if playlist.Err != nil {
+			status = "[bad]error: " + tview.Escape(playlist.Err.Error())
+		} else {
+			status = fmt.Sprintf("[good]%d segments", len(playlist.Manifest.Segments))
+		}
+		av.table.SetCell(1, i+1, headerCell(status))
+	}
+
+	for r, row := range av.report.Rows {
+		sequence := tview.NewTableCell(fmt.Sprintf("%d", row.Sequence)).SetTextColor(colors.Current.Label)
+		if len(row.Issues) > 0 {
+			sequence.SetText(fmt.Sprintf("%d !", row.Sequence)).SetTextColor(colors.Current.Warning)
+		}
+		av.table.SetCell(r+alignmentHeaderRows, 0, sequence)
+		for c, cell := range row.Cells {
+			av.table.SetCell(r+alignmentHeaderRows, c+1, alignmentCell(cell))
+		}
+	}
+
+	av.table.SetTitle(fmt.Sprintf(" Alignment - %d variants, %d sequences, %d misaligned ",
+		len(av.report.Variants), len(av.report.Rows), av.report.Misaligned())).SetBorder(true)
+}
+
+// alignmentCell renders one variant's segment for a sequence
+func alignmentCell(cell verify.AlignmentCell) *tview.TableCell {
+	if cell.Missing {
+		return tview.NewTableCell("missing").SetTextColor(colors.Current.Bad)
+	}
+	if cell.Segment == nil {
+		return tview.NewTableCell("-").SetTextColor(colors.Current.Muted)
+	}
+
+	text := fmt.Sprintf("%.3fs @%.3f", cell.Segment.Duration, cell.Start)
+	if cell.Segment.Discontinuity {
+		text = "DISC " + text
+	}
+	color := colors.Current.Text
+	if cell.Misaligned {
+		color = colors.Current.Bad
+	}
+	return tview.NewTableCell(text).SetTextColor(color)
+}
+
+// variantLabel names a variant by resolution and bandwidth, or by its URI for audio-only variants
+func variantLabel(variant *hls.Variant) string {
+	if variant.Resolution != "" {
+		return fmt.Sprintf("%s %dk", variant.Resolution, variant.Bandwidth/1000)
+	}
+	return path.Base(variant.URI)
+}
+
+// selectedRow returns the row under the selection
+func (av *AlignmentView) selectedRow() *verify.AlignmentRow {
+	row, _ := av.table.GetSelection()
+	index := row - alignmentHeaderRows
+	if index < 0 || index >= len(av.report.Rows) {
+		return nil
+	}
+	return &av.report.Rows[index]
+}
+
+// jumpToIssue selects the next or previous row with issues, keeping the column
+func (av *AlignmentView) jumpToIssue(forward bool) {
+	row, column := av.table.GetSelection()
+	index := row - alignmentHeaderRows
+	rows := av.report.Rows
+	for i := 1; i <= len(rows); i++ {
+		next := index - i
+		if forward {
+			next = index + i
+		}
+		next = (next%len(rows) + len(rows)) % len(rows)
+		if len(rows[next].Issues) > 0 {
+			av.table.Select(next+alignmentHeaderRows, column)
+			return
+		}
+	}
+	av.setStatus("All sequences are aligned")
+}
+
+// openSegment opens the selected variant's segment in the segment view
+func (av *AlignmentView) openSegment(row, column int) {
+	selected := av.selectedRow()
+	if selected == nil || column < 1 {
+		return
+	}
+	cell := selected.Cells[column-1]
+	if cell.Segment == nil {
+		av.setStatus(fmt.Sprintf("This variant has no segment %d", selected.Sequence))
+		return
+	}
+
+	if av.segmentNavigationCallback != nil {
+		av.segmentNavigationCallback(av.report.Variants[column-1].Manifest, cell.Segment)
+	}
+}
+
+// emitSelection reports every variant's segment at the selected sequence
+func (av *AlignmentView) emitSelection() {
+	if av.selectionCallback == nil {
+		return
+	}
+	row := av.selectedRow()
+	if row == nil {
+		av.selectionCallback("Alignment Findings", av.formatFindings())
+		return
+	}
+	av.selectionCallback(fmt.Sprintf("Sequence %d", row.Sequence), av.formatRowDetails(row))
+}
+
+// formatRowDetails formats the inspector content for a row
+func (av *AlignmentView) formatRowDetails(row *verify.AlignmentRow) string {
+	var details strings.Builder
+	if len(row.Issues) > 0 {
+		details.WriteString(fmt.Sprintf("[heading]Differs:[text] %s\n\n", strings.Join(row.Issues, ", ")))
+	}
+
+	for i, cell := range row.Cells {
+		playlist := av.report.Variants[i]
+		details.WriteString(fmt.Sprintf("[label]%s[text]\n", tview.Escape(variantLabel(playlist.Variant))))
+		switch {
+		case playlist.Err != nil:
+			details.WriteString(fmt.Sprintf("  [bad]%s[text]\n\n", tview.Escape(playlist.Err.Error())))
+			continue
+		case cell.Missing:
+			details.WriteString("  [bad]Missing from the playlist[text]\n\n")
+			continue
+		case cell.Segment == nil:
+			details.WriteString("  [muted]Outside the playlist's window[text]\n\n")
+			continue
+		}
+		if cell.Misaligned {
+			details.WriteString("  [bad]Differs from the first variant[text]\n")
+		}
+		details.WriteString(fmt.Sprintf("  URI: %s\n", tview.Escape(cell.Segment.URI)))
+		details.WriteString(fmt.Sprintf("  Duration: %.3fs\n", cell.Segment.Duration))
+		details.WriteString(fmt.Sprintf("  Start: %.3fs\n", cell.Start))
+		details.WriteString(fmt.Sprintf("  Discontinuity: %t\n\n", cell.Segment.Discontinuity))
+	}
+	return details.String()
+}
+
+// formatFindings lists the report's findings, or confirms the variants line up
+func (av *AlignmentView) formatFindings() string {
+	if len(av.report.Findings) == 0 {
+		return "[good]All variants have the same segments, durations, start times and discontinuities[text]\n"
+	}
+	var content strings.Builder
+	for _, finding := range av.report.Findings {
+		content.WriteString(fmt.Sprintf("[%s]%s:[text] %s\n\n", findingColors[finding.Severity], finding.Severity, tview.Escape(finding.Message)))
+	}
+	return content.String()
+}
+
+// setStatus reports a message in the status bar
+func (av *AlignmentView) setStatus(message string) {
+	if av.statusCallback != nil {
+		av.statusCallback(message)
+	}
+}
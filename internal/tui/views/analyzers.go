@@ -0,0 +1,275 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/analyzer"
+	"github.com/soldiermoth/pantui/internal/mp4"
+	"github.com/soldiermoth/pantui/internal/ts"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewAnalyzerRegistry registers the built-in analyzers followed by the external ones found in dir.
+// The registry is usable even when discovering external analyzers fails.
+func NewAnalyzerRegistry(dir string) (*analyzer.Registry, error) {
+	registry := analyzer.NewRegistry(&ffprobeAnalyzer{}, &tsAnalyzer{}, &mp4Analyzer{})
+	externals, err := analyzer.Discover(dir)
+	for _, external := range externals {
+		registry.Register(external)
+	}
+	return registry, err
+}
+
+// ffprobeAnalyzer probes the segment with ffprobe
+type ffprobeAnalyzer struct{}
+
+// Name returns the analyzer name
+func (fa *ffprobeAnalyzer) Name() string {
+	return "ffprobe"
+}
+
+// Applies reports whether ffprobe is installed
+func (fa *ffprobeAnalyzer) Applies(segment *analyzer.Segment, init []byte) bool {
+	return analyzer.FFProbeAvailable()
+}
+
+// Analyze runs ffprobe on the segment with its init fragment
+func (fa *ffprobeAnalyzer) Analyze(ctx context.Context, segment *analyzer.Segment, init []byte) (*analyzer.Result, error) {
+	probe, err := analyzer.ProbeSegment(ctx, segment, init)
+	if err != nil {
+		return nil, err
+	}
+	return &analyzer.Result{Data: probe, Section: fa.formatProbeData(probe)}, nil
+}
+
+// formatProbeData formats ffprobe data for display
+func (fa *ffprobeAnalyzer) formatProbeData(probe *analyzer.FFProbeOutput) string {
+	var content strings.Builder
+
+	// Format information
+	content.WriteString("[label]Format Information:[text]\n")
+	format := probe.Format
+
+	content.WriteString(fmt.Sprintf("Container: %s\n", format.FormatLongName))
+	if format.Duration != "" {
+		if duration, err := strconv.ParseFloat(format.Duration, 64); err == nil {
+			content.WriteString(fmt.Sprintf("Duration: %s\n", fa.formatDuration(duration)))
+		}
+	}
+	if format.Size != "" {
+		if size, err := strconv.ParseInt(format.Size, 10, 64); err == nil {
+			content.WriteString(fmt.Sprintf("File Size: %s\n", fa.formatBytes(size)))
+		}
+	}
+	if format.BitRate != "" {
+		if bitrate, err := strconv.ParseInt(format.BitRate, 10, 64); err == nil {
+			content.WriteString(fmt.Sprintf("Overall Bitrate: %s\n", fa.formatBitrate(bitrate)))
+		}
+	}
+
+	// Stream information
+	for i, stream := range probe.Streams {
+		content.WriteString(fmt.Sprintf("\n[label]Stream %d (%s):[text]\n", i, stream.CodecType))
+
+		content.WriteString(fmt.Sprintf("Codec: %s", stream.CodecName))
+		if stream.CodecLongName != "" {
+			content.WriteString(fmt.Sprintf(" (%s)", stream.CodecLongName))
+		}
+		content.WriteString("\n")
+
+		if stream.CodecType == "video" {
+			if stream.Width > 0 && stream.Height > 0 {
+				content.WriteString(fmt.Sprintf("Resolution: %dx%d\n", stream.Width, stream.Height))
+			}
+			if stream.AvgFrameRate != "" {
+				content.WriteString(fmt.Sprintf("Frame Rate: %s fps\n", fa.formatFrameRate(stream.AvgFrameRate)))
+			}
+			if stream.PixFmt != "" {
+				content.WriteString(fmt.Sprintf("Pixel Format: %s\n", stream.PixFmt))
+			}
+			if stream.Profile != "" {
+				content.WriteString(fmt.Sprintf("Profile: %s\n", stream.Profile))
+			}
+			if stream.Level > 0 {
+				content.WriteString(fmt.Sprintf("Level: %d\n", stream.Level))
+			}
+		} else if stream.CodecType == "audio" {
+			if stream.SampleRate != "" {
+				content.WriteString(fmt.Sprintf("Sample Rate: %s Hz\n", stream.SampleRate))
+			}
+			if stream.Channels > 0 {
+				content.WriteString(fmt.Sprintf("Channels: %d\n", stream.Channels))
+			}
+			if stream.ChannelLayout != "" {
+				content.WriteString(fmt.Sprintf("Channel Layout: %s\n", stream.ChannelLayout))
+			}
+			if stream.SampleFmt != "" {
+				content.WriteString(fmt.Sprintf("Sample Format: %s\n", stream.SampleFmt))
+			}
+		}
+
+		if stream.BitRate != "" {
+			if bitrate, err := strconv.ParseInt(stream.BitRate, 10, 64); err == nil {
+				content.WriteString(fmt.Sprintf("Bitrate: %s\n", fa.formatBitrate(bitrate)))
+			}
+		}
+
+		if stream.Duration != "" {
+			if duration, err := strconv.ParseFloat(stream.Duration, 64); err == nil {
+				content.WriteString(fmt.Sprintf("Duration: %s\n", fa.formatDuration(duration)))
+			}
+		}
+	}
+
+	return content.String()
+}
+
+// formatDuration formats duration in seconds to human-readable format
+func (fa *ffprobeAnalyzer) formatDuration(seconds float64) string {
+	duration := time.Duration(seconds * float64(time.Second))
+
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	secs := int(duration.Seconds()) % 60
+	millisecs := int(duration.Milliseconds()) % 1000
+
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%03d", hours, minutes, secs, millisecs)
+	}
+	return fmt.Sprintf("%d:%02d.%03d", minutes, secs, millisecs)
+}
+
+// formatBytes formats bytes to human-readable format
+func (fa *ffprobeAnalyzer) formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// formatBitrate formats bitrate to human-readable format
+func (fa *ffprobeAnalyzer) formatBitrate(bitrate int64) string {
+	if bitrate >= 1000000 {
+		return fmt.Sprintf("%.1f Mbps", float64(bitrate)/1000000)
+	} else if bitrate >= 1000 {
+		return fmt.Sprintf("%.1f Kbps", float64(bitrate)/1000)
+	}
+	return fmt.Sprintf("%d bps", bitrate)
+}
+
+// formatFrameRate formats frame rate from fraction string
+func (fa *ffprobeAnalyzer) formatFrameRate(frameRate string) string {
+	// Frame rate is often in format "num/den"
+	parts := strings.Split(frameRate, "/")
+	if len(parts) == 2 {
+		if num, err1 := strconv.ParseFloat(parts[0], 64); err1 == nil {
+			if den, err2 := strconv.ParseFloat(parts[1], 64); err2 == nil && den != 0 {
+				return fmt.Sprintf("%.2f", num/den)
+			}
+		}
+	}
+	return frameRate
+}
+
+// tsAnalyzer parses MPEG-TS segments natively
+type tsAnalyzer struct{}
+
+// Name returns the analyzer name
+func (ta *tsAnalyzer) Name() string {
+	return "MPEG-TS"
+}
+
+// Applies reports whether the segment is a transport stream
+func (ta *tsAnalyzer) Applies(segment *analyzer.Segment, init []byte) bool {
+	return ts.IsTS(segment.Data)
+}
+
+// Analyze parses the transport stream
+func (ta *tsAnalyzer) Analyze(ctx context.Context, segment *analyzer.Segment, init []byte) (*analyzer.Result, error) {
+	analysis, err := ts.Analyze(segment.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &analyzer.Result{Data: analysis, Section: formatTSAnalysis(analysis)}, nil
+}
+
+// mp4Analyzer summarizes the tracks and fragments of fMP4 segments natively
+type mp4Analyzer struct{}
+
+// Name returns the analyzer name
+func (ma *mp4Analyzer) Name() string {
+	return "fMP4"
+}
+
+// Applies reports whether the segment or its init fragment is ISO BMFF
+func (ma *mp4Analyzer) Applies(segment *analyzer.Segment, init []byte) bool {
+	data := segment.Data
+	return !ts.IsTS(data) && (len(init) > 0 || (len(data) >= 8 && fmp4Boxes[string(data[4:8])]))
+}
+
+// mp4Result is the structured result of the fMP4 analyzer
+type mp4Result struct {
+	Codecs    []string
+	Tracks    []mp4.Track
+	Fragments []mp4.Fragment
+}
+
+// Analyze reads the tracks from the init fragment and the fragments of the segment
+func (ma *mp4Analyzer) Analyze(ctx context.Context, segment *analyzer.Segment, init []byte) (*analyzer.Result, error) {
+	result := &mp4Result{}
+	if len(init) > 0 {
+		boxes, err := mp4.Parse(init)
+		if err != nil && len(boxes) == 0 {
+			return nil, fmt.Errorf("init fragment: %v", err)
+		}
+		result.Codecs = mp4.Codecs(boxes)
+		result.Tracks = mp4.Tracks(boxes)
+	}
+	boxes, err := mp4.Parse(segment.Data)
+	if err != nil && len(boxes) == 0 {
+		return nil, err
+	}
+	result.Fragments = mp4.Fragments(boxes, result.Tracks)
+	return &analyzer.Result{Data: result, Section: ma.format(result)}, nil
+}
+
+// format renders the tracks and fragments
+func (ma *mp4Analyzer) format(result *mp4Result) string {
+	var content strings.Builder
+	content.WriteString("\n[label]Tracks:[text]\n")
+	if len(result.Tracks) == 0 {
+		content.WriteString("No init fragment\n")
+	}
+	for _, track := range result.Tracks {
+		content.WriteString(fmt.Sprintf("Track %d: %s %s, timescale %d\n", track.ID, track.Handler, track.Format, track.Timescale))
+	}
+	if len(result.Codecs) > 0 {
+		content.WriteString(fmt.Sprintf("Codecs: %s\n", strings.Join(result.Codecs, ", ")))
+	}
+
+	content.WriteString("\n[label]Fragments:[text]\n")
+	if len(result.Fragments) == 0 {
+		content.WriteString("No movie fragments\n")
+	}
+	timescales := make(map[int]uint32)
+	for _, track := range result.Tracks {
+		timescales[track.ID] = track.Timescale
+	}
+	for _, fragment := range result.Fragments {
+		content.WriteString(fmt.Sprintf("Track %d: %d samples, decode time %d", fragment.TrackID, fragment.Samples, fragment.BaseDecodeTime))
+		if timescale := timescales[fragment.TrackID]; timescale > 0 {
+			content.WriteString(fmt.Sprintf(" (%.3fs), duration %.3fs", float64(fragment.BaseDecodeTime)/float64(timescale),
+				float64(fragment.Duration)/float64(timescale)))
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
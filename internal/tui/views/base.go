@@ -1,10 +1,10 @@
 package views
 
 import (
+	"github.com/soldiermoth/pantui/internal/compare"
 	"github.com/soldiermoth/pantui/internal/hls"
-	"github.com/soldiermoth/pantui/internal/tui/components"
+	"github.com/soldiermoth/pantui/internal/verify"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -16,6 +16,10 @@ const (
 	MediaViewType
 	SegmentViewType
 	HelpViewType
+	ReportViewType
+	CompareViewType
+	BoxTreeViewType
+	AlignmentViewType
 )
 
 // String returns the string representation of the view type
@@ -29,6 +33,14 @@ func (vt ViewType) String() string {
 		return "segment"
 	case HelpViewType:
 		return "help"
+	case ReportViewType:
+		return "report"
+	case CompareViewType:
+		return "compare"
+	case BoxTreeViewType:
+		return "boxes"
+	case AlignmentViewType:
+		return "alignment"
 	default:
 		return "unknown"
 	}
@@ -36,16 +48,37 @@ func (vt ViewType) String() string {
 
 // ViewState represents the state of a view for navigation
 type ViewState struct {
-	Type     ViewType
-	Manifest *hls.Manifest
-	Title    string
+	Type        ViewType
+	Manifest    *hls.Manifest // Manifest of the view, the media playlist of a segment view
+	Previous    *hls.Manifest // Manifest before the view's last refresh, for its diff
+	Comparison  *compare.Comparison
+	Alignment   *verify.AlignmentReport
+	Segment     *hls.Segment
+	ResolvedURL string
+	Files       []BoxFile
+	Title       string
+	Label       string // Short name in the breadcrumb
+
+	// Where the user was, restored when navigating back
+	Line         int // Cursor line of manifest views
+	Row, Column  int // Selected table cell, or tree node in depth-first order
+	ScrollRow    int
+	ScrollColumn int
+	Expanded     []bool // Expansion of tree nodes in depth-first order
+	AutoReload   bool
+	SearchQuery  string
+	FilterQuery  string
+	LastQuery    string
+	Heading      string // Title of report and box tree views
+	Content      string // Text shown by segment and report views, such as a finished analysis
+	ContentTitle string
 }
 
 // NavigationCallback is called when user wants to navigate to a resource
 type NavigationCallback func(uri string)
 
-// SegmentNavigationCallback is called when user wants to navigate to a segment
-type SegmentNavigationCallback func(segment *hls.Segment)
+// SegmentNavigationCallback is called when user wants to navigate to a segment of a media playlist
+type SegmentNavigationCallback func(playlist *hls.Manifest, segment *hls.Segment)
 
 // StatusCallback is called to update the status bar
 type StatusCallback func(status string)
@@ -53,17 +86,46 @@ type StatusCallback func(status string)
 // UpdateCallback is called to queue UI updates from goroutines
 type UpdateCallback func(updateFunc func())
 
+// SelectionCallback is called when the selected item changes
+type SelectionCallback func(title, details string)
+
+// PromptCallback is called to ask the user for a line of input
+type PromptCallback func(label, initial string, submit func(text string))
+
+// ReportCallback is called to show a text report in its own view
+type ReportCallback func(title, content string)
+
+// BoxFile is an ISO BMFF file to show in a box tree
+type BoxFile struct {
+	Name string
+	URL  string
+	Data []byte
+}
+
+// BoxTreeCallback is called to browse the boxes of fMP4 files in their own view
+type BoxTreeCallback func(title string, files []BoxFile)
+
+// AlignmentCallback is called to show a cross-variant segment alignment matrix in its own view
+type AlignmentCallback func(title string, report *verify.AlignmentReport)
+
 // View represents a view in the TUI
 type View interface {
 	GetPrimitive() tview.Primitive
 	GetType() ViewType
 	GetManifest() *hls.Manifest
-	GetKeyBindings() []components.KeyBinding
-	HandleKey(event *tcell.EventKey) *tcell.EventKey
+	ActionHandler(action string) func()
 	SetNavigationCallback(callback NavigationCallback)
 	SetSegmentNavigationCallback(callback SegmentNavigationCallback)
 	SetStatusCallback(callback StatusCallback)
 	SetUpdateCallback(callback UpdateCallback)
+	SetSelectionCallback(callback SelectionCallback)
+	SetPromptCallback(callback PromptCallback)
+	SetReportCallback(callback ReportCallback)
+	SetBoxTreeCallback(callback BoxTreeCallback)
+	SetAlignmentCallback(callback AlignmentCallback)
+	SaveState(state *ViewState)
+	RestoreState(state *ViewState)
+	Close()
 }
 
 // BaseView provides common functionality for all views
@@ -71,20 +133,25 @@ type BaseView struct {
 	primitive                 tview.Primitive
 	viewType                  ViewType
 	manifest                  *hls.Manifest
-	keyBindings               []components.KeyBinding
+	handlers                  map[string]func()
 	navigationCallback        NavigationCallback
 	segmentNavigationCallback SegmentNavigationCallback
 	statusCallback            StatusCallback
 	updateCallback            UpdateCallback
+	selectionCallback         SelectionCallback
+	promptCallback            PromptCallback
+	reportCallback            ReportCallback
+	boxTreeCallback           BoxTreeCallback
+	alignmentCallback         AlignmentCallback
 }
 
 // NewBaseView creates a new base view
 func NewBaseView(primitive tview.Primitive, viewType ViewType, manifest *hls.Manifest) *BaseView {
 	return &BaseView{
-		primitive:   primitive,
-		viewType:    viewType,
-		manifest:    manifest,
-		keyBindings: make([]components.KeyBinding, 0),
+		primitive: primitive,
+		viewType:  viewType,
+		manifest:  manifest,
+		handlers:  make(map[string]func()),
 	}
 }
 
@@ -103,22 +170,14 @@ func (bv *BaseView) GetManifest() *hls.Manifest {
 	return bv.manifest
 }
 
-// GetKeyBindings returns the key bindings for this view
-func (bv *BaseView) GetKeyBindings() []components.KeyBinding {
-	return bv.keyBindings
+// Handle sets the handler of a named action of the view, run by the keys the keymap binds to it
+func (bv *BaseView) Handle(action string, handler func()) {
+	bv.handlers[action] = handler
 }
 
-// SetKeyBindings sets the key bindings for this view
-func (bv *BaseView) SetKeyBindings(bindings []components.KeyBinding) {
-	bv.keyBindings = bindings
-}
-
-// AddKeyBinding adds a key binding
-func (bv *BaseView) AddKeyBinding(key, description string) {
-	bv.keyBindings = append(bv.keyBindings, components.KeyBinding{
-		Key:         key,
-		Description: description,
-	})
+// ActionHandler returns the handler of a named action, nil when the view doesn't have the action
+func (bv *BaseView) ActionHandler(action string) func() {
+	return bv.handlers[action]
 }
 
 // SetNavigationCallback sets the navigation callback
@@ -141,8 +200,36 @@ func (bv *BaseView) SetUpdateCallback(callback UpdateCallback) {
 	bv.updateCallback = callback
 }
 
-// HandleKey handles key events (default implementation)
-func (bv *BaseView) HandleKey(event *tcell.EventKey) *tcell.EventKey {
-	// Default implementation - pass through
-	return event
+// SetSelectionCallback sets the selection callback
+func (bv *BaseView) SetSelectionCallback(callback SelectionCallback) {
+	bv.selectionCallback = callback
+}
+
+// SetPromptCallback sets the prompt callback
+func (bv *BaseView) SetPromptCallback(callback PromptCallback) {
+	bv.promptCallback = callback
+}
+
+// SetReportCallback sets the report callback
+func (bv *BaseView) SetReportCallback(callback ReportCallback) {
+	bv.reportCallback = callback
+}
+
+// SetBoxTreeCallback sets the box tree callback
+func (bv *BaseView) SetBoxTreeCallback(callback BoxTreeCallback) {
+	bv.boxTreeCallback = callback
 }
+
+// SetAlignmentCallback sets the alignment callback
+func (bv *BaseView) SetAlignmentCallback(callback AlignmentCallback) {
+	bv.alignmentCallback = callback
+}
+
+// SaveState records where the user is in the view (default implementation)
+func (bv *BaseView) SaveState(state *ViewState) {}
+
+// RestoreState returns to a position recorded by SaveState (default implementation)
+func (bv *BaseView) RestoreState(state *ViewState) {}
+
+// Close releases background work when the view is left (default implementation)
+func (bv *BaseView) Close() {}
@@ -0,0 +1,266 @@
+package views
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/mp4"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// boxDumpBytes is how much of a box payload the inspector hex dumps
+const boxDumpBytes = 256
+
+// boxExpandDepth is how deep the tree starts expanded
+const boxExpandDepth = 3
+
+// BoxTreeView browses the ISO BMFF box tree of init fragments and media segments
+type BoxTreeView struct {
+	*BaseView
+	tree  *tview.TreeView
+	root  *tview.TreeNode
+	title string
+	files []BoxFile
+}
+
+// NewBoxTreeView creates a box tree for the given files
+func NewBoxTreeView(title string, files []BoxFile) *BoxTreeView {
+	root := tview.NewTreeNode(tview.Escape(title)).SetColor(colors.Current.Heading)
+	tree := tview.NewTreeView().
+		SetRoot(root).
+		SetCurrentNode(root)
+
+	bv := &BoxTreeView{
+		tree:  tree,
+		root:  root,
+		title: title,
+		files: files,
+	}
+
+	bv.BaseView = NewBaseView(tree, BoxTreeViewType, nil)
+	for _, file := range files {
+		root.AddChild(bv.fileNode(file))
+	}
+	bv.tree.SetTitle(" Box Tree - " + tview.Escape(title) + " ").SetBorder(true)
+	bv.tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+	bv.tree.SetChangedFunc(func(node *tview.TreeNode) {
+		bv.emitSelection(node)
+	})
+	bv.setupActions()
+
+	return bv
+}
+
+// SetSelectionCallback sets the selection callback and reports the current node
+func (bv *BoxTreeView) SetSelectionCallback(callback SelectionCallback) {
+	bv.BaseView.SetSelectionCallback(callback)
+	bv.emitSelection(bv.tree.GetCurrentNode())
+}
+
+// fileNode builds the subtree of one file, labelled with the codecs its sample entries declare
+func (bv *BoxTreeView) fileNode(file BoxFile) *tview.TreeNode {
+	boxes, err := mp4.Parse(file.Data)
+
+	label := fmt.Sprintf("%s (%s)", file.Name, bv.formatBytes(int64(len(file.Data))))
+	if codecs := mp4.Codecs(boxes); len(codecs) > 0 {
+		label += " codecs: " + strings.Join(codecs, ",")
+	}
+	node := tview.NewTreeNode(tview.Escape(label)).
+		SetColor(colors.Current.Label).
+		SetReference(file)
+
+	for _, box := range boxes {
+		node.AddChild(boxNode(box, 1))
+	}
+	if err != nil {
+		node.AddChild(tview.NewTreeNode(tview.Escape("Parse error: " + err.Error())).SetColor(colors.Current.Bad))
+	}
+	return node
+}
+
+// boxNode builds the node for a box and its children, collapsed below boxExpandDepth
+func boxNode(box *mp4.Box, depth int) *tview.TreeNode {
+	label := fmt.Sprintf("%s  %d bytes", box.Type, box.Size)
+	if description := mp4.Describe(box); description != "" {
+		label += "  " + description
+	}
+
+	node := tview.NewTreeNode(tview.Escape(label)).
+		SetReference(box).
+		SetExpanded(depth < boxExpandDepth)
+	if len(box.Children) > 0 {
+		node.SetColor(colors.Current.Good)
+	}
+	for _, child := range box.Children {
+		node.AddChild(boxNode(child, depth+1))
+	}
+	return node
+}
+
+// setupActions sets the handlers of the box tree view's actions
+func (bv *BoxTreeView) setupActions() {
+	bv.Handle("expand-all", func() { bv.root.ExpandAll() })
+	bv.Handle("collapse-all", bv.collapseAll)
+}
+
+// collapseAll collapses every box, leaving the files expanded
+func (bv *BoxTreeView) collapseAll() {
+	for _, file := range bv.root.GetChildren() {
+		for _, box := range file.GetChildren() {
+			box.CollapseAll()
+		}
+	}
+}
+
+// SaveState records the files, which boxes are expanded and the selected box
+func (bv *BoxTreeView) SaveState(state *ViewState) {
+	state.Heading = bv.title
+	state.Files = bv.files
+	state.Expanded = nil
+	current := bv.tree.GetCurrentNode()
+	bv.walk(func(index int, node *tview.TreeNode) {
+		state.Expanded = append(state.Expanded, node.IsExpanded())
+		if node == current {
+			state.Row = index
+		}
+	})
+}
+
+// RestoreState expands the saved boxes and selects the saved box again
+func (bv *BoxTreeView) RestoreState(state *ViewState) {
+	bv.walk(func(index int, node *tview.TreeNode) {
+		if index < len(state.Expanded) {
+			node.SetExpanded(state.Expanded[index])
+		}
+		if index == state.Row {
+			bv.tree.SetCurrentNode(node)
+		}
+	})
+	bv.emitSelection(bv.tree.GetCurrentNode())
+}
+
+// walk visits every node of the tree in depth-first order
+func (bv *BoxTreeView) walk(visit func(index int, node *tview.TreeNode)) {
+	index := 0
+	var walk func(node *tview.TreeNode)
+	walk = func(node *tview.TreeNode) {
+		visit(index, node)
+		index++
+		for _, child := range node.GetChildren() {
+			walk(child)
+		}
+	}
+	walk(bv.root)
+}
+
+// emitSelection describes the selected box in the inspector
+func (bv *BoxTreeView) emitSelection(node *tview.TreeNode) {
+	if bv.selectionCallback == nil || node == nil {
+		return
+	}
+
+	switch reference := node.GetReference().(type) {
+	case *mp4.Box:
+		bv.selectionCallback(reference.Type, formatBoxDetails(reference))
+	case BoxFile:
+		bv.selectionCallback(reference.Name, fmt.Sprintf("URL: %s\nSize: %s\n",
+			tview.Escape(reference.URL), bv.formatBytes(int64(len(reference.Data)))))
+	default:
+		bv.selectionCallback("", "")
+	}
+}
+
+// formatBoxDetails formats a box's position, fields and the start of its payload
+func formatBoxDetails(box *mp4.Box) string {
+	var details strings.Builder
+	details.WriteString(fmt.Sprintf("Type: %s\n", box.Type))
+	details.WriteString(fmt.Sprintf("Offset: %d\n", box.Offset))
+	details.WriteString(fmt.Sprintf("Size: %d (header %d)\n", box.Size, box.HeaderSize))
+	if len(box.Children) > 0 {
+		details.WriteString(fmt.Sprintf("Children: %d\n", len(box.Children)))
+	}
+	if description := mp4.Describe(box); description != "" {
+		details.WriteString(fmt.Sprintf("\n%s\n", tview.Escape(description)))
+	}
+
+	payload := box.Payload()
+	if len(payload) > boxDumpBytes {
+		payload = payload[:boxDumpBytes]
+	}
+	if len(payload) > 0 {
+		details.WriteString(fmt.Sprintf("\n[label]Payload (first %d bytes):[text]\n", len(payload)))
+		details.WriteString(tview.Escape(hex.Dump(payload)))
+	}
+	return details.String()
+}
+
+// formatBytes formats bytes to human-readable format
+func (bv *BoxTreeView) formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// fmp4Boxes are top level boxes that start fragmented MP4 init and media segments
+var fmp4Boxes = map[string]bool{
+	"ftyp": true, "styp": true, "moov": true, "moof": true, "sidx": true, "emsg": true, "prft": true, "free": true,
+}
+
+// showBoxTree reads the init fragment and the segment and opens them in a box tree
+func (sv *SegmentView) showBoxTree() {
+	if sv.boxTreeCallback == nil {
+		return
+	}
+	sv.showMessage("Reading boxes...")
+
+	go func() {
+		var files []BoxFile
+		var err error
+		if sv.segment.Map != nil && sv.segment.Map.URI != "" {
+			initURL := sv.playlist.ResolveURL(sv.segment.Map.URI)
+			var initData []byte
+			initData, err = fetch.ReadContext(sv.ctx, initURL, sv.segment.Map.ByteRange)
+			if err == nil {
+				files = append(files, BoxFile{Name: "Init Fragment", URL: initURL, Data: initData})
+			}
+		}
+
+		var segmentData []byte
+		if err == nil {
+			segmentData, err = sv.readSegment()
+		}
+		if err == nil {
+			if len(segmentData) < 8 || !fmp4Boxes[string(segmentData[4:8])] {
+				err = fmt.Errorf("segment is not fragmented MP4")
+			} else {
+				segmentURL := sv.resolvedURL
+				if segmentURL == "" {
+					segmentURL = sv.segment.URI
+				}
+				files = append(files, BoxFile{Name: "Media Segment", URL: segmentURL, Data: segmentData})
+			}
+		}
+
+		if sv.updateCallback != nil {
+			sv.updateCallback(func() {
+				if err != nil {
+					sv.showMessage(fmt.Sprintf("Box tree unavailable: %v", err))
+					return
+				}
+				sv.boxTreeCallback(sv.segment.URI, files)
+			})
+		}
+	}()
+}
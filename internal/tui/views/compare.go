@@ -0,0 +1,480 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/compare"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// compareHeaderRows is the number of fixed header rows in the comparison table
+const compareHeaderRows = 2
+
+// autoProbeRows is the number of newest sequences probed after every reload
+const autoProbeRows = 3
+
+// CompareView shows several origins of the same stream side by side, aligned by media sequence
+type CompareView struct {
+	*BaseView
+	table      *tview.Table
+	comparison *compare.Comparison
+	autoReload bool
+	stop       chan struct{}
+	stopOnce   sync.Once
+	loading    bool
+}
+
+// NewCompareView creates a comparison view from an already loaded comparison
+func NewCompareView(comparison *compare.Comparison) *CompareView {
+	table := tview.NewTable().
+		SetFixed(compareHeaderRows, 1).
+		SetSelectable(true, true).
+		SetSeparator(tview.Borders.Vertical)
+
+	cv := &CompareView{
+		table:      table,
+		comparison: comparison,
+		autoReload: comparison.ReloadInterval() > 0,
+		stop:       make(chan struct{}),
+	}
+
+	cv.BaseView = NewBaseView(table, CompareViewType, nil)
+	cv.table.SetSelectionChangedFunc(func(row, column int) {
+		cv.emitSelection()
+	})
+	cv.table.SetSelectedFunc(func(row, column int) {
+		cv.openSegment(row, column)
+	})
+	cv.setupActions()
+	cv.render()
+	cv.selectNewest()
+	go cv.reloadLoop()
+
+	return cv
+}
+
+// Comparison returns the comparison backing the view
+func (cv *CompareView) Comparison() *compare.Comparison {
+	return cv.comparison
+}
+
+// SaveState records the selected cell, the scroll position and whether auto reload is on
+func (cv *CompareView) SaveState(state *ViewState) {
+	state.Row, state.Column = cv.table.GetSelection()
+	state.ScrollRow, state.ScrollColumn = cv.table.GetOffset()
+	state.AutoReload = cv.autoReload
+}
+
+// RestoreState selects the saved cell again
+func (cv *CompareView) RestoreState(state *ViewState) {
+	cv.autoReload = state.AutoReload
+	cv.table.SetOffset(state.ScrollRow, state.ScrollColumn)
+	cv.table.Select(state.Row, state.Column)
+}
+
+// SetSelectionCallback sets the selection callback and reports the current row
+func (cv *CompareView) SetSelectionCallback(callback SelectionCallback) {
+	cv.BaseView.SetSelectionCallback(callback)
+	cv.emitSelection()
+}
+
+// setupActions sets the handlers of the compare view's actions
+func (cv *CompareView) setupActions() {
+	cv.Handle("reload", cv.reload)
+	cv.Handle("auto-reload", cv.toggleAutoReload)
+	cv.Handle("probe", cv.probeSelected)
+	cv.Handle("newest", cv.selectNewest)
+}
+
+// toggleAutoReload turns reloading the playlists on the comparison's interval on or off
+func (cv *CompareView) toggleAutoReload() {
+	cv.autoReload = !cv.autoReload
+	if cv.autoReload {
+		cv.setStatus("Auto reload on")
+	} else {
+		cv.setStatus("Auto reload off")
+	}
+}
+
+// probeSelected probes the size and ETag of the selected sequence's segments
+func (cv *CompareView) probeSelected() {
+	row, _ := cv.table.GetSelection()
+	if index := row - compareHeaderRows; index >= 0 {
+		cv.probeRows([]int{index})
+	}
+}
+
+// Close stops the reload loop
+func (cv *CompareView) Close() {
+	cv.stopOnce.Do(func() {
+		close(cv.stop)
+	})
+}
+
+// reloadLoop reloads the playlists on the comparison's interval while auto reload is on
+func (cv *CompareView) reloadLoop() {
+	interval := cv.comparison.ReloadInterval()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cv.stop:
+			return
+		case <-ticker.C:
+			if cv.updateCallback == nil {
+				continue
+			}
+			cv.updateCallback(func() {
+				if cv.autoReload {
+					cv.reload()
+				}
+			})
+		}
+	}
+}
+
+// reload fetches every origin again and re-renders; must run on the UI goroutine
+func (cv *CompareView) reload() {
+	if cv.loading {
+		return
+	}
+	cv.loading = true
+	cv.setStatus("Reloading playlists...")
+
+	go func() {
+		snapshot := cv.comparison.Load()
+		if cv.updateCallback == nil {
+			return
+		}
+		cv.updateCallback(func() {
+			cv.loading = false
+			follow := cv.isNewestSelected()
+			cv.comparison.Current = snapshot
+			cv.render()
+			if follow {
+				cv.selectNewest()
+			}
+			cv.setStatus(cv.summary())
+			cv.probeNewest()
+		})
+	}()
+}
+
+// probeNewest probes the newest rows so size and ETag differences show up without asking
+func (cv *CompareView) probeNewest() {
+	rows := cv.comparison.Current.Rows
+	var indexes []int
+	for i := len(rows) - 1; i >= 0 && len(indexes) < autoProbeRows; i-- {
+		indexes = append(indexes, i)
+	}
+	cv.probeRows(indexes)
+}
+
+// probeRows issues HEAD requests for every present, unprobed cell in the given rows
+func (cv *CompareView) probeRows(indexes []int) {
+	snapshot := cv.comparison.Current
+	type target struct {
+		row, column int
+		cell        compare.Cell
+	}
+	var targets []target
+	for _, index := range indexes {
+		if index >= len(snapshot.Rows) {
+			continue
+		}
+		for column, cell := range snapshot.Rows[index].Cells {
+			if cell.State == compare.Present && cell.Probe == nil {
+				targets = append(targets, target{index, column, cell})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	go func() {
+		probes := make([]*compare.Probe, len(targets))
+		var wg sync.WaitGroup
+		for i, t := range targets {
+			wg.Add(1)
+			go func(i int, cell compare.Cell) {
+				defer wg.Done()
+				probes[i] = compare.ProbeSegment(cell)
+			}(i, t.cell)
+		}
+		wg.Wait()
+
+		if cv.updateCallback == nil {
+			return
+		}
+		cv.updateCallback(func() {
+			// Drop results that arrived after a reload replaced the snapshot
+			if cv.comparison.Current != snapshot {
+				return
+			}
+			for i, t := range targets {
+				snapshot.Rows[t.row].Cells[t.column].Probe = probes[i]
+			}
+			cv.render()
+			cv.emitSelection()
+		})
+	}()
+}
+
+// render fills the table from the current snapshot
+func (cv *CompareView) render() {
+	snapshot := cv.comparison.Current
+	cv.table.Clear()
+
+	cv.table.SetCell(0, 0, headerCell("SEQ"))
+	cv.table.SetCell(1, 0, headerCell(""))
+	for i, origin := range snapshot.Origins {
+		cv.table.SetCell(0, i+1, headerCell(originLabel(origin.URL)))
+		cv.table.SetCell(1, i+1, headerCell(cv.originStatus(i)))
+	}
+
+	for r, row := range snapshot.Rows {
+		differences := row.Differences()
+		sequence := tview.NewTableCell(fmt.Sprintf("%d", row.Sequence)).SetTextColor(colors.Current.Label)
+		if len(differences) > 0 {
+			sequence.SetText(fmt.Sprintf("%d !", row.Sequence)).SetTextColor(colors.Current.Warning)
+		}
+		cv.table.SetCell(r+compareHeaderRows, 0, sequence)
+		for c, cell := range row.Cells {
+			cv.table.SetCell(r+compareHeaderRows, c+1, cv.segmentCell(cell, len(differences) > 0))
+		}
+	}
+
+	title := fmt.Sprintf(" Compare - %d origins, %d sequences", len(snapshot.Origins), len(snapshot.Rows))
+	if interval := cv.comparison.ReloadInterval(); interval > 0 && cv.autoReload {
+		title += fmt.Sprintf(" (reload every %s)", interval)
+	}
+	cv.table.SetTitle(title + " ").SetBorder(true)
+}
+
+// originStatus describes an origin's window and lag for the second header row
+func (cv *CompareView) originStatus(index int) string {
+	snapshot := cv.comparison.Current
+	origin := snapshot.Origins[index]
+	if origin.Err != nil {
+		return "[bad]error: " + tview.Escape(origin.Err.Error())
+	}
+	if origin.LastSequence() < 0 {
+		return "[muted]no segments"
+	}
+
+	status := fmt.Sprintf("%d-%d", origin.FirstSequence(), origin.LastSequence())
+	if segments, behind := snapshot.Lag(index); segments > 0 {
+		return fmt.Sprintf("[bad]%s lag %d (%s)", status, segments, behind.Round(100*time.Millisecond))
+	}
+	return "[good]" + status + " up to date"
+}
+
+// headerCell creates a non-selectable header cell
+func headerCell(text string) *tview.TableCell {
+	return tview.NewTableCell(text).
+		SetTextColor(colors.Current.Heading).
+		SetSelectable(false)
+}
+
+// segmentCell renders one origin's segment for a sequence
+func (cv *CompareView) segmentCell(cell compare.Cell, rowDiffers bool) *tview.TableCell {
+	switch cell.State {
+	case compare.Missing:
+		return tview.NewTableCell("missing").SetTextColor(colors.Current.Bad)
+	case compare.Lagging:
+		return tview.NewTableCell("lagging").SetTextColor(colors.Current.Warning)
+	case compare.Expired:
+		return tview.NewTableCell("expired").SetTextColor(colors.Current.Muted)
+	case compare.Unavailable:
+		return tview.NewTableCell("-").SetTextColor(colors.Current.Bad)
+	}
+
+	text := fmt.Sprintf("%s %.3fs", path.Base(cell.Segment.URI), cell.Segment.Duration)
+	if probe := cell.Probe; probe != nil {
+		if probe.Err != nil {
+			text += " (probe failed)"
+		} else {
+			text += " " + cv.formatBytes(probe.Size)
+			if probe.ETag != "" {
+				text += " " + shortETag(probe.ETag)
+			}
+		}
+	}
+
+	color := colors.Current.Text
+	if rowDiffers {
+		color = colors.Current.Warning
+	}
+	return tview.NewTableCell(tview.Escape(text)).SetTextColor(color)
+}
+
+// originLabel shortens an origin URL to host and file name
+func originLabel(target string) string {
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		return parsed.Host + "/…/" + path.Base(parsed.Path)
+	}
+	return path.Base(target)
+}
+
+// shortETag keeps ETags readable in a table cell
+func shortETag(etag string) string {
+	if len(etag) > 10 {
+		return etag[:10] + "…"
+	}
+	return etag
+}
+
+// formatBytes formats bytes to human-readable format
+func (cv *CompareView) formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// selectNewest selects the newest sequence, keeping the current column
+func (cv *CompareView) selectNewest() {
+	rows := len(cv.comparison.Current.Rows)
+	if rows == 0 {
+		return
+	}
+	_, column := cv.table.GetSelection()
+	if column < 1 {
+		column = 1
+	}
+	cv.table.Select(rows-1+compareHeaderRows, column)
+}
+
+// isNewestSelected reports whether the selection is on the newest sequence
+func (cv *CompareView) isNewestSelected() bool {
+	row, _ := cv.table.GetSelection()
+	return row == len(cv.comparison.Current.Rows)-1+compareHeaderRows
+}
+
+// selectedRow returns the aligned row under the selection
+func (cv *CompareView) selectedRow() *compare.Row {
+	row, _ := cv.table.GetSelection()
+	index := row - compareHeaderRows
+	if index < 0 || index >= len(cv.comparison.Current.Rows) {
+		return nil
+	}
+	return &cv.comparison.Current.Rows[index]
+}
+
+// openSegment opens the selected origin's segment in the segment view
+func (cv *CompareView) openSegment(row, column int) {
+	selected := cv.selectedRow()
+	if selected == nil || column < 1 {
+		return
+	}
+	cell := selected.Cells[column-1]
+	if cell.State != compare.Present {
+		cv.setStatus(fmt.Sprintf("Segment %d is %s on this origin", selected.Sequence, cell.State))
+		return
+	}
+
+	if cv.segmentNavigationCallback != nil {
+		cv.segmentNavigationCallback(cv.comparison.Current.Origins[column-1].Manifest, cell.Segment)
+	}
+}
+
+// emitSelection reports every origin's view of the selected sequence
+func (cv *CompareView) emitSelection() {
+	if cv.selectionCallback == nil {
+		return
+	}
+	row := cv.selectedRow()
+	if row == nil {
+		cv.selectionCallback("Compare", cv.summary())
+		return
+	}
+	cv.selectionCallback(fmt.Sprintf("Sequence %d", row.Sequence), cv.formatRowDetails(row))
+}
+
+// formatRowDetails formats the inspector content for an aligned row
+func (cv *CompareView) formatRowDetails(row *compare.Row) string {
+	var details strings.Builder
+	if differences := row.Differences(); len(differences) > 0 {
+		details.WriteString(fmt.Sprintf("[heading]Differs:[text] %s\n\n", strings.Join(differences, ", ")))
+	}
+
+	for i, cell := range row.Cells {
+		origin := cv.comparison.Current.Origins[i]
+		details.WriteString(fmt.Sprintf("[label]%s[text]\n", tview.Escape(originLabel(origin.URL))))
+		if cell.State != compare.Present {
+			details.WriteString(fmt.Sprintf("  State: %s\n\n", cell.State))
+			continue
+		}
+		details.WriteString(fmt.Sprintf("  URL: %s\n", tview.Escape(cell.URL)))
+		details.WriteString(fmt.Sprintf("  Duration: %.3fs\n", cell.Segment.Duration))
+		if cell.Segment.ByteRange != "" {
+			details.WriteString(fmt.Sprintf("  Byte Range: %s\n", cell.Segment.ByteRange))
+		}
+		if probe := cell.Probe; probe != nil {
+			if probe.Err != nil {
+				details.WriteString(fmt.Sprintf("  Probe: [bad]%s[text]\n", tview.Escape(probe.Err.Error())))
+			} else {
+				details.WriteString(fmt.Sprintf("  Size: %s (%d bytes)\n", cv.formatBytes(probe.Size), probe.Size))
+				if probe.ETag != "" {
+					details.WriteString(fmt.Sprintf("  ETag: %s\n", tview.Escape(probe.ETag)))
+				}
+			}
+		} else {
+			details.WriteString("  [muted]Press h to probe size and ETag[text]\n")
+		}
+		details.WriteString("\n")
+	}
+	return details.String()
+}
+
+// summary describes the comparison for the status bar
+func (cv *CompareView) summary() string {
+	snapshot := cv.comparison.Current
+	lagging, failed := 0, 0
+	for i, origin := range snapshot.Origins {
+		if origin.Err != nil {
+			failed++
+		} else if segments, _ := snapshot.Lag(i); segments > 0 {
+			lagging++
+		}
+	}
+
+	gaps, differing := 0, 0
+	for _, row := range snapshot.Rows {
+		for _, cell := range row.Cells {
+			if cell.State == compare.Missing {
+				gaps++
+			}
+		}
+		if len(row.Differences()) > 0 {
+			differing++
+		}
+	}
+
+	return fmt.Sprintf("Compare - latest %d, %d lagging, %d failed, %d missing segments, %d differing sequences",
+		snapshot.Latest, lagging, failed, gaps, differing)
+}
+
+// setStatus reports a message in the status bar
+func (cv *CompareView) setStatus(message string) {
+	if cv.statusCallback != nil {
+		cv.statusCallback(message)
+	}
+}
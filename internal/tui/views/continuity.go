@@ -0,0 +1,100 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/verify"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// defaultContinuitySegments is how many segments the continuity check reads unless told otherwise
+const defaultContinuitySegments = 10
+
+// startContinuity prompts for a number of segments and checks their timestamps from the selected segment on
+func (mv *MediaView) startContinuity() {
+	if mv.promptCallback == nil || mv.reportCallback == nil {
+		return
+	}
+	index := 0
+	if segment := mv.currentSegment(); segment != nil {
+		index = mv.segmentIndex(segment)
+	}
+
+	mv.promptCallback("Segments to check: ", strconv.Itoa(defaultContinuitySegments), func(input string) {
+		count, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || count < 2 {
+			mv.setStatus("Continuity check needs at least 2 segments")
+			return
+		}
+		mv.checkContinuity(index, count)
+	})
+}
+
+// segmentIndex finds a segment's position in the manifest
+func (mv *MediaView) segmentIndex(segment *hls.Segment) int {
+	for i := range mv.manifest.Segments {
+		if &mv.manifest.Segments[i] == segment {
+			return i
+		}
+	}
+	return 0
+}
+
+// checkContinuity reads the segments in the background and shows the timing report
+func (mv *MediaView) checkContinuity(index, count int) {
+	manifest := mv.manifest
+	mv.setStatus(fmt.Sprintf("Reading %d segments for timestamp continuity...", count))
+	go func() {
+		report := verify.Continuity(mv.ctx, manifest, index, count)
+		if mv.ctx.Err() != nil || mv.updateCallback == nil {
+			return
+		}
+		content := formatContinuity(report)
+		mv.updateCallback(func() {
+			mv.setStatus(fmt.Sprintf("Continuity check complete: %d issues", len(report.Issues)))
+			mv.reportCallback(fmt.Sprintf("Timestamp Continuity - %s", manifest.URL), content)
+		})
+	}()
+}
+
+// formatContinuity renders the issues followed by the timing of every segment
+func formatContinuity(report *verify.ContinuityReport) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("[label]Segments:[text] %d  [label]Issues:[text] %d\n", len(report.Segments), len(report.Issues)))
+
+	if len(report.Issues) > 0 {
+		content.WriteString("\n[label]Issues:[text]\n")
+		for _, issue := range report.Issues {
+			content.WriteString(fmt.Sprintf("  [%s]%-13s[text] seq %d  %-16s %s\n",
+				findingColors[issue.Severity], issue.Kind, issue.Sequence, issue.Track, tview.Escape(issue.Message)))
+		}
+	}
+
+	content.WriteString("\n[label]Segments:[text]\n")
+	for _, timing := range report.Segments {
+		segment := timing.Segment
+		marker := ""
+		if segment.Discontinuity {
+			marker = " [heading]DISCONTINUITY[text]"
+		}
+		content.WriteString(fmt.Sprintf("\n[heading]#%d[text] %s (EXTINF %.3fs)%s\n", segment.Sequence, tview.Escape(segment.URI), segment.Duration, marker))
+		if timing.Err != nil {
+			content.WriteString(fmt.Sprintf("  [bad]%s[text]\n", tview.Escape(timing.Err.Error())))
+			continue
+		}
+		if len(timing.Tracks) == 0 {
+			content.WriteString("  [muted]No audio or video timestamps found[text]\n")
+		}
+		for _, track := range timing.Tracks {
+			content.WriteString(fmt.Sprintf("  %-16s %12.6f → %12.6f  (%.3fs, frame %.2fms)\n",
+				track.Name(), track.Start, track.End, track.End-track.Start, track.Frame*1000))
+		}
+		if offset, ok := timing.AVOffset(); ok {
+			content.WriteString(fmt.Sprintf("  A/V start offset %.1fms\n", offset*1000))
+		}
+	}
+	return content.String()
+}
@@ -0,0 +1,27 @@
+package views
+
+// lineRows returns the navigable line up to a number of rows away from a line, backwards when rows is negative,
+// moving at least one navigable line unless there is none. Lines hidden by a filter take no rows and are skipped.
+func lineRows(items map[int]string, hidden func(line int) bool, from, maxLine, rows int) int {
+	step := 1
+	if rows < 0 {
+		step, rows = -1, -rows
+	}
+
+	target := from
+	moved := 0
+	for line := from + step; line >= 1 && line <= maxLine; line += step {
+		if hidden(line) {
+			continue
+		}
+		moved++
+		if _, ok := items[line]; !ok {
+			continue
+		}
+		if moved > rows && target != from {
+			break
+		}
+		target = line
+	}
+	return target
+}
@@ -0,0 +1,63 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/diff"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// renderManifestDiff renders semantic changes and a colorized line diff between two manifests
+func renderManifestDiff(oldManifest, newManifest *hls.Manifest) string {
+	var content strings.Builder
+
+	changes := diff.Manifests(oldManifest, newManifest)
+	content.WriteString(fmt.Sprintf("[heading]Semantic Changes (%d)[text]\n", len(changes)))
+	if len(changes) == 0 {
+		content.WriteString("[muted]No semantic changes[text]\n")
+	}
+	for _, change := range changes {
+		color := "warning"
+		switch change.Kind {
+		case diff.Added:
+			color = "good"
+		case diff.Removed:
+			color = "bad"
+		}
+		line := fmt.Sprintf("  [%s]%-7s[text] %s", color, change.Kind, tview.Escape(change.Subject))
+		if change.Detail != "" {
+			line += ": " + tview.Escape(change.Detail)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	content.WriteString("\n[heading]Line Diff[text]\n")
+	hunks := diff.Hunks(diff.Lines(oldManifest.Content, newManifest.Content), diffContext)
+	if len(hunks) == 0 {
+		content.WriteString("[muted]Manifests are identical[text]\n")
+	}
+
+	// Style lines the same way as the manifest views
+	renderer := NewManifestRenderer(newManifest)
+	for _, hunk := range hunks {
+		content.WriteString(fmt.Sprintf("[muted]%s[text]\n", hunk.Header()))
+		for _, edit := range hunk.Edits {
+			line := renderer.ColorizeLine(edit.Text)
+			switch edit.Op {
+			case diff.Equal:
+				content.WriteString("  " + line + "\n")
+			case diff.Insert:
+				content.WriteString("[good::b]+[-::-] " + line + "\n")
+			case diff.Delete:
+				content.WriteString("[bad::b]-[-::-] " + line + "\n")
+			}
+		}
+	}
+
+	return content.String()
+}
@@ -0,0 +1,224 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/drm"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/mp4"
+	"github.com/soldiermoth/pantui/internal/tui/colors"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// showDRMInfo describes the key systems protecting the segment, reading the init map for fMP4
+func (sv *SegmentView) showDRMInfo() {
+	keys := drm.ActiveKeys(sv.segment)
+	hasMap := sv.segment.Map != nil && sv.segment.Map.URI != ""
+	if len(keys) == 0 && !hasMap {
+		sv.showMessage("Segment is not encrypted")
+		return
+	}
+
+	sv.showMessage("Reading DRM information...")
+
+	go func() {
+		var content strings.Builder
+		content.WriteString(sv.formatPlaylistKeys(keys))
+
+		if hasMap {
+			initURL := sv.playlist.ResolveURL(sv.segment.Map.URI)
+			initData, err := fetch.ReadContext(sv.ctx, initURL, sv.segment.Map.ByteRange)
+			if err != nil {
+				content.WriteString(fmt.Sprintf("\n[bad]Failed to read init fragment %s: %v[text]\n", tview.Escape(initURL), err))
+			} else {
+				protection := mp4.ParseProtection(initData)
+				content.WriteString(formatProtection("Init Fragment", protection))
+
+				// Sample encryption only appears in the media segment's moof
+				if protection.Encrypted() {
+					segmentURL := sv.resolvedURL
+					if segmentURL == "" {
+						segmentURL = sv.segment.URI
+					}
+					segmentData, err := fetch.ReadContext(sv.ctx, segmentURL, sv.segment.ByteRange)
+					if err != nil {
+						content.WriteString(fmt.Sprintf("\n[bad]Failed to read segment: %v[text]\n", err))
+					} else {
+						content.WriteString(formatProtection("Media Segment", mp4.ParseProtection(segmentData)))
+					}
+				}
+			}
+		}
+
+		if sv.ctx.Err() != nil || sv.updateCallback == nil {
+			return
+		}
+		sv.updateCallback(func() {
+			sv.updateContentWithDRM(content.String())
+			sv.showMessage("DRM information loaded")
+		})
+	}()
+}
+
+// formatPlaylistKeys describes the EXT-X-KEY tags that apply to the segment
+func (sv *SegmentView) formatPlaylistKeys(keys []*hls.Key) string {
+	var content strings.Builder
+	content.WriteString("[label]Playlist Keys:[text]\n")
+	if len(keys) == 0 {
+		content.WriteString("None\n")
+		return content.String()
+	}
+
+	for _, key := range keys {
+		info := drm.DescribeKey(key)
+		content.WriteString(fmt.Sprintf("\n[good]%s[text]\n", info.System.Name))
+		content.WriteString(fmt.Sprintf("  Method: %s\n", info.Scheme))
+		content.WriteString(fmt.Sprintf("  KEYFORMAT: %s\n", tview.Escape(info.KeyFormat)))
+		if info.KeyFormatVersions != "" {
+			content.WriteString(fmt.Sprintf("  KEYFORMATVERSIONS: %s\n", tview.Escape(info.KeyFormatVersions)))
+		}
+		if info.URI != "" && !strings.HasPrefix(info.URI, "data:") {
+			content.WriteString(fmt.Sprintf("  URI: %s\n", tview.Escape(info.URI)))
+		}
+		if info.PSSH != nil {
+			content.WriteString(fmt.Sprintf("  PSSH: v%d, system %s\n", info.PSSH.Version, info.PSSH.SystemID))
+		}
+		for _, kid := range info.KeyIDs {
+			content.WriteString(fmt.Sprintf("  Key ID: %s\n", kid))
+		}
+		for _, note := range info.Notes {
+			content.WriteString(fmt.Sprintf("  %s\n", tview.Escape(note)))
+		}
+	}
+	return content.String()
+}
+
+// formatProtection describes the protection boxes found in fMP4 data
+func formatProtection(title string, protection *mp4.Protection) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("\n[label]%s:[text]\n", title))
+	if !protection.Encrypted() {
+		content.WriteString("No protection boxes\n")
+	}
+
+	for _, track := range protection.Tracks {
+		content.WriteString(fmt.Sprintf("Scheme: %s %s (original format %s)\n", track.SchemeType, track.SchemeVersion, track.OriginalFormat))
+		content.WriteString(fmt.Sprintf("  Default KID: %s\n", track.DefaultKID))
+		content.WriteString(fmt.Sprintf("  Protected: %t, per-sample IV size: %d\n", track.DefaultIsProtected, track.DefaultPerSampleIVSize))
+		if track.CryptByteBlock > 0 || track.SkipByteBlock > 0 {
+			content.WriteString(fmt.Sprintf("  Pattern: %d:%d (crypt:skip)\n", track.CryptByteBlock, track.SkipByteBlock))
+		}
+		if track.DefaultConstantIV != "" {
+			content.WriteString(fmt.Sprintf("  Constant IV: 0x%s\n", track.DefaultConstantIV))
+		}
+	}
+	for _, pssh := range protection.PSSH {
+		content.WriteString(fmt.Sprintf("PSSH: [good]%s[text] (v%d, %d bytes of data)\n", pssh.System().Name, pssh.Version, len(pssh.Data)))
+		for _, kid := range pssh.KeyIDs {
+			content.WriteString(fmt.Sprintf("  Key ID: %s\n", kid))
+		}
+		for _, detail := range pssh.Details() {
+			content.WriteString(fmt.Sprintf("  %s\n", tview.Escape(detail)))
+		}
+	}
+	for _, senc := range protection.SampleEncryption {
+		content.WriteString(fmt.Sprintf("Sample encryption: %d samples, subsamples: %t\n", senc.SampleCount, senc.UseSubsamples))
+	}
+	for _, err := range protection.Errors {
+		content.WriteString(fmt.Sprintf("[bad]%s[text]\n", tview.Escape(err)))
+	}
+	return content.String()
+}
+
+// updateContentWithDRM updates the content with DRM information
+func (sv *SegmentView) updateContentWithDRM(info string) {
+	content := fmt.Sprintf(`[heading]Segment DRM[text]
+
+[label]Original URI:[text]
+%s
+
+[label]Resolved URL:[text]
+%s
+
+%s`,
+		sv.segment.URI,
+		sv.resolvedURL,
+		info)
+
+	sv.textView.SetText(content)
+	sv.textView.SetTitle(" Segment DRM ").SetBorder(true)
+}
+
+// keyPeriodBands maps manifest lines to a band color per encrypted key period; nil when nothing is encrypted
+func keyPeriodBands(periods []drm.Period) map[int]string {
+	encrypted := false
+	for _, period := range periods {
+		if period.Encrypted() {
+			encrypted = true
+		}
+	}
+	if !encrypted {
+		return nil
+	}
+
+	bands := make(map[int]string)
+	start := 1
+	for i, period := range periods {
+		if i == 0 {
+			// Cover the EXTINF and key tags in front of the first segment
+			start = period.FirstLine - 1
+			for _, key := range period.Keys {
+				if key.LineNumber > 0 && key.LineNumber < start {
+					start = key.LineNumber
+				}
+			}
+		}
+		if period.Encrypted() {
+			color := colors.BandTag(period.Index)
+			for line := start; line <= period.LastLine; line++ {
+				bands[line] = color
+			}
+		}
+		start = period.LastLine + 1
+	}
+	return bands
+}
+
+// keyPeriodFor returns the key period containing a media sequence number
+func keyPeriodFor(periods []drm.Period, sequence int) *drm.Period {
+	for i := range periods {
+		if sequence >= periods[i].FirstSequence && sequence <= periods[i].LastSequence {
+			return &periods[i]
+		}
+	}
+	return nil
+}
+
+// formatKeyPeriods describes key periods and rotation warnings for the summary
+func formatKeyPeriods(periods []drm.Period, warnings []drm.Warning) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("[heading]Key Periods (%d)[text]\n", len(periods)))
+	for _, period := range periods {
+		color := "muted"
+		if period.Encrypted() {
+			color = colors.BandTag(period.Index)
+		}
+		discontinuity := ""
+		if period.Discontinuity {
+			discontinuity = " [heading]after discontinuity[text]"
+		}
+		content.WriteString(fmt.Sprintf("[%s]▌[text] #%d  seq %d-%d  %d segments  %.1fs%s\n",
+			color, period.Index+1, period.FirstSequence, period.LastSequence, period.Segments, period.Duration, discontinuity))
+		content.WriteString(fmt.Sprintf("    %s\n", tview.Escape(period.Label())))
+	}
+
+	if len(warnings) > 0 {
+		content.WriteString(fmt.Sprintf("\n[bad]Rotation Warnings (%d)[text]\n", len(warnings)))
+		for _, warning := range warnings {
+			content.WriteString(fmt.Sprintf("  seq %d: %s\n", warning.Sequence, tview.Escape(warning.Message)))
+		}
+	}
+	return content.String()
+}
@@ -0,0 +1,128 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/verify"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// maxListedFrames bounds the frame table so long segments stay readable
+const maxListedFrames = 500
+
+// frameTypeColors colors frames by type
+var frameTypeColors = map[string]string{"I": "bad", "P": "good", "B": "accent"}
+
+// showFrames lists the video frames of the segment and checks it starts with a keyframe
+func (sv *SegmentView) showFrames() {
+	sv.showMessage("Reading video frames...")
+
+	go func() {
+		var content string
+		frames, err := sv.readFrames()
+		if sv.ctx.Err() != nil || sv.updateCallback == nil {
+			return
+		}
+		if err == nil {
+			content = formatFrames(frames)
+		} else {
+			content = fmt.Sprintf("[bad]Frame analysis failed:[text]\n%s\n", tview.Escape(err.Error()))
+		}
+
+		sv.updateCallback(func() {
+			sv.updateContentWithFrames(content)
+			sv.showMessage("Frame analysis complete")
+		})
+	}()
+}
+
+// readFrames reads the segment, and its init fragment for fMP4, and lists the frames of its video track
+func (sv *SegmentView) readFrames() (*verify.FrameAnalysis, error) {
+	data, err := sv.readSegment()
+	if err != nil {
+		return nil, err
+	}
+	var init []byte
+	if sv.segment.Map != nil && sv.segment.Map.URI != "" {
+		initURL := sv.playlist.ResolveURL(sv.segment.Map.URI)
+		if init, err = fetch.ReadContext(sv.ctx, initURL, sv.segment.Map.ByteRange); err != nil {
+			return nil, fmt.Errorf("failed to read init fragment %s: %v", initURL, err)
+		}
+	}
+	return verify.SegmentFrames(init, data)
+}
+
+// updateContentWithFrames shows the frame analysis
+func (sv *SegmentView) updateContentWithFrames(analysis string) {
+	content := fmt.Sprintf(`[heading]Frame Analysis[text]
+
+[label]Original URI:[text]
+%s
+
+[label]Resolved URL:[text]
+%s
+%s
+%s`,
+		sv.segment.URI,
+		sv.resolvedURL,
+		sv.formatDecryption(),
+		analysis)
+
+	sv.textView.SetText(content)
+	sv.textView.SetTitle(" Frame Analysis ").SetBorder(true)
+	sv.textView.ScrollToBeginning()
+}
+
+// formatFrames formats the keyframe check, the GOPs and the frame list
+func formatFrames(frames *verify.FrameAnalysis) string {
+	var content strings.Builder
+	counts := frames.Counts()
+
+	content.WriteString("\n[label]Video Track:[text]\n")
+	content.WriteString(fmt.Sprintf("Track: %s\n", tview.Escape(frames.Track)))
+	content.WriteString(fmt.Sprintf("Frames: %d (I %d, P %d, B %d", len(frames.Frames), counts["I"], counts["P"], counts["B"]))
+	if counts[""] > 0 {
+		content.WriteString(fmt.Sprintf(", unknown %d", counts[""]))
+	}
+	content.WriteString(")\n")
+	if duration := frames.FrameDuration(); duration > 0 {
+		content.WriteString(fmt.Sprintf("Frame rate: %.3f fps\n", 1/duration))
+	}
+	content.WriteString(fmt.Sprintf("Starts with keyframe: %s\n", formatCheck(frames.StartsWithKeyframe())))
+	if !frames.StartsWithKeyframe() {
+		content.WriteString("[bad]The segment can't be decoded on its own, which EXT-X-INDEPENDENT-SEGMENTS promises and ABR switching relies on[text]\n")
+	}
+
+	content.WriteString("\n[label]GOPs:[text]\n")
+	for _, gop := range frames.GOPs {
+		label := "GOP    "
+		if !gop.Keyframe {
+			label = "[bad]Leading[text]"
+		}
+		content.WriteString(fmt.Sprintf("%s at %.3fs: %d frames, %.3fs\n", label, gop.Start, gop.Frames, gop.Duration))
+	}
+
+	content.WriteString("\n[label]Frames (decode order):[text]\n")
+	content.WriteString(fmt.Sprintf("%-6s %-4s %-12s %-12s %10s\n", "#", "Type", "PTS", "DTS", "Size"))
+	for i, frame := range frames.Frames {
+		if i == maxListedFrames {
+			content.WriteString(fmt.Sprintf("[muted]... %d more frames[text]\n", len(frames.Frames)-i))
+			break
+		}
+		frameType := frame.Type
+		if frameType == "" {
+			frameType = "?"
+		}
+		if color, ok := frameTypeColors[frame.Type]; ok {
+			frameType = fmt.Sprintf("[%s]%s[text]", color, frame.Type)
+		}
+		marker := ""
+		if frame.Keyframe {
+			marker = " [heading]keyframe[text]"
+		}
+		content.WriteString(fmt.Sprintf("%-6d %s    %-12.6f %-12.6f %10d%s\n", i, frameType, frame.PTS, frame.DTS, frame.Size, marker))
+	}
+	return content.String()
+}
@@ -0,0 +1,165 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gotoKind identifies what a goto target refers to
+type gotoKind int
+
+const (
+	gotoSequence gotoKind = iota
+	gotoOffset
+	gotoWallClock
+	gotoLine
+)
+
+// gotoTarget is a parsed goto prompt entry
+type gotoTarget struct {
+	kind     gotoKind
+	sequence int
+	line     int
+	offset   time.Duration
+	clock    time.Time
+	clockRel bool // Wall clock given as time of day only
+}
+
+// parseGotoTarget parses a goto entry:
+//
+//	1042                      media sequence number
+//	L120                      manifest line number
+//	12:34, 1:02:03.5, 90s     time offset from the start of the playlist
+//	@12:34:56                 PROGRAM-DATE-TIME time of day (UTC)
+//	2024-01-01T12:34:56Z      PROGRAM-DATE-TIME wall clock
+func parseGotoTarget(input string) (gotoTarget, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return gotoTarget{}, fmt.Errorf("empty target")
+	}
+
+	if strings.HasPrefix(input, "L") || strings.HasPrefix(input, "l") {
+		line, err := strconv.Atoi(input[1:])
+		if err != nil || line < 1 {
+			return gotoTarget{}, fmt.Errorf("invalid line number %q", input)
+		}
+		return gotoTarget{kind: gotoLine, line: line}, nil
+	}
+
+	if strings.HasPrefix(input, "@") {
+		clock, err := time.Parse("15:04:05.999999999", input[1:])
+		if err != nil {
+			return gotoTarget{}, fmt.Errorf("invalid time of day %q (expected @HH:MM:SS)", input)
+		}
+		return gotoTarget{kind: gotoWallClock, clock: clock, clockRel: true}, nil
+	}
+
+	if strings.Contains(input, "T") && strings.Contains(input, "-") {
+		clock, err := time.Parse(time.RFC3339Nano, input)
+		if err != nil {
+			return gotoTarget{}, fmt.Errorf("invalid wall clock %q (expected RFC 3339)", input)
+		}
+		return gotoTarget{kind: gotoWallClock, clock: clock}, nil
+	}
+
+	if strings.Contains(input, ":") {
+		offset, err := parseClockOffset(input)
+		if err != nil {
+			return gotoTarget{}, err
+		}
+		return gotoTarget{kind: gotoOffset, offset: offset}, nil
+	}
+
+	if strings.HasSuffix(input, "s") || strings.HasSuffix(input, "m") || strings.HasSuffix(input, "h") {
+		offset, err := time.ParseDuration(input)
+		if err != nil {
+			return gotoTarget{}, fmt.Errorf("invalid offset %q", input)
+		}
+		return gotoTarget{kind: gotoOffset, offset: offset}, nil
+	}
+
+	sequence, err := strconv.Atoi(input)
+	if err != nil {
+		return gotoTarget{}, fmt.Errorf("unrecognized target %q", input)
+	}
+	return gotoTarget{kind: gotoSequence, sequence: sequence}, nil
+}
+
+// parseClockOffset parses [H:]MM:SS[.fff] into a duration
+func parseClockOffset(input string) (time.Duration, error) {
+	parts := strings.Split(input, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid offset %q", input)
+	}
+
+	var total float64
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid offset %q", input)
+		}
+		total = total*60 + value
+	}
+	return time.Duration(total * float64(time.Second)), nil
+}
+
+// resolveGotoTarget finds the manifest line a goto target refers to
+func resolveGotoTarget(manifest *hls.Manifest, target gotoTarget) (int, error) {
+	var segment *hls.Segment
+
+	switch target.kind {
+	case gotoLine:
+		return target.line, nil
+	case gotoSequence:
+		segment = manifest.SegmentBySequence(target.sequence)
+		if segment == nil {
+			return 0, fmt.Errorf("no segment with media sequence %d", target.sequence)
+		}
+	case gotoOffset:
+		segment = manifest.SegmentAtOffset(target.offset)
+		if segment == nil {
+			return 0, fmt.Errorf("offset %s is past the end of the playlist", target.offset)
+		}
+	case gotoWallClock:
+		if target.clockRel {
+			var err error
+			if segment, err = segmentAtTimeOfDay(manifest, target.clock); err != nil {
+				return 0, err
+			}
+			break
+		}
+		segment = manifest.SegmentAtTime(target.clock)
+		if segment == nil {
+			return 0, fmt.Errorf("no segment covers %s", target.clock.Format(time.RFC3339))
+		}
+	}
+
+	return segment.LineNumber, nil
+}
+
+// segmentAtTimeOfDay finds the segment covering a UTC time of day on any date the playlist covers,
+// so a live window crossing midnight finds times after it
+func segmentAtTimeOfDay(manifest *hls.Manifest, clock time.Time) (*hls.Segment, error) {
+	first := manifest.FirstProgramDateTime()
+	if first == nil {
+		return nil, fmt.Errorf("playlist has no EXT-X-PROGRAM-DATE-TIME")
+	}
+	var last time.Time
+	for _, segment := range manifest.Segments {
+		if segment.ProgramDateTime != nil {
+			last = segment.ProgramDateTime.Add(time.Duration(segment.Duration * float64(time.Second)))
+		}
+	}
+
+	start := first.UTC()
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC); !day.After(last); day = day.AddDate(0, 0, 1) {
+		at := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), time.UTC)
+		if segment := manifest.SegmentAtTime(at); segment != nil {
+			return segment, nil
+		}
+	}
+	return nil, fmt.Errorf("no segment covers %s UTC", clock.Format("15:04:05"))
+}
@@ -0,0 +1,114 @@
+package views
+
+import (
+	"github.com/soldiermoth/pantui/internal/hls"
+	"testing"
+	"time"
+)
+
+func TestParseGotoTarget(t *testing.T) {
+	tests := []struct {
+		input string
+		want  gotoTarget
+	}{
+		{"1042", gotoTarget{kind: gotoSequence, sequence: 1042}},
+		{" 7 ", gotoTarget{kind: gotoSequence, sequence: 7}},
+		{"L120", gotoTarget{kind: gotoLine, line: 120}},
+		{"l3", gotoTarget{kind: gotoLine, line: 3}},
+		{"12:34", gotoTarget{kind: gotoOffset, offset: 12*time.Minute + 34*time.Second}},
+		{"1:02:03.5", gotoTarget{kind: gotoOffset, offset: time.Hour + 2*time.Minute + 3500*time.Millisecond}},
+		{"90s", gotoTarget{kind: gotoOffset, offset: 90 * time.Second}},
+		{"2m", gotoTarget{kind: gotoOffset, offset: 2 * time.Minute}},
+		{"@12:34:56", gotoTarget{kind: gotoWallClock, clock: time.Date(0, 1, 1, 12, 34, 56, 0, time.UTC), clockRel: true}},
+		{"@00:01:00.25", gotoTarget{kind: gotoWallClock, clock: time.Date(0, 1, 1, 0, 1, 0, 250000000, time.UTC), clockRel: true}},
+		{"2024-01-01T12:34:56Z", gotoTarget{kind: gotoWallClock, clock: time.Date(2024, 1, 1, 12, 34, 56, 0, time.UTC)}},
+		{"2024-01-01T12:34:56.5+02:00", gotoTarget{kind: gotoWallClock, clock: time.Date(2024, 1, 1, 10, 34, 56, 500000000, time.UTC)}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseGotoTarget(tt.input)
+		if err != nil {
+			t.Errorf("parseGotoTarget(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got.kind != tt.want.kind || got.sequence != tt.want.sequence || got.line != tt.want.line ||
+			got.offset != tt.want.offset || !got.clock.Equal(tt.want.clock) || got.clockRel != tt.want.clockRel {
+			t.Errorf("parseGotoTarget(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseGotoTargetErrors(t *testing.T) {
+	for _, input := range []string{"", "L0", "Lx", "@25:00:00", "@12:34", "2024-01-01T12:34:56", "1:2:3:4", "-5:00", "1:xx", "90x", "tenth"} {
+		if target, err := parseGotoTarget(input); err == nil {
+			t.Errorf("parseGotoTarget(%q) = %+v, expected an error", input, target)
+		}
+	}
+}
+
+func TestParseClockOffset(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"0:30", 30 * time.Second},
+		{"12:34", 12*time.Minute + 34*time.Second},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"1:02:03.5", time.Hour + 2*time.Minute + 3500*time.Millisecond},
+		{"90:00", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, err := parseClockOffset(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("parseClockOffset(%q) = %s, %v, want %s", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveTimeOfDayAcrossMidnight(t *testing.T) {
+	manifest, err := hls.ParseContent(`#EXTM3U
+#EXT-X-TARGETDURATION:60
+#EXT-X-MEDIA-SEQUENCE:10
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T23:58:00Z
+#EXTINF:60.0,
+seg10.ts
+#EXTINF:60.0,
+seg11.ts
+#EXTINF:60.0,
+seg12.ts
+#EXTINF:60.0,
+seg13.ts
+`, "live.m3u8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  int // Sequence of the segment, -1 for none
+	}{
+		{"@23:58:30", 10},
+		{"@23:59:59", 11},
+		{"@00:01:00", 13},
+		{"@00:02:00", -1},
+		{"@12:00:00", -1},
+	}
+
+	for _, tt := range tests {
+		target, err := parseGotoTarget(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, err := resolveGotoTarget(manifest, target)
+		if tt.want < 0 {
+			if err == nil {
+				t.Errorf("Expected %s to be outside the playlist, got line %d", tt.input, line)
+			}
+			continue
+		}
+		if segment := manifest.SegmentBySequence(tt.want); err != nil || segment.LineNumber != line {
+			t.Errorf("Expected %s to go to segment %d, got line %d, %v", tt.input, tt.want, line, err)
+		}
+	}
+}
@@ -1,79 +1,101 @@
 package views
 
 import (
+	"fmt"
 	"github.com/soldiermoth/pantui/internal/hls"
 	"github.com/soldiermoth/pantui/internal/tui/components"
-
-	"github.com/gdamore/tcell/v2"
+	"strings"
 )
 
+// helpKeyWidth is the width of the key column of the help view
+const helpKeyWidth = 18
+
+// KeySection lists the keys of a view, or the global ones, in the help view
+type KeySection struct {
+	Title string
+	Keys  []components.KeyBinding
+}
+
 // HelpView displays help information
 type HelpView struct {
 	*BaseView
 	content string
 }
 
-// NewHelpView creates a new help view
-func NewHelpView() *HelpView {
+// NewHelpView creates a new help view listing the keys of the active keymap
+func NewHelpView(sections []KeySection) *HelpView {
 	hv := &HelpView{}
 	hv.BaseView = NewBaseView(nil, HelpViewType, nil)
-	hv.setupContent()
+	hv.setupContent(sections)
 	
 	return hv
 }
 
 // setupContent sets up the help content
-func (hv *HelpView) setupContent() {
-	hv.content = `pantui - HLS Manifest Explorer
+func (hv *HelpView) setupContent(sections []KeySection) {
+	hv.content = fmt.Sprintf(`pantui - HLS Manifest Explorer
 
 OVERVIEW:
 pantui is a terminal user interface for exploring HLS (HTTP Live Streaming) 
 manifests. Navigate through master manifests, media manifests, and segments 
 with an intuitive interface similar to k9s.
 
-GLOBAL KEYS:
-  F1                Show this help
-  Esc               Go back / Exit application
-  Ctrl+C            Exit application
-
-MASTER MANIFEST VIEW:
-  ↑↓                Navigate variant streams
-  Enter             Open selected variant manifest
-  d                 Show variant details
-  r                 Refresh manifest
-
-MEDIA MANIFEST VIEW:
-  ↑↓                Navigate segments
-  Enter             Open selected segment details
-  d                 Show segment details
-  s                 Show manifest summary
-  r                 Refresh manifest
-
-SEGMENT VIEW:
-  c                 Copy segment URL to clipboard
-  o                 Open segment in browser
-  h                 Show HTTP headers
-  i                 Inspect segment content
-
-NAVIGATION:
+%sNAVIGATION:
 - Use arrow keys to navigate through lists
 - Press Enter to drill down into sub-manifests or segments
 - Press Esc to go back to the previous view
 - Navigation stack preserves your path for easy backtracking
 
+SEARCH AND FILTER:
+- Plain text matches raw lines and parsed attribute values
+- FIELD OP VALUE terms compare parsed fields, joined with &&
+  Operators: = != > < >= <= ~ (regex) !~
+  e.g.  Key.Method != NONE     CODECS~hvc1 && BANDWIDTH>2000000
+
 FEATURES:
 - Colorized manifest display
 - Support for both master and media manifests
 - URL and file path input
 - Detailed segment information
+- Inspector pane with live variant and segment details
 - Encryption status display
+- AES-128 segments decrypted before inspection
+- Semantic and line diffs between refreshes
+- Multi-CDN comparison aligned by media sequence
 - Human-readable duration and bandwidth formatting
 
+QUERIES:
+  segments | where duration > 6.1 | select sequence, uri
+  variants | where resolution == "1920x1080"
+  Sources: segments, variants, renditions, tags
+  Stages:  where, select, sort [by] FIELD [desc], limit N, count
+
 USAGE EXAMPLES:
   pantui -u https://example.com/master.m3u8
   pantui -f ./local_manifest.m3u8
+  pantui query ./media.m3u8 'segments | where key.method != "NONE"'
+  pantui diff ./before.m3u8 https://example.com/media.m3u8
+  pantui compare https://origin.example.com/a.m3u8 https://cdn.example.com/a.m3u8
 
-For more information, visit: https://github.com/user/pantui`
+For more information, visit: https://github.com/user/pantui`, formatKeySections(sections))
+}
+
+// formatKeySections lists each section's keys with their descriptions, continuing long ones on indented lines
+func formatKeySections(sections []KeySection) string {
+	var b strings.Builder
+	indent := strings.Repeat(" ", helpKeyWidth+2)
+	for _, section := range sections {
+		b.WriteString(section.Title + ":\n")
+		for _, key := range section.Keys {
+			lines := strings.Split(key.Description, "\n")
+			fmt.Fprintf(&b, "  %-*s%s\n", helpKeyWidth, key.Key, lines[0])
+			for _, line := range lines[1:] {
+				b.WriteString(indent + line + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
 }
 
 // GetContent returns the help content
@@ -81,17 +103,6 @@ func (hv *HelpView) GetContent() string {
 	return hv.content
 }
 
-// HandleKey handles key events for the help view
-func (hv *HelpView) HandleKey(event *tcell.EventKey) *tcell.EventKey {
-	// Help view doesn't handle any special keys
-	return event
-}
-
-// GetKeyBindings returns empty key bindings for help view
-func (hv *HelpView) GetKeyBindings() []components.KeyBinding {
-	return []components.KeyBinding{}
-}
-
 // GetManifest returns nil for help view
 func (hv *HelpView) GetManifest() *hls.Manifest {
 	return nil
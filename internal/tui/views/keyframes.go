@@ -0,0 +1,103 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/verify"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// startKeyframeAlignment prompts for a media sequence and compares keyframes across the variants there
+func (mv *MasterView) startKeyframeAlignment() {
+	if mv.promptCallback == nil || mv.reportCallback == nil {
+		return
+	}
+	if len(mv.manifest.Variants) == 0 {
+		mv.setStatus("No variants to compare")
+		return
+	}
+
+	mv.promptCallback("Media sequence (empty for the first shared one): ", "", func(input string) {
+		sequence := -1
+		if input = strings.TrimSpace(input); input != "" {
+			value, err := strconv.Atoi(input)
+			if err != nil || value < 0 {
+				mv.setStatus(fmt.Sprintf("Invalid media sequence %q", input))
+				return
+			}
+			sequence = value
+		}
+
+		manifest := mv.manifest
+		mv.setStatus(fmt.Sprintf("Reading frames of %d variants...", len(manifest.Variants)))
+		go func() {
+			report, err := verify.KeyframeAlignment(mv.ctx, manifest, sequence)
+			if mv.ctx.Err() != nil || mv.updateCallback == nil {
+				return
+			}
+			mv.updateCallback(func() {
+				if err != nil {
+					mv.setStatus(fmt.Sprintf("Keyframe alignment failed: %v", err))
+					return
+				}
+				mv.setStatus("Keyframe alignment complete")
+				mv.reportCallback(fmt.Sprintf("Keyframe Alignment - sequence %d - %s", report.Sequence, manifest.URL), formatKeyframeReport(report))
+			})
+		}()
+	})
+}
+
+// formatKeyframeReport renders each variant's keyframes and GOPs with its findings against the reference
+func formatKeyframeReport(report *verify.KeyframeReport) string {
+	var content strings.Builder
+	aligned := 0
+	for _, variant := range report.Variants {
+		if len(variant.Findings) == 0 {
+			aligned++
+		}
+	}
+	content.WriteString(fmt.Sprintf("[label]Media sequence:[text] %d  [label]Variants:[text] %d  [good]Aligned:[text] %d  [bad]Misaligned or failed:[text] %d\n",
+		report.Sequence, len(report.Variants), aligned, len(report.Variants)-aligned))
+
+	for _, variant := range report.Variants {
+		status := "[good]OK[text]"
+		for _, finding := range variant.Findings {
+			status = fmt.Sprintf("[%s]%s[text]", findingColors[finding.Severity], strings.ToUpper(finding.Severity))
+			if finding.Severity == codecs.Error {
+				break
+			}
+		}
+		reference := ""
+		if variant == report.Reference {
+			reference = " [muted](reference)[text]"
+		}
+		content.WriteString(fmt.Sprintf("\n%s [heading]%s[text]%s\n", status, tview.Escape(variant.Variant.URI), reference))
+		if variant.Variant.Resolution != "" {
+			content.WriteString(fmt.Sprintf("  Resolution: %s, bandwidth %d\n", variant.Variant.Resolution, variant.Variant.Bandwidth))
+		}
+
+		if frames := variant.Frames; frames != nil {
+			content.WriteString(fmt.Sprintf("  %s: %d frames from %.3fs\n", tview.Escape(frames.Track), len(frames.Frames), frames.Start()))
+			var keyframes []string
+			for _, t := range frames.Keyframes() {
+				keyframes = append(keyframes, fmt.Sprintf("%.3f", t))
+			}
+			content.WriteString(fmt.Sprintf("  Keyframes: %s\n", strings.Join(keyframes, ", ")))
+			var gops []string
+			for _, gop := range frames.GOPs {
+				if gop.Keyframe {
+					gops = append(gops, fmt.Sprintf("%d (%.3fs)", gop.Frames, gop.Duration))
+				}
+			}
+			content.WriteString(fmt.Sprintf("  GOPs: %s\n", strings.Join(gops, ", ")))
+		}
+
+		for _, finding := range variant.Findings {
+			content.WriteString(fmt.Sprintf("  [%s]%s:[text] %s\n", findingColors[finding.Severity], finding.Severity, tview.Escape(finding.Message)))
+		}
+	}
+	return content.String()
+}
@@ -13,6 +13,9 @@ import (
 type ManifestRenderer struct {
 	manifest      *hls.Manifest
 	highlightLine int  // Line number to highlight (0 = no highlight)
+	markedLines   map[int]bool // Lines marked as search matches
+	hiddenLines   map[int]bool // Lines hidden by a filter
+	bands         map[int]string // Band color per line, e.g. for key periods
 }
 
 // NewManifestRenderer creates a new manifest renderer
@@ -28,16 +31,45 @@ func (mr *ManifestRenderer) SetHighlightLine(lineNum int) {
 	mr.highlightLine = lineNum
 }
 
+// SetMarkedLines sets the lines marked as search matches
+func (mr *ManifestRenderer) SetMarkedLines(lines map[int]bool) {
+	mr.markedLines = lines
+}
+
+// SetHiddenLines sets the lines omitted from rendering
+func (mr *ManifestRenderer) SetHiddenLines(lines map[int]bool) {
+	mr.hiddenLines = lines
+}
+
+// SetBands sets a colored band drawn left of the selection gutter; nil removes the band column
+func (mr *ManifestRenderer) SetBands(bands map[int]string) {
+	mr.bands = bands
+}
+
+// RowForLine returns the rendered row of a manifest line, accounting for hidden lines
+func (mr *ManifestRenderer) RowForLine(lineNum int) int {
+	row := lineNum - 1
+	for line := range mr.hiddenLines {
+		if line < lineNum {
+			row--
+		}
+	}
+	return row
+}
+
 // RenderColorized returns the manifest content with tview color tags
 func (mr *ManifestRenderer) RenderColorized() string {
 	if mr.manifest == nil || mr.manifest.Content == "" {
-		return "[red]No manifest content available[white]"
+		return "[bad]No manifest content available[text]"
 	}
 
 	lines := strings.Split(mr.manifest.Content, "\n")
 	var colorizedLines []string
 	
 	for i, line := range lines {
+		if mr.hiddenLines[i+1] {
+			continue
+		}
 		colorizedLine := mr.colorizeLine(line, i+1)
 		colorizedLines = append(colorizedLines, colorizedLine)
 	}
@@ -128,29 +160,34 @@ func (mr *ManifestRenderer) extractAttributeValue(line, attributeName string) st
 	return line[valueStart : valueStart+valueEnd]
 }
 
-// colorizeLine applies syntax highlighting to a single line
-func (mr *ManifestRenderer) colorizeLine(line string, lineNum int) string {
+// ColorizeLine applies syntax highlighting to a single line without selection markers
+func (mr *ManifestRenderer) ColorizeLine(line string) string {
 	line = strings.TrimSpace(line)
 	
-	if line == "" {
-		return ""
-	}
-	
-	var colorizedLine string
-	
 	// Comment lines
 	if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#EXT") {
-		colorizedLine = mr.colorText(line, colors.CommentColor)
+		return mr.colorText(line, colors.Current.Comment)
 	} else if strings.HasPrefix(line, "#EXT") {
 		// EXT tags
-		colorizedLine = mr.colorizeExtTag(line)
-	} else if !strings.HasPrefix(line, "#") {
+		return mr.colorizeExtTag(line)
+	} else if line != "" {
 		// URI lines (not starting with #)
-		colorizedLine = mr.colorText(line, colors.URIColor)
-	} else {
-		colorizedLine = line
+		return mr.colorText(line, colors.Current.URI)
+	}
+	return line
+}
+
+// colorizeLine applies syntax highlighting to a single line
+func (mr *ManifestRenderer) colorizeLine(line string, lineNum int) string {
+	line = strings.TrimSpace(line)
+	
+	if line == "" {
+		return ""
 	}
 	
+	colorizedLine := mr.ColorizeLine(line)
+	band := mr.band(lineNum)
+	
 	// Add highlighting if this is the selected line
 	if lineNum == mr.highlightLine {
 		// Check if this line contains a URI within a tag
@@ -158,15 +195,31 @@ func (mr *ManifestRenderer) colorizeLine(line string, lineNum int) string {
 			uri := mr.extractURIFromTag(line)
 			if uri != "" {
 				// Highlight the entire line but emphasize the URI
-				return fmt.Sprintf("[black:white]> %s[-:-]", mr.highlightURIInTag(line, uri))
+				return fmt.Sprintf("%s%s> %s[-:-:-]", band, colors.Current.SelectedTag(), mr.highlightURIInTag(line, uri))
 			}
 		}
 		// Add background highlight and selection indicator for regular lines
-		return fmt.Sprintf("[black:white]> %s[-:-]", colorizedLine)
+		return fmt.Sprintf("%s%s> %s[-:-:-]", band, colors.Current.SelectedTag(), colorizedLine)
+	}
+	
+	// Mark search matches in the selection gutter
+	if mr.markedLines[lineNum] {
+		return fmt.Sprintf("%s[heading]*[text] %s", band, colorizedLine)
 	}
 	
 	// Add space for alignment with highlighted lines
-	return fmt.Sprintf("  %s", colorizedLine)
+	return fmt.Sprintf("%s  %s", band, colorizedLine)
+}
+
+// band returns the band column for a line, empty when no bands are set
+func (mr *ManifestRenderer) band(lineNum int) string {
+	if mr.bands == nil {
+		return ""
+	}
+	if color, ok := mr.bands[lineNum]; ok {
+		return fmt.Sprintf("[%s]▌[-]", color)
+	}
+	return " "
 }
 
 // highlightURIInTag highlights the URI portion within an HLS tag
@@ -201,7 +254,7 @@ func (mr *ManifestRenderer) highlightURIInTag(line, uri string) string {
 	
 	// Colorize each part and emphasize the URI
 	colorizedBefore := mr.colorizeExtTag(beforeURI + `"`)
-	colorizedURI := fmt.Sprintf("[red:white]%s[-:-]", uri) // Red text on white background for emphasis
+	colorizedURI := fmt.Sprintf("[%s::u]%s[text::U]", colors.Tag(colors.Current.Bad), uri) // Underlined in the error color for emphasis
 	colorizedAfter := mr.colorizeExtTag(`"` + afterURI)
 	
 	return colorizedBefore + colorizedURI + colorizedAfter
@@ -213,13 +266,13 @@ func (mr *ManifestRenderer) colorizeExtTag(line string) string {
 	colonIdx := strings.Index(line, ":")
 	if colonIdx == -1 {
 		// Tag without value
-		return mr.colorText(line, colors.TagColor)
+		return mr.colorText(line, colors.Current.Tag)
 	}
 	
 	tagName := line[:colonIdx]
 	tagValue := line[colonIdx+1:]
 	
-	coloredTag := mr.colorText(tagName+":", colors.TagColor)
+	coloredTag := mr.colorText(tagName+":", colors.Current.Tag)
 	coloredValue := mr.colorizeTagValue(tagName, tagValue)
 	
 	return coloredTag + coloredValue
@@ -233,13 +286,13 @@ func (mr *ManifestRenderer) colorizeTagValue(tagName, value string) string {
 	case "#EXT-X-STREAM-INF":
 		return mr.colorizeStreamInf(value)
 	case "#EXT-X-BYTERANGE":
-		return mr.colorText(value, colors.DurationColor)
+		return mr.colorText(value, colors.Current.Duration)
 	case "#EXT-X-KEY":
 		return mr.colorizeKey(value)
 	case "#EXT-X-TARGETDURATION", "#EXT-X-MEDIA-SEQUENCE", "#EXT-X-VERSION":
-		return mr.colorText(value, colors.SequenceColor)
+		return mr.colorText(value, colors.Current.Sequence)
 	default:
-		return mr.colorText(value, colors.TagValueColor)
+		return mr.colorText(value, colors.Current.TagValue)
 	}
 }
 
@@ -248,13 +301,13 @@ func (mr *ManifestRenderer) colorizeExtInf(value string) string {
 	// EXTINF format: duration,title
 	commaIdx := strings.Index(value, ",")
 	if commaIdx == -1 {
-		return mr.colorText(value, colors.DurationColor)
+		return mr.colorText(value, colors.Current.Duration)
 	}
 	
 	duration := value[:commaIdx]
 	title := value[commaIdx:]
 	
-	return mr.colorText(duration, colors.DurationColor) + mr.colorText(title, colors.TagValueColor)
+	return mr.colorText(duration, colors.Current.Duration) + mr.colorText(title, colors.Current.TagValue)
 }
 
 // colorizeStreamInf colorizes stream attributes
@@ -266,7 +319,7 @@ func (mr *ManifestRenderer) colorizeStreamInf(value string) string {
 	for _, part := range parts {
 		equalIdx := strings.Index(part, "=")
 		if equalIdx == -1 {
-			colorizedParts = append(colorizedParts, mr.colorText(part, colors.TagValueColor))
+			colorizedParts = append(colorizedParts, mr.colorText(part, colors.Current.TagValue))
 			continue
 		}
 		
@@ -276,20 +329,20 @@ func (mr *ManifestRenderer) colorizeStreamInf(value string) string {
 		var coloredValue string
 		switch key {
 		case "BANDWIDTH", "AVERAGE-BANDWIDTH":
-			coloredValue = mr.colorText(val, colors.BandwidthColor)
+			coloredValue = mr.colorText(val, colors.Current.Bandwidth)
 		case "RESOLUTION":
-			coloredValue = mr.colorText(val, colors.ResolutionColor)
+			coloredValue = mr.colorText(val, colors.Current.Resolution)
 		case "CODECS":
-			coloredValue = mr.colorText(val, colors.CodecsColor)
+			coloredValue = mr.colorText(val, colors.Current.Codecs)
 		default:
-			coloredValue = mr.colorText(val, colors.TagValueColor)
+			coloredValue = mr.colorText(val, colors.Current.TagValue)
 		}
 		
 		colorizedParts = append(colorizedParts, 
-			mr.colorText(key+"=", colors.TagValueColor)+coloredValue)
+			mr.colorText(key+"=", colors.Current.TagValue)+coloredValue)
 	}
 	
-	return strings.Join(colorizedParts, mr.colorText(",", colors.TagValueColor))
+	return strings.Join(colorizedParts, mr.colorText(",", colors.Current.TagValue))
 }
 
 // colorizeKey colorizes encryption key attributes
@@ -300,7 +353,7 @@ func (mr *ManifestRenderer) colorizeKey(value string) string {
 	for _, part := range parts {
 		equalIdx := strings.Index(part, "=")
 		if equalIdx == -1 {
-			colorizedParts = append(colorizedParts, mr.colorText(part, colors.TagValueColor))
+			colorizedParts = append(colorizedParts, mr.colorText(part, colors.Current.TagValue))
 			continue
 		}
 		
@@ -310,19 +363,19 @@ func (mr *ManifestRenderer) colorizeKey(value string) string {
 		var coloredValue string
 		if key == "METHOD" {
 			if val == "NONE" || val == `"NONE"` {
-				coloredValue = mr.colorText(val, colors.UnencryptedColor)
+				coloredValue = mr.colorText(val, colors.Current.Unencrypted)
 			} else {
-				coloredValue = mr.colorText(val, colors.EncryptedColor)
+				coloredValue = mr.colorText(val, colors.Current.Encrypted)
 			}
 		} else {
-			coloredValue = mr.colorText(val, colors.TagValueColor)
+			coloredValue = mr.colorText(val, colors.Current.TagValue)
 		}
 		
 		colorizedParts = append(colorizedParts, 
-			mr.colorText(key+"=", colors.TagValueColor)+coloredValue)
+			mr.colorText(key+"=", colors.Current.TagValue)+coloredValue)
 	}
 	
-	return strings.Join(colorizedParts, mr.colorText(",", colors.TagValueColor))
+	return strings.Join(colorizedParts, mr.colorText(",", colors.Current.TagValue))
 }
 
 // splitAttributes splits attribute string handling quoted values
@@ -359,32 +412,5 @@ func (mr *ManifestRenderer) splitAttributes(attrStr string) []string {
 
 // colorText applies tview color tags to text
 func (mr *ManifestRenderer) colorText(text string, color tcell.Color) string {
-	colorName := mr.colorToName(color)
-	return fmt.Sprintf("[%s]%s[white]", colorName, text)
-}
-
-// colorToName converts tcell color to tview color name
-func (mr *ManifestRenderer) colorToName(color tcell.Color) string {
-	switch color {
-	case tcell.ColorYellow:
-		return "yellow"
-	case tcell.ColorGreen:
-		return "green"
-	case tcell.ColorBlue:
-		return "blue"
-	case tcell.ColorPurple:
-		return "purple"
-	case tcell.ColorRed:
-		return "red"
-	case tcell.ColorLightCyan:
-		return "aqua"
-	case tcell.ColorAqua:
-		return "aqua"
-	case tcell.ColorDarkGray:
-		return "darkgray"
-	case tcell.ColorWhite:
-		return "white"
-	default:
-		return "white"
-	}
+	return fmt.Sprintf("[%s]%s[text]", colors.Tag(color), text)
 }
\ No newline at end of file
@@ -1,12 +1,17 @@
 package views
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"github.com/soldiermoth/pantui/internal/diff"
+	"github.com/soldiermoth/pantui/internal/drm"
 	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/query"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -18,7 +23,12 @@ type MasterView struct {
 	parser        *hls.Parser
 	renderer      *ManifestRenderer
 	navigableItems map[int]string
+	search        *searchState
+	lastQuery     string
+	previous      *hls.Manifest // Manifest before the last refresh that changed it
 	currentLine   int
+	ctx           context.Context // Cancelled when the view is left, stopping running checks
+	cancel        context.CancelFunc
 }
 
 // NewMasterView creates a new master manifest view
@@ -37,13 +47,14 @@ func NewMasterView(manifest *hls.Manifest, parser *hls.Parser) *MasterView {
 		parser:        parser,
 		renderer:      renderer,
 		navigableItems: renderer.GetNavigableItems(),
+		search:        newSearchState(manifest, renderer.GetNavigableItems()),
 		currentLine:   1,
 	}
 
+	mv.ctx, mv.cancel = context.WithCancel(context.Background())
 	mv.BaseView = NewBaseView(textView, MasterViewType, manifest)
 	mv.setupContent()
-	mv.setupKeyBindings()
-	mv.setupInputCapture()
+	mv.setupActions()
 
 	return mv
 }
@@ -51,14 +62,14 @@ func NewMasterView(manifest *hls.Manifest, parser *hls.Parser) *MasterView {
 // setupContent sets up the manifest content with syntax highlighting
 func (mv *MasterView) setupContent() {
 	if mv.manifest == nil {
-		mv.textView.SetText("[red]No manifest data available[white]")
+		mv.textView.SetText("[bad]No manifest data available[text]")
 		mv.textView.SetTitle(" Master Manifest - Error ").SetBorder(true)
 		return
 	}
 
-	// Find the first navigable line and set it as current
+	// Find the first visible navigable line and set it as current
 	for lineNum := 1; lineNum <= len(strings.Split(mv.manifest.Content, "\n")); lineNum++ {
-		if _, exists := mv.navigableItems[lineNum]; exists {
+		if _, exists := mv.navigableItems[lineNum]; exists && !mv.search.IsHidden(lineNum) {
 			mv.currentLine = lineNum
 			break
 		}
@@ -70,40 +81,47 @@ func (mv *MasterView) setupContent() {
 	// Render the colorized manifest content
 	colorizedContent := mv.renderer.RenderColorized()
 	mv.textView.SetText(colorizedContent)
+	mv.emitSelection()
 	
 	// Set title with manifest info
 	variantCount := len(mv.manifest.Variants)
 	title := fmt.Sprintf(" Master Manifest - %d variants", variantCount)
+	if systems := mv.sessionKeySystems(); len(systems) > 0 {
+		title += fmt.Sprintf(" (session keys: %s)", strings.Join(systems, ", "))
+	}
+	if mv.search.filterQuery != "" {
+		title += fmt.Sprintf(" (filter: %s)", tview.Escape(mv.search.filterQuery))
+	}
 	mv.textView.SetTitle(title + " ").SetBorder(true)
 }
 
-// setupInputCapture sets up input capture for navigation
-func (mv *MasterView) setupInputCapture() {
-	mv.textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEnter:
-			// Find URI on current line and navigate to it
-			if uri, exists := mv.navigableItems[mv.currentLine]; exists {
-				if mv.navigationCallback != nil {
-					mv.navigationCallback(uri)
-				}
-			}
-			return nil
-		case tcell.KeyUp:
-			mv.navigateUp()
-			return nil
-		case tcell.KeyDown:
-			mv.navigateDown()
-			return nil
+// sessionKeySystems lists the key systems announced by EXT-X-SESSION-KEY
+func (mv *MasterView) sessionKeySystems() []string {
+	var systems []string
+	seen := make(map[string]bool)
+	for _, key := range mv.manifest.SessionKeys {
+		system, _ := drm.ByKeyFormat(key.KeyFormat)
+		if !seen[system.Name] {
+			seen[system.Name] = true
+			systems = append(systems, system.Name)
 		}
-		return event // Let other keys pass through
-	})
+	}
+	return systems
+}
+
+// openSelection navigates to the variant or rendition on the current line
+func (mv *MasterView) openSelection() {
+	if uri, exists := mv.navigableItems[mv.currentLine]; exists {
+		if mv.navigationCallback != nil {
+			mv.navigationCallback(uri)
+		}
+	}
 }
 
 // navigateUp moves to the previous navigable line
 func (mv *MasterView) navigateUp() {
 	for line := mv.currentLine - 1; line >= 1; line-- {
-		if _, exists := mv.navigableItems[line]; exists {
+		if _, exists := mv.navigableItems[line]; exists && !mv.search.IsHidden(line) {
 			mv.currentLine = line
 			mv.highlightCurrentLine()
 			return
@@ -115,7 +133,7 @@ func (mv *MasterView) navigateUp() {
 func (mv *MasterView) navigateDown() {
 	maxLine := len(strings.Split(mv.manifest.Content, "\n"))
 	for line := mv.currentLine + 1; line <= maxLine; line++ {
-		if _, exists := mv.navigableItems[line]; exists {
+		if _, exists := mv.navigableItems[line]; exists && !mv.search.IsHidden(line) {
 			mv.currentLine = line
 			mv.highlightCurrentLine()
 			return
@@ -123,6 +141,25 @@ func (mv *MasterView) navigateDown() {
 	}
 }
 
+// navigateRows moves the cursor up to a number of rows down, or up when rows is negative
+func (mv *MasterView) navigateRows(rows int) {
+	if line := lineRows(mv.navigableItems, mv.search.IsHidden, mv.currentLine, mv.lineCount(), rows); line != mv.currentLine {
+		mv.currentLine = line
+		mv.highlightCurrentLine()
+	}
+}
+
+// lineCount is the number of manifest lines
+func (mv *MasterView) lineCount() int {
+	return len(strings.Split(mv.manifest.Content, "\n"))
+}
+
+// pageRows is the number of rows the page keys move, the height of the view
+func (mv *MasterView) pageRows() int {
+	_, _, _, height := mv.textView.GetInnerRect()
+	return height - 1
+}
+
 // highlightCurrentLine highlights the current line and refreshes the view
 func (mv *MasterView) highlightCurrentLine() {
 	// Update the renderer with the new highlight line
@@ -134,6 +171,7 @@ func (mv *MasterView) highlightCurrentLine() {
 	
 	// Only scroll if the current line is not visible
 	mv.scrollToLineIfNeeded(mv.currentLine)
+	mv.emitSelection()
 }
 
 // scrollToLineIfNeeded scrolls to the line only if it's not currently visible
@@ -147,8 +185,8 @@ func (mv *MasterView) scrollToLineIfNeeded(lineNum int) {
 	currentRow, _ := mv.textView.GetScrollOffset()
 	
 	// Calculate if the line is visible in the current viewport
-	// Account for 0-based indexing (lineNum is 1-based)
-	targetRow := lineNum - 1
+	// Rows are 0-based and skip lines hidden by a filter
+	targetRow := mv.renderer.RowForLine(lineNum)
 	bottomVisibleRow := currentRow + height - 1
 	
 	// Check if line is above the viewport
@@ -171,13 +209,26 @@ func (mv *MasterView) scrollToLineIfNeeded(lineNum int) {
 	// Line is already visible, no scrolling needed
 }
 
-// setupKeyBindings sets up key bindings for the master view
-func (mv *MasterView) setupKeyBindings() {
-	mv.AddKeyBinding("Enter", "Open Variant")
-	mv.AddKeyBinding("↑↓", "Navigate")
-	mv.AddKeyBinding("p", "Play")
-	mv.AddKeyBinding("d", "Details")
-	mv.AddKeyBinding("r", "Refresh")
+// setupActions sets the handlers of the master view's actions
+func (mv *MasterView) setupActions() {
+	mv.Handle("open", mv.openSelection)
+	mv.Handle("up", mv.navigateUp)
+	mv.Handle("down", mv.navigateDown)
+	mv.Handle("page-up", func() { mv.navigateRows(-mv.pageRows()) })
+	mv.Handle("page-down", func() { mv.navigateRows(mv.pageRows()) })
+	mv.Handle("top", func() { mv.navigateRows(-mv.lineCount()) })
+	mv.Handle("bottom", func() { mv.navigateRows(mv.lineCount()) })
+	mv.Handle("play", mv.playManifest)
+	mv.Handle("refresh", mv.refresh)
+	mv.Handle("search", mv.startSearch)
+	mv.Handle("next-match", func() { mv.jumpToMatch(true) })
+	mv.Handle("previous-match", func() { mv.jumpToMatch(false) })
+	mv.Handle("filter", mv.startFilter)
+	mv.Handle("query", mv.startQuery)
+	mv.Handle("diff", mv.showDiff)
+	mv.Handle("verify", mv.verifyCodecs)
+	mv.Handle("keyframes", mv.startKeyframeAlignment)
+	mv.Handle("alignment", mv.checkAlignment)
 }
 
 // formatBandwidth formats bandwidth in human-readable format
@@ -190,24 +241,6 @@ func (mv *MasterView) formatBandwidth(bandwidth int) string {
 	return fmt.Sprintf("%d bps", bandwidth)
 }
 
-// HandleKey handles key events for the master view
-func (mv *MasterView) HandleKey(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Rune() {
-	case 'p':
-		mv.playManifest()
-		return nil
-	case 'd':
-		mv.showDetails()
-		return nil
-	case 'r':
-		mv.refresh()
-		return nil
-	}
-
-	// Let the text view handle other keys (Enter is handled in input capture)
-	return event
-}
-
 // playManifest plays the current manifest using ffplay
 func (mv *MasterView) playManifest() {
 	if mv.manifest == nil {
@@ -224,7 +257,7 @@ func (mv *MasterView) playManifest() {
 
 	// Launch ffplay in a goroutine so it doesn't block the UI
 	go func() {
-		cmd := exec.Command("ffplay", "-hide_banner", mv.manifest.URL)
+		cmd := ffplayCommand(mv.manifest.URL)
 		
 		// Start the process
 		err := cmd.Start()
@@ -258,55 +291,279 @@ func (mv *MasterView) playManifest() {
 	}()
 }
 
-// showDetails shows detailed information about the selected variant
-func (mv *MasterView) showDetails() {
-	// Find the variant for the current line
-	uri, exists := mv.navigableItems[mv.currentLine]
-	if !exists {
+// SetSelectionCallback sets the selection callback and reports the current selection
+func (mv *MasterView) SetSelectionCallback(callback SelectionCallback) {
+	mv.BaseView.SetSelectionCallback(callback)
+	mv.emitSelection()
+}
+
+// emitSelection reports the details of the selected line to the inspector
+func (mv *MasterView) emitSelection() {
+	if mv.selectionCallback == nil {
 		return
 	}
-	
-	// Find the variant by URI
-	var variant *hls.Variant
-	for _, v := range mv.manifest.Variants {
-		if v.URI == uri {
-			variant = &v
-			break
+	title, details := mv.selectionDetails()
+	mv.selectionCallback(title, details)
+}
+
+// selectionDetails formats the parsed fields of the item on the current line
+func (mv *MasterView) selectionDetails() (string, string) {
+	if mv.manifest == nil {
+		return "Inspector", "[muted]Nothing selected[text]"
+	}
+
+	for i := range mv.manifest.Variants {
+		if mv.manifest.Variants[i].LineNumber == mv.currentLine {
+			return "Variant", mv.formatVariantDetails(&mv.manifest.Variants[i])
+		}
+	}
+
+	for i := range mv.manifest.Renditions {
+		if mv.manifest.Renditions[i].LineNumber == mv.currentLine {
+			return "Rendition", mv.formatRenditionDetails(&mv.manifest.Renditions[i])
+		}
+	}
+
+	// Fall back to the raw tag attributes, e.g. for I-frame streams
+	for _, tag := range mv.manifest.Tags {
+		if tag.LineNumber == mv.currentLine {
+			var details strings.Builder
+			details.WriteString(fmt.Sprintf("[label]Tag:[text] %s\n", strings.TrimPrefix(tag.Name, "#")))
+			details.WriteString(mv.formatAttributes(tag.Attributes, nil))
+			return "Tag", details.String()
 		}
 	}
-	if variant == nil {
+
+	return "Inspector", "[muted]Nothing selected[text]"
+}
+
+// formatVariantDetails formats a variant stream and its rendition groups
+func (mv *MasterView) formatVariantDetails(variant *hls.Variant) string {
+	var details strings.Builder
+
+	details.WriteString(fmt.Sprintf("[label]URI:[text] %s\n", variant.URI))
+	details.WriteString(fmt.Sprintf("[label]Bandwidth:[text] %s\n", mv.formatBandwidth(variant.Bandwidth)))
+	if avg, ok := variant.Attributes["AVERAGE-BANDWIDTH"]; ok {
+		if bw, err := strconv.Atoi(avg); err == nil {
+			details.WriteString(fmt.Sprintf("[label]Average Bandwidth:[text] %s\n", mv.formatBandwidth(bw)))
+		}
+	}
+	if variant.Resolution != "" {
+		details.WriteString(fmt.Sprintf("[label]Resolution:[text] %s\n", variant.Resolution))
+	}
+	if variant.Codecs != "" {
+		details.WriteString(fmt.Sprintf("[label]Codecs:[text] %s\n", variant.Codecs))
+	}
+	if frameRate, ok := variant.Attributes["FRAME-RATE"]; ok {
+		details.WriteString(fmt.Sprintf("[label]Frame Rate:[text] %s\n", frameRate))
+	}
+
+	// Rendition groups referenced by this variant
+	for _, groupType := range []string{"AUDIO", "VIDEO", "SUBTITLES", "CLOSED-CAPTIONS"} {
+		groupID, ok := variant.Attributes[groupType]
+		if !ok {
+			continue
+		}
+		details.WriteString(fmt.Sprintf("\n[label]%s Group:[text] %s\n", groupType, groupID))
+		for _, rendition := range mv.manifest.Renditions {
+			if rendition.GroupID != groupID || rendition.Type != groupType {
+				continue
+			}
+			line := fmt.Sprintf("  • %s", rendition.Name)
+			if rendition.Language != "" {
+				line += fmt.Sprintf(" (%s)", rendition.Language)
+			}
+			if rendition.Default {
+				line += " [good]default[text]"
+			}
+			details.WriteString(line + "\n")
+		}
+	}
+
+	skip := map[string]bool{
+		"BANDWIDTH": true, "AVERAGE-BANDWIDTH": true, "RESOLUTION": true, "CODECS": true, "FRAME-RATE": true,
+		"AUDIO": true, "VIDEO": true, "SUBTITLES": true, "CLOSED-CAPTIONS": true,
+	}
+	if other := mv.formatAttributes(variant.Attributes, skip); other != "" {
+		details.WriteString("\n[label]Other Attributes:[text]\n" + other)
+	}
+
+	return details.String()
+}
+
+// formatRenditionDetails formats an EXT-X-MEDIA rendition
+func (mv *MasterView) formatRenditionDetails(rendition *hls.Rendition) string {
+	var details strings.Builder
+
+	details.WriteString(fmt.Sprintf("[label]Type:[text] %s\n", rendition.Type))
+	details.WriteString(fmt.Sprintf("[label]Group ID:[text] %s\n", rendition.GroupID))
+	details.WriteString(fmt.Sprintf("[label]Name:[text] %s\n", rendition.Name))
+	if rendition.Language != "" {
+		details.WriteString(fmt.Sprintf("[label]Language:[text] %s\n", rendition.Language))
+	}
+	if rendition.URI != "" {
+		details.WriteString(fmt.Sprintf("[label]URI:[text] %s\n", rendition.URI))
+	}
+	details.WriteString(fmt.Sprintf("[label]Default:[text] %t\n", rendition.Default))
+	details.WriteString(fmt.Sprintf("[label]Autoselect:[text] %t\n", rendition.Autoselect))
+
+	// Variants using this rendition group
+	var users []string
+	for _, variant := range mv.manifest.Variants {
+		if variant.Attributes[rendition.Type] == rendition.GroupID {
+			users = append(users, variant.URI)
+		}
+	}
+	if len(users) > 0 {
+		details.WriteString("\n[label]Used By:[text]\n")
+		for _, uri := range users {
+			details.WriteString(fmt.Sprintf("  • %s\n", uri))
+		}
+	}
+
+	skip := map[string]bool{
+		"TYPE": true, "GROUP-ID": true, "NAME": true, "LANGUAGE": true, "URI": true, "DEFAULT": true, "AUTOSELECT": true,
+	}
+	if other := mv.formatAttributes(rendition.Attributes, skip); other != "" {
+		details.WriteString("\n[label]Other Attributes:[text]\n" + other)
+	}
+
+	return details.String()
+}
+
+// formatAttributes formats attributes in a stable order, omitting skipped keys
+func (mv *MasterView) formatAttributes(attributes map[string]string, skip map[string]bool) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		if !skip[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var details strings.Builder
+	for _, key := range keys {
+		details.WriteString(fmt.Sprintf("%s: %s\n", key, attributes[key]))
+	}
+	return details.String()
+}
+
+// startSearch prompts for a search query and jumps to the first match
+func (mv *MasterView) startSearch() {
+	if mv.promptCallback == nil {
 		return
 	}
-	
-	details := fmt.Sprintf(`Variant Stream Details:
-
-URI: %s
-Bandwidth: %s
-Resolution: %s
-Codecs: %s
-
-Additional Attributes:`, 
-		variant.URI,
-		mv.formatBandwidth(variant.Bandwidth),
-		variant.Resolution,
-		variant.Codecs)
-
-	for key, value := range variant.Attributes {
-		if key != "BANDWIDTH" && key != "RESOLUTION" && key != "CODECS" {
-			details += fmt.Sprintf("\n%s: %s", key, value)
+
+	mv.promptCallback("Search: ", mv.search.query, func(query string) {
+		if err := mv.search.Search(query); err != nil {
+			mv.setStatus(fmt.Sprintf("Invalid search: %v", err))
+			return
+		}
+		mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+		if line, ok := mv.search.Next(mv.currentLine - 1); ok {
+			mv.currentLine = line
 		}
+		mv.highlightCurrentLine()
+		mv.setStatus(mv.search.Status())
+	})
+}
+
+// jumpToMatch moves to the next or previous search match
+func (mv *MasterView) jumpToMatch(forward bool) {
+	if mv.search.query == "" {
+		return
 	}
 
-	// Create a modal to show details
-	modal := tview.NewModal().
-		SetText(details).
-		AddButtons([]string{"Close"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			// Close modal - this would need to be handled by the parent app
-		})
+	line, ok := mv.search.Previous(mv.currentLine)
+	if forward {
+		line, ok = mv.search.Next(mv.currentLine)
+	}
+	if ok {
+		mv.currentLine = line
+		mv.highlightCurrentLine()
+	}
+	mv.setStatus(mv.search.Status())
+}
 
-	// This would need to be handled by the parent application
-	_ = modal
+// startFilter prompts for a filter query; an empty query shows all lines
+func (mv *MasterView) startFilter() {
+	if mv.promptCallback == nil {
+		return
+	}
+
+	mv.promptCallback("Filter: ", mv.search.filterQuery, func(query string) {
+		shown, err := mv.search.Filter(query)
+		if err != nil {
+			mv.setStatus(fmt.Sprintf("Invalid filter: %v", err))
+		}
+		mv.applyFilter()
+		if mv.search.filterQuery != "" {
+			mv.setStatus(fmt.Sprintf("Showing %d of %d entries matching %q", shown, len(mv.search.entries), mv.search.filterQuery))
+		}
+	})
+}
+
+// applyFilter re-renders the manifest with the current filter and search marks
+func (mv *MasterView) applyFilter() {
+	mv.renderer.SetHiddenLines(mv.search.HiddenLines())
+	mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+
+	// Keep the cursor where it is unless its line was filtered out
+	currentLine := mv.currentLine
+	mv.setupContent()
+	if _, ok := mv.navigableItems[currentLine]; ok && !mv.search.IsHidden(currentLine) {
+		mv.currentLine = currentLine
+	}
+	mv.highlightCurrentLine()
+}
+
+// startQuery prompts for a query expression and shows the result as a report
+func (mv *MasterView) startQuery() {
+	if mv.promptCallback == nil || mv.reportCallback == nil {
+		return
+	}
+
+	mv.promptCallback("Query: ", mv.lastQuery, func(expression string) {
+		mv.lastQuery = expression
+		result, err := query.Run(expression, mv.manifest)
+		if err != nil {
+			mv.setStatus(fmt.Sprintf("Query failed: %v", err))
+			return
+		}
+		mv.reportCallback(fmt.Sprintf("Query - %d rows - %s", len(result.Rows), expression), tview.Escape(result.Table()))
+	})
+}
+
+// showDiff shows the changes made by the last refresh
+func (mv *MasterView) showDiff() {
+	if mv.previous == nil {
+		mv.setStatus("No earlier version to compare; press r to refresh")
+		return
+	}
+	if mv.reportCallback != nil {
+		mv.reportCallback(fmt.Sprintf("Diff - %s", mv.manifest.URL), renderManifestDiff(mv.previous, mv.manifest))
+	}
+}
+
+// resetSearch rebuilds search state for a new manifest, keeping the queries
+func (mv *MasterView) resetSearch() {
+	query, filterQuery := mv.search.query, mv.search.filterQuery
+	mv.search = newSearchState(mv.manifest, mv.navigableItems)
+	if filterQuery != "" {
+		mv.search.Filter(filterQuery)
+	}
+	if query != "" {
+		mv.search.Search(query)
+	}
+	mv.renderer.SetHiddenLines(mv.search.HiddenLines())
+	mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+}
+
+// setStatus updates the status bar
+func (mv *MasterView) setStatus(status string) {
+	if mv.statusCallback != nil {
+		mv.statusCallback(status)
+	}
 }
 
 // refresh refreshes the manifest data
@@ -328,15 +585,83 @@ func (mv *MasterView) refresh() {
 					}
 					return
 				}
+				changed := newManifest.Content != mv.manifest.Content
+				if changed {
+					mv.previous = mv.manifest
+				}
 				mv.manifest = newManifest
 				mv.BaseView.manifest = newManifest
 				mv.renderer = NewManifestRenderer(newManifest)
 				mv.navigableItems = mv.renderer.GetNavigableItems()
+				mv.resetSearch()
 				mv.setupContent()
 				if mv.statusCallback != nil {
-					mv.statusCallback(fmt.Sprintf("Master Manifest - %s", mv.manifest.URL))
+					status := fmt.Sprintf("Master Manifest - %s", mv.manifest.URL)
+					if changed {
+						status += fmt.Sprintf(" - %d changes, press D to diff", len(diff.Manifests(mv.previous, mv.manifest)))
+					}
+					mv.statusCallback(status)
 				}
 			})
 		}
 	}()
 }
+
+// Close cancels the running checks, which then leave their reports unshown
+func (mv *MasterView) Close() {
+	mv.cancel()
+}
+
+// SaveState records the cursor, scroll position, queries and the manifest before the last refresh
+func (mv *MasterView) SaveState(state *ViewState) {
+	state.Line = mv.currentLine
+	state.ScrollRow, state.ScrollColumn = mv.textView.GetScrollOffset()
+	state.SearchQuery = mv.search.query
+	state.FilterQuery = mv.search.filterQuery
+	state.LastQuery = mv.lastQuery
+	state.Previous = mv.previous
+}
+
+// RestoreState reapplies the saved queries and returns to the saved cursor and scroll position
+func (mv *MasterView) RestoreState(state *ViewState) {
+	mv.lastQuery = state.LastQuery
+	mv.previous = state.Previous
+	if state.FilterQuery != "" {
+		mv.search.Filter(state.FilterQuery)
+	}
+	if state.SearchQuery != "" {
+		mv.search.Search(state.SearchQuery)
+	}
+	mv.renderer.SetHiddenLines(mv.search.HiddenLines())
+	mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+
+	mv.setupContent()
+	if _, ok := mv.navigableItems[state.Line]; ok && !mv.search.IsHidden(state.Line) {
+		mv.currentLine = state.Line
+	}
+	mv.renderer.SetHighlightLine(mv.currentLine)
+	mv.textView.SetText(mv.renderer.RenderColorized())
+	mv.textView.ScrollTo(state.ScrollRow, state.ScrollColumn)
+	mv.emitSelection()
+}
+
+// ManifestLabel names a playlist for the breadcrumb and history, by the variant or rendition that references it
+// when it was opened from a master manifest, otherwise by its type or file name
+func ManifestLabel(manifest, parent *hls.Manifest, uri string) string {
+	if parent != nil {
+		for i := range parent.Variants {
+			if parent.Variants[i].URI == uri {
+				return variantLabel(&parent.Variants[i])
+			}
+		}
+		for _, rendition := range parent.Renditions {
+			if rendition.URI == uri {
+				return fmt.Sprintf("%s %s", strings.ToLower(rendition.Type), rendition.Name)
+			}
+		}
+	}
+	if manifest.Type == hls.MasterManifest {
+		return "Master"
+	}
+	return path.Base(manifest.URL)
+}
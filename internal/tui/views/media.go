@@ -1,13 +1,15 @@
 package views
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"github.com/soldiermoth/pantui/internal/diff"
+	"github.com/soldiermoth/pantui/internal/drm"
 	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/query"
 	"strings"
 	"time"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -19,7 +21,15 @@ type MediaView struct {
 	parser        *hls.Parser
 	renderer      *ManifestRenderer
 	navigableItems map[int]string
+	search        *searchState
+	lastQuery     string
+	previous      *hls.Manifest // Manifest before the last refresh that changed it
+	segmentLines  map[int]int
+	keyPeriods    []drm.Period
+	keyWarnings   []drm.Warning
 	currentLine   int
+	ctx           context.Context // Cancelled when the view is left, stopping running checks
+	cancel        context.CancelFunc
 }
 
 // NewMediaView creates a new media manifest view
@@ -38,13 +48,16 @@ func NewMediaView(manifest *hls.Manifest, parser *hls.Parser) *MediaView {
 		parser:        parser,
 		renderer:      renderer,
 		navigableItems: renderer.GetNavigableItems(),
+		search:        newSearchState(manifest, renderer.GetNavigableItems()),
+		segmentLines:  segmentLineIndex(manifest),
 		currentLine:   1,
 	}
 
+	mv.ctx, mv.cancel = context.WithCancel(context.Background())
 	mv.BaseView = NewBaseView(textView, MediaViewType, manifest)
+	mv.updateKeyPeriods()
 	mv.setupContent()
-	mv.setupKeyBindings()
-	mv.setupInputCapture()
+	mv.setupActions()
 
 	return mv
 }
@@ -52,14 +65,14 @@ func NewMediaView(manifest *hls.Manifest, parser *hls.Parser) *MediaView {
 // setupContent sets up the manifest content with syntax highlighting
 func (mv *MediaView) setupContent() {
 	if mv.manifest == nil {
-		mv.textView.SetText("[red]No manifest data available[white]")
+		mv.textView.SetText("[bad]No manifest data available[text]")
 		mv.textView.SetTitle(" Media Manifest - Error ").SetBorder(true)
 		return
 	}
 
-	// Find the first navigable line and set it as current
+	// Find the first visible navigable line and set it as current
 	for lineNum := 1; lineNum <= len(strings.Split(mv.manifest.Content, "\n")); lineNum++ {
-		if _, exists := mv.navigableItems[lineNum]; exists {
+		if _, exists := mv.navigableItems[lineNum]; exists && !mv.search.IsHidden(lineNum) {
 			mv.currentLine = lineNum
 			break
 		}
@@ -71,6 +84,7 @@ func (mv *MediaView) setupContent() {
 	// Render the colorized manifest content
 	colorizedContent := mv.renderer.RenderColorized()
 	mv.textView.SetText(colorizedContent)
+	mv.emitSelection()
 	
 	// Set title with manifest info
 	segmentCount := len(mv.manifest.Segments)
@@ -78,47 +92,43 @@ func (mv *MediaView) setupContent() {
 	if mv.manifest.TargetDuration > 0 {
 		title += fmt.Sprintf(" (Target: %ds)", mv.manifest.TargetDuration)
 	}
+	if encrypted := mv.encryptedKeyPeriods(); encrypted > 0 {
+		title += fmt.Sprintf(" (%d key periods", encrypted)
+		if len(mv.keyWarnings) > 0 {
+			title += fmt.Sprintf(", [bad]%d rotation warnings[-]", len(mv.keyWarnings))
+		}
+		title += ")"
+	}
+	if mv.search.filterQuery != "" {
+		title += fmt.Sprintf(" (filter: %s)", tview.Escape(mv.search.filterQuery))
+	}
 	mv.textView.SetTitle(title + " ").SetBorder(true)
 }
 
-// setupInputCapture sets up input capture for navigation
-func (mv *MediaView) setupInputCapture() {
-	mv.textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEnter:
-			// Find URI on current line and navigate to it
-			if uri, exists := mv.navigableItems[mv.currentLine]; exists {
-				// Find the segment by URI
-				for _, segment := range mv.manifest.Segments {
-					if segment.URI == uri {
-						if mv.segmentNavigationCallback != nil {
-							mv.segmentNavigationCallback(&segment)
-						}
-						return nil
-					}
-				}
-				// If no segment found, fall back to regular navigation
-				if mv.navigationCallback != nil {
-					mv.navigationCallback(uri)
-				}
-			}
-			return nil
-		case tcell.KeyUp:
-			mv.navigateUp()
-			return nil
-		case tcell.KeyDown:
-			mv.navigateDown()
-			return nil
+// openSelection navigates to the segment on the current line
+func (mv *MediaView) openSelection() {
+	uri, exists := mv.navigableItems[mv.currentLine]
+	if !exists {
+		return
+	}
+	// Find the segment by its line, URIs repeat with byte ranges
+	if segment := mv.currentSegment(); segment != nil {
+		if mv.segmentNavigationCallback != nil {
+			mv.segmentNavigationCallback(mv.manifest, segment)
 		}
-		return event // Let other keys pass through
-	})
+		return
+	}
+	// If no segment found, fall back to regular navigation
+	if mv.navigationCallback != nil {
+		mv.navigationCallback(uri)
+	}
 }
 
 // navigateUp moves to the previous navigable line
 func (mv *MediaView) navigateUp() {
 	// Find previous navigable line
 	for line := mv.currentLine - 1; line >= 1; line-- {
-		if _, exists := mv.navigableItems[line]; exists {
+		if _, exists := mv.navigableItems[line]; exists && !mv.search.IsHidden(line) {
 			mv.currentLine = line
 			mv.highlightCurrentLine()
 			return
@@ -131,7 +141,7 @@ func (mv *MediaView) navigateDown() {
 	// Find next navigable line
 	maxLine := len(strings.Split(mv.manifest.Content, "\n"))
 	for line := mv.currentLine + 1; line <= maxLine; line++ {
-		if _, exists := mv.navigableItems[line]; exists {
+		if _, exists := mv.navigableItems[line]; exists && !mv.search.IsHidden(line) {
 			mv.currentLine = line
 			mv.highlightCurrentLine()
 			return
@@ -139,6 +149,25 @@ func (mv *MediaView) navigateDown() {
 	}
 }
 
+// navigateRows moves the cursor up to a number of rows down, or up when rows is negative
+func (mv *MediaView) navigateRows(rows int) {
+	if line := lineRows(mv.navigableItems, mv.search.IsHidden, mv.currentLine, mv.lineCount(), rows); line != mv.currentLine {
+		mv.currentLine = line
+		mv.highlightCurrentLine()
+	}
+}
+
+// lineCount is the number of manifest lines
+func (mv *MediaView) lineCount() int {
+	return len(strings.Split(mv.manifest.Content, "\n"))
+}
+
+// pageRows is the number of rows the page keys move, the height of the view
+func (mv *MediaView) pageRows() int {
+	_, _, _, height := mv.textView.GetInnerRect()
+	return height - 1
+}
+
 // highlightCurrentLine highlights the current line and refreshes the view
 func (mv *MediaView) highlightCurrentLine() {
 	// Update the renderer with the new highlight line
@@ -150,6 +179,7 @@ func (mv *MediaView) highlightCurrentLine() {
 	
 	// Only scroll if the current line is not visible
 	mv.scrollToLineIfNeeded(mv.currentLine)
+	mv.emitSelection()
 }
 
 // scrollToLineIfNeeded scrolls to the line only if it's not currently visible
@@ -163,8 +193,8 @@ func (mv *MediaView) scrollToLineIfNeeded(lineNum int) {
 	currentRow, _ := mv.textView.GetScrollOffset()
 	
 	// Calculate if the line is visible in the current viewport
-	// Account for 0-based indexing (lineNum is 1-based)
-	targetRow := lineNum - 1
+	// Rows are 0-based and skip lines hidden by a filter
+	targetRow := mv.renderer.RowForLine(lineNum)
 	bottomVisibleRow := currentRow + height - 1
 	
 	// Check if line is above the viewport
@@ -187,35 +217,26 @@ func (mv *MediaView) scrollToLineIfNeeded(lineNum int) {
 	// Line is already visible, no scrolling needed
 }
 
-// setupKeyBindings sets up key bindings for the media view
-func (mv *MediaView) setupKeyBindings() {
-	mv.AddKeyBinding("Enter", "Open Segment")
-	mv.AddKeyBinding("↑↓", "Navigate")
-	mv.AddKeyBinding("p", "Play")
-	mv.AddKeyBinding("d", "Details")
-	mv.AddKeyBinding("s", "Summary")
-	mv.AddKeyBinding("r", "Refresh")
-}
-
-// HandleKey handles key events for the media view
-func (mv *MediaView) HandleKey(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Rune() {
-	case 'p':
-		mv.playManifest()
-		return nil
-	case 'd':
-		mv.showDetails()
-		return nil
-	case 's':
-		mv.showSummary()
-		return nil
-	case 'r':
-		mv.refresh()
-		return nil
-	}
-
-	// Let the text view handle other keys
-	return event
+// setupActions sets the handlers of the media view's actions
+func (mv *MediaView) setupActions() {
+	mv.Handle("open", mv.openSelection)
+	mv.Handle("up", mv.navigateUp)
+	mv.Handle("down", mv.navigateDown)
+	mv.Handle("page-up", func() { mv.navigateRows(-mv.pageRows()) })
+	mv.Handle("page-down", func() { mv.navigateRows(mv.pageRows()) })
+	mv.Handle("top", func() { mv.navigateRows(-mv.lineCount()) })
+	mv.Handle("bottom", func() { mv.navigateRows(mv.lineCount()) })
+	mv.Handle("play", mv.playManifest)
+	mv.Handle("summary", mv.showSummary)
+	mv.Handle("refresh", mv.refresh)
+	mv.Handle("search", mv.startSearch)
+	mv.Handle("next-match", func() { mv.jumpToMatch(true) })
+	mv.Handle("previous-match", func() { mv.jumpToMatch(false) })
+	mv.Handle("filter", mv.startFilter)
+	mv.Handle("query", mv.startQuery)
+	mv.Handle("diff", mv.showDiff)
+	mv.Handle("goto", mv.startGoto)
+	mv.Handle("continuity", mv.startContinuity)
 }
 
 // playManifest plays the current manifest using ffplay
@@ -234,7 +255,7 @@ func (mv *MediaView) playManifest() {
 
 	// Launch ffplay in a goroutine so it doesn't block the UI
 	go func() {
-		cmd := exec.Command("ffplay", "-hide_banner", mv.manifest.URL)
+		cmd := ffplayCommand(mv.manifest.URL)
 		
 		// Start the process
 		err := cmd.Start()
@@ -268,61 +289,105 @@ func (mv *MediaView) playManifest() {
 	}()
 }
 
-// showDetails shows detailed information about the selected segment
-func (mv *MediaView) showDetails() {
-	// Find the segment for the current line
-	uri, exists := mv.navigableItems[mv.currentLine]
-	if !exists {
-		return
+// segmentLineIndex maps URI line numbers to segment indexes
+func segmentLineIndex(manifest *hls.Manifest) map[int]int {
+	index := make(map[int]int)
+	if manifest == nil {
+		return index
 	}
-	
-	// Find the segment by URI
-	var segment *hls.Segment
-	for _, s := range mv.manifest.Segments {
-		if s.URI == uri {
-			segment = &s
-			break
-		}
+	for i, segment := range manifest.Segments {
+		index[segment.LineNumber] = i
+	}
+	return index
+}
+
+// currentSegment returns the segment on the current line, if any
+func (mv *MediaView) currentSegment() *hls.Segment {
+	if i, ok := mv.segmentLines[mv.currentLine]; ok {
+		return &mv.manifest.Segments[i]
+	}
+	return nil
+}
+
+// SetSelectionCallback sets the selection callback and reports the current selection
+func (mv *MediaView) SetSelectionCallback(callback SelectionCallback) {
+	mv.BaseView.SetSelectionCallback(callback)
+	mv.emitSelection()
+}
+
+// emitSelection reports the details of the selected segment to the inspector
+func (mv *MediaView) emitSelection() {
+	if mv.selectionCallback == nil {
+		return
 	}
+
+	segment := mv.currentSegment()
 	if segment == nil {
+		mv.selectionCallback("Inspector", "[muted]Nothing selected[text]")
 		return
 	}
-	
-	details := fmt.Sprintf(`Segment Details:
-
-Sequence: %d
-Duration: %.3f seconds
-URI: %s
-Byte Range: %s`,
-		segment.Sequence,
-		segment.Duration,
-		segment.URI,
-		segment.ByteRange)
-
-	if segment.Key != nil {
-		details += fmt.Sprintf(`
-
-Encryption:
-Method: %s
-URI: %s
-IV: %s
-Key Format: %s`,
-			segment.Key.Method,
-			segment.Key.URI,
-			segment.Key.IV,
-			segment.Key.KeyFormat)
-	}
-
-	// Create a modal to show details
-	modal := tview.NewModal().
-		SetText(details).
-		AddButtons([]string{"Close"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			// Close modal - this would need to be handled by the parent app
-		})
-
-	// This would need to be handled by the parent application
-	_ = modal
+	mv.selectionCallback(fmt.Sprintf("Segment %d", segment.Sequence), mv.formatSegmentDetails(segment))
+}
+
+// formatSegmentDetails formats the parsed fields of a segment
+func (mv *MediaView) formatSegmentDetails(segment *hls.Segment) string {
+	var details strings.Builder
+
+	details.WriteString(fmt.Sprintf("[label]Sequence:[text] %d\n", segment.Sequence))
+	details.WriteString(fmt.Sprintf("[label]Duration:[text] %.3f seconds\n", segment.Duration))
+	details.WriteString(fmt.Sprintf("[label]URI:[text] %s\n", segment.URI))
+	if segment.ByteRange != "" {
+		details.WriteString(fmt.Sprintf("[label]Byte Range:[text] %s\n", segment.ByteRange))
+	}
+	if segment.ProgramDateTime != nil {
+		details.WriteString(fmt.Sprintf("[label]Program Date Time:[text] %s\n", segment.ProgramDateTime.Format("2006-01-02T15:04:05.000Z07:00")))
+	}
+	if segment.Discontinuity {
+		details.WriteString("[heading]Discontinuity[text]\n")
+	}
+
+	details.WriteString("\n[label]Encryption:[text]\n")
+	keys := drm.ActiveKeys(segment)
+	if len(keys) == 0 {
+		details.WriteString("Method: NONE\n")
+	}
+	for i, key := range keys {
+		if i > 0 {
+			details.WriteString("\n")
+		}
+		system, _ := drm.ByKeyFormat(key.KeyFormat)
+		details.WriteString(fmt.Sprintf("Method: [bad]%s[text] (%s)\n", key.Method, system.Name))
+		if strings.HasPrefix(key.URI, "data:") {
+			details.WriteString("URI: data URI (press Enter, then d to decode)\n")
+		} else if key.URI != "" {
+			details.WriteString(fmt.Sprintf("URI: %s\n", key.URI))
+		}
+		if key.IV != "" {
+			details.WriteString(fmt.Sprintf("IV: %s\n", key.IV))
+		}
+		if key.KeyFormat != "" {
+			details.WriteString(fmt.Sprintf("Key Format: %s\n", key.KeyFormat))
+		}
+	}
+
+	if period := keyPeriodFor(mv.keyPeriods, segment.Sequence); period != nil && period.Encrypted() {
+		details.WriteString(fmt.Sprintf("Key Period: #%d (seq %d-%d, %.1fs)\n", period.Index+1, period.FirstSequence, period.LastSequence, period.Duration))
+		for _, warning := range mv.keyWarnings {
+			if warning.Sequence == period.FirstSequence {
+				details.WriteString(fmt.Sprintf("[bad]Warning:[text] %s\n", tview.Escape(warning.Message)))
+			}
+		}
+	}
+
+	if segment.Map != nil {
+		details.WriteString("\n[label]Init Fragment:[text]\n")
+		details.WriteString(fmt.Sprintf("URI: %s\n", segment.Map.URI))
+		if segment.Map.ByteRange != "" {
+			details.WriteString(fmt.Sprintf("Byte Range: %s\n", segment.Map.ByteRange))
+		}
+	}
+
+	return details.String()
 }
 
 // showSummary shows a summary of the manifest
@@ -330,14 +395,15 @@ func (mv *MediaView) showSummary() {
 	totalDuration := 0.0
 	encryptedSegments := 0
 	
-	for _, segment := range mv.manifest.Segments {
+	for i := range mv.manifest.Segments {
+		segment := &mv.manifest.Segments[i]
 		totalDuration += segment.Duration
-		if segment.Key != nil && segment.Key.Method != "NONE" && segment.Key.Method != "" {
+		if len(drm.ActiveKeys(segment)) > 0 {
 			encryptedSegments++
 		}
 	}
 
-	summary := fmt.Sprintf(`Media Manifest Summary:
+	summary := fmt.Sprintf(`[heading]Media Manifest Summary[text]
 
 Version: %d
 Target Duration: %d seconds
@@ -346,25 +412,39 @@ Total Segments: %d
 Total Duration: %s
 Encrypted Segments: %d
 
-Base URL: %s`,
+Base URL: %s
+
+%s`,
 		mv.manifest.Version,
 		mv.manifest.TargetDuration,
 		mv.manifest.Sequence,
 		len(mv.manifest.Segments),
 		mv.formatDuration(totalDuration),
 		encryptedSegments,
-		mv.manifest.BaseURL)
+		tview.Escape(mv.manifest.BaseURL),
+		formatKeyPeriods(mv.keyPeriods, mv.keyWarnings))
 
-	// Create a modal to show summary
-	modal := tview.NewModal().
-		SetText(summary).
-		AddButtons([]string{"Close"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			// Close modal - this would need to be handled by the parent app
-		})
+	if mv.reportCallback != nil {
+		mv.reportCallback("Summary - "+mv.manifest.URL, summary)
+	}
+}
 
-	// This would need to be handled by the parent application
-	_ = modal
+// updateKeyPeriods recomputes key periods and rotation warnings and sets the period bands
+func (mv *MediaView) updateKeyPeriods() {
+	mv.keyPeriods = drm.Periods(mv.manifest)
+	mv.keyWarnings = drm.RotationWarnings(mv.keyPeriods)
+	mv.renderer.SetBands(keyPeriodBands(mv.keyPeriods))
+}
+
+// encryptedKeyPeriods counts the encrypted key periods
+func (mv *MediaView) encryptedKeyPeriods() int {
+	count := 0
+	for _, period := range mv.keyPeriods {
+		if period.Encrypted() {
+			count++
+		}
+	}
+	return count
 }
 
 // formatDuration formats duration in human-readable format
@@ -381,6 +461,168 @@ func (mv *MediaView) formatDuration(seconds float64) string {
 	return fmt.Sprintf("%d:%02d", minutes, secs)
 }
 
+// startSearch prompts for a search query and jumps to the first match
+func (mv *MediaView) startSearch() {
+	if mv.promptCallback == nil {
+		return
+	}
+
+	mv.promptCallback("Search: ", mv.search.query, func(query string) {
+		if err := mv.search.Search(query); err != nil {
+			mv.setStatus(fmt.Sprintf("Invalid search: %v", err))
+			return
+		}
+		mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+		if line, ok := mv.search.Next(mv.currentLine - 1); ok {
+			mv.currentLine = line
+		}
+		mv.highlightCurrentLine()
+		mv.setStatus(mv.search.Status())
+	})
+}
+
+// jumpToMatch moves to the next or previous search match
+func (mv *MediaView) jumpToMatch(forward bool) {
+	if mv.search.query == "" {
+		return
+	}
+
+	line, ok := mv.search.Previous(mv.currentLine)
+	if forward {
+		line, ok = mv.search.Next(mv.currentLine)
+	}
+	if ok {
+		mv.currentLine = line
+		mv.highlightCurrentLine()
+	}
+	mv.setStatus(mv.search.Status())
+}
+
+// startFilter prompts for a filter query; an empty query shows all lines
+func (mv *MediaView) startFilter() {
+	if mv.promptCallback == nil {
+		return
+	}
+
+	mv.promptCallback("Filter: ", mv.search.filterQuery, func(query string) {
+		shown, err := mv.search.Filter(query)
+		if err != nil {
+			mv.setStatus(fmt.Sprintf("Invalid filter: %v", err))
+		}
+		mv.applyFilter()
+		if mv.search.filterQuery != "" {
+			mv.setStatus(fmt.Sprintf("Showing %d of %d entries matching %q", shown, len(mv.search.entries), mv.search.filterQuery))
+		}
+	})
+}
+
+// applyFilter re-renders the manifest with the current filter and search marks
+func (mv *MediaView) applyFilter() {
+	mv.renderer.SetHiddenLines(mv.search.HiddenLines())
+	mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+
+	// Keep the cursor where it is unless its line was filtered out
+	currentLine := mv.currentLine
+	mv.setupContent()
+	if _, ok := mv.navigableItems[currentLine]; ok && !mv.search.IsHidden(currentLine) {
+		mv.currentLine = currentLine
+	}
+	mv.highlightCurrentLine()
+}
+
+// startQuery prompts for a query expression and shows the result as a report
+func (mv *MediaView) startQuery() {
+	if mv.promptCallback == nil || mv.reportCallback == nil {
+		return
+	}
+
+	mv.promptCallback("Query: ", mv.lastQuery, func(expression string) {
+		mv.lastQuery = expression
+		result, err := query.Run(expression, mv.manifest)
+		if err != nil {
+			mv.setStatus(fmt.Sprintf("Query failed: %v", err))
+			return
+		}
+		mv.reportCallback(fmt.Sprintf("Query - %d rows - %s", len(result.Rows), expression), tview.Escape(result.Table()))
+	})
+}
+
+// startGoto prompts for a sequence number, time offset, wall clock or line and jumps there
+func (mv *MediaView) startGoto() {
+	if mv.promptCallback == nil {
+		return
+	}
+
+	mv.promptCallback("Go to (seq, 12:34, @hh:mm:ss, L120): ", "", func(input string) {
+		target, err := parseGotoTarget(input)
+		if err != nil {
+			mv.setStatus(fmt.Sprintf("Go to failed: %v", err))
+			return
+		}
+		line, err := resolveGotoTarget(mv.manifest, target)
+		if err != nil {
+			mv.setStatus(fmt.Sprintf("Go to failed: %v", err))
+			return
+		}
+		if mv.search.IsHidden(line) {
+			mv.setStatus(fmt.Sprintf("Line %d is hidden by the filter", line))
+			return
+		}
+		if !mv.moveToLine(line) {
+			mv.setStatus(fmt.Sprintf("No segment at or after line %d", line))
+			return
+		}
+		if segment := mv.currentSegment(); segment != nil {
+			mv.setStatus(fmt.Sprintf("Segment %d (line %d)", segment.Sequence, mv.currentLine))
+		}
+	})
+}
+
+// moveToLine moves the cursor to the first visible navigable line at or after the given line
+func (mv *MediaView) moveToLine(line int) bool {
+	maxLine := len(strings.Split(mv.manifest.Content, "\n"))
+	for l := line; l <= maxLine; l++ {
+		if _, exists := mv.navigableItems[l]; exists && !mv.search.IsHidden(l) {
+			mv.currentLine = l
+			mv.highlightCurrentLine()
+			return true
+		}
+	}
+	return false
+}
+
+// showDiff shows the changes made by the last refresh
+func (mv *MediaView) showDiff() {
+	if mv.previous == nil {
+		mv.setStatus("No earlier version to compare; press r to refresh")
+		return
+	}
+	if mv.reportCallback != nil {
+		mv.reportCallback(fmt.Sprintf("Diff - %s", mv.manifest.URL), renderManifestDiff(mv.previous, mv.manifest))
+	}
+}
+
+// resetSearch rebuilds search state for a new manifest, keeping the queries
+func (mv *MediaView) resetSearch() {
+	query, filterQuery := mv.search.query, mv.search.filterQuery
+	mv.search = newSearchState(mv.manifest, mv.navigableItems)
+	if filterQuery != "" {
+		mv.search.Filter(filterQuery)
+	}
+	if query != "" {
+		mv.search.Search(query)
+	}
+	mv.renderer.SetHiddenLines(mv.search.HiddenLines())
+	mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+}
+
+// setStatus updates the status bar
+func (mv *MediaView) setStatus(status string) {
+	if mv.statusCallback != nil {
+		mv.statusCallback(status)
+	}
+}
+
 // refresh refreshes the manifest data
 func (mv *MediaView) refresh() {
 	// Show loading indicator via status bar
@@ -400,19 +642,67 @@ func (mv *MediaView) refresh() {
 					}
 					return
 				}
+				changed := newManifest.Content != mv.manifest.Content
+				if changed {
+					mv.previous = mv.manifest
+				}
 				mv.manifest = newManifest
 				mv.BaseView.manifest = newManifest
 				mv.renderer = NewManifestRenderer(newManifest)
 				mv.navigableItems = mv.renderer.GetNavigableItems()
+				mv.resetSearch()
+				mv.segmentLines = segmentLineIndex(newManifest)
+				mv.updateKeyPeriods()
 				mv.setupContent()
 				if mv.statusCallback != nil {
 					title := fmt.Sprintf(" Media Manifest - %d segments", len(mv.manifest.Segments))
 					if mv.manifest.TargetDuration > 0 {
 						title += fmt.Sprintf(" (Target: %ds)", mv.manifest.TargetDuration)
 					}
+					if changed {
+						title += fmt.Sprintf(" - %d changes, press D to diff", len(diff.Manifests(mv.previous, mv.manifest)))
+					}
 					mv.statusCallback(title)
 				}
 			})
 		}
 	}()
 }
+
+// Close cancels the running checks, which then leave their reports unshown
+func (mv *MediaView) Close() {
+	mv.cancel()
+}
+
+// SaveState records the cursor, scroll position, queries and the manifest before the last refresh
+func (mv *MediaView) SaveState(state *ViewState) {
+	state.Line = mv.currentLine
+	state.ScrollRow, state.ScrollColumn = mv.textView.GetScrollOffset()
+	state.SearchQuery = mv.search.query
+	state.FilterQuery = mv.search.filterQuery
+	state.LastQuery = mv.lastQuery
+	state.Previous = mv.previous
+}
+
+// RestoreState reapplies the saved queries and returns to the saved cursor and scroll position
+func (mv *MediaView) RestoreState(state *ViewState) {
+	mv.lastQuery = state.LastQuery
+	mv.previous = state.Previous
+	if state.FilterQuery != "" {
+		mv.search.Filter(state.FilterQuery)
+	}
+	if state.SearchQuery != "" {
+		mv.search.Search(state.SearchQuery)
+	}
+	mv.renderer.SetHiddenLines(mv.search.HiddenLines())
+	mv.renderer.SetMarkedLines(mv.search.MatchedLines())
+
+	mv.setupContent()
+	if _, ok := mv.navigableItems[state.Line]; ok && !mv.search.IsHidden(state.Line) {
+		mv.currentLine = state.Line
+	}
+	mv.renderer.SetHighlightLine(mv.currentLine)
+	mv.textView.SetText(mv.renderer.RenderColorized())
+	mv.textView.ScrollTo(state.ScrollRow, state.ScrollColumn)
+	mv.emitSelection()
+}
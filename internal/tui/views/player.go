@@ -0,0 +1,35 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// FFPlayPath is the ffplay executable launched by the p key, looked up on the PATH unless it is a path
+var FFPlayPath = "ffplay"
+
+// FFPlayArgs are extra arguments passed to ffplay before the manifest URL
+var FFPlayArgs []string
+
+// ffplayCommand plays a manifest with ffplay, sending the headers of the matching host rules
+func ffplayCommand(manifestURL string) *exec.Cmd {
+	args := append([]string{"-hide_banner"}, FFPlayArgs...)
+
+	if headers := fetch.HeadersFor(manifestURL); len(headers) > 0 {
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var lines strings.Builder
+		for _, name := range names {
+			lines.WriteString(fmt.Sprintf("%s: %s\r\n", name, headers[name]))
+		}
+		args = append(args, "-headers", lines.String())
+	}
+
+	return exec.Command(FFPlayPath, append(args, manifestURL)...)
+}
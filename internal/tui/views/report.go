@@ -0,0 +1,50 @@
+package views
+
+import (
+	"github.com/rivo/tview"
+)
+
+// ReportView displays a read-only text report such as query results
+type ReportView struct {
+	*BaseView
+	textView *tview.TextView
+	title    string
+}
+
+// NewReportView creates a new report view; content may contain color tags
+func NewReportView(title, content string) *ReportView {
+	textView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false).
+		SetScrollable(true)
+
+	rv := &ReportView{
+		textView: textView,
+		title:    title,
+	}
+
+	rv.BaseView = NewBaseView(textView, ReportViewType, nil)
+	rv.textView.SetText(content)
+	rv.textView.SetTitle(" " + tview.Escape(title) + " ").SetBorder(true)
+	rv.setupActions()
+
+	return rv
+}
+
+// setupActions sets the handlers of the report view's actions
+func (rv *ReportView) setupActions() {
+	rv.Handle("top", func() { rv.textView.ScrollToBeginning() })
+	rv.Handle("bottom", func() { rv.textView.ScrollToEnd() })
+}
+
+// SaveState records the report and its scroll position
+func (rv *ReportView) SaveState(state *ViewState) {
+	state.Heading = rv.title
+	state.Content = rv.textView.GetText(false)
+	state.ScrollRow, state.ScrollColumn = rv.textView.GetScrollOffset()
+}
+
+// RestoreState returns to the saved scroll position
+func (rv *ReportView) RestoreState(state *ViewState) {
+	rv.textView.ScrollTo(state.ScrollRow, state.ScrollColumn)
+}
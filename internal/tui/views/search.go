@@ -0,0 +1,435 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/drm"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// playlistTags are tags describing the whole playlist rather than an entry
+var playlistTags = map[string]bool{
+	"#EXTM3U":                       true,
+	"#EXT-X-VERSION":                true,
+	"#EXT-X-TARGETDURATION":         true,
+	"#EXT-X-MEDIA-SEQUENCE":         true,
+	"#EXT-X-DISCONTINUITY-SEQUENCE": true,
+	"#EXT-X-PLAYLIST-TYPE":          true,
+	"#EXT-X-INDEPENDENT-SEGMENTS":   true,
+	"#EXT-X-ENDLIST":                true,
+	"#EXT-X-START":                  true,
+	"#EXT-X-ALLOW-CACHE":            true,
+	"#EXT-X-SERVER-CONTROL":         true,
+	"#EXT-X-PART-INF":               true,
+	"#EXT-X-SESSION-DATA":           true,
+	"#EXT-X-SESSION-KEY":            true,
+}
+
+// manifestEntry groups the lines belonging to one navigable item
+type manifestEntry struct {
+	line   int               // Navigable line of the entry
+	lines  []int             // All manifest lines belonging to the entry
+	text   string            // Raw text of all entry lines
+	fields map[string]string // Parsed attributes, keyed by normalized name
+}
+
+// isPlaylistLine reports whether a raw line is a playlist-level tag
+func isPlaylistLine(line string) bool {
+	name := line
+	if colonIdx := strings.Index(line, ":"); colonIdx != -1 {
+		name = line[:colonIdx]
+	}
+	return playlistTags[name]
+}
+
+// buildEntries splits the manifest into entries ending at each navigable line
+func buildEntries(manifest *hls.Manifest, navigableItems map[int]string) []*manifestEntry {
+	if manifest == nil {
+		return nil
+	}
+
+	lines := strings.Split(manifest.Content, "\n")
+	entries := make([]*manifestEntry, 0, len(navigableItems))
+	var pending []int
+
+	for i, raw := range lines {
+		lineNum := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || isPlaylistLine(line) {
+			continue
+		}
+
+		// Tags with their own URI form an entry on their own line
+		if strings.HasPrefix(line, "#") {
+			if _, ok := navigableItems[lineNum]; !ok {
+				pending = append(pending, lineNum)
+				continue
+			}
+		}
+		if _, ok := navigableItems[lineNum]; !ok {
+			continue
+		}
+
+		entry := &manifestEntry{
+			line:   lineNum,
+			fields: make(map[string]string),
+		}
+		if !strings.HasPrefix(line, "#") {
+			entry.lines = append(entry.lines, pending...)
+			pending = nil
+		}
+		entry.lines = append(entry.lines, lineNum)
+
+		var text []string
+		for _, l := range entry.lines {
+			text = append(text, strings.TrimSpace(lines[l-1]))
+		}
+		entry.text = strings.Join(text, "\n")
+		entries = append(entries, entry)
+	}
+
+	addEntryFields(manifest, entries)
+	return entries
+}
+
+// addEntryFields attaches parsed attributes to each entry
+func addEntryFields(manifest *hls.Manifest, entries []*manifestEntry) {
+	byLine := make(map[int]*manifestEntry)
+	for _, entry := range entries {
+		for _, line := range entry.lines {
+			byLine[line] = entry
+		}
+	}
+
+	for _, tag := range manifest.Tags {
+		if entry, ok := byLine[tag.LineNumber]; ok {
+			for key, value := range tag.Attributes {
+				entry.fields[normalizeField(key)] = value
+			}
+		}
+	}
+
+	for _, variant := range manifest.Variants {
+		if entry, ok := byLine[variant.LineNumber]; ok {
+			entry.fields["URI"] = variant.URI
+		}
+	}
+
+	for _, segment := range manifest.Segments {
+		entry, ok := byLine[segment.LineNumber]
+		if !ok {
+			continue
+		}
+		entry.fields["URI"] = segment.URI
+		entry.fields["SEQUENCE"] = strconv.Itoa(segment.Sequence)
+		entry.fields["DURATION"] = strconv.FormatFloat(segment.Duration, 'f', -1, 64)
+		entry.fields["BYTERANGE"] = segment.ByteRange
+		entry.fields["DISCONTINUITY"] = "NO"
+		if segment.Discontinuity {
+			entry.fields["DISCONTINUITY"] = "YES"
+		}
+		if segment.ProgramDateTime != nil {
+			entry.fields["PROGRAM-DATE-TIME"] = segment.ProgramDateTime.Format("2006-01-02T15:04:05.000Z07:00")
+			entry.fields["PDT"] = entry.fields["PROGRAM-DATE-TIME"]
+		}
+
+		method := "NONE"
+		if segment.Key != nil && segment.Key.Method != "" {
+			method = segment.Key.Method
+			entry.fields["KEY-URI"] = segment.Key.URI
+			entry.fields["KEY-IV"] = segment.Key.IV
+			entry.fields["KEY-KEYFORMAT"] = segment.Key.KeyFormat
+			entry.fields["KEYFORMAT"] = segment.Key.KeyFormat
+		}
+		entry.fields["KEY-METHOD"] = method
+		entry.fields["METHOD"] = method
+		entry.fields["DRM"] = strings.Join(drm.SegmentSystems(&segment), ",")
+
+		if segment.Map != nil {
+			entry.fields["MAP-URI"] = segment.Map.URI
+			entry.fields["MAP-BYTERANGE"] = segment.Map.ByteRange
+		}
+	}
+}
+
+// normalizeField normalizes field names so Key.Method matches KEY-METHOD
+func normalizeField(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, ".", "-")
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// condition is a single FIELD OP VALUE comparison
+type condition struct {
+	field string
+	op    string
+	value string
+	regex *regexp.Regexp
+}
+
+// entryMatcher matches entries against a search or filter query
+type entryMatcher struct {
+	text       string
+	conditions []condition
+}
+
+// conditionOperators are the comparison operators, two-character ones first so != is not read as =
+var conditionOperators = []string{"!=", ">=", "<=", "==", "!~", "=", ">", "<", "~"}
+
+// compileMatcher parses a query: either free text or FIELD OP VALUE terms joined by &&
+func compileMatcher(query string) (*entryMatcher, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	terms := strings.Split(query, "&&")
+	matcher := &entryMatcher{}
+	for _, term := range terms {
+		cond, ok, err := parseCondition(term)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Anything that is not a comparison is a plain text search
+			return &entryMatcher{text: strings.ToLower(query)}, nil
+		}
+		matcher.conditions = append(matcher.conditions, cond)
+	}
+
+	return matcher, nil
+}
+
+// findOperator returns the earliest operator in a term and its position, the longest one at that position
+func findOperator(term string) (string, int) {
+	op, at := "", -1
+	for _, candidate := range conditionOperators {
+		idx := strings.Index(term, candidate)
+		if idx == -1 {
+			continue
+		}
+		if at == -1 || idx < at || idx == at && len(candidate) > len(op) {
+			op, at = candidate, idx
+		}
+	}
+	return op, at
+}
+
+// parseCondition parses a FIELD OP VALUE term
+func parseCondition(term string) (condition, bool, error) {
+	term = strings.TrimSpace(term)
+	op, idx := findOperator(term)
+	if idx <= 0 {
+		return condition{}, false, nil
+	}
+	field := strings.TrimSpace(term[:idx])
+	if strings.ContainsAny(field, " \t\"") {
+		return condition{}, false, nil
+	}
+	cond := condition{
+		field: normalizeField(field),
+		op:    op,
+		value: strings.Trim(strings.TrimSpace(term[idx+len(op):]), `"`),
+	}
+	if op == "~" || op == "!~" {
+		re, err := regexp.Compile("(?i)" + cond.value)
+		if err != nil {
+			return condition{}, false, fmt.Errorf("invalid pattern %q: %w", cond.value, err)
+		}
+		cond.regex = re
+	}
+	return cond, true, nil
+}
+
+// Matches reports whether the entry satisfies the query
+func (m *entryMatcher) Matches(entry *manifestEntry) bool {
+	if m.text != "" {
+		if strings.Contains(strings.ToLower(entry.text), m.text) {
+			return true
+		}
+		for _, value := range entry.fields {
+			if strings.Contains(strings.ToLower(value), m.text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, cond := range m.conditions {
+		if !cond.matches(entry.fields[cond.field]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches evaluates the condition against a field value
+func (c condition) matches(actual string) bool {
+	switch c.op {
+	case "~":
+		return c.regex.MatchString(actual)
+	case "!~":
+		return !c.regex.MatchString(actual)
+	}
+
+	// Compare numerically when both sides are numbers
+	a, errA := strconv.ParseFloat(actual, 64)
+	b, errB := strconv.ParseFloat(c.value, 64)
+	if errA == nil && errB == nil {
+		switch c.op {
+		case "=", "==":
+			return a == b
+		case "!=":
+			return a != b
+		case ">":
+			return a > b
+		case "<":
+			return a < b
+		case ">=":
+			return a >= b
+		case "<=":
+			return a <= b
+		}
+	}
+
+	cmp := strings.Compare(strings.ToLower(actual), strings.ToLower(c.value))
+	switch c.op {
+	case "=", "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// searchState tracks search matches and the active filter of a manifest view
+type searchState struct {
+	entries     []*manifestEntry
+	query       string
+	matches     []int // Navigable lines of matching entries, in order
+	index       int
+	filterQuery string
+	hidden      map[int]bool
+}
+
+// newSearchState creates search state for a manifest
+func newSearchState(manifest *hls.Manifest, navigableItems map[int]string) *searchState {
+	return &searchState{
+		entries: buildEntries(manifest, navigableItems),
+		hidden:  make(map[int]bool),
+	}
+}
+
+// Search finds entries matching the query
+func (ss *searchState) Search(query string) error {
+	matcher, err := compileMatcher(query)
+	if err != nil {
+		return err
+	}
+
+	ss.query = query
+	ss.matches = ss.matches[:0]
+	ss.index = -1
+	for _, entry := range ss.entries {
+		if !ss.hidden[entry.line] && matcher.Matches(entry) {
+			ss.matches = append(ss.matches, entry.line)
+		}
+	}
+	return nil
+}
+
+// Next returns the first match after the given line, wrapping around
+func (ss *searchState) Next(fromLine int) (int, bool) {
+	if len(ss.matches) == 0 {
+		return 0, false
+	}
+	i := sort.SearchInts(ss.matches, fromLine+1)
+	if i == len(ss.matches) {
+		i = 0
+	}
+	ss.index = i
+	return ss.matches[i], true
+}
+
+// Previous returns the last match before the given line, wrapping around
+func (ss *searchState) Previous(fromLine int) (int, bool) {
+	if len(ss.matches) == 0 {
+		return 0, false
+	}
+	i := sort.SearchInts(ss.matches, fromLine) - 1
+	if i < 0 {
+		i = len(ss.matches) - 1
+	}
+	ss.index = i
+	return ss.matches[i], true
+}
+
+// MatchedLines returns the lines of every matching entry
+func (ss *searchState) MatchedLines() map[int]bool {
+	marked := make(map[int]bool, len(ss.matches))
+	for _, line := range ss.matches {
+		marked[line] = true
+	}
+	return marked
+}
+
+// Status describes the current match position
+func (ss *searchState) Status() string {
+	if len(ss.matches) == 0 {
+		return fmt.Sprintf("No matches for %q", ss.query)
+	}
+	return fmt.Sprintf("Match %d/%d for %q", ss.index+1, len(ss.matches), ss.query)
+}
+
+// Filter hides entries not matching the query; an empty query clears the filter
+func (ss *searchState) Filter(query string) (int, error) {
+	ss.hidden = make(map[int]bool)
+	ss.filterQuery = strings.TrimSpace(query)
+	if ss.filterQuery == "" {
+		return len(ss.entries), nil
+	}
+
+	matcher, err := compileMatcher(ss.filterQuery)
+	if err != nil {
+		ss.filterQuery = ""
+		return len(ss.entries), err
+	}
+
+	shown := 0
+	for _, entry := range ss.entries {
+		if matcher.Matches(entry) {
+			shown++
+			continue
+		}
+		for _, line := range entry.lines {
+			ss.hidden[line] = true
+		}
+	}
+
+	// Drop search matches that are no longer visible
+	if ss.query != "" {
+		ss.Search(ss.query)
+	}
+	return shown, nil
+}
+
+// IsHidden reports whether a line is hidden by the filter
+func (ss *searchState) IsHidden(line int) bool {
+	return ss.hidden[line]
+}
+
+// HiddenLines returns the lines hidden by the filter
+func (ss *searchState) HiddenLines() map[int]bool {
+	return ss.hidden
+}
@@ -0,0 +1,99 @@
+package views
+
+import (
+	"testing"
+)
+
+func TestCompileMatcher(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string // Expected plain text search, empty for conditions
+		field string // Expected field of the first condition
+		op    string
+		value string
+	}{
+		{query: "segment", text: "segment"},
+		{query: "BANDWIDTH>2000000", field: "BANDWIDTH", op: ">", value: "2000000"},
+		{query: "Key.Method != NONE", field: "KEY-METHOD", op: "!=", value: "NONE"},
+		{query: "duration >= 6", field: "DURATION", op: ">=", value: "6"},
+		{query: "uri~a=b", field: "URI", op: "~", value: "a=b"},
+		{query: "uri!~a<b", field: "URI", op: "!~", value: "a<b"},
+		{query: "uri=a~b", field: "URI", op: "=", value: "a~b"},
+		{query: `codecs == "avc1"`, field: "CODECS", op: "==", value: "avc1"},
+		{query: "=value", text: "=value"},
+		{query: "two words=value", text: "two words=value"},
+	}
+
+	for _, tt := range tests {
+		matcher, err := compileMatcher(tt.query)
+		if err != nil {
+			t.Errorf("compileMatcher(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if matcher.text != tt.text {
+			t.Errorf("compileMatcher(%q) text = %q, want %q", tt.query, matcher.text, tt.text)
+		}
+		if tt.text != "" {
+			continue
+		}
+		if len(matcher.conditions) != 1 {
+			t.Errorf("compileMatcher(%q) has %d conditions, want 1", tt.query, len(matcher.conditions))
+			continue
+		}
+		cond := matcher.conditions[0]
+		if cond.field != tt.field || cond.op != tt.op || cond.value != tt.value {
+			t.Errorf("compileMatcher(%q) = %s %s %s, want %s %s %s",
+				tt.query, cond.field, cond.op, cond.value, tt.field, tt.op, tt.value)
+		}
+	}
+}
+
+func TestCompileMatcherErrors(t *testing.T) {
+	for _, query := range []string{"", "   ", "uri~[a"} {
+		if _, err := compileMatcher(query); err == nil {
+			t.Errorf("compileMatcher(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestEntryMatcherMatches(t *testing.T) {
+	entry := &manifestEntry{
+		text: "#EXTINF:6.006,\nsegment100.ts?token=a=b",
+		fields: map[string]string{
+			"URI":        "segment100.ts?token=a=b",
+			"DURATION":   "6.006",
+			"KEY-METHOD": "AES-128",
+			"CODECS":     "avc1.640028",
+		},
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"segment100", true},
+		{"aes-128", true},
+		{"segment200", false},
+		{"duration > 6", true},
+		{"duration > 6.1", false},
+		{"DURATION=6.006", true},
+		{"key.method != NONE", true},
+		{"uri~a=b", true},
+		{"uri~a=c", false},
+		{"uri!~a=b", false},
+		{"codecs~^AVC1 && duration < 7", true},
+		{"codecs~^avc1 && duration < 6", false},
+		{"missing = x", false},
+	}
+
+	for _, tt := range tests {
+		matcher, err := compileMatcher(tt.query)
+		if err != nil {
+			t.Errorf("compileMatcher(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got := matcher.Matches(entry); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
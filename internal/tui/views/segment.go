@@ -1,96 +1,39 @@
 package views
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
+	"github.com/soldiermoth/pantui/internal/analyzer"
+	"github.com/soldiermoth/pantui/internal/decrypt"
+	"github.com/soldiermoth/pantui/internal/drm"
+	"github.com/soldiermoth/pantui/internal/fetch"
 	"net/url"
-	"os"
 	"os/exec"
 	"github.com/soldiermoth/pantui/internal/hls"
 	"runtime"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-// FFProbeOutput represents ffprobe JSON output
-type FFProbeOutput struct {
-	Format  FFProbeFormat  `json:"format"`
-	Streams []FFProbeStream `json:"streams"`
-}
-
-// FFProbeFormat represents format information from ffprobe
-type FFProbeFormat struct {
-	Filename       string            `json:"filename"`
-	NBStreams      int               `json:"nb_streams"`
-	NBPrograms     int               `json:"nb_programs"`
-	FormatName     string            `json:"format_name"`
-	FormatLongName string            `json:"format_long_name"`
-	StartTime      string            `json:"start_time"`
-	Duration       string            `json:"duration"`
-	Size           string            `json:"size"`
-	BitRate        string            `json:"bit_rate"`
-	ProbeScore     int               `json:"probe_score"`
-	Tags           map[string]string `json:"tags"`
-}
-
-// FFProbeStream represents stream information from ffprobe
-type FFProbeStream struct {
-	Index              int               `json:"index"`
-	CodecName          string            `json:"codec_name"`
-	CodecLongName      string            `json:"codec_long_name"`
-	Profile            string            `json:"profile"`
-	CodecType          string            `json:"codec_type"`
-	CodecTimeBase      string            `json:"codec_time_base"`
-	CodecTagString     string            `json:"codec_tag_string"`
-	CodecTag           string            `json:"codec_tag"`
-	Width              int               `json:"width"`
-	Height             int               `json:"height"`
-	CodedWidth         int               `json:"coded_width"`
-	CodedHeight        int               `json:"coded_height"`
-	HasBFrames         int               `json:"has_b_frames"`
-	SampleAspectRatio  string            `json:"sample_aspect_ratio"`
-	DisplayAspectRatio string            `json:"display_aspect_ratio"`
-	PixFmt             string            `json:"pix_fmt"`
-	Level              int               `json:"level"`
-	ColorRange         string            `json:"color_range"`
-	ColorSpace         string            `json:"color_space"`
-	ColorTransfer      string            `json:"color_transfer"`
-	ColorPrimaries     string            `json:"color_primaries"`
-	ChromaLocation     string            `json:"chroma_location"`
-	RFrameRate         string            `json:"r_frame_rate"`
-	AvgFrameRate       string            `json:"avg_frame_rate"`
-	TimeBase           string            `json:"time_base"`
-	StartPts           int               `json:"start_pts"`
-	StartTime          string            `json:"start_time"`
-	DurationTs         int64             `json:"duration_ts"`
-	Duration           string            `json:"duration"`
-	BitRate            string            `json:"bit_rate"`
-	BitsPerRawSample   string            `json:"bits_per_raw_sample"`
-	NBFrames           string            `json:"nb_frames"`
-	SampleFmt          string            `json:"sample_fmt"`
-	SampleRate         string            `json:"sample_rate"`
-	Channels           int               `json:"channels"`
-	ChannelLayout      string            `json:"channel_layout"`
-	BitsPerSample      int               `json:"bits_per_sample"`
-	Tags               map[string]string `json:"tags"`
-}
-
 // SegmentView displays segment information
 type SegmentView struct {
 	*BaseView
 	textView    *tview.TextView
+	playlist    *hls.Manifest // Media playlist of the segment, which its URIs resolve against
 	segment     *hls.Segment
 	resolvedURL string
-	probeData   *FFProbeOutput
+	analyzers   *analyzer.Registry
+	decryption  string // Describes how the probed data was decrypted, if it was
+	inspecting  bool
+	ctx         context.Context // Cancelled when the view is left, stopping fetches and analyzers
+	cancel      context.CancelFunc
 }
 
-// NewSegmentView creates a new segment view
-func NewSegmentView(segment *hls.Segment, resolvedURL string) *SegmentView {
+// NewSegmentView creates a new segment view for a segment of a media playlist
+func NewSegmentView(playlist *hls.Manifest, segment *hls.Segment, analyzers *analyzer.Registry) *SegmentView {
 	textView := tview.NewTextView().
 		SetDynamicColors(true).
 		SetRegions(true).
@@ -99,43 +42,39 @@ func NewSegmentView(segment *hls.Segment, resolvedURL string) *SegmentView {
 
 	sv := &SegmentView{
 		textView:    textView,
+		playlist:    playlist,
 		segment:     segment,
-		resolvedURL: resolvedURL,
+		resolvedURL: playlist.ResolveURL(segment.URI),
+		analyzers:   analyzers,
 	}
+	sv.ctx, sv.cancel = context.WithCancel(context.Background())
 
 	sv.BaseView = NewBaseView(textView, SegmentViewType, nil)
 	sv.setupContent()
-	sv.setupKeyBindings()
+	sv.setupActions()
 
 	return sv
 }
 
 // setupContent sets up the content for the segment view
 func (sv *SegmentView) setupContent() {
-	content := fmt.Sprintf(`[yellow]Segment Information[white]
+	content := fmt.Sprintf(`[heading]Segment Information[text]
 
-[cyan]Original URI:[white]
+[label]Original URI:[text]
 %s
 
-[cyan]Resolved URL:[white]
+[label]Resolved URL:[text]
 %s
 
-[cyan]Segment Details:[white]
+[label]Segment Details:[text]
 %s
 
-[cyan]URL Components:[white]
+[label]URL Components:[text]
 %s
 
-[cyan]Available Actions:[white]
-• Press [green]c[white] to copy URL to clipboard
-• Press [green]o[white] to open in browser
-• Press [green]h[white] to show HTTP headers
-• Press [green]i[white] to inspect segment
-• Press [yellow]Esc[white] to go back
-
-[darkgray]Note: This view shows segment metadata. 
+[muted]Note: This view shows segment metadata. 
 Actual segment content inspection would require 
-downloading and analyzing the media file.[white]`,
+downloading and analyzing the media file.[text]`,
 		sv.segment.URI,
 		sv.resolvedURL,
 		sv.formatSegmentDetails(),
@@ -148,7 +87,7 @@ downloading and analyzing the media file.[white]`,
 // formatSegmentDetails formats segment-specific details
 func (sv *SegmentView) formatSegmentDetails() string {
 	if sv.segment == nil {
-		return "[red]No segment data available[white]"
+		return "[bad]No segment data available[text]"
 	}
 
 	details := fmt.Sprintf("Duration: %.3f seconds\nSequence: %d", 
@@ -163,6 +102,18 @@ func (sv *SegmentView) formatSegmentDetails() string {
 		if sv.segment.Key.URI != "" {
 			details += fmt.Sprintf(" (Key URI: %s)", sv.segment.Key.URI)
 		}
+		if systems := drm.SegmentSystems(sv.segment); len(systems) > 0 {
+			details += fmt.Sprintf("\nKey Systems: %s", strings.Join(systems, ", "))
+		}
+		if sv.isAES128() {
+			if iv, err := decrypt.IV(sv.segment.Key.IV, sv.segment.Sequence); err != nil {
+				details += fmt.Sprintf("\nIV: [bad]%v[text]", err)
+			} else if sv.segment.Key.IV == "" {
+				details += fmt.Sprintf("\nIV: 0x%x (derived from media sequence)", iv)
+			} else {
+				details += fmt.Sprintf("\nIV: 0x%x", iv)
+			}
+		}
 	}
 
 	if sv.segment.Map != nil {
@@ -198,33 +149,40 @@ Fragment: %s`,
 		parsedURL.Fragment)
 }
 
-// setupKeyBindings sets up key bindings for the segment view
-func (sv *SegmentView) setupKeyBindings() {
-	sv.AddKeyBinding("c", "Copy URL")
-	sv.AddKeyBinding("o", "Open in Browser")
-	sv.AddKeyBinding("h", "HTTP Headers")
-	sv.AddKeyBinding("i", "Inspect")
+// setupActions sets the handlers of the segment view's actions
+func (sv *SegmentView) setupActions() {
+	sv.Handle("copy-url", sv.copyURL)
+	sv.Handle("open-browser", sv.openInBrowser)
+	sv.Handle("headers", sv.showHTTPHeaders)
+	sv.Handle("inspect", sv.inspectSegment)
+	sv.Handle("drm", sv.showDRMInfo)
+	sv.Handle("ts-analysis", sv.showTSAnalysis)
+	sv.Handle("boxes", sv.showBoxTree)
+	sv.Handle("frames", sv.showFrames)
+}
+
+// Close cancels running fetches and analyzers, killing their processes and removing their temporary files
+func (sv *SegmentView) Close() {
+	sv.cancel()
+}
+
+// SaveState records the segment and what the view shows, such as a finished analysis, with its scroll position
+func (sv *SegmentView) SaveState(state *ViewState) {
+	state.Manifest = sv.playlist
+	state.Segment = sv.segment
+	state.ResolvedURL = sv.resolvedURL
+	state.Content = sv.textView.GetText(false)
+	state.ContentTitle = sv.textView.GetTitle()
+	state.ScrollRow, state.ScrollColumn = sv.textView.GetScrollOffset()
 }
 
-// HandleKey handles key events for the segment view
-func (sv *SegmentView) HandleKey(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Rune() {
-	case 'c':
-		sv.copyURL()
-		return nil
-	case 'o':
-		sv.openInBrowser()
-		return nil
-	case 'h':
-		sv.showHTTPHeaders()
-		return nil
-	case 'i':
-		sv.inspectSegment()
-		return nil
-	}
-
-	// Let the text view handle other keys (scrolling, etc.)
-	return event
+// RestoreState shows the saved content again without fetching or analyzing anything
+func (sv *SegmentView) RestoreState(state *ViewState) {
+	if state.Content != "" {
+		sv.textView.SetText(state.Content)
+		sv.textView.SetTitle(state.ContentTitle)
+	}
+	sv.textView.ScrollTo(state.ScrollRow, state.ScrollColumn)
 }
 
 // copyURL copies the URL to clipboard
@@ -295,7 +253,7 @@ func (sv *SegmentView) showHTTPHeaders() {
 	sv.showMessage("Fetching HTTP headers...")
 	
 	go func() {
-		resp, err := http.Head(sv.resolvedURL)
+		resp, err := fetch.Head(sv.resolvedURL)
 		if err != nil {
 			sv.showMessage(fmt.Sprintf("Failed to fetch headers: %v", err))
 			return
@@ -318,26 +276,19 @@ func (sv *SegmentView) showHTTPHeaders() {
 
 // updateContentWithHeaders updates the content with HTTP headers
 func (sv *SegmentView) updateContentWithHeaders(headers string) {
-	content := fmt.Sprintf(`[yellow]Segment Information[white]
-
-[cyan]Original URI:[white]
-%s
+	content := fmt.Sprintf(`[heading]Segment Information[text]
 
-[cyan]Resolved URL:[white]
+[label]Original URI:[text]
 %s
 
-[cyan]HTTP Headers:[white]
+[label]Resolved URL:[text]
 %s
 
-[cyan]URL Components:[white]
+[label]HTTP Headers:[text]
 %s
 
-[cyan]Available Actions:[white]
-• Press [green]c[white] to copy URL to clipboard
-• Press [green]o[white] to open in browser
-• Press [green]h[white] to show HTTP headers
-• Press [green]i[white] to inspect segment
-• Press [yellow]Esc[white] to go back`,
+[label]URL Components:[text]
+%s`,
 		sv.segment.URI,
 		sv.resolvedURL,
 		headers,
@@ -346,341 +297,241 @@ func (sv *SegmentView) updateContentWithHeaders(headers string) {
 	sv.textView.SetText(content)
 }
 
-// inspectSegment inspects the segment using ffprobe
+// inspectSegment runs every analyzer that applies to the segment, showing the elapsed time until it finishes.
+// Leaving the view cancels it.
 func (sv *SegmentView) inspectSegment() {
-	url := sv.resolvedURL
-	if url == "" {
-		url = sv.segment.URI
+	if sv.inspecting {
+		sv.showMessage("Segment analysis is already running")
+		return
 	}
-
-	sv.showMessage("Running ffprobe analysis...")
+	sv.inspecting = true
+	progress := &analysisProgress{stage: "Reading segment", start: time.Now()}
+	sv.showMessage(progress.String())
+	done := make(chan struct{})
+	go sv.showProgress(progress, done)
 	
 	go func() {
-		// Run ffprobe to get detailed information
-		// If there's an init fragment, we need to concatenate it with the segment
-		probeData, err := sv.runFFProbeWithInit(url)
-		if err != nil {
-			if sv.updateCallback != nil {
-				sv.updateCallback(func() {
-					sv.showFFProbeError(err)
-				})
-			}
+		sections, err := sv.runAnalyzers(progress)
+		close(done)
+		if sv.ctx.Err() != nil || sv.updateCallback == nil {
 			return
 		}
+		sv.updateCallback(func() {
+			sv.inspecting = false
+			if err != nil {
+				sv.showAnalysisError(err)
+				return
+			}
+			sv.updateContentWithAnalysis(sections)
+			sv.showMessage(fmt.Sprintf("Segment analysis complete in %s", progress.elapsed()))
+		})
+	}()
+}
 
-		sv.probeData = probeData
-		
-		// Update the UI with probe results
-		if sv.updateCallback != nil {
+// analysisProgress is the stage of a running analysis, shared with the status bar ticker
+type analysisProgress struct {
+	mu    sync.Mutex
+	stage string
+	start time.Time
+}
+
+// set moves the analysis to a new stage
+func (p *analysisProgress) set(stage string) {
+	p.mu.Lock()
+	p.stage = stage
+	p.mu.Unlock()
+}
+
+// elapsed is the time since the analysis started, rounded for display
+func (p *analysisProgress) elapsed() time.Duration {
+	return time.Since(p.start).Round(100 * time.Millisecond)
+}
+
+// String describes the stage and the elapsed time
+func (p *analysisProgress) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return fmt.Sprintf("%s... %s (Esc cancels)", p.stage, p.elapsed())
+}
+
+// showProgress refreshes the status bar with the analysis progress until it is done or the view is left
+func (sv *SegmentView) showProgress(progress *analysisProgress, done chan struct{}) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-sv.ctx.Done():
+			return
+		case <-ticker.C:
+			if sv.updateCallback == nil {
+				continue
+			}
 			sv.updateCallback(func() {
-				sv.updateContentWithProbeData()
+				// A tick queued before completion mustn't overwrite the final status
+				if sv.inspecting {
+					sv.showMessage(progress.String())
+				}
 			})
 		}
-	}()
+	}
 }
 
-// runFFProbeWithInit executes ffprobe with init fragment support
-func (sv *SegmentView) runFFProbeWithInit(segmentURL string) (*FFProbeOutput, error) {
-	// Check if we have an init fragment
-	if sv.segment.Map != nil && sv.segment.Map.URI != "" {
-		// We have an init fragment, create a temporary concat file
-		initURL := sv.resolveInitFragmentURL(sv.segment.Map.URI)
-		
-		// Create temporary concat file
-		concatFile, err := sv.createConcatFile(initURL, segmentURL)
-		if err != nil {
-			// If we can't create concat file, fall back to segment-only analysis
-			concatError := fmt.Errorf("Failed to create concat file: %v", err)
-			result, fallbackErr := sv.runFFProbe(segmentURL)
-			if fallbackErr != nil {
-				return nil, fmt.Errorf("%v\n\nFallback segment-only analysis also failed: %v", concatError, fallbackErr)
-			}
-			return result, nil
+// runAnalyzers reads the segment once and renders the section of each applicable analyzer in registry order.
+// A failing analyzer shows its error in its section instead of failing the whole analysis.
+func (sv *SegmentView) runAnalyzers(progress *analysisProgress) (string, error) {
+	input, init, err := sv.analyzerInput()
+	if err != nil {
+		return "", err
+	}
+	applicable := sv.analyzers.Applicable(input, init)
+	if len(applicable) == 0 {
+		return "", fmt.Errorf("No analyzer applies to this segment")
+	}
+
+	var sections strings.Builder
+	for _, a := range applicable {
+		progress.set("Running " + a.Name())
+		result, err := sv.analyzers.Run(sv.ctx, a, input, init)
+		if sv.ctx.Err() != nil {
+			return "", sv.ctx.Err()
 		}
-		defer os.Remove(concatFile) // Clean up temp file
-		
-		cmd := exec.Command("ffprobe",
-			"-v", "quiet",
-			"-print_format", "json",
-			"-show_format",
-			"-show_streams",
-			"-f", "concat",
-			"-safe", "0",
-			"-protocol_whitelist", "concat,file,http,https,tcp,tls",
-			concatFile)
-
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			// Create detailed error message with full command context
-			concatError := fmt.Errorf("Init fragment concatenation failed\nCommand: ffprobe -f concat -safe 0 -protocol_whitelist concat,file,http,https,tcp,tls \"%s\"\nConcat file content:\nfile '%s'\nfile '%s'\nError: %v\nOutput: %s", 
-				concatFile, initURL, segmentURL, err, string(output))
-			
-			// Try segment-only analysis and include concat error info
-			result, fallbackErr := sv.runFFProbe(segmentURL)
-			if fallbackErr != nil {
-				return nil, fmt.Errorf("%v\n\nFallback segment-only analysis also failed: %v", concatError, fallbackErr)
-			}
-			
-			// Successful fallback - we could optionally log the concat error
-			return result, nil
+		if err == analyzer.ErrNotApplicable {
+			continue
 		}
-
-		var probeOutput FFProbeOutput
-		err = json.Unmarshal(output, &probeOutput)
+		header := tview.Escape(a.Name())
+		if err == nil && result.Cached {
+			header += " [muted](cached)[heading]"
+		}
+		sections.WriteString(fmt.Sprintf("\n[heading]── %s ──[text]\n", header))
 		if err != nil {
-			// If parsing fails, fall back to analyzing just the segment
-			parseError := fmt.Errorf("Init fragment concat succeeded but JSON parsing failed\nJSON Error: %v\nFFProbe Raw Output:\n%s", err, string(output))
-			
-			result, fallbackErr := sv.runFFProbe(segmentURL)
-			if fallbackErr != nil {
-				return nil, fmt.Errorf("%v\n\nFallback segment-only analysis also failed: %v", parseError, fallbackErr)
+			sections.WriteString(fmt.Sprintf("[bad]Analysis failed:[text]\n%s\n", tview.Escape(err.Error())))
+			continue
+		}
+		sections.WriteString(result.Section)
+		for _, finding := range result.Findings {
+			color, ok := findingColors[finding.Severity]
+			if !ok {
+				color = "text"
 			}
-			
-			return result, nil
+			sections.WriteString(fmt.Sprintf("[%s]%s:[text] %s\n", color, tview.Escape(finding.Severity), tview.Escape(finding.Message)))
+		}
+		if result.Section == "" && len(result.Findings) == 0 {
+			sections.WriteString("[muted]No output[text]\n")
 		}
-
-		return &probeOutput, nil
 	}
-
-	// No init fragment, analyze segment directly
-	return sv.runFFProbe(segmentURL)
+	return sections.String(), nil
 }
 
-// runFFProbe executes ffprobe and returns parsed results
-func (sv *SegmentView) runFFProbe(url string) (*FFProbeOutput, error) {
-	// ffprobe command with JSON output
-	cmd := exec.Command("ffprobe",
-		"-v", "quiet",
-		"-print_format", "json",
-		"-show_format",
-		"-show_streams",
-		url)
-
-	// Use CombinedOutput to capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
+// analyzerInput reads the segment, decrypting AES-128, and its init fragment for the analyzers
+func (sv *SegmentView) analyzerInput() (*analyzer.Segment, []byte, error) {
+	data, err := sv.readSegment()
 	if err != nil {
-		return nil, fmt.Errorf("FFProbe execution failed\nCommand: ffprobe -v quiet -print_format json -show_format -show_streams \"%s\"\nError: %v\nOutput:\n%s", url, err, string(output))
+		return nil, nil, err
+	}
+	input := &analyzer.Segment{
+		URI:       sv.segment.URI,
+		URL:       sv.resolvedURL,
+		Sequence:  sv.segment.Sequence,
+		Duration:  sv.segment.Duration,
+		ByteRange: sv.segment.ByteRange,
+		Decrypted: sv.isAES128(),
+		Data:      data,
+	}
+	if input.URL == "" {
+		input.URL = sv.segment.URI
+	}
+	if sv.segment.Key != nil {
+		input.KeyMethod = sv.segment.Key.Method
 	}
 
-	var probeOutput FFProbeOutput
-	err = json.Unmarshal(output, &probeOutput)
-	if err != nil {
-		return nil, fmt.Errorf("FFProbe succeeded but JSON parsing failed\nJSON Error: %v\nFFProbe Raw Output:\n%s", err, string(output))
+	var init []byte
+	if sv.segment.Map != nil && sv.segment.Map.URI != "" {
+		input.InitURL = sv.playlist.ResolveURL(sv.segment.Map.URI)
+		input.InitByteRange = sv.segment.Map.ByteRange
+		if init, err = fetch.ReadContext(sv.ctx, input.InitURL, input.InitByteRange); err != nil {
+			return nil, nil, fmt.Errorf("Failed to fetch init fragment\nURL: %s\nError: %v", input.InitURL, err)
+		}
 	}
+	return input, init, nil
+}
 
-	return &probeOutput, nil
+// isAES128 reports whether the segment is encrypted with METHOD=AES-128
+func (sv *SegmentView) isAES128() bool {
+	return sv.segment.Key != nil && sv.segment.Key.Method == "AES-128"
 }
 
-// createConcatFile creates a temporary concat file for ffprobe
-func (sv *SegmentView) createConcatFile(initURL, segmentURL string) (string, error) {
-	// Create temporary file
-	tmpFile, err := os.CreateTemp("", "pantui_concat_*.txt")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %v", err)
+// readSegment fetches the segment's bytes, decrypting AES-128 segments.
+// It also records how the data was decrypted for display.
+func (sv *SegmentView) readSegment() ([]byte, error) {
+	segmentURL := sv.resolvedURL
+	if segmentURL == "" {
+		segmentURL = sv.segment.URI
 	}
-	defer tmpFile.Close()
-
-	// Write concat file format
-	// Format: https://ffmpeg.org/ffmpeg-formats.html#concat-1
-	concatContent := fmt.Sprintf("file '%s'\nfile '%s'\n", initURL, segmentURL)
-	
-	_, err = tmpFile.WriteString(concatContent)
+	data, err := fetch.ReadContext(sv.ctx, segmentURL, sv.segment.ByteRange)
 	if err != nil {
-		os.Remove(tmpFile.Name())
-		return "", fmt.Errorf("failed to write concat file: %v", err)
+		return nil, fmt.Errorf("Failed to fetch segment\nURL: %s\nError: %v", segmentURL, err)
+	}
+	if !sv.isAES128() {
+		return data, nil
 	}
 
-	return tmpFile.Name(), nil
-}
-
-// resolveInitFragmentURL resolves the init fragment URL relative to the segment
-func (sv *SegmentView) resolveInitFragmentURL(initURI string) string {
-	// If init URI is absolute, return as-is
-	if strings.HasPrefix(initURI, "http://") || strings.HasPrefix(initURI, "https://") {
-		return initURI
+	key := sv.segment.Key
+	if key.URI == "" {
+		return nil, fmt.Errorf("AES-128 segment has no key URI")
 	}
+	keyURL := sv.playlist.ResolveURL(key.URI)
 
-	// If we have a resolved segment URL, resolve init relative to it
-	if sv.resolvedURL != "" {
-		if baseURL, err := url.Parse(sv.resolvedURL); err == nil {
-			if initURL, err := baseURL.Parse(initURI); err == nil {
-				return initURL.String()
-			}
-		}
+	keyData, err := fetch.ReadContext(sv.ctx, keyURL, "")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch AES-128 key\nKey URL: %s\nError: %v", keyURL, err)
+	}
+	iv, err := decrypt.IV(key.IV, sv.segment.Sequence)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fallback: return the init URI as-is (might be a local file)
-	return initURI
-}
+	plaintext, err := decrypt.AES128(data, keyData, iv)
+	if err != nil {
+		return nil, fmt.Errorf("AES-128 decryption failed\nKey URL: %s\nIV: 0x%x\nError: %v", keyURL, iv, err)
+	}
 
-// updateContentWithProbeData updates the content with ffprobe analysis
-func (sv *SegmentView) updateContentWithProbeData() {
-	if sv.probeData == nil {
-		sv.showMessage("No probe data available")
-		return
+	ivSource := "IV attribute"
+	if key.IV == "" {
+		ivSource = "media sequence"
 	}
+	sv.decryption = fmt.Sprintf("AES-128 with key %s, IV 0x%x (from %s)", keyURL, iv, ivSource)
+	return plaintext, nil
+}
 
-	content := fmt.Sprintf(`[yellow]Segment Analysis[white]
+// updateContentWithAnalysis updates the content with the analyzer sections
+func (sv *SegmentView) updateContentWithAnalysis(sections string) {
+	content := fmt.Sprintf(`[heading]Segment Analysis[text]
 
-[cyan]Original URI:[white]
+[label]Original URI:[text]
 %s
 
-[cyan]Resolved URL:[white]
+[label]Resolved URL:[text]
 %s
-
 %s
-
-[cyan]Available Actions:[white]
-• Press [green]c[white] to copy URL to clipboard
-• Press [green]o[white] to open in browser
-• Press [green]h[white] to show HTTP headers
-• Press [green]i[white] to inspect segment
-• Press [yellow]Esc[white] to go back`,
+%s`,
 		sv.segment.URI,
 		sv.resolvedURL,
-		sv.formatProbeData())
+		sv.formatDecryption(),
+		sections)
 
 	sv.textView.SetText(content)
 	sv.textView.SetTitle(" Segment Analysis ").SetBorder(true)
+	sv.textView.ScrollToBeginning()
 }
 
-// formatProbeData formats ffprobe data for display
-func (sv *SegmentView) formatProbeData() string {
-	if sv.probeData == nil {
-		return "[red]No probe data available[white]"
-	}
-
-	var content strings.Builder
-	
-	// Format information
-	content.WriteString("[cyan]Format Information:[white]\n")
-	format := sv.probeData.Format
-	
-	content.WriteString(fmt.Sprintf("Container: %s\n", format.FormatLongName))
-	if format.Duration != "" {
-		if duration, err := strconv.ParseFloat(format.Duration, 64); err == nil {
-			content.WriteString(fmt.Sprintf("Duration: %s\n", sv.formatDuration(duration)))
-		}
-	}
-	if format.Size != "" {
-		if size, err := strconv.ParseInt(format.Size, 10, 64); err == nil {
-			content.WriteString(fmt.Sprintf("File Size: %s\n", sv.formatBytes(size)))
-		}
-	}
-	if format.BitRate != "" {
-		if bitrate, err := strconv.ParseInt(format.BitRate, 10, 64); err == nil {
-			content.WriteString(fmt.Sprintf("Overall Bitrate: %s\n", sv.formatBitrate(bitrate)))
-		}
-	}
-
-	// Stream information
-	for i, stream := range sv.probeData.Streams {
-		content.WriteString(fmt.Sprintf("\n[cyan]Stream %d (%s):[white]\n", i, stream.CodecType))
-		
-		content.WriteString(fmt.Sprintf("Codec: %s", stream.CodecName))
-		if stream.CodecLongName != "" {
-			content.WriteString(fmt.Sprintf(" (%s)", stream.CodecLongName))
-		}
-		content.WriteString("\n")
-
-		if stream.CodecType == "video" {
-			if stream.Width > 0 && stream.Height > 0 {
-				content.WriteString(fmt.Sprintf("Resolution: %dx%d\n", stream.Width, stream.Height))
-			}
-			if stream.AvgFrameRate != "" {
-				content.WriteString(fmt.Sprintf("Frame Rate: %s fps\n", sv.formatFrameRate(stream.AvgFrameRate)))
-			}
-			if stream.PixFmt != "" {
-				content.WriteString(fmt.Sprintf("Pixel Format: %s\n", stream.PixFmt))
-			}
-			if stream.Profile != "" {
-				content.WriteString(fmt.Sprintf("Profile: %s\n", stream.Profile))
-			}
-			if stream.Level > 0 {
-				content.WriteString(fmt.Sprintf("Level: %d\n", stream.Level))
-			}
-		} else if stream.CodecType == "audio" {
-			if stream.SampleRate != "" {
-				content.WriteString(fmt.Sprintf("Sample Rate: %s Hz\n", stream.SampleRate))
-			}
-			if stream.Channels > 0 {
-				content.WriteString(fmt.Sprintf("Channels: %d\n", stream.Channels))
-			}
-			if stream.ChannelLayout != "" {
-				content.WriteString(fmt.Sprintf("Channel Layout: %s\n", stream.ChannelLayout))
-			}
-			if stream.SampleFmt != "" {
-				content.WriteString(fmt.Sprintf("Sample Format: %s\n", stream.SampleFmt))
-			}
-		}
-
-		if stream.BitRate != "" {
-			if bitrate, err := strconv.ParseInt(stream.BitRate, 10, 64); err == nil {
-				content.WriteString(fmt.Sprintf("Bitrate: %s\n", sv.formatBitrate(bitrate)))
-			}
-		}
-
-		if stream.Duration != "" {
-			if duration, err := strconv.ParseFloat(stream.Duration, 64); err == nil {
-				content.WriteString(fmt.Sprintf("Duration: %s\n", sv.formatDuration(duration)))
-			}
-		}
-	}
-
-	return content.String()
-}
-
-// formatDuration formats duration in seconds to human-readable format
-func (sv *SegmentView) formatDuration(seconds float64) string {
-	duration := time.Duration(seconds * float64(time.Second))
-	
-	hours := int(duration.Hours())
-	minutes := int(duration.Minutes()) % 60
-	secs := int(duration.Seconds()) % 60
-	millisecs := int(duration.Milliseconds()) % 1000
-	
-	if hours > 0 {
-		return fmt.Sprintf("%d:%02d:%02d.%03d", hours, minutes, secs, millisecs)
-	}
-	return fmt.Sprintf("%d:%02d.%03d", minutes, secs, millisecs)
-}
-
-// formatBytes formats bytes to human-readable format
-func (sv *SegmentView) formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
-// formatBitrate formats bitrate to human-readable format
-func (sv *SegmentView) formatBitrate(bitrate int64) string {
-	if bitrate >= 1000000 {
-		return fmt.Sprintf("%.1f Mbps", float64(bitrate)/1000000)
-	} else if bitrate >= 1000 {
-		return fmt.Sprintf("%.1f Kbps", float64(bitrate)/1000)
-	}
-	return fmt.Sprintf("%d bps", bitrate)
-}
-
-// formatFrameRate formats frame rate from fraction string
-func (sv *SegmentView) formatFrameRate(frameRate string) string {
-	// Frame rate is often in format "num/den"
-	parts := strings.Split(frameRate, "/")
-	if len(parts) == 2 {
-		if num, err1 := strconv.ParseFloat(parts[0], 64); err1 == nil {
-			if den, err2 := strconv.ParseFloat(parts[1], 64); err2 == nil && den != 0 {
-				return fmt.Sprintf("%.2f", num/den)
-			}
-		}
+// formatDecryption describes how the probed data was decrypted
+func (sv *SegmentView) formatDecryption() string {
+	if sv.decryption == "" {
+		return ""
 	}
-	return frameRate
+	return fmt.Sprintf("\n[label]Decrypted:[text]\n%s\n", tview.Escape(sv.decryption))
 }
 
 // showMessage shows a temporary message
@@ -690,40 +541,33 @@ func (sv *SegmentView) showMessage(message string) {
 	}
 }
 
-// showFFProbeError displays detailed ffprobe error information
-func (sv *SegmentView) showFFProbeError(err error) {
+// showAnalysisError displays detailed error information when the segment couldn't be analyzed
+func (sv *SegmentView) showAnalysisError(err error) {
 	// Show brief message in status bar
 	if sv.statusCallback != nil {
-		sv.statusCallback("FFProbe analysis failed - see details below")
+		sv.statusCallback("Segment analysis failed - see details below")
 	}
 
 	// Display full error details in the main content area
-	content := fmt.Sprintf(`[yellow]Segment Analysis Failed[white]
+	content := fmt.Sprintf(`[heading]Segment Analysis Failed[text]
 
-[cyan]Original URI:[white]
+[label]Original URI:[text]
 %s
 
-[cyan]Resolved URL:[white]
+[label]Resolved URL:[text]
 %s
 
-[cyan]Segment Details:[white]
+[label]Segment Details:[text]
 %s
 
-[red]FFProbe Error Details:[white]
+[bad]Error Details:[text]
 %s
 
-[cyan]Available Actions:[white]
-• Press [green]c[white] to copy URL to clipboard
-• Press [green]o[white] to open in browser
-• Press [green]h[white] to show HTTP headers
-• Press [green]i[white] to retry inspection
-• Press [yellow]Esc[white] to go back
-
-[darkgray]Tip: Check if the segment URL is accessible and if ffprobe is installed.[white]`,
+[muted]Tip: Check if the segment URL is accessible, then inspect again. Install ffprobe for container details.[text]`,
 		sv.segment.URI,
 		sv.resolvedURL,
 		sv.formatSegmentDetails(),
-		err.Error())
+		tview.Escape(err.Error()))
 
 	sv.textView.SetText(content)
 	sv.textView.SetTitle(" Segment Analysis - Error ").SetBorder(true)
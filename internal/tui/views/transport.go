@@ -0,0 +1,143 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/ts"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// showTSAnalysis parses the segment as MPEG-TS in Go, so it works without ffprobe
+func (sv *SegmentView) showTSAnalysis() {
+	sv.showMessage("Analyzing transport stream...")
+
+	go func() {
+		var content string
+		data, err := sv.readSegment()
+		if err == nil {
+			var analysis *ts.Analysis
+			analysis, err = ts.Analyze(data)
+			if err == nil {
+				content = formatTSAnalysis(analysis)
+			}
+		}
+		if err != nil {
+			content = fmt.Sprintf("[bad]Transport stream analysis failed:[text]\n%s\n", tview.Escape(err.Error()))
+		}
+
+		if sv.ctx.Err() != nil || sv.updateCallback == nil {
+			return
+		}
+		sv.updateCallback(func() {
+			sv.updateContentWithTS(content)
+			sv.showMessage("Transport stream analysis complete")
+		})
+	}()
+}
+
+// updateContentWithTS shows the transport stream analysis
+func (sv *SegmentView) updateContentWithTS(analysis string) {
+	content := fmt.Sprintf(`[heading]Transport Stream Analysis[text]
+
+[label]Original URI:[text]
+%s
+
+[label]Resolved URL:[text]
+%s
+%s
+%s`,
+		sv.segment.URI,
+		sv.resolvedURL,
+		sv.formatDecryption(),
+		analysis)
+
+	sv.textView.SetText(content)
+	sv.textView.SetTitle(" Transport Stream Analysis ").SetBorder(true)
+}
+
+// formatTSAnalysis formats the result of the native transport stream parser
+func formatTSAnalysis(analysis *ts.Analysis) string {
+	var content strings.Builder
+
+	content.WriteString("\n[label]Structure:[text]\n")
+	content.WriteString(fmt.Sprintf("Size: %d bytes, %d packets\n", analysis.Size, analysis.Packets))
+	if analysis.Skipped > 0 {
+		content.WriteString(fmt.Sprintf("Skipped: %d bytes before the first sync byte\n", analysis.Skipped))
+	}
+	content.WriteString(fmt.Sprintf("Starts with PAT: %s\n", formatCheck(analysis.StartsWithPAT)))
+	content.WriteString(fmt.Sprintf("PAT followed by PMT: %s\n", formatCheck(analysis.StartsWithPMT)))
+	if analysis.VideoPID != 0 {
+		content.WriteString(fmt.Sprintf("Starts with keyframe: %s\n", formatCheck(analysis.StartsWithKeyframe)))
+	} else {
+		content.WriteString("Starts with keyframe: n/a (no video)\n")
+	}
+	programs := make([]int, 0, len(analysis.Programs))
+	for program := range analysis.Programs {
+		programs = append(programs, program)
+	}
+	sort.Ints(programs)
+	for _, program := range programs {
+		content.WriteString(fmt.Sprintf("Program %d: PMT on PID 0x%04x\n", program, analysis.Programs[program]))
+	}
+
+	content.WriteString("\n[label]Streams:[text]\n")
+	if len(analysis.Streams) == 0 {
+		content.WriteString("No PMT found\n")
+	}
+	for _, stream := range analysis.Streams {
+		content.WriteString(fmt.Sprintf("PID 0x%04x: [good]%s[text] (%s, stream type 0x%02x)\n",
+			stream.PID, stream.Codec, stream.Kind, stream.StreamType))
+	}
+
+	content.WriteString("\n[label]PIDs:[text]\n")
+	for _, pid := range analysis.PIDs {
+		content.WriteString(fmt.Sprintf("0x%04x %-20s %6d packets", pid.PID, tview.Escape(pid.Kind), pid.Packets))
+		if pid.ContinuityErrors > 0 {
+			content.WriteString(fmt.Sprintf("  [bad]%d CC errors[text]", pid.ContinuityErrors))
+		}
+		content.WriteString("\n")
+		if pid.PTS.Count > 0 {
+			content.WriteString(fmt.Sprintf("       PTS %s - %s, DTS %s - %s (%d PES, %.3fs)\n",
+				formatTimestamp(pid.PTS.First), formatTimestamp(pid.PTS.Last),
+				formatTimestamp(pid.DTS.First), formatTimestamp(pid.DTS.Last),
+				pid.PES, pid.PTS.Duration()))
+		}
+		if pid.Keyframes > 0 {
+			content.WriteString(fmt.Sprintf("       Keyframes: %d\n", pid.Keyframes))
+		}
+	}
+
+	if len(analysis.ContinuityErrors) > 0 {
+		content.WriteString("\n[label]Continuity Errors:[text]\n")
+		for i, cc := range analysis.ContinuityErrors {
+			if i == 20 {
+				content.WriteString(fmt.Sprintf("... and %d more\n", len(analysis.ContinuityErrors)-i))
+				break
+			}
+			content.WriteString(fmt.Sprintf("Packet %d, PID 0x%04x: expected %d, got %d\n", cc.Packet, cc.PID, cc.Expected, cc.Got))
+		}
+	}
+
+	if warnings := analysis.Warnings(); len(warnings) > 0 {
+		content.WriteString("\n[bad]Warnings:[text]\n")
+		for _, warning := range warnings {
+			content.WriteString(fmt.Sprintf("• %s\n", tview.Escape(warning)))
+		}
+	}
+	return content.String()
+}
+
+// formatCheck renders a pass/fail check
+func formatCheck(ok bool) string {
+	if ok {
+		return "[good]yes[text]"
+	}
+	return "[bad]no[text]"
+}
+
+// formatTimestamp shows a 90kHz timestamp as ticks and seconds
+func formatTimestamp(value int64) string {
+	return fmt.Sprintf("%d (%.3fs)", value, float64(value)/ts.Clock)
+}
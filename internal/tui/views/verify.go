@@ -0,0 +1,89 @@
+package views
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/verify"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// findingColors colors findings by severity
+var findingColors = map[string]string{
+	codecs.Error:   "bad",
+	codecs.Warning: "warning",
+	codecs.Info:    "accent",
+}
+
+// verifyCodecs probes the first segment of every variant and reports how its CODECS attribute compares
+func (mv *MasterView) verifyCodecs() {
+	if mv.reportCallback == nil {
+		return
+	}
+	if len(mv.manifest.Variants) == 0 {
+		mv.setStatus("No variants to verify")
+		return
+	}
+
+	mv.setStatus(fmt.Sprintf("Verifying CODECS of %d variants...", len(mv.manifest.Variants)))
+	manifest := mv.manifest
+	go func() {
+		reports := verify.Codecs(mv.ctx, manifest)
+		if mv.ctx.Err() != nil || mv.updateCallback == nil {
+			return
+		}
+		content := formatCodecReports(reports)
+		mv.updateCallback(func() {
+			mv.setStatus("CODECS verification complete")
+			mv.reportCallback(fmt.Sprintf("CODECS Verification - %s", manifest.URL), content)
+		})
+	}()
+}
+
+// formatCodecReports renders a summary line followed by the findings for each variant
+func formatCodecReports(reports []*verify.CodecReport) string {
+	counts := make(map[string]int)
+	for _, report := range reports {
+		counts[report.Severity()]++
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("[label]Variants:[text] %d  [good]OK:[text] %d  [bad]Errors:[text] %d  [warning]Warnings:[text] %d  [accent]Info:[text] %d\n",
+		len(reports), counts[""], counts[codecs.Error], counts[codecs.Warning], counts[codecs.Info]))
+
+	for _, report := range reports {
+		variant := report.Variant
+		status := "[good]OK[text]"
+		if severity := report.Severity(); severity != "" {
+			status = fmt.Sprintf("[%s]%s[text]", findingColors[severity], strings.ToUpper(severity))
+		}
+		content.WriteString(fmt.Sprintf("\n%s [heading]%s[text] (line %d)\n", status, tview.Escape(variant.URI), variant.LineNumber))
+
+		content.WriteString(fmt.Sprintf("  Declared: %s\n", formatCodecList(report.Declared)))
+		for _, probe := range report.Probes {
+			if probe.Err != nil {
+				continue
+			}
+			content.WriteString(fmt.Sprintf("  %s: %s\n", probe.Name, formatCodecList(codecs.Split(strings.Join(probe.Codecs, ",")))))
+			content.WriteString(fmt.Sprintf("    [muted]%s[text]\n", tview.Escape(probe.Segment)))
+		}
+
+		for _, finding := range report.Findings {
+			content.WriteString(fmt.Sprintf("  [%s]%s:[text] %s\n", findingColors[finding.Severity], finding.Severity, tview.Escape(finding.Message)))
+		}
+	}
+	return content.String()
+}
+
+// formatCodecList lists codec strings with their descriptions
+func formatCodecList(list []codecs.Codec) string {
+	if len(list) == 0 {
+		return "[muted]none[text]"
+	}
+	var parts []string
+	for _, codec := range list {
+		parts = append(parts, fmt.Sprintf("%s (%s)", tview.Escape(codec.Value), tview.Escape(codec.Describe())))
+	}
+	return strings.Join(parts, ", ")
+}
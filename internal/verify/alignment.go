@@ -0,0 +1,245 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"math"
+	"sort"
+	"strings"
+)
+
+// alignmentTolerance is how far durations and start times may differ between variants, in seconds
+const alignmentTolerance = 0.001
+
+// maxListedSequences bounds the sequences named in a finding
+const maxListedSequences = 5
+
+// Issues a row of the alignment matrix can have
+const (
+	IssueDuration      = "duration"
+	IssueStart         = "start"
+	IssueDiscontinuity = "discontinuity"
+	IssueMissing       = "missing"
+)
+
+// AlignmentCell is one variant's segment at a media sequence
+type AlignmentCell struct {
+	Segment    *hls.Segment // Nil when the variant doesn't have the sequence
+	Start      float64      // Summed EXTINF durations since the first shared sequence
+	Missing    bool         // The sequence is inside the variant's window but absent
+	Misaligned bool         // The segment differs from the row's reference segment
+}
+
+// AlignmentRow is every variant's segment at one media sequence
+type AlignmentRow struct {
+	Sequence int
+	Cells    []AlignmentCell
+	Issues   []string
+}
+
+// Reference is the first variant's segment the others are compared against, nil if no variant has one
+func (r *AlignmentRow) Reference() *AlignmentCell {
+	for i := range r.Cells {
+		if r.Cells[i].Segment != nil {
+			return &r.Cells[i]
+		}
+	}
+	return nil
+}
+
+// AlignmentReport is the segment matrix of a master playlist's variants, rows by media sequence
+type AlignmentReport struct {
+	Variants []*VariantPlaylist
+	Rows     []AlignmentRow
+	Findings []codecs.Finding
+}
+
+// Misaligned counts the rows with issues
+func (r *AlignmentReport) Misaligned() int {
+	count := 0
+	for _, row := range r.Rows {
+		if len(row.Issues) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// SegmentAlignment loads every variant's media playlist and checks that segments with the same media
+// sequence have the same duration, start time and discontinuities, and that the variants have as many segments
+func SegmentAlignment(ctx context.Context, master *hls.Manifest) *AlignmentReport {
+	return alignPlaylists(LoadVariants(ctx, master))
+}
+
+// alignPlaylists builds the alignment matrix of loaded playlists
+func alignPlaylists(playlists []*VariantPlaylist) *AlignmentReport {
+	report := &AlignmentReport{Variants: playlists}
+	common := commonSequences(playlists)
+
+	segments := make([]map[int]*hls.Segment, len(playlists))
+	starts := make([]map[int]float64, len(playlists))
+	seen := make(map[int]bool)
+	loaded := 0
+	for i, playlist := range playlists {
+		if playlist.Err != nil {
+			report.Findings = append(report.Findings, codecs.Finding{Severity: codecs.Error,
+				Message: fmt.Sprintf("%s: %v", playlist.Variant.URI, playlist.Err)})
+			continue
+		}
+		loaded++
+		segments[i] = make(map[int]*hls.Segment)
+		for j := range playlist.Manifest.Segments {
+			segment := &playlist.Manifest.Segments[j]
+			segments[i][segment.Sequence] = segment
+			seen[segment.Sequence] = true
+		}
+		starts[i] = segmentStarts(playlist.Manifest, common)
+	}
+	comparable := len(common) > 0
+	if !comparable && loaded > 1 {
+		report.Findings = append(report.Findings, codecs.Finding{Severity: codecs.Error,
+			Message: "No media sequence is shared by every variant, so start times can't be compared"})
+	}
+
+	var sequences []int
+	for sequence := range seen {
+		sequences = append(sequences, sequence)
+	}
+	sort.Ints(sequences)
+
+	for _, sequence := range sequences {
+		row := AlignmentRow{Sequence: sequence, Cells: make([]AlignmentCell, len(playlists))}
+		for i, playlist := range playlists {
+			if segments[i] == nil {
+				continue
+			}
+			cell := &row.Cells[i]
+			if cell.Segment = segments[i][sequence]; cell.Segment != nil {
+				cell.Start = starts[i][sequence]
+				continue
+			}
+			list := playlist.Manifest.Segments
+			cell.Missing = len(list) > 0 && sequence > list[0].Sequence && sequence < list[len(list)-1].Sequence
+		}
+		row.Issues = compareRow(&row, comparable)
+		report.Rows = append(report.Rows, row)
+	}
+
+	report.Findings = append(report.Findings, countFinding(playlists)...)
+	for _, issue := range []struct{ kind, message string }{
+		{IssueDuration, "Segment durations differ"},
+		{IssueStart, "Segment start times differ"},
+		{IssueDiscontinuity, "Discontinuities don't line up"},
+		{IssueMissing, "Variants are missing segments"},
+	} {
+		var affected []int
+		for _, row := range report.Rows {
+			for _, kind := range row.Issues {
+				if kind == issue.kind {
+					affected = append(affected, row.Sequence)
+				}
+			}
+		}
+		if len(affected) > 0 {
+			report.Findings = append(report.Findings, codecs.Finding{Severity: codecs.Error,
+				Message: fmt.Sprintf("%s at %d sequences: %s", issue.message, len(affected), formatSequences(affected))})
+		}
+	}
+	return report
+}
+
+// segmentStarts sums the EXTINF durations of a playlist, relative to the first shared sequence so that
+// playlists with different windows line up. Without a shared sequence they start at the first segment.
+func segmentStarts(manifest *hls.Manifest, common []int) map[int]float64 {
+	starts := make(map[int]float64)
+	elapsed, origin := 0.0, 0.0
+	for _, segment := range manifest.Segments {
+		if len(common) > 0 && segment.Sequence == common[0] {
+			origin = elapsed
+		}
+		starts[segment.Sequence] = elapsed
+		elapsed += segment.Duration
+	}
+	for sequence := range starts {
+		starts[sequence] -= origin
+	}
+	return starts
+}
+
+// compareRow marks the segments that differ from the row's reference and lists the row's issues
+func compareRow(row *AlignmentRow, comparable bool) []string {
+	found := make(map[string]bool)
+	reference := row.Reference()
+	for i := range row.Cells {
+		cell := &row.Cells[i]
+		if cell.Missing {
+			found[IssueMissing] = true
+		}
+		if cell.Segment == nil || cell == reference {
+			continue
+		}
+		if math.Abs(cell.Segment.Duration-reference.Segment.Duration) > alignmentTolerance {
+			found[IssueDuration], cell.Misaligned = true, true
+		}
+		if comparable && math.Abs(cell.Start-reference.Start) > alignmentTolerance {
+			found[IssueStart], cell.Misaligned = true, true
+		}
+		if cell.Segment.Discontinuity != reference.Segment.Discontinuity {
+			found[IssueDiscontinuity], cell.Misaligned = true, true
+		}
+	}
+
+	var issues []string
+	for _, kind := range []string{IssueDuration, IssueStart, IssueDiscontinuity, IssueMissing} {
+		if found[kind] {
+			issues = append(issues, kind)
+		}
+	}
+	return issues
+}
+
+// countFinding reports variants with different segment counts. Live windows can be a segment apart
+// depending on when each playlist was fetched, so only ended playlists make it an error.
+func countFinding(playlists []*VariantPlaylist) []codecs.Finding {
+	var counts []string
+	first, differ, ended := -1, false, true
+	for _, playlist := range playlists {
+		if playlist.Err != nil {
+			continue
+		}
+		count := len(playlist.Manifest.Segments)
+		if first < 0 {
+			first = count
+		} else if count != first {
+			differ = true
+		}
+		if !playlist.Manifest.Ended() {
+			ended = false
+		}
+		counts = append(counts, fmt.Sprintf("%s has %d", playlist.Variant.URI, count))
+	}
+	if !differ {
+		return nil
+	}
+
+	severity := codecs.Warning
+	if ended {
+		severity = codecs.Error
+	}
+	return []codecs.Finding{{Severity: severity, Message: "Segment counts differ: " + strings.Join(counts, ", ")}}
+}
+
+// formatSequences lists the first few media sequences
+func formatSequences(sequences []int) string {
+	var parts []string
+	for i, sequence := range sequences {
+		if i == maxListedSequences {
+			parts = append(parts, fmt.Sprintf("and %d more", len(sequences)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d", sequence))
+	}
+	return strings.Join(parts, ", ")
+}
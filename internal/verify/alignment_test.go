@@ -0,0 +1,153 @@
+package verify
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// playlist builds a loaded variant playlist with segments of the given durations from a media sequence.
+// A zero duration leaves the sequence out, a negative one marks a discontinuity.
+func playlist(uri string, sequence int, ended bool, durations ...float64) *VariantPlaylist {
+	manifest := &hls.Manifest{Type: hls.MediaManifest, Sequence: sequence}
+	for i, duration := range durations {
+		if duration == 0 {
+			continue
+		}
+		segment := hls.Segment{URI: fmt.Sprintf("%d.ts", sequence+i), Sequence: sequence + i, Duration: duration}
+		if duration < 0 {
+			segment.Duration, segment.Discontinuity = -duration, true
+		}
+		manifest.Segments = append(manifest.Segments, segment)
+	}
+	if ended {
+		manifest.Tags = append(manifest.Tags, hls.Tag{Name: "#EXT-X-ENDLIST"})
+	}
+	return &VariantPlaylist{Variant: &hls.Variant{URI: uri}, URL: uri, Manifest: manifest}
+}
+
+func TestAlignPlaylists(t *testing.T) {
+	tests := []struct {
+		name      string
+		playlists []*VariantPlaylist
+		issues    map[int][]string // Issues by sequence, rows not listed must be aligned
+		findings  []string         // Severity and message prefix of each finding
+	}{
+		{
+			name: "aligned",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, true, 6, 6, -4),
+				playlist("b.m3u8", 10, true, 6, 6, -4),
+			},
+		},
+		{
+			name: "duration shifts later starts",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, true, 6, 6, 6),
+				playlist("b.m3u8", 10, true, 6, 5.9, 6),
+			},
+			issues: map[int][]string{11: {IssueDuration}, 12: {IssueStart}},
+			findings: []string{
+				"error: Segment durations differ at 1 sequences: 11",
+				"error: Segment start times differ at 1 sequences: 12",
+			},
+		},
+		{
+			name: "discontinuity",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, true, 6, -6),
+				playlist("b.m3u8", 10, true, 6, 6),
+			},
+			issues:   map[int][]string{11: {IssueDiscontinuity}},
+			findings: []string{"error: Discontinuities don't line up"},
+		},
+		{
+			name: "missing segment",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, true, 6, 6, 6),
+				playlist("b.m3u8", 10, true, 6, 0, 6),
+			},
+			issues: map[int][]string{11: {IssueMissing}, 12: {IssueStart}},
+			findings: []string{
+				"error: Segment counts differ: a.m3u8 has 3, b.m3u8 has 2",
+				"error: Segment start times differ at 1 sequences: 12",
+				"error: Variants are missing segments at 1 sequences: 11",
+			},
+		},
+		{
+			name: "live windows a segment apart",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, false, 6, 6, 6),
+				playlist("b.m3u8", 11, false, 6, 6, 6),
+			},
+		},
+		{
+			name: "live window lagging",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, false, 6, 6, 6),
+				playlist("b.m3u8", 10, false, 6, 6),
+			},
+			findings: []string{"warning: Segment counts differ"},
+		},
+		{
+			name: "failed variant",
+			playlists: []*VariantPlaylist{
+				playlist("a.m3u8", 10, true, 6),
+				{Variant: &hls.Variant{URI: "b.m3u8"}, Err: fmt.Errorf("not found")},
+			},
+			findings: []string{"error: b.m3u8: not found"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := alignPlaylists(test.playlists)
+			for _, row := range report.Rows {
+				if want := test.issues[row.Sequence]; !reflect.DeepEqual(row.Issues, want) {
+					t.Errorf("Sequence %d issues = %v, want %v", row.Sequence, row.Issues, want)
+				}
+			}
+			if len(report.Findings) != len(test.findings) {
+				t.Fatalf("Expected %d findings, got %+v", len(test.findings), report.Findings)
+			}
+			for i, want := range test.findings {
+				if got := report.Findings[i].Severity + ": " + report.Findings[i].Message; !strings.HasPrefix(got, want) {
+					t.Errorf("Finding %d = %q, want prefix %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAlignPlaylistsMarksCells(t *testing.T) {
+	report := alignPlaylists([]*VariantPlaylist{
+		playlist("a.m3u8", 10, true, 6, 6),
+		playlist("b.m3u8", 10, true, 6, 4),
+		playlist("c.m3u8", 11, true, 6),
+	})
+	if len(report.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(report.Rows))
+	}
+
+	first := report.Rows[0]
+	if first.Cells[2].Segment != nil || first.Cells[2].Missing {
+		t.Errorf("Sequence 10 is before c's window and shouldn't be missing, got %+v", first.Cells[2])
+	}
+	if first.Cells[0].Start != -6 {
+		t.Errorf("Expected starts relative to the first shared sequence, got %v", first.Cells[0].Start)
+	}
+
+	second := report.Rows[1]
+	if second.Cells[0].Misaligned || !second.Cells[1].Misaligned || second.Cells[2].Misaligned {
+		t.Errorf("Expected only b to be misaligned at sequence 11, got %+v", second.Cells)
+	}
+	if report.Misaligned() != 1 {
+		t.Errorf("Expected 1 misaligned row, got %d", report.Misaligned())
+	}
+	if report.Findings[0].Severity != codecs.Error {
+		t.Errorf("Expected different counts of ended playlists to be an error, got %+v", report.Findings[0])
+	}
+}
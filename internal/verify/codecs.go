@@ -0,0 +1,156 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"sort"
+	"sync"
+)
+
+// concurrency bounds how many playlists are probed at once
+const concurrency = 4
+
+// CodecReport compares the CODECS attribute of a variant with the codecs found in its media
+type CodecReport struct {
+	Variant  *hls.Variant
+	Declared []codecs.Codec
+	Probes   []*Probe // The variant's own media, then its audio rendition if it has one
+	Findings []codecs.Finding
+}
+
+// Actual returns the codecs found across the report's probes
+func (r *CodecReport) Actual() []codecs.Codec {
+	var actual []codecs.Codec
+	for _, probe := range r.Probes {
+		for _, value := range probe.Codecs {
+			actual = append(actual, codecs.Parse(value))
+		}
+	}
+	return actual
+}
+
+// Severity is the worst severity among the findings, empty when everything matches
+func (r *CodecReport) Severity() string {
+	severity := ""
+	for _, finding := range r.Findings {
+		switch {
+		case finding.Severity == codecs.Error:
+			return codecs.Error
+		case finding.Severity == codecs.Warning:
+			severity = codecs.Warning
+		case severity == "":
+			severity = finding.Severity
+		}
+	}
+	return severity
+}
+
+// Codecs verifies the CODECS attribute of every variant in a master playlist
+func Codecs(ctx context.Context, master *hls.Manifest) []*CodecReport {
+	// Audio groups are shared by many variants, probe each one once
+	groups := audioGroups(master)
+	audio := make(map[string]*Probe)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for group, rendition := range groups {
+		wg.Add(1)
+		go func(group string, rendition *hls.Rendition) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			probe := ProbePlaylist(ctx, fmt.Sprintf("Audio %q", rendition.Name), master.ResolveURL(rendition.URI))
+			mu.Lock()
+			audio[group] = probe
+			mu.Unlock()
+		}(group, rendition)
+	}
+	wg.Wait()
+
+	reports := make([]*CodecReport, len(master.Variants))
+	for i := range master.Variants {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			variant := &master.Variants[i]
+			probe := ProbePlaylist(ctx, "Variant", master.ResolveURL(variant.URI))
+			reports[i] = compareVariant(variant, probe, audio[variant.Attributes["AUDIO"]])
+		}(i)
+	}
+	wg.Wait()
+	return reports
+}
+
+// audioGroups picks the rendition to probe for each audio group with its own playlist, preferring the default
+func audioGroups(master *hls.Manifest) map[string]*hls.Rendition {
+	groups := make(map[string]*hls.Rendition)
+	for i := range master.Renditions {
+		rendition := &master.Renditions[i]
+		if rendition.Type != "AUDIO" || rendition.URI == "" {
+			continue
+		}
+		if current, ok := groups[rendition.GroupID]; !ok || (rendition.Default && !current.Default) {
+			groups[rendition.GroupID] = rendition
+		}
+	}
+	return groups
+}
+
+// compareVariant builds the report for one variant from its probes
+func compareVariant(variant *hls.Variant, probe, audio *Probe) *CodecReport {
+	report := &CodecReport{
+		Variant:  variant,
+		Declared: codecs.Split(variant.Codecs),
+		Probes:   []*Probe{probe},
+	}
+	if audio != nil {
+		report.Probes = append(report.Probes, audio)
+	}
+
+	if variant.Codecs == "" {
+		report.Findings = append(report.Findings, codecs.Finding{Severity: codecs.Error, Message: "Variant has no CODECS attribute"})
+	}
+
+	adts := false
+	declared := report.Declared
+	for _, probe := range report.Probes {
+		if probe.Err != nil {
+			report.Findings = append(report.Findings, codecs.Finding{
+				Severity: codecs.Error,
+				Message:  fmt.Sprintf("Could not read %s media: %v", probe.Name, probe.Err),
+			})
+			if probe != audio {
+				return report
+			}
+			// Without the rendition the declared audio can't be checked
+			declared = withoutKind(declared, codecs.Audio)
+		}
+		adts = adts || probe.ADTS
+	}
+
+	if variant.Codecs != "" {
+		report.Findings = append(report.Findings, codecs.Compare(declared, report.Actual(), adts)...)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severityRank[report.Findings[i].Severity] < severityRank[report.Findings[j].Severity]
+	})
+	return report
+}
+
+// severityRank orders findings worst first
+var severityRank = map[string]int{codecs.Error: 0, codecs.Warning: 1, codecs.Info: 2}
+
+// withoutKind drops the codecs of a kind
+func withoutKind(list []codecs.Codec, kind string) []codecs.Codec {
+	var filtered []codecs.Codec
+	for _, codec := range list {
+		if codec.Kind() != kind {
+			filtered = append(filtered, codec)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,97 @@
+package verify
+
+import (
+	"context"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes test fixtures into a temporary directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// packedAAC is an ID3 tag followed by an ADTS header for AAC-LC
+const packedAAC = "ID3\x04\x00\x00\x00\x00\x00\x02\x00\x00\xff\xf1\x50\x80"
+
+func TestPackedAudioCodec(t *testing.T) {
+	tests := map[string]string{
+		packedAAC:                         "mp4a.40.2",
+		"\xff\xf1\x10\x80":                "mp4a.40.1",
+		"\x0b\x77\x00\x00\x00\x40":        "ac-3",
+		"\x0b\x77\x00\x00\x00\x80":        "ec-3",
+		"ID3\x04\x00\x00\x00\x00\x00\x7f": "",
+		"not audio":                       "",
+	}
+	for data, want := range tests {
+		if got := packedAudioCodec([]byte(data)); got != want {
+			t.Errorf("packedAudioCodec(%q) = %q, want %q", data, got, want)
+		}
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"master.m3u8": `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,URI="audio.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=64000,CODECS="mp4a.40.5"
+audio.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=64000,CODECS="mp4a.40.2,ac-3"
+audio.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=1000000,CODECS="avc1.64001f,mp4a.40.2",AUDIO="aac"
+video.m3u8
+`,
+		"audio.m3u8": `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.0,
+audio.aac
+#EXT-X-ENDLIST
+`,
+		"video.m3u8": `#EXTM3U
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.0,
+missing.ts
+#EXT-X-ENDLIST
+`,
+		"audio.aac": packedAAC,
+	})
+
+	master, err := hls.NewParser().ParseFromFile(filepath.Join(dir, "master.m3u8"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	reports := Codecs(context.Background(), master)
+	if len(reports) != 3 {
+		t.Fatalf("Expected 3 reports, got %d", len(reports))
+	}
+
+	// ADTS can't signal HE-AAC, so the declared mp4a.40.5 is only informational
+	if got := reports[0].Severity(); got != codecs.Info {
+		t.Errorf("Expected an info finding for implicit HE-AAC, got %q: %+v", got, reports[0].Findings)
+	}
+	if actual := reports[0].Actual(); len(actual) != 1 || actual[0].Value != "mp4a.40.2" {
+		t.Errorf("Unexpected actual codecs: %+v", actual)
+	}
+
+	if got := reports[1].Severity(); got != codecs.Warning {
+		t.Errorf("Expected a warning for the undeclared ac-3 stream, got %q: %+v", got, reports[1].Findings)
+	}
+
+	// The video segment is missing, the audio rendition is still probed
+	if len(reports[2].Probes) != 2 || reports[2].Probes[1].Err != nil {
+		t.Errorf("Expected the audio rendition to be probed: %+v", reports[2].Probes)
+	}
+	if reports[2].Severity() != codecs.Error || !strings.Contains(reports[2].Findings[0].Message, "Could not read Variant media") {
+		t.Errorf("Expected a read error, got %+v", reports[2].Findings)
+	}
+}
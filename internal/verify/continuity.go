@@ -0,0 +1,325 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/mp4"
+	"github.com/soldiermoth/pantui/internal/ts"
+	"math"
+	"sync"
+)
+
+// ptsWrap is the period of 33 bit MPEG-TS timestamps in seconds
+const ptsWrap = float64(1<<33) / ts.Clock
+
+// jumpThreshold is how far timestamps must move to count as a jump rather than a gap or overlap
+const jumpThreshold = 1.0
+
+// TrackTiming is when one elementary stream starts and ends within a segment, in seconds
+type TrackTiming struct {
+	Kind  string // video or audio
+	ID    int    // PID for MPEG-TS, track ID for fMP4
+	Start float64
+	End   float64 // Last timestamp plus one frame
+	Frame float64 // Frame duration, estimated from the timestamp spacing
+	wrap  float64 // Period after which the timestamps wrap, 0 when they don't
+}
+
+// Name identifies the track in messages
+func (t TrackTiming) Name() string {
+	if t.wrap > 0 {
+		return fmt.Sprintf("%s PID 0x%04x", t.Kind, t.ID)
+	}
+	return fmt.Sprintf("%s track %d", t.Kind, t.ID)
+}
+
+// SegmentTiming is the timing of every track of a segment
+type SegmentTiming struct {
+	Segment *hls.Segment
+	Tracks  []TrackTiming
+	Err     error
+}
+
+// AVOffset is how far the first audio track starts after the first video track
+func (s *SegmentTiming) AVOffset() (float64, bool) {
+	video, audio := s.track("video", -1), s.track("audio", -1)
+	if video == nil || audio == nil {
+		return 0, false
+	}
+	return delta(audio.Start, video.Start, video.wrap), true
+}
+
+// track finds a track by kind, preferring the given ID
+func (s *SegmentTiming) track(kind string, id int) *TrackTiming {
+	var found *TrackTiming
+	for i := range s.Tracks {
+		track := &s.Tracks[i]
+		if track.Kind != kind {
+			continue
+		}
+		if track.ID == id {
+			return track
+		}
+		if found == nil {
+			found = track
+		}
+	}
+	return found
+}
+
+// TimingIssue is a timestamp problem between a segment and the one before it
+type TimingIssue struct {
+	Sequence int
+	Track    string
+	Severity string
+	Kind     string // gap, overlap, jump, drift or discontinuity
+	Delta    float64
+	Message  string
+}
+
+// ContinuityReport is the timing of a run of consecutive segments and the issues found between them
+type ContinuityReport struct {
+	Segments []*SegmentTiming
+	Issues   []TimingIssue
+}
+
+// Continuity reads count segments starting at index and checks that each track's timestamps
+// continue where the previous segment ended, jumping only at EXT-X-DISCONTINUITY
+func Continuity(ctx context.Context, manifest *hls.Manifest, index, count int) *ContinuityReport {
+	if index < 0 {
+		index = 0
+	}
+	if index+count > len(manifest.Segments) {
+		count = len(manifest.Segments) - index
+	}
+
+	report := &ContinuityReport{Segments: make([]*SegmentTiming, count)}
+	inits := newInitCache()
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			segment := &manifest.Segments[index+i]
+			timing := &SegmentTiming{Segment: segment}
+			timing.Tracks, timing.Err = segmentTiming(ctx, manifest, segment, inits)
+			report.Segments[i] = timing
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < len(report.Segments); i++ {
+		report.Issues = append(report.Issues, compareTiming(report.Segments[i-1], report.Segments[i])...)
+	}
+	return report
+}
+
+// segmentTiming reads a segment and measures its tracks
+func segmentTiming(ctx context.Context, manifest *hls.Manifest, segment *hls.Segment, inits *initCache) ([]TrackTiming, error) {
+	data, err := ReadSegment(ctx, manifest, segment)
+	if err != nil {
+		return nil, err
+	}
+	if ts.IsTS(data) {
+		analysis, err := ts.Analyze(data)
+		if err != nil {
+			return nil, err
+		}
+		return transportTiming(analysis), nil
+	}
+
+	if segment.Map == nil || segment.Map.URI == "" {
+		return nil, fmt.Errorf("segment is neither MPEG-TS nor fMP4 with an init fragment")
+	}
+	tracks, err := inits.tracks(ctx, manifest.ResolveURL(segment.Map.URI), segment.Map.ByteRange)
+	if err != nil {
+		return nil, err
+	}
+	boxes, err := mp4.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return fragmentTiming(tracks, mp4.Fragments(boxes, tracks)), nil
+}
+
+// transportTiming measures the PTS range of each audio and video PID
+func transportTiming(analysis *ts.Analysis) []TrackTiming {
+	var tracks []TrackTiming
+	for _, stats := range analysis.PIDs {
+		stream := analysis.Stream(stats.PID)
+		if stream == nil || (stream.Kind != "video" && stream.Kind != "audio") || stats.PTS.Count == 0 {
+			continue
+		}
+		pts := stats.PTS
+		track := TrackTiming{
+			Kind:  stream.Kind,
+			ID:    stats.PID,
+			Start: float64(pts.Min) / ts.Clock,
+			wrap:  ptsWrap,
+		}
+		if pts.Count > 1 {
+			track.Frame = float64(pts.Max-pts.Min) / float64(pts.Count-1) / ts.Clock
+		}
+		track.End = float64(pts.Max)/ts.Clock + track.Frame
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
+
+// fragmentTiming measures the decode time range of each audio and video track, merging multiple fragments
+func fragmentTiming(tracks []mp4.Track, fragments []mp4.Fragment) []TrackTiming {
+	kinds := map[string]string{"vide": "video", "soun": "audio"}
+	var timings []TrackTiming
+	for _, track := range tracks {
+		kind := kinds[track.Handler]
+		if kind == "" || track.Timescale == 0 {
+			continue
+		}
+		timing := TrackTiming{Kind: kind, ID: track.ID}
+		samples, found := 0, false
+		for _, fragment := range fragments {
+			if fragment.TrackID != track.ID {
+				continue
+			}
+			start := float64(fragment.BaseDecodeTime) / float64(track.Timescale)
+			end := float64(fragment.BaseDecodeTime+fragment.Duration) / float64(track.Timescale)
+			if !found || start < timing.Start {
+				timing.Start = start
+			}
+			if !found || end > timing.End {
+				timing.End = end
+			}
+			samples += fragment.Samples
+			found = true
+		}
+		if !found {
+			continue
+		}
+		if samples > 0 {
+			timing.Frame = (timing.End - timing.Start) / float64(samples)
+		}
+		timings = append(timings, timing)
+	}
+	return timings
+}
+
+// compareTiming checks that each track of a segment continues from the previous segment
+func compareTiming(previous, current *SegmentTiming) []TimingIssue {
+	if previous.Err != nil || current.Err != nil {
+		return nil
+	}
+	var issues []TimingIssue
+	sequence := current.Segment.Sequence
+	discontinuity := current.Segment.Discontinuity
+
+	for _, track := range current.Tracks {
+		before := previous.track(track.Kind, track.ID)
+		if before == nil {
+			continue
+		}
+		d := delta(track.Start, before.End, track.wrap)
+		tolerance := math.Max(math.Max(track.Frame, before.Frame)/2, 0.001)
+		if math.Abs(d) <= tolerance {
+			continue
+		}
+
+		issue := TimingIssue{Sequence: sequence, Track: track.Name(), Delta: d}
+		switch {
+		case discontinuity:
+			issue.Severity, issue.Kind = codecs.Info, "discontinuity"
+			issue.Message = fmt.Sprintf("Timestamps move by %s at EXT-X-DISCONTINUITY", formatDelta(d))
+		case math.Abs(d) > jumpThreshold:
+			issue.Severity, issue.Kind = codecs.Error, "jump"
+			issue.Message = fmt.Sprintf("Timestamps jump by %s without EXT-X-DISCONTINUITY", formatDelta(d))
+		case d > 0:
+			issue.Severity, issue.Kind = codecs.Warning, "gap"
+			issue.Message = fmt.Sprintf("Gap of %s after the previous segment", formatDelta(d))
+		default:
+			issue.Severity, issue.Kind = codecs.Warning, "overlap"
+			issue.Message = fmt.Sprintf("Overlaps the previous segment by %s", formatDelta(-d))
+		}
+		issues = append(issues, issue)
+	}
+
+	// Audio frames don't line up with segment boundaries, so the offset may move by up to one audio frame
+	offset, ok := current.AVOffset()
+	previousOffset, previousOK := previous.AVOffset()
+	if ok && previousOK && !discontinuity {
+		drift := offset - previousOffset
+		tolerance := 0.05
+		if audio := current.track("audio", -1); audio.Frame > 0 {
+			tolerance = audio.Frame + 0.001
+		}
+		if math.Abs(drift) > tolerance {
+			issues = append(issues, TimingIssue{
+				Sequence: sequence,
+				Track:    "A/V",
+				Severity: codecs.Warning,
+				Kind:     "drift",
+				Delta:    drift,
+				Message:  fmt.Sprintf("Audio/video start offset drifted by %s to %s", formatDelta(drift), formatDelta(offset)),
+			})
+		}
+	}
+	return issues
+}
+
+// delta is a - b, taking the shortest way around when timestamps wrap
+func delta(a, b, wrap float64) float64 {
+	d := a - b
+	if wrap > 0 {
+		d = math.Mod(d, wrap)
+		if d >= wrap/2 {
+			d -= wrap
+		} else if d < -wrap/2 {
+			d += wrap
+		}
+	}
+	return d
+}
+
+// formatDelta formats a time difference in milliseconds, or seconds when large
+func formatDelta(seconds float64) string {
+	if math.Abs(seconds) >= 1 {
+		return fmt.Sprintf("%.3fs", seconds)
+	}
+	return fmt.Sprintf("%.1fms", seconds*1000)
+}
+
+// initCache reads each init fragment once
+type initCache struct {
+	mu     sync.Mutex
+	loaded map[string][]mp4.Track
+}
+
+// newInitCache creates an empty init fragment cache
+func newInitCache() *initCache {
+	return &initCache{loaded: make(map[string][]mp4.Track)}
+}
+
+// tracks returns the tracks of an init fragment, reading it on first use
+func (c *initCache) tracks(ctx context.Context, initURL, byteRange string) ([]mp4.Track, error) {
+	key := initURL + "@" + byteRange
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tracks, ok := c.loaded[key]; ok {
+		return tracks, nil
+	}
+	data, err := fetch.ReadContext(ctx, initURL, byteRange)
+	if err != nil {
+		return nil, fmt.Errorf("init fragment %s: %v", initURL, err)
+	}
+	boxes, err := mp4.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("init fragment %s: %v", initURL, err)
+	}
+	tracks := mp4.Tracks(boxes)
+	c.loaded[key] = tracks
+	return tracks, nil
+}
@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// timing builds a segment with 25fps video and 1024 sample 48kHz audio starting at the given times
+func timing(sequence int, discontinuity bool, video, audio float64) *SegmentTiming {
+	const frame, audioFrame = 0.04, 1024.0 / 48000
+	return &SegmentTiming{
+		Segment: &hls.Segment{Sequence: sequence, Discontinuity: discontinuity, Duration: 2},
+		Tracks: []TrackTiming{
+			{Kind: "video", ID: 0x100, Start: video, End: video + 2, Frame: frame, wrap: ptsWrap},
+			{Kind: "audio", ID: 0x101, Start: audio, End: audio + 2, Frame: audioFrame, wrap: ptsWrap},
+		},
+	}
+}
+
+func TestCompareTiming(t *testing.T) {
+	tests := []struct {
+		name    string
+		current *SegmentTiming
+		want    []string // Kind of each issue
+	}{
+		{"continuous", timing(2, false, 12, 12.01), nil},
+		{"within half a frame", timing(2, false, 12.015, 12.01), nil},
+		{"gap", timing(2, false, 12.2, 12.21), []string{"gap", "gap"}},
+		{"overlap", timing(2, false, 11.9, 11.91), []string{"overlap", "overlap"}},
+		{"jump", timing(2, false, 30, 30.01), []string{"jump", "jump"}},
+		{"discontinuity", timing(2, true, 30, 30.5), []string{"discontinuity", "discontinuity"}},
+		{"drift", timing(2, false, 12, 12.1), []string{"gap", "drift"}},
+	}
+
+	previous := timing(1, false, 10, 10.01)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues := compareTiming(previous, test.current)
+			if len(issues) != len(test.want) {
+				t.Fatalf("Expected %v, got %+v", test.want, issues)
+			}
+			for i, kind := range test.want {
+				if issues[i].Kind != kind {
+					t.Errorf("Issue %d: expected %s, got %+v", i, kind, issues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeltaWraps(t *testing.T) {
+	// The next segment starts just after the 33 bit PTS wrapped
+	if d := delta(0.02, ptsWrap-0.02, ptsWrap); math.Abs(d-0.04) > 1e-6 {
+		t.Errorf("Expected 40ms across the wrap, got %f", d)
+	}
+	if d := delta(5, 7, 0); d != -2 {
+		t.Errorf("Expected -2 without wrapping, got %f", d)
+	}
+}
+
+func TestContinuityCancelled(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	manifest := &hls.Manifest{URL: server.URL + "/media.m3u8", BaseURL: server.URL + "/"}
+	for i := 0; i < 3; i++ {
+		manifest.Segments = append(manifest.Segments, hls.Segment{URI: fmt.Sprintf("seg%d.ts", i), Sequence: i})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := Continuity(ctx, manifest, 0, 3)
+	for _, timing := range report.Segments {
+		if timing.Err == nil {
+			t.Errorf("Expected segment %d to fail once the check is cancelled", timing.Segment.Sequence)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("Expected no requests after cancelling, got %d", n)
+	}
+}
@@ -0,0 +1,197 @@
+package verify
+
+import (
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/mp4"
+	"github.com/soldiermoth/pantui/internal/nal"
+	"github.com/soldiermoth/pantui/internal/ts"
+	"sort"
+)
+
+// Frame is a video frame in decode order, with times in seconds
+type Frame struct {
+	PTS      float64
+	DTS      float64
+	Size     int
+	Type     string // I, P or B, empty when the slice header couldn't be read
+	Keyframe bool
+}
+
+// GOP is a run of frames starting at a keyframe, or the frames before the first keyframe
+type GOP struct {
+	Start    float64 // PTS of the first frame
+	Frames   int
+	Duration float64
+	Keyframe bool // False for leading frames that don't start with a keyframe
+}
+
+// FrameAnalysis is the frame structure of a segment's first video track
+type FrameAnalysis struct {
+	Track  string
+	Frames []Frame
+	GOPs   []GOP
+}
+
+// StartsWithKeyframe reports whether the first frame in decode order is a keyframe
+func (f *FrameAnalysis) StartsWithKeyframe() bool {
+	return len(f.Frames) > 0 && f.Frames[0].Keyframe
+}
+
+// Keyframes lists the presentation times of the keyframes
+func (f *FrameAnalysis) Keyframes() []float64 {
+	var times []float64
+	for _, frame := range f.Frames {
+		if frame.Keyframe {
+			times = append(times, frame.PTS)
+		}
+	}
+	return times
+}
+
+// FrameDuration is the median spacing of the presentation times, 0 with fewer than two frames
+func (f *FrameAnalysis) FrameDuration() float64 {
+	times := make([]float64, len(f.Frames))
+	for i, frame := range f.Frames {
+		times[i] = frame.PTS
+	}
+	sort.Float64s(times)
+	var spacing []float64
+	for i := 1; i < len(times); i++ {
+		spacing = append(spacing, times[i]-times[i-1])
+	}
+	if len(spacing) == 0 {
+		return 0
+	}
+	sort.Float64s(spacing)
+	return spacing[len(spacing)/2]
+}
+
+// Start is the earliest presentation time
+func (f *FrameAnalysis) Start() float64 {
+	start := 0.0
+	for i, frame := range f.Frames {
+		if i == 0 || frame.PTS < start {
+			start = frame.PTS
+		}
+	}
+	return start
+}
+
+// Counts tallies the frames by type
+func (f *FrameAnalysis) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, frame := range f.Frames {
+		counts[frame.Type]++
+	}
+	return counts
+}
+
+// SegmentFrames lists the video frames of an MPEG-TS segment, or of an fMP4 segment with its init fragment
+func SegmentFrames(init, data []byte) (*FrameAnalysis, error) {
+	if ts.IsTS(data) {
+		analysis, err := ts.Analyze(data)
+		if err != nil {
+			return nil, err
+		}
+		stream := analysis.Stream(analysis.VideoPID)
+		if stream == nil {
+			return nil, fmt.Errorf("segment has no video stream")
+		}
+		frames := &FrameAnalysis{Track: fmt.Sprintf("%s PID 0x%04x", stream.Codec, stream.PID)}
+		for _, frame := range analysis.Frames {
+			frames.Frames = append(frames.Frames, Frame{
+				PTS:      float64(frame.PTS) / ts.Clock,
+				DTS:      float64(frame.DTS) / ts.Clock,
+				Size:     frame.Size,
+				Type:     frame.Type,
+				Keyframe: frame.Keyframe,
+			})
+		}
+		frames.GOPs = groupFrames(frames)
+		return frames, nil
+	}
+
+	if len(init) == 0 {
+		return nil, fmt.Errorf("segment is neither MPEG-TS nor fMP4 with an init fragment")
+	}
+	initBoxes, err := mp4.Parse(init)
+	if err != nil {
+		return nil, fmt.Errorf("init fragment: %v", err)
+	}
+	var video *mp4.Track
+	tracks := mp4.Tracks(initBoxes)
+	for i := range tracks {
+		if tracks[i].Handler == "vide" && tracks[i].Timescale > 0 {
+			video = &tracks[i]
+			break
+		}
+	}
+	if video == nil {
+		return nil, fmt.Errorf("init fragment has no video track")
+	}
+
+	boxes, err := mp4.Parse(data)
+	if err != nil && len(boxes) == 0 {
+		return nil, err
+	}
+	frames := &FrameAnalysis{Track: fmt.Sprintf("%s track %d", video.Format, video.ID)}
+	hevc := video.Format == "hvc1" || video.Format == "hev1" || video.Format == "dvh1" || video.Format == "dvhe"
+	timescale := float64(video.Timescale)
+	extraBits := 0
+	for _, sample := range mp4.Samples(data, boxes, tracks) {
+		if sample.TrackID != video.ID {
+			continue
+		}
+		frame := Frame{
+			DTS:      float64(sample.DecodeTime) / timescale,
+			PTS:      float64(int64(sample.DecodeTime)+sample.CompositionOffset) / timescale,
+			Size:     int(sample.Size),
+			Keyframe: sample.Sync,
+		}
+		if video.LengthSize > 0 && sample.Data != nil {
+			units := nal.LengthPrefixed(sample.Data, video.LengthSize)
+			if bits := nal.ExtraSliceHeaderBits(units); bits >= 0 {
+				extraBits = bits
+			}
+			frame.Type = nal.SliceType(hevc, units, extraBits)
+		}
+		// Without a readable slice header a sync sample is still an intra frame
+		if frame.Type == "" && sample.Sync {
+			frame.Type = nal.I
+		}
+		frames.Frames = append(frames.Frames, frame)
+	}
+	if len(frames.Frames) == 0 {
+		return nil, fmt.Errorf("no samples found for video track %d", video.ID)
+	}
+	frames.GOPs = groupFrames(frames)
+	return frames, nil
+}
+
+// groupFrames splits the frames at each keyframe. A GOP lasts until the next keyframe's presentation time,
+// the last one until the segment's last frame ends.
+func groupFrames(f *FrameAnalysis) []GOP {
+	var gops []GOP
+	for i, frame := range f.Frames {
+		if i == 0 || frame.Keyframe {
+			gops = append(gops, GOP{Start: frame.PTS, Keyframe: frame.Keyframe})
+		}
+		gops[len(gops)-1].Frames++
+	}
+
+	end := 0.0
+	for _, frame := range f.Frames {
+		if frame.PTS > end {
+			end = frame.PTS
+		}
+	}
+	end += f.FrameDuration()
+	for i := range gops {
+		next := end
+		if i+1 < len(gops) {
+			next = gops[i+1].Start
+		}
+		gops[i].Duration = next - gops[i].Start
+	}
+	return gops
+}
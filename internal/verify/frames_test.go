@@ -0,0 +1,67 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+// frames builds a 25fps analysis without B-frames, with keyframes at the given frame indexes
+func frames(start float64, count int, keyframes ...int) *FrameAnalysis {
+	analysis := &FrameAnalysis{Track: "H.264 PID 0x0100"}
+	for i := 0; i < count; i++ {
+		frame := Frame{PTS: start + float64(i)*0.04, Type: "P"}
+		frame.DTS = frame.PTS
+		for _, k := range keyframes {
+			if k == i {
+				frame.Keyframe, frame.Type = true, "I"
+			}
+		}
+		analysis.Frames = append(analysis.Frames, frame)
+	}
+	analysis.GOPs = groupFrames(analysis)
+	return analysis
+}
+
+func TestGroupFrames(t *testing.T) {
+	gops := frames(10, 50, 2, 27).GOPs
+	if len(gops) != 3 {
+		t.Fatalf("Expected leading frames and 2 GOPs, got %+v", gops)
+	}
+	if gops[0].Keyframe || gops[0].Frames != 2 {
+		t.Errorf("Expected 2 leading frames, got %+v", gops[0])
+	}
+	if !gops[1].Keyframe || gops[1].Frames != 25 || gops[1].Duration < 0.999 || gops[1].Duration > 1.001 {
+		t.Errorf("Expected a 1s GOP of 25 frames, got %+v", gops[1])
+	}
+	if gops[2].Frames != 23 || gops[2].Duration < 0.919 || gops[2].Duration > 0.921 {
+		t.Errorf("Expected the last GOP to run to the end of the segment, got %+v", gops[2])
+	}
+}
+
+func TestCompareKeyframes(t *testing.T) {
+	reference := &VariantFrames{Frames: frames(10, 50, 0, 25)}
+	tests := []struct {
+		name   string
+		frames *FrameAnalysis
+		want   []string
+	}{
+		{"aligned", frames(10, 50, 0, 25), nil},
+		{"extra keyframe", frames(10, 50, 0, 10, 25), []string{"warning: Keyframes the reference doesn't have at 10.400s"}},
+		{"missing keyframe", frames(10, 50, 0), []string{"warning: No keyframe where the reference has one at 11.000s"}},
+		{"no keyframe at start", frames(10, 50, 25), []string{"error: Segment does not start", "warning: No keyframe where the reference has one at 10.000s"}},
+		{"shifted", frames(10.5, 50, 0, 25), []string{"error: Segment starts at 10.500s", "warning: No keyframe", "warning: Keyframes"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings := compareKeyframes(reference, &VariantFrames{Frames: test.frames})
+			if len(findings) != len(test.want) {
+				t.Fatalf("Expected %d findings, got %+v", len(test.want), findings)
+			}
+			for i, want := range test.want {
+				if got := findings[i].Severity + ": " + findings[i].Message; !strings.HasPrefix(got, want) {
+					t.Errorf("Finding %d = %q, want prefix %q", i, got, want)
+				}
+			}
+		})
+	}
+}
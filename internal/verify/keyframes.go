@@ -0,0 +1,164 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/codecs"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"math"
+	"strings"
+	"sync"
+)
+
+// VariantFrames is the frame structure of one variant's segment at the checked media sequence
+type VariantFrames struct {
+	*VariantPlaylist
+	Segment  *hls.Segment
+	Frames   *FrameAnalysis
+	Err      error
+	Findings []codecs.Finding
+}
+
+// KeyframeReport compares keyframe positions across variants at one media sequence
+type KeyframeReport struct {
+	Sequence  int
+	Variants  []*VariantFrames
+	Reference *VariantFrames // The variant the others are compared against
+}
+
+// KeyframeAlignment reads the segment with the given media sequence from every variant and checks that
+// they all start with a keyframe and place their keyframes at the same times. A negative sequence picks
+// the first sequence every variant has.
+func KeyframeAlignment(ctx context.Context, master *hls.Manifest, sequence int) (*KeyframeReport, error) {
+	playlists := LoadVariants(ctx, master)
+	if sequence < 0 {
+		common := commonSequences(playlists)
+		if len(common) == 0 {
+			return nil, fmt.Errorf("no media sequence is shared by every variant")
+		}
+		sequence = common[0]
+	}
+
+	report := &KeyframeReport{Sequence: sequence, Variants: make([]*VariantFrames, len(playlists))}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i, playlist := range playlists {
+		wg.Add(1)
+		go func(i int, playlist *VariantPlaylist) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			report.Variants[i] = variantFrames(ctx, playlist, sequence)
+		}(i, playlist)
+	}
+	wg.Wait()
+
+	for _, variant := range report.Variants {
+		if variant.Err == nil {
+			report.Reference = variant
+			break
+		}
+	}
+	for _, variant := range report.Variants {
+		variant.Findings = compareKeyframes(report.Reference, variant)
+	}
+	return report, nil
+}
+
+// variantFrames reads the frames of a variant's segment
+func variantFrames(ctx context.Context, playlist *VariantPlaylist, sequence int) *VariantFrames {
+	frames := &VariantFrames{VariantPlaylist: playlist}
+	if playlist.Err != nil {
+		frames.Err = playlist.Err
+		return frames
+	}
+	frames.Segment = playlist.Segment(sequence)
+	if frames.Segment == nil {
+		frames.Err = fmt.Errorf("playlist has no segment with media sequence %d", sequence)
+		return frames
+	}
+
+	manifest := playlist.Manifest
+	data, err := ReadSegment(ctx, manifest, frames.Segment)
+	if err != nil {
+		frames.Err = err
+		return frames
+	}
+	var init []byte
+	if m := frames.Segment.Map; m != nil && m.URI != "" {
+		if init, err = fetch.ReadContext(ctx, manifest.ResolveURL(m.URI), m.ByteRange); err != nil {
+			frames.Err = fmt.Errorf("init fragment: %v", err)
+			return frames
+		}
+	}
+	frames.Frames, frames.Err = SegmentFrames(init, data)
+	return frames
+}
+
+// compareKeyframes checks a variant's keyframes against the reference variant's
+func compareKeyframes(reference, variant *VariantFrames) []codecs.Finding {
+	if variant.Err != nil {
+		return []codecs.Finding{{Severity: codecs.Error, Message: variant.Err.Error()}}
+	}
+
+	var findings []codecs.Finding
+	frames := variant.Frames
+	if !frames.StartsWithKeyframe() {
+		findings = append(findings, codecs.Finding{Severity: codecs.Error,
+			Message: "Segment does not start with a keyframe, so players can't switch to this variant here"})
+	}
+	if variant == reference {
+		return findings
+	}
+
+	// Frame rates may differ between rungs, so match within half of the shorter frame
+	tolerance := 0.001
+	if duration := math.Min(frames.FrameDuration(), reference.Frames.FrameDuration()); duration > 0 {
+		tolerance = duration / 2
+	}
+
+	if d := frames.Start() - reference.Frames.Start(); math.Abs(d) > tolerance {
+		findings = append(findings, codecs.Finding{Severity: codecs.Error,
+			Message: fmt.Sprintf("Segment starts at %.3fs, %s from the reference variant", frames.Start(), formatDelta(d))})
+	}
+
+	missing := unmatched(reference.Frames.Keyframes(), frames.Keyframes(), tolerance)
+	extra := unmatched(frames.Keyframes(), reference.Frames.Keyframes(), tolerance)
+	if len(missing) > 0 {
+		findings = append(findings, codecs.Finding{Severity: codecs.Warning,
+			Message: fmt.Sprintf("No keyframe where the reference has one at %s", formatTimes(missing))})
+	}
+	if len(extra) > 0 {
+		findings = append(findings, codecs.Finding{Severity: codecs.Warning,
+			Message: fmt.Sprintf("Keyframes the reference doesn't have at %s", formatTimes(extra))})
+	}
+	return findings
+}
+
+// unmatched lists the times in a with no time in b within the tolerance
+func unmatched(a, b []float64, tolerance float64) []float64 {
+	var missing []float64
+	for _, t := range a {
+		found := false
+		for _, other := range b {
+			if math.Abs(t-other) <= tolerance {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// formatTimes lists times in seconds
+func formatTimes(times []float64) string {
+	parts := make([]string, len(times))
+	for i, t := range times {
+		parts[i] = fmt.Sprintf("%.3fs", t)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,153 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"github.com/soldiermoth/pantui/internal/decrypt"
+	"github.com/soldiermoth/pantui/internal/fetch"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"github.com/soldiermoth/pantui/internal/mp4"
+	"github.com/soldiermoth/pantui/internal/ts"
+	"strings"
+)
+
+// Probe is the codecs found in the first segment of a media playlist
+type Probe struct {
+	Name     string
+	Playlist string
+	Segment  string
+	Codecs   []string
+	ADTS     bool // Audio was identified from ADTS headers, which can't signal HE-AAC
+	Err      error
+}
+
+// ProbePlaylist loads a media playlist and derives the codecs of its first segment
+func ProbePlaylist(ctx context.Context, name, playlistURL string) *Probe {
+	probe := &Probe{Name: name, Playlist: playlistURL}
+
+	manifest, err := loadPlaylist(ctx, playlistURL)
+	if err != nil {
+		probe.Err = err
+		return probe
+	}
+	if len(manifest.Segments) == 0 {
+		probe.Err = fmt.Errorf("playlist has no segments")
+		return probe
+	}
+
+	segment := &manifest.Segments[0]
+	probe.Segment = manifest.ResolveURL(segment.URI)
+	data, err := ReadSegment(ctx, manifest, segment)
+	if err != nil {
+		probe.Err = err
+		return probe
+	}
+
+	var init []byte
+	if segment.Map != nil && segment.Map.URI != "" {
+		initURL := manifest.ResolveURL(segment.Map.URI)
+		if init, err = fetch.ReadContext(ctx, initURL, segment.Map.ByteRange); err != nil {
+			probe.Err = fmt.Errorf("init fragment %s: %v", initURL, err)
+			return probe
+		}
+	}
+
+	probe.Codecs, probe.ADTS, probe.Err = SegmentCodecs(init, data)
+	return probe
+}
+
+// loadPlaylist parses a media playlist from a URL or a local file
+func loadPlaylist(ctx context.Context, target string) (*hls.Manifest, error) {
+	manifest, err := hls.NewParser().LoadContext(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Type != hls.MediaManifest {
+		return nil, fmt.Errorf("%s is not a media playlist", target)
+	}
+	return manifest, nil
+}
+
+// ReadSegment fetches a segment, decrypting it when it uses AES-128
+func ReadSegment(ctx context.Context, manifest *hls.Manifest, segment *hls.Segment) ([]byte, error) {
+	segmentURL := manifest.ResolveURL(segment.URI)
+	data, err := fetch.ReadContext(ctx, segmentURL, segment.ByteRange)
+	if err != nil {
+		return nil, fmt.Errorf("segment %s: %v", segmentURL, err)
+	}
+	if segment.Key == nil || segment.Key.Method != "AES-128" {
+		return data, nil
+	}
+
+	keyURL := manifest.ResolveURL(segment.Key.URI)
+	key, err := fetch.ReadContext(ctx, keyURL, "")
+	if err != nil {
+		return nil, fmt.Errorf("AES-128 key %s: %v", keyURL, err)
+	}
+	iv, err := decrypt.IV(segment.Key.IV, segment.Sequence)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt.AES128(data, key, iv)
+}
+
+// SegmentCodecs derives RFC 6381 codec strings from MPEG-TS, fMP4 or packed audio segments
+func SegmentCodecs(init, data []byte) ([]string, bool, error) {
+	if ts.IsTS(data) {
+		analysis, err := ts.Analyze(data)
+		if err != nil {
+			return nil, false, err
+		}
+		var found []string
+		adts := false
+		for _, stream := range analysis.Streams {
+			if stream.Kind != "video" && stream.Kind != "audio" {
+				continue
+			}
+			if stream.CodecString == "" {
+				return found, adts, fmt.Errorf("could not identify the %s codec on PID 0x%04x", stream.Codec, stream.PID)
+			}
+			found = append(found, stream.CodecString)
+			adts = adts || stream.StreamType == 0x0f || stream.StreamType == 0xcf
+		}
+		return found, adts, nil
+	}
+
+	if codec := packedAudioCodec(data); codec != "" {
+		return []string{codec}, strings.HasPrefix(codec, "mp4a"), nil
+	}
+
+	boxes, err := mp4.Parse(append(append([]byte(nil), init...), data...))
+	found := mp4.Codecs(boxes)
+	if len(found) == 0 {
+		if err != nil {
+			return nil, false, err
+		}
+		return nil, false, fmt.Errorf("no sample entries found; segment is neither MPEG-TS nor fMP4 with an init fragment")
+	}
+	return found, false, nil
+}
+
+// packedAudioCodec identifies packed audio segments: an optional ID3 tag followed by ADTS or AC-3 frames
+func packedAudioCodec(data []byte) string {
+	// ID3v2 tags carry the timestamp of packed audio, their size is syncsafe
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		if 10+size > len(data) {
+			return ""
+		}
+		data = data[10+size:]
+	}
+
+	switch {
+	case len(data) >= 3 && data[0] == 0xff && data[1]&0xf6 == 0xf0:
+		return fmt.Sprintf("mp4a.40.%d", data[2]>>6+1)
+	case len(data) >= 6 && data[0] == 0x0b && data[1] == 0x77:
+		// bsid above 10 is E-AC-3
+		if data[5]>>3 > 10 {
+			return "ec-3"
+		}
+		return "ac-3"
+	}
+	return ""
+}
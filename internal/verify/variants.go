@@ -0,0 +1,73 @@
+package verify
+
+import (
+	"context"
+	"github.com/soldiermoth/pantui/internal/hls"
+	"sync"
+)
+
+// VariantPlaylist is a variant of a master playlist with its media playlist loaded
+type VariantPlaylist struct {
+	Variant  *hls.Variant
+	URL      string
+	Manifest *hls.Manifest
+	Err      error
+}
+
+// Segment returns the segment with a media sequence number, nil if the playlist doesn't have it
+func (v *VariantPlaylist) Segment(sequence int) *hls.Segment {
+	if v.Manifest == nil {
+		return nil
+	}
+	return v.Manifest.SegmentBySequence(sequence)
+}
+
+// LoadVariants loads the media playlist of every variant in a master playlist
+func LoadVariants(ctx context.Context, master *hls.Manifest) []*VariantPlaylist {
+	playlists := make([]*VariantPlaylist, len(master.Variants))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i := range master.Variants {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			variant := &master.Variants[i]
+			playlist := &VariantPlaylist{Variant: variant, URL: master.ResolveURL(variant.URI)}
+			playlist.Manifest, playlist.Err = loadPlaylist(ctx, playlist.URL)
+			playlists[i] = playlist
+		}(i)
+	}
+	wg.Wait()
+	return playlists
+}
+
+// commonSequences lists the media sequence numbers every loaded playlist has, in order
+func commonSequences(playlists []*VariantPlaylist) []int {
+	counts := make(map[int]int)
+	loaded := 0
+	for _, playlist := range playlists {
+		if playlist.Err != nil {
+			continue
+		}
+		loaded++
+		for _, segment := range playlist.Manifest.Segments {
+			counts[segment.Sequence]++
+		}
+	}
+
+	var common []int
+	for _, playlist := range playlists {
+		if playlist.Err != nil {
+			continue
+		}
+		for _, segment := range playlist.Manifest.Segments {
+			if counts[segment.Sequence] == loaded {
+				common = append(common, segment.Sequence)
+			}
+		}
+		break
+	}
+	return common
+}